@@ -1,7 +1,6 @@
 package config
 
 import (
-	"calmind/model"
 	"fmt"
 	"os"
 
@@ -38,6 +37,7 @@ func InitDB() (*gorm.DB, error) {
 		configDB.Name,
 	)
 
+	// Foreign key dibuat eksplisit oleh migrasi (config/migration), bukan dari tag relasi model
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
 		DisableForeignKeyConstraintWhenMigrating: true,
 	})
@@ -46,37 +46,5 @@ func InitDB() (*gorm.DB, error) {
 	}
 	fmt.Println("Koneksi database berhasil.")
 
-	models := []interface{}{
-		&model.User{},
-		&model.Admin{},
-		&model.Doctor{},
-		&model.Otp{},
-		&model.Consultation{},
-		&model.Tags{},
-		&model.Artikel{},
-		&model.ChatLog{},
-		&model.Rekomendasi{},
-		&model.Chatbot{},
-	}
-
-	for _, model := range models {
-		if !db.Migrator().HasTable(model) {
-			if err := db.AutoMigrate(model); err != nil {
-				return nil, fmt.Errorf("gagal melakukan migrasi untuk model %T: %w", model, err)
-			}
-			fmt.Printf("Migrasi berhasil untuk model: %T\n", model)
-		} else {
-			fmt.Printf("Tabel untuk model %T sudah ada, tidak dilakukan migrasi.\n", model)
-		}
-	}
-
-	if err := SeedTitles(db); err != nil {
-		fmt.Println("Error seeding titles:", err)
-	}
-
-	if err := SeedSpecialties(db); err != nil {
-		fmt.Println("Error seeding specialties:", err)
-	}
-
 	return db, nil
 }
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// Konfigurasi penyedia model bahasa untuk chatbot
+type LLMConfig struct {
+	Provider    string // gemini, openai (server kompatibel OpenAI), atau fake
+	Model       string
+	Temperature float32
+	MaxTokens   int // 0 berarti mengikuti batas bawaan penyedia
+	BaseURL     string
+	APIKey      string
+}
+
+func NewLLMConfig() *LLMConfig {
+	cfg := &LLMConfig{
+		Provider: os.Getenv("LLM_PROVIDER"),
+		Model:    os.Getenv("LLM_MODEL"),
+		BaseURL:  os.Getenv("LLM_BASE_URL"),
+		APIKey:   os.Getenv("LLM_API_KEY"),
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = "gemini"
+	}
+	if cfg.APIKey == "" {
+		cfg.APIKey = os.Getenv("AI_API_KEY") // Nama variabel lama
+	}
+	if temperature, err := strconv.ParseFloat(os.Getenv("LLM_TEMPERATURE"), 32); err == nil {
+		cfg.Temperature = float32(temperature)
+	}
+	if maxTokens, err := strconv.Atoi(os.Getenv("LLM_MAX_TOKENS")); err == nil && maxTokens > 0 {
+		cfg.MaxTokens = maxTokens
+	}
+	return cfg
+}
@@ -0,0 +1,485 @@
+package migration
+
+import (
+	"calmind/model"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Salinan beku skema saat sistem migrasi diperkenalkan. Jangan disamakan dengan model terbaru: kolom dan tabel
+// yang ditambahkan sesudahnya menjadi tanggung jawab migrasi berikutnya, jadi baseline tidak boleh membuatnya.
+
+type baselineUser struct {
+	ID                int    `gorm:"primaryKey;autoIncrement"`
+	Username          string `gorm:"not null"`
+	NoHp              string `gorm:"not null"`
+	Email             string `gorm:"unique;not null"`
+	Password          string `gorm:"not null"`
+	Role              string `gorm:"not null"`
+	Avatar            string
+	Alamat            string
+	TglLahir          string
+	JenisKelamin      string `gorm:"type:enum('Laki-laki', 'Perempuan');default:'Laki-laki'"`
+	Pekerjaan         string
+	DeleteURL         string
+	IsVerified        bool `gorm:"default:false"`
+	SessionsRevokedAt *time.Time
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineUser) TableName() string { return "users" }
+
+type baselineAdmin struct {
+	ID                int    `gorm:"primaryKey;autoIncrement"`
+	Username          string `gorm:"not null"`
+	Avatar            string `gorm:"not null"`
+	Email             string `gorm:"unique;not null"`
+	Password          string `gorm:"not null"`
+	Role              string `gorm:"not null"`
+	SessionsRevokedAt *time.Time
+}
+
+func (baselineAdmin) TableName() string { return "admins" }
+
+type baselineTitle struct {
+	ID     int    `gorm:"primaryKey;autoIncrement"`
+	Name   string `gorm:"unique;not null"`
+	Gambar string `gorm:"not null"`
+}
+
+func (baselineTitle) TableName() string { return "titles" }
+
+type baselineTags struct {
+	ID     int    `gorm:"primaryKey;autoIncrement"`
+	Name   string `gorm:"unique;not null"`
+	Gambar string `gorm:"not null"`
+}
+
+func (baselineTags) TableName() string { return "tags" }
+
+type baselineDoctor struct {
+	ID                   int    `gorm:"primaryKey;autoIncrement"`
+	Username             string `gorm:"not null"`
+	NoHp                 string `gorm:"not null"`
+	Email                string `gorm:"unique;not null"`
+	Password             string `gorm:"not null"`
+	Role                 string `gorm:"not null"`
+	Avatar               string
+	DateOfBirth          string
+	Address              string
+	Schedule             string
+	IsVerified           bool `gorm:"default:false"`
+	IsActive             bool `gorm:"default:true"`
+	SessionsRevokedAt    *time.Time
+	Price                float64 `gorm:"default:100000"`
+	Experience           int
+	STRNumber            string
+	CredentialStatus     string `gorm:"type:varchar(20);default:'unsubmitted';index"`
+	CredentialNote       string
+	CredentialReviewedAt *time.Time
+	About                string
+	JenisKelamin         string `gorm:"type:enum('Laki-laki', 'Perempuan')"`
+	TitleID              int
+	DeleteURL            string
+	CreatedAt            time.Time `gorm:"autoCreateTime"`
+	UpdatedAt            time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineDoctor) TableName() string { return "doctors" }
+
+type baselineDoctorTag struct {
+	DoctorID int `gorm:"primaryKey;autoIncrement:false"`
+	TagsID   int `gorm:"primaryKey;autoIncrement:false"`
+}
+
+func (baselineDoctorTag) TableName() string { return "doctor_tags" }
+
+type baselineOtp struct {
+	ID        int `gorm:"primaryKey"`
+	Email     string
+	Code      string
+	Purpose   string `gorm:"type:varchar(30);default:'verification';index"`
+	Attempts  int    `gorm:"default:0"`
+	ExpiresAt time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineOtp) TableName() string { return "otps" }
+
+type baselineConsultation struct {
+	ID            int    `gorm:"primaryKey;autoIncrement"`
+	UserID        int    `gorm:"not null"`
+	DoctorID      int    `gorm:"not null"`
+	Title         string `gorm:"not null"`
+	Description   string `gorm:"type:text;not null"`
+	Duration      int    `gorm:"default:120"`
+	IsApproved    bool   `gorm:"default:false"`
+	PaymentStatus string `gorm:"type:varchar(20)"`
+	OrderID       string
+	Status        string `gorm:"type:varchar(20);default:'pending_payment';index"`
+	StartTime     time.Time
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineConsultation) TableName() string { return "consultations" }
+
+type baselineArtikel struct {
+	ID        int    `gorm:"primaryKey;autoIncrement"`
+	AdminID   int    `gorm:"not null"`
+	Judul     string `gorm:"not null"`
+	DeleteURL string
+	Gambar    string
+	Isi       string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineArtikel) TableName() string { return "artikels" }
+
+type baselineChatLog struct {
+	ID        int       `gorm:"primaryKey;autoIncrement"`
+	UserID    int       `gorm:"not null"`
+	SessionID int       `gorm:"index"`
+	Message   string    `gorm:"type:text;not null"`
+	Response  string    `gorm:"type:text;not null"`
+	RiskLevel string    `gorm:"type:varchar(10);default:'none'"`
+	Flagged   bool      `gorm:"default:false;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (baselineChatLog) TableName() string { return "chat_logs" }
+
+type baselineRekomendasi struct {
+	ID             int       `gorm:"primaryKey;autoIncrement"`
+	ConsultationID int       `gorm:"not null"`
+	DoctorID       int       `gorm:"not null"`
+	Rekomendasi    string    `gorm:"type:text;not null"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+func (baselineRekomendasi) TableName() string { return "rekomendasis" }
+
+type baselineChatbot struct {
+	ID             int `gorm:"primaryKey"`
+	UserID         int
+	ConsultationID int `gorm:"index"`
+	Message        string
+	Response       string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (baselineChatbot) TableName() string { return "chatbots" }
+
+type baselineDoctorSchedule struct {
+	ID           int       `gorm:"primaryKey;autoIncrement"`
+	DoctorID     int       `gorm:"not null;index"`
+	DayOfWeek    int       `gorm:"not null"`
+	StartTime    string    `gorm:"type:varchar(5);not null"`
+	EndTime      string    `gorm:"type:varchar(5);not null"`
+	SlotDuration int       `gorm:"not null;default:60"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineDoctorSchedule) TableName() string { return "doctor_schedules" }
+
+type baselineDoctorScheduleException struct {
+	ID           int    `gorm:"primaryKey;autoIncrement"`
+	DoctorID     int    `gorm:"not null;index"`
+	Date         string `gorm:"type:varchar(10);not null;index"`
+	IsAvailable  bool   `gorm:"default:false"`
+	StartTime    string `gorm:"type:varchar(5)"`
+	EndTime      string `gorm:"type:varchar(5)"`
+	SlotDuration int    `gorm:"default:60"`
+	Reason       string
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+func (baselineDoctorScheduleException) TableName() string { return "doctor_schedule_exceptions" }
+
+type baselinePaymentEvent struct {
+	ID                int     `gorm:"primaryKey;autoIncrement"`
+	TransactionID     string  `gorm:"type:varchar(64);index"`
+	OrderID           string  `gorm:"type:varchar(64);index"`
+	TransactionStatus string  `gorm:"type:varchar(32)"`
+	StatusCode        string  `gorm:"type:varchar(8)"`
+	GrossAmount       string  `gorm:"type:varchar(32)"`
+	FraudStatus       string  `gorm:"type:varchar(32)"`
+	PaymentType       string  `gorm:"type:varchar(32)"`
+	DedupKey          *string `gorm:"type:varchar(100);uniqueIndex"`
+	Outcome           string  `gorm:"type:varchar(32);index"`
+	Note              string
+	RawPayload        string    `gorm:"type:text"`
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+}
+
+func (baselinePaymentEvent) TableName() string { return "payment_events" }
+
+type baselineConsultationStatusHistory struct {
+	ID             int    `gorm:"primaryKey;autoIncrement"`
+	ConsultationID int    `gorm:"not null;index"`
+	FromStatus     string `gorm:"type:varchar(20)"`
+	ToStatus       string `gorm:"type:varchar(20);not null"`
+	ActorRole      string `gorm:"type:varchar(10);not null"`
+	ActorID        int
+	Note           string
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+func (baselineConsultationStatusHistory) TableName() string { return "consultation_status_histories" }
+
+type baselineScheduledJob struct {
+	ID          int       `gorm:"primaryKey;autoIncrement"`
+	Type        string    `gorm:"type:varchar(50);not null;index"`
+	UniqueKey   *string   `gorm:"type:varchar(100);uniqueIndex"`
+	Payload     string    `gorm:"type:text"`
+	Status      string    `gorm:"type:varchar(20);not null;default:'pending';index:idx_scheduled_jobs_due,priority:1"`
+	RunAt       time.Time `gorm:"not null;index:idx_scheduled_jobs_due,priority:2"`
+	Attempts    int       `gorm:"default:0"`
+	MaxAttempts int       `gorm:"default:5"`
+	LastError   string    `gorm:"type:text"`
+	LockedBy    string    `gorm:"type:varchar(100)"`
+	LockedAt    *time.Time
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineScheduledJob) TableName() string { return "scheduled_jobs" }
+
+type baselineConsultationMessage struct {
+	ID             int       `gorm:"primaryKey;autoIncrement"`
+	ConsultationID int       `gorm:"not null;index"`
+	SenderRole     string    `gorm:"type:varchar(10);not null"`
+	SenderID       int       `gorm:"not null"`
+	Content        string    `gorm:"type:text;not null"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+func (baselineConsultationMessage) TableName() string { return "consultation_messages" }
+
+type baselineRefund struct {
+	ID             int    `gorm:"primaryKey;autoIncrement"`
+	ConsultationID int    `gorm:"not null;index"`
+	OrderID        string `gorm:"type:varchar(64)"`
+	RefundKey      string `gorm:"type:varchar(100);uniqueIndex"`
+	Method         string `gorm:"type:varchar(10)"`
+	PaidAmount     float64
+	Percentage     int
+	Amount         float64
+	Reason         string `gorm:"type:text"`
+	RequestedBy    string `gorm:"type:varchar(10)"`
+	RequestedByID  int
+	Status         string    `gorm:"type:varchar(20);index"`
+	ErrorMessage   string    `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineRefund) TableName() string { return "refunds" }
+
+type baselineRefreshToken struct {
+	ID         int    `gorm:"primaryKey;autoIncrement"`
+	FamilyID   string `gorm:"type:varchar(64);not null;index"`
+	TokenHash  string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Role       string `gorm:"type:varchar(10);not null;index:idx_refresh_tokens_account"`
+	AccountID  int    `gorm:"not null;index:idx_refresh_tokens_account"`
+	Email      string
+	IsVerified bool
+	ExpiresAt  time.Time `gorm:"index"`
+	UsedAt     *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func (baselineRefreshToken) TableName() string { return "refresh_tokens" }
+
+type baselineRevokedToken struct {
+	ID        int    `gorm:"primaryKey;autoIncrement"`
+	JTI       string `gorm:"column:jti;type:varchar(64);not null;uniqueIndex"`
+	Role      string `gorm:"type:varchar(10)"`
+	AccountID int
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (baselineRevokedToken) TableName() string { return "revoked_tokens" }
+
+type baselineDoctorCredential struct {
+	ID        int    `gorm:"primaryKey;autoIncrement"`
+	DoctorID  int    `gorm:"not null;index"`
+	Type      string `gorm:"type:varchar(20);not null"`
+	FileName  string
+	FileURL   string `gorm:"not null"`
+	PublicID  string
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (baselineDoctorCredential) TableName() string { return "doctor_credentials" }
+
+type baselineDoctorCredentialReview struct {
+	ID        int `gorm:"primaryKey;autoIncrement"`
+	DoctorID  int `gorm:"not null;index"`
+	AdminID   int
+	Decision  string `gorm:"type:varchar(20);not null"`
+	Reason    string
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (baselineDoctorCredentialReview) TableName() string { return "doctor_credential_reviews" }
+
+type baselineChatSession struct {
+	ID                int    `gorm:"primaryKey;autoIncrement"`
+	UserID            int    `gorm:"not null;index"`
+	Title             string `gorm:"type:varchar(100);not null"`
+	Summary           string `gorm:"type:text"`
+	SummarizedUntilID int
+	LastMessageAt     *time.Time
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineChatSession) TableName() string { return "chat_sessions" }
+
+type baselineSafetyAlert struct {
+	ID         int `gorm:"primaryKey;autoIncrement"`
+	UserID     int `gorm:"not null;index"`
+	ChatLogID  int `gorm:"index"`
+	SessionID  int
+	RiskLevel  string `gorm:"type:varchar(10)"`
+	Source     string `gorm:"type:varchar(10)"`
+	FlaggedOn  string `gorm:"type:varchar(10)"`
+	Matches    string `gorm:"type:text"`
+	Message    string `gorm:"type:text"`
+	Status     string `gorm:"type:varchar(20);default:'open';index"`
+	HandledBy  *int
+	Note       string `gorm:"type:text"`
+	ResolvedAt *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineSafetyAlert) TableName() string { return "safety_alerts" }
+
+type baselineCustService struct {
+	ID              int    `gorm:"primaryKey;autoIncrement"`
+	UserID          int    `gorm:"index"`
+	Subject         string `gorm:"type:varchar(150)"`
+	Message         string `gorm:"type:text"`
+	Answer          string `gorm:"type:text"`
+	IsAnswered      bool
+	Status          string    `gorm:"type:varchar(20);default:'open';index"`
+	Priority        string    `gorm:"type:varchar(10);default:'normal';index"`
+	AssignedAdminID *int      `gorm:"index"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+	AnsweredAt      *time.Time
+}
+
+func (baselineCustService) TableName() string { return "cust_services" }
+
+type baselineCustServiceReply struct {
+	ID         int    `gorm:"primaryKey;autoIncrement"`
+	TicketID   int    `gorm:"not null;index"`
+	AuthorRole string `gorm:"type:varchar(10)"`
+	AuthorID   int
+	Message    string    `gorm:"type:text;not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func (baselineCustServiceReply) TableName() string { return "cust_service_replies" }
+
+type baselineFaqCategory struct {
+	ID        int    `gorm:"primaryKey;autoIncrement"`
+	Name      string `gorm:"type:varchar(100);unique;not null"`
+	Position  int
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineFaqCategory) TableName() string { return "faq_categories" }
+
+type baselineFaq struct {
+	ID         int    `gorm:"primaryKey;autoIncrement"`
+	CategoryID int    `gorm:"not null;index"`
+	Question   string `gorm:"type:varchar(255);not null"`
+	Answer     string `gorm:"type:text;not null"`
+	Keywords   string `gorm:"type:varchar(255)"`
+	Position   int
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineFaq) TableName() string { return "faqs" }
+
+// Tabel skema awal, urut mengikuti ketergantungan antartabel
+var baselineModels = []interface{}{
+	&baselineUser{},
+	&baselineAdmin{},
+	&baselineTitle{},
+	&baselineTags{},
+	&baselineDoctor{},
+	&baselineDoctorTag{},
+	&baselineOtp{},
+	&baselineConsultation{},
+	&baselineArtikel{},
+	&baselineChatLog{},
+	&baselineRekomendasi{},
+	&baselineChatbot{},
+	&baselineDoctorSchedule{},
+	&baselineDoctorScheduleException{},
+	&baselinePaymentEvent{},
+	&baselineConsultationStatusHistory{},
+	&baselineScheduledJob{},
+	&baselineConsultationMessage{},
+	&baselineRefund{},
+	&baselineRefreshToken{},
+	&baselineRevokedToken{},
+	&baselineDoctorCredential{},
+	&baselineDoctorCredentialReview{},
+	&baselineChatSession{},
+	&baselineSafetyAlert{},
+	&baselineCustService{},
+	&baselineCustServiceReply{},
+	&baselineFaqCategory{},
+	&baselineFaq{},
+}
+
+// Skema saat sistem migrasi diperkenalkan. Pada database lama, AutoMigrate menambahkan tabel dan kolom
+// yang terlewat karena InitDB sebelumnya hanya memigrasi tabel yang belum ada.
+var baselineSchema = Migration{
+	Version: "0001",
+	Name:    "baseline_schema",
+	Up: func(db *gorm.DB) error {
+		// Dokter yang terdaftar sebelum verifikasi kredensial tetap tampil di daftar dokter
+		grandfatherDoctors := db.Migrator().HasTable(&baselineDoctor{}) && !db.Migrator().HasColumn(&baselineDoctor{}, "CredentialStatus")
+
+		for _, m := range baselineModels {
+			if err := db.AutoMigrate(m); err != nil {
+				return fmt.Errorf("gagal memigrasi model %T: %w", m, err)
+			}
+		}
+
+		if grandfatherDoctors {
+			if err := db.Model(&baselineDoctor{}).Where("1 = 1").Update("credential_status", model.CredentialStatusApproved).Error; err != nil {
+				return fmt.Errorf("gagal menyetujui dokter lama: %w", err)
+			}
+		}
+
+		// Status "pending" lama disamakan dengan pending_payment pada state machine konsultasi
+		return db.Model(&baselineConsultation{}).Where("status = ?", "pending").Update("status", "pending_payment").Error
+	},
+	Down: func(db *gorm.DB) error {
+		for i := len(baselineModels) - 1; i >= 0; i-- {
+			if err := db.Migrator().DropTable(baselineModels[i]); err != nil {
+				return fmt.Errorf("gagal menghapus tabel %T: %w", baselineModels[i], err)
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,113 @@
+package migration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type foreignKey struct {
+	Name     string
+	Table    string
+	Column   string
+	RefTable string
+	OnDelete string
+}
+
+// Foreign key dibuat eksplisit karena AutoMigrate dijalankan dengan DisableForeignKeyConstraintWhenMigrating.
+// Relasi belongs-to seperti doctors.title_id dan artikels.admin_id sengaja tidak diberi constraint karena nilai 0 masih dipakai.
+// Konsultasi menyimpan riwayat pembayaran, jadi user dan dokter yang masih punya konsultasi tidak boleh dihapus.
+var foreignKeyDefinitions = []foreignKey{
+	{"fk_consultations_user", "consultations", "user_id", "users", "RESTRICT"},
+	{"fk_consultations_doctor", "consultations", "doctor_id", "doctors", "RESTRICT"},
+	{"fk_rekomendasis_consultation", "rekomendasis", "consultation_id", "consultations", "CASCADE"},
+	{"fk_doctor_tags_doctor", "doctor_tags", "doctor_id", "doctors", "CASCADE"},
+	{"fk_doctor_tags_tag", "doctor_tags", "tags_id", "tags", "CASCADE"},
+	{"fk_doctor_credentials_doctor", "doctor_credentials", "doctor_id", "doctors", "CASCADE"},
+	{"fk_cust_service_replies_ticket", "cust_service_replies", "ticket_id", "cust_services", "CASCADE"},
+	{"fk_faqs_category", "faqs", "category_id", "faq_categories", "RESTRICT"},
+}
+
+var foreignKeys = Migration{
+	Version: "0002",
+	Name:    "foreign_keys",
+	Up: func(db *gorm.DB) error {
+		for _, fk := range foreignKeyDefinitions {
+			if err := createForeignKey(db, fk); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(db *gorm.DB) error {
+		for i := len(foreignKeyDefinitions) - 1; i >= 0; i-- {
+			if err := dropForeignKey(db, foreignKeyDefinitions[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// Membuat foreign key jika belum ada. Baris yatim dari masa tanpa constraint tidak dihapus otomatis: migrasi gagal
+// dan menampilkan barisnya agar bisa diperiksa dan diperbaiki manual sebelum dijalankan ulang.
+func createForeignKey(db *gorm.DB, fk foreignKey) error {
+	if db.Migrator().HasConstraint(fk.Table, fk.Name) {
+		return nil
+	}
+
+	if err := checkOrphans(db, fk); err != nil {
+		return err
+	}
+
+	sql := fmt.Sprintf("ALTER TABLE `%s` ADD CONSTRAINT `%s` FOREIGN KEY (`%s`) REFERENCES `%s` (`id`) ON DELETE %s ON UPDATE CASCADE",
+		fk.Table, fk.Name, fk.Column, fk.RefTable, fk.OnDelete)
+	if err := db.Exec(sql).Error; err != nil {
+		return fmt.Errorf("gagal membuat foreign key %s: %w", fk.Name, err)
+	}
+	return nil
+}
+
+// Jumlah nilai yatim yang ditampilkan di pesan error
+const maxReportedOrphans = 20
+
+func checkOrphans(db *gorm.DB, fk foreignKey) error {
+	orphaned := fmt.Sprintf("FROM `%s` WHERE `%s` IS NOT NULL AND `%s` NOT IN (SELECT id FROM `%s`)", fk.Table, fk.Column, fk.Column, fk.RefTable)
+
+	var count int64
+	if err := db.Raw("SELECT COUNT(*) " + orphaned).Scan(&count).Error; err != nil {
+		return fmt.Errorf("gagal memeriksa %s.%s: %w", fk.Table, fk.Column, err)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	var values []int
+	err := db.Raw(fmt.Sprintf("SELECT DISTINCT `%s` %s ORDER BY `%s` LIMIT %d", fk.Column, orphaned, fk.Column, maxReportedOrphans)).
+		Scan(&values).Error
+	if err != nil {
+		return fmt.Errorf("gagal membaca baris yatim %s.%s: %w", fk.Table, fk.Column, err)
+	}
+	return fmt.Errorf("gagal membuat foreign key %s: %d baris %s memiliki %s yang tidak ada di %s (%s: %s), perbaiki atau hapus baris tersebut lalu jalankan ulang migrasi",
+		fk.Name, count, fk.Table, fk.Column, fk.RefTable, fk.Column, joinInts(values))
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.Itoa(value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func dropForeignKey(db *gorm.DB, fk foreignKey) error {
+	if !db.Migrator().HasConstraint(fk.Table, fk.Name) {
+		return nil
+	}
+	if err := db.Exec(fmt.Sprintf("ALTER TABLE `%s` DROP FOREIGN KEY `%s`", fk.Table, fk.Name)).Error; err != nil {
+		return fmt.Errorf("gagal menghapus foreign key %s: %w", fk.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package migration
+
+import "gorm.io/gorm"
+
+// Data referensi awal. Setiap seed memeriksa data yang sudah ada, jadi aman untuk database lama.
+var seedReferenceData = Migration{
+	Version: "0003",
+	Name:    "seed_reference_data",
+	Up: func(db *gorm.DB) error {
+		if err := SeedTitles(db); err != nil {
+			return err
+		}
+		if err := SeedSpecialties(db); err != nil {
+			return err
+		}
+		return SeedFaqs(db)
+	},
+	// Data referensi tidak dihapus karena mungkin sudah dipakai dokter dan diubah admin
+	Down: func(db *gorm.DB) error {
+		return nil
+	},
+}
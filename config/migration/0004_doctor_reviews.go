@@ -0,0 +1,78 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Salinan beku skema versi 0004, lihat catatan pada baseline 0001
+
+type reviewDoctorReview struct {
+	ID             int    `gorm:"primaryKey;autoIncrement"`
+	ConsultationID int    `gorm:"not null;uniqueIndex"`
+	UserID         int    `gorm:"not null;index"`
+	DoctorID       int    `gorm:"not null;index"`
+	Rating         int    `gorm:"not null"`
+	Comment        string `gorm:"type:text"`
+	Reply          string `gorm:"type:text"`
+	RepliedAt      *time.Time
+	Status         string `gorm:"type:varchar(20);default:'visible';index"`
+	ModeratedBy    *int
+	ModerationNote string    `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+func (reviewDoctorReview) TableName() string { return "doctor_reviews" }
+
+type reviewDoctor struct {
+	ID            int     `gorm:"primaryKey;autoIncrement"`
+	RatingAverage float64 `gorm:"default:0"`
+	RatingCount   int     `gorm:"default:0"`
+}
+
+func (reviewDoctor) TableName() string { return "doctors" }
+
+var reviewForeignKeys = []foreignKey{
+	{"fk_doctor_reviews_consultation", "doctor_reviews", "consultation_id", "consultations", "CASCADE"},
+	{"fk_doctor_reviews_user", "doctor_reviews", "user_id", "users", "CASCADE"},
+	{"fk_doctor_reviews_doctor", "doctor_reviews", "doctor_id", "doctors", "CASCADE"},
+}
+
+// Tabel ulasan dokter dan ringkasan rating di tabel doctors
+var doctorReviews = Migration{
+	Version: "0004",
+	Name:    "doctor_reviews",
+	Up: func(db *gorm.DB) error {
+		if err := db.AutoMigrate(&reviewDoctorReview{}); err != nil {
+			return fmt.Errorf("gagal membuat tabel doctor_reviews: %w", err)
+		}
+		for _, column := range []string{"RatingAverage", "RatingCount"} {
+			if db.Migrator().HasColumn(&reviewDoctor{}, column) {
+				continue
+			}
+			if err := db.Migrator().AddColumn(&reviewDoctor{}, column); err != nil {
+				return fmt.Errorf("gagal menambah kolom doctors.%s: %w", column, err)
+			}
+		}
+		for _, fk := range reviewForeignKeys {
+			if err := createForeignKey(db, fk); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(db *gorm.DB) error {
+		for _, column := range []string{"RatingAverage", "RatingCount"} {
+			if !db.Migrator().HasColumn(&reviewDoctor{}, column) {
+				continue
+			}
+			if err := db.Migrator().DropColumn(&reviewDoctor{}, column); err != nil {
+				return fmt.Errorf("gagal menghapus kolom doctors.%s: %w", column, err)
+			}
+		}
+		return db.Migrator().DropTable(&reviewDoctorReview{})
+	},
+}
@@ -0,0 +1,36 @@
+package migration
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Salinan beku skema versi 0005, lihat catatan pada baseline 0001
+type thumbnailArtikel struct {
+	ID              int `gorm:"primaryKey;autoIncrement"`
+	GambarThumbnail string
+}
+
+func (thumbnailArtikel) TableName() string { return "artikels" }
+
+// Thumbnail gambar artikel yang dihasilkan pipeline upload
+var artikelThumbnail = Migration{
+	Version: "0005",
+	Name:    "artikel_thumbnail",
+	Up: func(db *gorm.DB) error {
+		if db.Migrator().HasColumn(&thumbnailArtikel{}, "GambarThumbnail") {
+			return nil
+		}
+		if err := db.Migrator().AddColumn(&thumbnailArtikel{}, "GambarThumbnail"); err != nil {
+			return fmt.Errorf("gagal menambah kolom artikels.gambar_thumbnail: %w", err)
+		}
+		return nil
+	},
+	Down: func(db *gorm.DB) error {
+		if !db.Migrator().HasColumn(&thumbnailArtikel{}, "GambarThumbnail") {
+			return nil
+		}
+		return db.Migrator().DropColumn(&thumbnailArtikel{}, "GambarThumbnail")
+	},
+}
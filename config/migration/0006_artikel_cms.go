@@ -0,0 +1,161 @@
+package migration
+
+import (
+	"calmind/helper"
+	"calmind/model"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Salinan beku skema versi 0006, lihat catatan pada baseline 0001
+
+type cmsArtikelCategory struct {
+	ID        int       `gorm:"primaryKey;autoIncrement"`
+	Name      string    `gorm:"type:varchar(100);uniqueIndex;not null"`
+	Slug      string    `gorm:"type:varchar(120);uniqueIndex;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (cmsArtikelCategory) TableName() string { return "artikel_categories" }
+
+type cmsArtikel struct {
+	ID             int `gorm:"primaryKey;autoIncrement"`
+	Judul          string
+	Isi            string
+	Slug           string     `gorm:"type:varchar(191);uniqueIndex"`
+	Ringkasan      string     `gorm:"type:text"`
+	Status         string     `gorm:"type:varchar(20);not null;default:'draft';index"`
+	PublishedAt    *time.Time `gorm:"index"`
+	CategoryID     *int       `gorm:"index"`
+	ReadingMinutes int        `gorm:"not null;default:0"`
+}
+
+func (cmsArtikel) TableName() string { return "artikels" }
+
+type cmsArtikelTag struct {
+	ArtikelID int `gorm:"primaryKey;autoIncrement:false"`
+	TagsID    int `gorm:"primaryKey;autoIncrement:false"`
+}
+
+func (cmsArtikelTag) TableName() string { return "artikel_tags" }
+
+var artikelCmsColumns = []string{"Slug", "Ringkasan", "Status", "PublishedAt", "CategoryID", "ReadingMinutes"}
+
+var artikelCmsIndexes = []string{"Slug", "Status", "PublishedAt", "CategoryID"}
+
+var artikelCmsForeignKeys = []foreignKey{
+	{"fk_artikels_category", "artikels", "category_id", "artikel_categories", "SET NULL"},
+	{"fk_artikel_tags_artikel", "artikel_tags", "artikel_id", "artikels", "CASCADE"},
+	{"fk_artikel_tags_tags", "artikel_tags", "tags_id", "tags", "CASCADE"},
+}
+
+// Status terbit, jadwal tayang, slug, ringkasan, kategori, tag, dan waktu baca artikel
+var artikelCms = Migration{
+	Version: "0006",
+	Name:    "artikel_cms",
+	Up: func(db *gorm.DB) error {
+		if err := db.AutoMigrate(&cmsArtikelCategory{}); err != nil {
+			return fmt.Errorf("gagal membuat tabel artikel_categories: %w", err)
+		}
+
+		// Kolom ditambahkan tanpa index dulu agar slug bisa diisi sebelum index unik dibuat
+		for _, column := range artikelCmsColumns {
+			if db.Migrator().HasColumn(&cmsArtikel{}, column) {
+				continue
+			}
+			if err := db.Migrator().AddColumn(&cmsArtikel{}, column); err != nil {
+				return fmt.Errorf("gagal menambah kolom artikels.%s: %w", column, err)
+			}
+		}
+
+		// Artikel lama sebelumnya langsung tampil, jadi dianggap sudah terbit sejak dibuat. Artikel lama dikenali
+		// dari datanya (belum punya slug atau waktu tayang), bukan dari kolomnya, agar migrasi yang gagal di tengah
+		// jalan bisa dijalankan ulang.
+		err := db.Exec("UPDATE artikels SET status = ?, published_at = created_at WHERE slug IS NULL OR slug = '' OR published_at IS NULL",
+			model.ArtikelStatusPublished).Error
+		if err != nil {
+			return fmt.Errorf("gagal mengisi status artikel lama: %w", err)
+		}
+		if err := backfillArtikels(db); err != nil {
+			return err
+		}
+
+		for _, index := range artikelCmsIndexes {
+			if db.Migrator().HasIndex(&cmsArtikel{}, index) {
+				continue
+			}
+			if err := db.Migrator().CreateIndex(&cmsArtikel{}, index); err != nil {
+				return fmt.Errorf("gagal membuat index artikels.%s: %w", index, err)
+			}
+		}
+		if err := db.AutoMigrate(&cmsArtikelTag{}); err != nil {
+			return fmt.Errorf("gagal membuat tabel artikel_tags: %w", err)
+		}
+		for _, fk := range artikelCmsForeignKeys {
+			if err := createForeignKey(db, fk); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(db *gorm.DB) error {
+		for i := len(artikelCmsForeignKeys) - 1; i >= 0; i-- {
+			if err := dropForeignKey(db, artikelCmsForeignKeys[i]); err != nil {
+				return err
+			}
+		}
+		if err := db.Migrator().DropTable(&cmsArtikelTag{}); err != nil {
+			return fmt.Errorf("gagal menghapus tabel artikel_tags: %w", err)
+		}
+		for _, column := range artikelCmsColumns {
+			if !db.Migrator().HasColumn(&cmsArtikel{}, column) {
+				continue
+			}
+			if err := db.Migrator().DropColumn(&cmsArtikel{}, column); err != nil {
+				return fmt.Errorf("gagal menghapus kolom artikels.%s: %w", column, err)
+			}
+		}
+		return db.Migrator().DropTable(&cmsArtikelCategory{})
+	},
+}
+
+// Mengisi slug unik, ringkasan, dan waktu baca untuk artikel yang belum memilikinya
+func backfillArtikels(db *gorm.DB) error {
+	var artikels []cmsArtikel
+	if err := db.Select("id", "judul", "isi", "slug", "ringkasan").Order("id").Find(&artikels).Error; err != nil {
+		return fmt.Errorf("gagal membaca artikel: %w", err)
+	}
+
+	used := map[string]bool{}
+	for _, artikel := range artikels {
+		if artikel.Slug != "" {
+			used[artikel.Slug] = true
+		}
+	}
+
+	for _, artikel := range artikels {
+		updates := map[string]interface{}{"reading_minutes": helper.ReadingMinutes(artikel.Isi)}
+		if artikel.Ringkasan == "" {
+			updates["ringkasan"] = helper.Summarize(artikel.Isi, 160)
+		}
+		if artikel.Slug == "" {
+			base := helper.Slugify(artikel.Judul)
+			if base == "" {
+				base = "artikel"
+			}
+			slug := base
+			for n := 2; used[slug]; n++ {
+				slug = fmt.Sprintf("%s-%d", base, n)
+			}
+			used[slug] = true
+			updates["slug"] = slug
+		}
+		if err := db.Model(&cmsArtikel{}).Where("id = ?", artikel.ID).UpdateColumns(updates).Error; err != nil {
+			return fmt.Errorf("gagal mengisi data artikel %d: %w", artikel.ID, err)
+		}
+	}
+	return nil
+}
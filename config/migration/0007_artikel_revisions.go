@@ -0,0 +1,119 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Salinan beku skema versi 0007, lihat catatan pada baseline 0001
+
+type revisionArtikelRevision struct {
+	ID              int    `gorm:"primaryKey;autoIncrement"`
+	ArtikelID       int    `gorm:"not null;uniqueIndex:idx_artikel_revisions_number"`
+	Number          int    `gorm:"not null;uniqueIndex:idx_artikel_revisions_number"`
+	AdminID         int    `gorm:"not null;index"`
+	Note            string `gorm:"type:varchar(255)"`
+	Judul           string `gorm:"not null"`
+	Slug            string `gorm:"type:varchar(191)"`
+	Ringkasan       string `gorm:"type:text"`
+	Isi             string `gorm:"type:longtext;not null"`
+	Gambar          string
+	GambarThumbnail string
+	Status          string `gorm:"type:varchar(20);not null"`
+	PublishedAt     *time.Time
+	CategoryID      *int
+	TagNames        []string  `gorm:"type:text;serializer:json"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+}
+
+func (revisionArtikelRevision) TableName() string { return "artikel_revisions" }
+
+type revisionArtikel struct {
+	ID              int
+	AdminID         int
+	Judul           string
+	Slug            string
+	Ringkasan       string
+	Isi             string
+	Gambar          string
+	GambarThumbnail string
+	Status          string
+	PublishedAt     *time.Time
+	CategoryID      *int
+	Tags            []revisionTag `gorm:"many2many:artikel_tags;joinForeignKey:ArtikelID;joinReferences:TagsID"`
+	UpdatedAt       time.Time
+}
+
+func (revisionArtikel) TableName() string { return "artikels" }
+
+type revisionTag struct {
+	ID   int
+	Name string
+}
+
+func (revisionTag) TableName() string { return "tags" }
+
+var revisionForeignKeys = []foreignKey{
+	{"fk_artikel_revisions_artikel", "artikel_revisions", "artikel_id", "artikels", "CASCADE"},
+}
+
+// Riwayat revisi artikel. Artikel yang sudah ada mendapat revisi awal agar suntingan pertamanya bisa dibatalkan.
+var artikelRevisions = Migration{
+	Version: "0007",
+	Name:    "artikel_revisions",
+	Up: func(db *gorm.DB) error {
+		if err := db.AutoMigrate(&revisionArtikelRevision{}); err != nil {
+			return fmt.Errorf("gagal membuat tabel artikel_revisions: %w", err)
+		}
+		for _, fk := range revisionForeignKeys {
+			if err := createForeignKey(db, fk); err != nil {
+				return err
+			}
+		}
+
+		var artikels []revisionArtikel
+		err := db.Preload("Tags").
+			Where("id NOT IN (?)", db.Model(&revisionArtikelRevision{}).Select("artikel_id")).
+			Order("id").Find(&artikels).Error
+		if err != nil {
+			return fmt.Errorf("gagal membaca artikel: %w", err)
+		}
+		for _, artikel := range artikels {
+			tagNames := make([]string, 0, len(artikel.Tags))
+			for _, tag := range artikel.Tags {
+				tagNames = append(tagNames, tag.Name)
+			}
+			revision := revisionArtikelRevision{
+				ArtikelID:       artikel.ID,
+				Number:          1,
+				AdminID:         artikel.AdminID,
+				Note:            "Revisi awal",
+				Judul:           artikel.Judul,
+				Slug:            artikel.Slug,
+				Ringkasan:       artikel.Ringkasan,
+				Isi:             artikel.Isi,
+				Gambar:          artikel.Gambar,
+				GambarThumbnail: artikel.GambarThumbnail,
+				Status:          artikel.Status,
+				PublishedAt:     artikel.PublishedAt,
+				CategoryID:      artikel.CategoryID,
+				TagNames:        tagNames,
+				CreatedAt:       artikel.UpdatedAt,
+			}
+			if err := db.Create(&revision).Error; err != nil {
+				return fmt.Errorf("gagal membuat revisi awal artikel %d: %w", artikel.ID, err)
+			}
+		}
+		return nil
+	},
+	Down: func(db *gorm.DB) error {
+		for _, fk := range revisionForeignKeys {
+			if err := dropForeignKey(db, fk); err != nil {
+				return err
+			}
+		}
+		return db.Migrator().DropTable(&revisionArtikelRevision{})
+	},
+}
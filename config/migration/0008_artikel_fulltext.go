@@ -0,0 +1,83 @@
+package migration
+
+import (
+	"calmind/helper"
+	"calmind/service/textsearch"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Salinan beku skema versi 0008, lihat catatan pada baseline 0001
+type searchArtikel struct {
+	ID          int `gorm:"primaryKey;autoIncrement"`
+	Judul       string
+	Isi         string
+	SearchJudul string `gorm:"type:text;index:idx_artikels_search_judul,class:FULLTEXT;index:idx_artikels_search,class:FULLTEXT,priority:1"`
+	SearchIsi   string `gorm:"type:longtext;index:idx_artikels_search,class:FULLTEXT,priority:2"`
+}
+
+func (searchArtikel) TableName() string { return "artikels" }
+
+var artikelSearchColumns = []string{"SearchJudul", "SearchIsi"}
+
+var artikelSearchIndexes = []string{"idx_artikels_search_judul", "idx_artikels_search"}
+
+// Kolom kata dasar judul dan isi artikel beserta indeks FULLTEXT untuk pencarian
+var artikelFulltext = Migration{
+	Version: "0008",
+	Name:    "artikel_fulltext",
+	Up: func(db *gorm.DB) error {
+		for _, column := range artikelSearchColumns {
+			if db.Migrator().HasColumn(&searchArtikel{}, column) {
+				continue
+			}
+			if err := db.Migrator().AddColumn(&searchArtikel{}, column); err != nil {
+				return fmt.Errorf("gagal menambah kolom artikels.%s: %w", column, err)
+			}
+		}
+
+		var artikels []searchArtikel
+		if err := db.Select("id", "judul", "isi").Order("id").Find(&artikels).Error; err != nil {
+			return fmt.Errorf("gagal membaca artikel: %w", err)
+		}
+		for _, artikel := range artikels {
+			err := db.Model(&searchArtikel{}).Where("id = ?", artikel.ID).UpdateColumns(map[string]interface{}{
+				"search_judul": textsearch.Normalize(artikel.Judul),
+				"search_isi":   textsearch.Normalize(helper.PlainText(artikel.Isi)),
+			}).Error
+			if err != nil {
+				return fmt.Errorf("gagal mengisi kolom pencarian artikel %d: %w", artikel.ID, err)
+			}
+		}
+
+		for _, index := range artikelSearchIndexes {
+			if db.Migrator().HasIndex(&searchArtikel{}, index) {
+				continue
+			}
+			if err := db.Migrator().CreateIndex(&searchArtikel{}, index); err != nil {
+				return fmt.Errorf("gagal membuat index %s: %w", index, err)
+			}
+		}
+		return nil
+	},
+	Down: func(db *gorm.DB) error {
+		for _, index := range artikelSearchIndexes {
+			if !db.Migrator().HasIndex(&searchArtikel{}, index) {
+				continue
+			}
+			if err := db.Migrator().DropIndex(&searchArtikel{}, index); err != nil {
+				return fmt.Errorf("gagal menghapus index %s: %w", index, err)
+			}
+		}
+		for _, column := range artikelSearchColumns {
+			if !db.Migrator().HasColumn(&searchArtikel{}, column) {
+				continue
+			}
+			if err := db.Migrator().DropColumn(&searchArtikel{}, column); err != nil {
+				return fmt.Errorf("gagal menghapus kolom artikels.%s: %w", column, err)
+			}
+		}
+		return nil
+	},
+}
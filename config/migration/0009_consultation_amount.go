@@ -0,0 +1,42 @@
+package migration
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Salinan beku skema versi 0009, lihat catatan pada baseline 0001
+type amountConsultation struct {
+	ID     int     `gorm:"primaryKey;autoIncrement"`
+	Amount float64 `gorm:"not null;default:0"`
+}
+
+func (amountConsultation) TableName() string { return "consultations" }
+
+// Nominal yang ditagihkan saat konsultasi dipesan, terlepas dari perubahan harga dokter setelahnya
+var consultationAmount = Migration{
+	Version: "0009",
+	Name:    "consultation_amount",
+	Up: func(db *gorm.DB) error {
+		if !db.Migrator().HasColumn(&amountConsultation{}, "Amount") {
+			if err := db.Migrator().AddColumn(&amountConsultation{}, "Amount"); err != nil {
+				return fmt.Errorf("gagal menambah kolom consultations.amount: %w", err)
+			}
+		}
+
+		// Konsultasi lama ditagih dengan harga dokter, nilai terbaik yang masih tersedia
+		err := db.Exec("UPDATE consultations JOIN doctors ON doctors.id = consultations.doctor_id " +
+			"SET consultations.amount = doctors.price WHERE consultations.amount = 0").Error
+		if err != nil {
+			return fmt.Errorf("gagal mengisi nominal konsultasi lama: %w", err)
+		}
+		return nil
+	},
+	Down: func(db *gorm.DB) error {
+		if !db.Migrator().HasColumn(&amountConsultation{}, "Amount") {
+			return nil
+		}
+		return db.Migrator().DropColumn(&amountConsultation{}, "Amount")
+	},
+}
@@ -0,0 +1,216 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Nama lock MySQL agar dua instance yang start bersamaan tidak menjalankan migrasi yang sama
+const lockName = "calmind_schema_migrations"
+
+// Lama menunggu lock sebelum menyerah (detik)
+const lockTimeout = 60
+
+var ErrLockTimeout = errors.New("gagal mendapatkan lock migrasi, kemungkinan migrasi lain sedang berjalan")
+
+// Satu langkah perubahan skema. Version menentukan urutan dan tidak boleh diubah setelah dirilis.
+// DDL MySQL tidak transaksional, jadi Up dan Down sebaiknya aman dijalankan ulang (periksa HasTable/HasColumn).
+type Migration struct {
+	Version string
+	Name    string
+	Up      func(db *gorm.DB) error
+	Down    func(db *gorm.DB) error
+}
+
+// Catatan migrasi yang sudah dijalankan
+type SchemaMigration struct {
+	Version   string    `gorm:"primaryKey;type:varchar(20)"`
+	Name      string    `gorm:"type:varchar(150);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Status struct {
+	Version   string
+	Name      string
+	AppliedAt *time.Time // nil berarti belum dijalankan
+}
+
+// Daftar migrasi urut berdasarkan versi. Perubahan skema baru ditambahkan di akhir dengan versi berikutnya.
+var migrations = []Migration{
+	baselineSchema,
+	foreignKeys,
+	seedReferenceData,
+	doctorReviews,
+	artikelThumbnail,
+	artikelCms,
+	artikelRevisions,
+	artikelFulltext,
+	consultationAmount,
+}
+
+// Menjalankan semua migrasi yang belum dijalankan, mengembalikan versi yang berhasil diterapkan
+func Up(db *gorm.DB) ([]string, error) {
+	var applied []string
+	err := withLock(db, func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if _, ok := done[m.Version]; ok {
+				continue
+			}
+			if err := m.Up(conn); err != nil {
+				return fmt.Errorf("migrasi %s_%s gagal: %w", m.Version, m.Name, err)
+			}
+			record := SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}
+			if err := conn.Create(&record).Error; err != nil {
+				return fmt.Errorf("gagal mencatat migrasi %s: %w", m.Version, err)
+			}
+			applied = append(applied, m.Version)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Membatalkan sejumlah migrasi terakhir yang sudah dijalankan, dari yang terbaru
+func Down(db *gorm.DB, steps int) ([]string, error) {
+	var rolledBack []string
+	err := withLock(db, func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			m := migrations[i]
+			if _, ok := done[m.Version]; !ok {
+				continue
+			}
+			if err := m.Down(conn); err != nil {
+				return fmt.Errorf("rollback %s_%s gagal: %w", m.Version, m.Name, err)
+			}
+			if err := conn.Delete(&SchemaMigration{}, "version = ?", m.Version).Error; err != nil {
+				return fmt.Errorf("gagal menghapus catatan migrasi %s: %w", m.Version, err)
+			}
+			rolledBack = append(rolledBack, m.Version)
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+func GetStatus(db *gorm.DB) ([]Status, error) {
+	done, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		status := Status{Version: m.Version, Name: m.Name}
+		if appliedAt, ok := done[m.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Subcommand "migrate up|down [jumlah]|status"
+func Run(db *gorm.DB, args []string, out io.Writer) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := Up(db)
+		for _, version := range applied {
+			fmt.Fprintf(out, "Migrasi %s diterapkan.\n", version)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(out, "Skema sudah terbaru.")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("jumlah langkah rollback tidak valid: %s", args[1])
+			}
+			steps = n
+		}
+		rolledBack, err := Down(db, steps)
+		for _, version := range rolledBack {
+			fmt.Fprintf(out, "Migrasi %s dibatalkan.\n", version)
+		}
+		if err == nil && len(rolledBack) == 0 {
+			fmt.Fprintln(out, "Tidak ada migrasi yang bisa dibatalkan.")
+		}
+		return err
+	case "status":
+		statuses, err := GetStatus(db)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%s  %-28s %s\n", status.Version, status.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("perintah migrate tidak dikenal: %s (gunakan up, down [jumlah], atau status)", command)
+	}
+}
+
+func appliedVersions(db *gorm.DB) (map[string]time.Time, error) {
+	done := map[string]time.Time{}
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return done, nil
+	}
+
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("gagal membaca schema_migrations: %w", err)
+	}
+	for _, record := range records {
+		done[record.Version] = record.AppliedAt
+	}
+	return done, nil
+}
+
+// GET_LOCK terikat pada satu koneksi, jadi seluruh migrasi dijalankan di koneksi yang sama
+func withLock(db *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		var acquired int
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if acquired != 1 {
+			return ErrLockTimeout
+		}
+		defer conn.Exec("SELECT RELEASE_LOCK(?)", lockName)
+
+		if err := conn.AutoMigrate(&SchemaMigration{}); err != nil {
+			return fmt.Errorf("gagal menyiapkan tabel schema_migrations: %w", err)
+		}
+		return fn(conn)
+	})
+}
@@ -0,0 +1,79 @@
+package migration
+
+import (
+	"bytes"
+	"calmind/model"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm/schema"
+)
+
+func TestMigrationsAreOrdered(t *testing.T) {
+	seen := map[string]bool{}
+	previous := ""
+	for _, m := range migrations {
+		assert.NotEmpty(t, m.Name, m.Version)
+		assert.NotNil(t, m.Up, m.Version)
+		assert.NotNil(t, m.Down, m.Version)
+		assert.False(t, seen[m.Version], "versi %s ganda", m.Version)
+		assert.Greater(t, m.Version, previous, "versi %s tidak berurutan", m.Version)
+		seen[m.Version] = true
+		previous = m.Version
+	}
+}
+
+func TestRun_InvalidArguments(t *testing.T) {
+	var out bytes.Buffer
+
+	assert.ErrorContains(t, Run(nil, []string{"reset"}, &out), "tidak dikenal")
+	assert.ErrorContains(t, Run(nil, []string{"down", "0"}, &out), "tidak valid")
+	assert.ErrorContains(t, Run(nil, []string{"down", "semua"}, &out), "tidak valid")
+}
+
+// Kolom dan tabel yang ditambahkan migrasi sesudah baseline tidak boleh ikut dibuat oleh 0001,
+// jika tidak migrasi tersebut menjadi no-op pada database lama
+func TestBaselineExcludesLaterSchema(t *testing.T) {
+	columns := map[string]bool{}
+	tables := map[string]bool{}
+	for _, m := range baselineModels {
+		parsed, err := schema.Parse(m, &sync.Map{}, schema.NamingStrategy{})
+		assert.NoError(t, err)
+		tables[parsed.Table] = true
+		for _, field := range parsed.Fields {
+			if field.DBName != "" {
+				columns[parsed.Table+"."+field.DBName] = true
+			}
+		}
+	}
+
+	assert.True(t, columns["doctors.credential_status"])
+	assert.True(t, tables["doctor_tags"])
+	for _, column := range []string{
+		"doctors.rating_average", "doctors.rating_count", "consultations.amount", "artikels.gambar_thumbnail",
+		"artikels.slug", "artikels.status", "artikels.published_at", "artikels.category_id", "artikels.search_isi",
+	} {
+		assert.False(t, columns[column], column)
+	}
+	for _, table := range []string{"doctor_reviews", "artikel_categories", "artikel_tags", "artikel_revisions"} {
+		assert.False(t, tables[table], table)
+	}
+}
+
+// Index yang dibuat 0006 harus bernama sama dengan index model agar tidak dibuat ulang dengan nama lain
+func TestArtikelCmsIndexesMatchModel(t *testing.T) {
+	frozen, err := schema.Parse(&cmsArtikel{}, &sync.Map{}, schema.NamingStrategy{})
+	assert.NoError(t, err)
+	live, err := schema.Parse(&model.Artikel{}, &sync.Map{}, schema.NamingStrategy{})
+	assert.NoError(t, err)
+
+	for _, name := range artikelCmsIndexes {
+		index := frozen.LookIndex(name)
+		if assert.NotNil(t, index, name) {
+			assert.NotNil(t, live.LookIndex(index.Name), index.Name)
+		}
+	}
+	assert.Nil(t, frozen.LookUpField("search_judul"))
+	assert.Nil(t, frozen.LookUpField("search_isi"))
+}
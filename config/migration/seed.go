@@ -1,4 +1,4 @@
-package config
+package migration
 
 import (
 	"calmind/model"
@@ -86,3 +86,46 @@ func SeedSpecialties(db *gorm.DB) error {
 	fmt.Println("Seeding Specialties selesai.")
 	return nil
 }
+
+// SeedFaqs mengisi FAQ awal hanya jika belum ada kategori, agar perubahan admin tidak tertimpa.
+// ID 1-7 dipertahankan karena klien lama memakai nomor pertanyaan sebagai pesan.
+func SeedFaqs(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&model.FaqCategory{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("error saat memeriksa kategori FAQ: %w", err)
+	}
+	if count > 0 {
+		fmt.Println("FAQ sudah ada di database.")
+		return nil
+	}
+
+	categories := []model.FaqCategory{
+		{ID: 1, Name: "Pembayaran", Position: 1, Faqs: []model.Faq{
+			{ID: 1, Position: 1, Question: "Metode pembayaran?", Keywords: "cara bayar, gopay, ovo, e-wallet, transfer bank, kartu kredit, debit",
+				Answer: "Metode pembayaran yang bisa digunakan Kartu kredit/debit E-wallet (GoPay, OVO, dll.) Transfer bank Silahkan pilih metode yang paling nyaman bagi Anda 😊"},
+			{ID: 2, Position: 2, Question: "Proses pembayaran?", Keywords: "cara bayar, halaman pembayaran, buat janji, checkout",
+				Answer: "Setelah memilih dokter dan metode pembayaran, Anda akan diarahkan ke halaman pembayaran. Ikuti petunjuk di layar untuk menyelesaikan pembayaran. Pilih dokter dan klik buat janji Isi keluhan yang sedang dialami Pilih metode pembayaran Ikuti petunjuk di layar untuk menyelesaikan pembayaran."},
+		}},
+		{ID: 2, Name: "Konsultasi", Position: 2, Faqs: []model.Faq{
+			{ID: 3, Position: 1, Question: "Kapan harus melakukan konsultasi?", Keywords: "gejala, stres, emosi, pikiran negatif, perlu konsultasi",
+				Answer: "Anda harus melakukan konsultasi jika mengalami gejala-gejala berikut: Kesulitan mengelola emosi atau stres. Memiliki pikiran negatif yang mengganggu aktivitas sehari-hari. Mengalami perubahan perilaku yang signifikan. Ingin mengembangkan diri atau meningkatkan kualitas hidup."},
+			{ID: 4, Position: 2, Question: "Bagaimana cara melakukan konsultasi?", Keywords: "pilih dokter, psikolog, psikiater, form keluhan, janji temu",
+				Answer: "Konsultasi bisa dilakukan dengan cara sebagai berikut: Pilih dokter yang ingin dihubungi. Isi form keluhan sesuai dengan keadaan yang dialami. Lakukan pembayaran Anda akan terhubung dengan dokter."},
+		}},
+		{ID: 3, Name: "Akun", Position: 3, Faqs: []model.Faq{
+			{ID: 5, Position: 1, Question: "Bagaimana cara mendaftar akun?", Keywords: "daftar, register, registrasi, buat akun, sign up",
+				Answer: "Mendaftar akun CalmMind sangat mudah. Klik tombol 'Daftar' pada halaman awal. Isi data pada form sesuai instruksi yang diberikan. Konfirmasi pada email yang terdaftar. Akun berhasil didaftarkan."},
+			{ID: 6, Position: 2, Question: "Apa yang harus dilakukan jika tidak bisa masuk?", Keywords: "gagal login, tidak bisa login, masuk akun, sign in",
+				Answer: "Jika tidak bisa masuk ke akun Anda, ada beberapa hal yang harus Anda lakukan. Periksa kembali koneksi internet. Pastikan nama pengguna dan kata sandi yang dimasukkan sudah benar. Jika masih mengalami masalah silahkan reset kata sandi dan hubungi Admin."},
+			{ID: 7, Position: 3, Question: "Bagaimana jika lupa kata sandi?", Keywords: "lupa password, reset password, ganti kata sandi",
+				Answer: "Jangan khawatir, Anda bisa mereset kata sandi dengan mudah. Klik tombol 'Lupa Kata Sandi' pada halaman masuk. Ikuti petunjuk yang diberikan. Anda akan menerima email berisi tautan untuk mengatur ulang kata sandi."},
+		}},
+	}
+
+	fmt.Println("Seeding FAQ...")
+	if err := db.Create(&categories).Error; err != nil {
+		return fmt.Errorf("gagal menambahkan FAQ: %w", err)
+	}
+	fmt.Println("Seeding FAQ selesai.")
+	return nil
+}
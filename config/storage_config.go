@@ -0,0 +1,89 @@
+package config
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Konfigurasi penyimpanan file (avatar, gambar artikel, dokumen kredensial)
+type StorageConfig struct {
+	Driver string // cloudinary, local, atau s3
+
+	// local: file disimpan di LocalDir dan disajikan lewat route static PublicURL
+	LocalDir       string
+	LocalPublicURL string
+
+	// Dokumen privat (kredensial dokter) disimpan di LocalPrivateDir yang tidak disajikan route static,
+	// atau di S3PrivateBucket jika driver s3 dan bucket privat diatur. Selain driver local, LocalPrivateDir
+	// harus diatur eksplisit ke direktori yang persisten (volume), karena isi container hilang saat redeploy.
+	LocalPrivateDir string
+
+	CloudinaryCloudName    string
+	CloudinaryUploadPreset string
+	CloudinaryAPIKey       string
+	CloudinaryAPISecret    string
+
+	// s3: AWS S3 atau layanan kompatibel (MinIO, R2, dll.)
+	S3Endpoint  string // Kosong berarti endpoint AWS untuk S3Region
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool   // Wajib untuk sebagian besar layanan kompatibel S3
+	S3PublicURL string // URL publik bucket (misalnya CDN), kosong berarti URL objek langsung
+
+	S3PrivateBucket string
+}
+
+func NewStorageConfig() *StorageConfig {
+	cfg := &StorageConfig{
+		Driver:                 strings.ToLower(strings.TrimSpace(os.Getenv("STORAGE_DRIVER"))),
+		LocalDir:               os.Getenv("STORAGE_LOCAL_DIR"),
+		LocalPublicURL:         os.Getenv("STORAGE_LOCAL_PUBLIC_URL"),
+		LocalPrivateDir:        os.Getenv("STORAGE_PRIVATE_DIR"),
+		CloudinaryCloudName:    os.Getenv("CLOUDINARY_CLOUD_NAME"),
+		CloudinaryUploadPreset: os.Getenv("CLOUDINARY_UPLOAD_PRESET"),
+		CloudinaryAPIKey:       os.Getenv("CLOUDINARY_API_KEY"),
+		CloudinaryAPISecret:    os.Getenv("CLOUDINARY_API_SECRET"),
+		S3Endpoint:             os.Getenv("S3_ENDPOINT"),
+		S3Region:               os.Getenv("S3_REGION"),
+		S3Bucket:               os.Getenv("S3_BUCKET"),
+		S3AccessKey:            os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:            os.Getenv("S3_SECRET_KEY"),
+		S3PathStyle:            os.Getenv("S3_PATH_STYLE") == "true",
+		S3PublicURL:            os.Getenv("S3_PUBLIC_URL"),
+		S3PrivateBucket:        os.Getenv("S3_PRIVATE_BUCKET"),
+	}
+
+	// Tanpa pilihan eksplisit, Cloudinary dipakai jika sudah dikonfigurasi agar deployment lama tetap berjalan
+	if cfg.Driver == "" {
+		if cfg.CloudinaryCloudName != "" {
+			cfg.Driver = "cloudinary"
+		} else {
+			cfg.Driver = "local"
+		}
+	}
+	if cfg.LocalDir == "" {
+		cfg.LocalDir = "uploads"
+	}
+	if cfg.LocalPublicURL == "" {
+		cfg.LocalPublicURL = "/uploads"
+	}
+	if cfg.LocalPrivateDir == "" && cfg.Driver == "local" {
+		cfg.LocalPrivateDir = "private_uploads"
+	}
+	if cfg.S3Region == "" {
+		cfg.S3Region = "us-east-1"
+	}
+	return cfg
+}
+
+// Path route static untuk file driver local, diambil dari LocalPublicURL yang bisa berupa path atau URL lengkap
+func (cfg *StorageConfig) LocalStaticPath() string {
+	parsed, err := url.Parse(cfg.LocalPublicURL)
+	if err != nil || parsed.Path == "" {
+		return "/"
+	}
+	return "/" + strings.Trim(parsed.Path, "/")
+}
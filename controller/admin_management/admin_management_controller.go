@@ -3,6 +3,7 @@ package controller
 import (
 	"calmind/helper"
 	usecase "calmind/usecase/admin_management"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -17,24 +18,32 @@ func NewAdminManagementController(usecase usecase.AdminManagementUsecase) *Admin
 	return &AdminManagementController{AdminUsecase: usecase}
 }
 
-// Get All Users
+// Get All Users (?page=&limit=&sort=&q=&is_verified=&jenis_kelamin=)
 func (ac *AdminManagementController) GetAllUsers(c echo.Context) error {
-	users, err := ac.AdminUsecase.GetAllUsers()
+	opts := helper.ParseQueryOptions(c, "is_verified", "jenis_kelamin")
+	users, page, err := ac.AdminUsecase.GetAllUsers(opts)
 	if err != nil {
+		if helper.IsInvalidQuery(err) {
+			return helper.JSONErrorResponse(c, http.StatusBadRequest, err.Error())
+		}
 		return helper.JSONErrorResponse(c, http.StatusInternalServerError, "Failed to fetch users")
 	}
 
-	return helper.JSONSuccessResponse(c, users)
+	return helper.JSONPaginatedResponse(c, users, page)
 }
 
-// Get All Doctors
+// Get All Doctors (?page=&limit=&sort=&q=&is_active=&is_verified=&credential_status=&title_id=)
 func (ac *AdminManagementController) GetAllDoctors(c echo.Context) error {
-	doctors, err := ac.AdminUsecase.GetAllDoctors()
+	opts := helper.ParseQueryOptions(c, "is_active", "is_verified", "credential_status", "title_id")
+	doctors, page, err := ac.AdminUsecase.GetAllDoctors(opts)
 	if err != nil {
+		if helper.IsInvalidQuery(err) {
+			return helper.JSONErrorResponse(c, http.StatusBadRequest, err.Error())
+		}
 		return helper.JSONErrorResponse(c, http.StatusInternalServerError, "Failed to fetch doctors")
 	}
 
-	return helper.JSONSuccessResponse(c, doctors)
+	return helper.JSONPaginatedResponse(c, doctors, page)
 }
 
 // Delete User
@@ -46,7 +55,7 @@ func (ac *AdminManagementController) DeleteUser(c echo.Context) error {
 
 	user, err := ac.AdminUsecase.DeleteUser(id)
 	if err != nil {
-		return helper.JSONErrorResponse(c, http.StatusInternalServerError, "Failed to delete user")
+		return deleteErrorResponse(c, err, "Failed to delete user")
 	}
 
 	return helper.JSONSuccessResponse(c, map[string]interface{}{
@@ -64,7 +73,7 @@ func (ac *AdminManagementController) DeleteDoctor(c echo.Context) error {
 
 	doctor, err := ac.AdminUsecase.DeleteDoctor(id)
 	if err != nil {
-		return helper.JSONErrorResponse(c, http.StatusInternalServerError, "Failed to delete doctor")
+		return deleteErrorResponse(c, err, "Failed to delete doctor")
 	}
 
 	return helper.JSONSuccessResponse(c, map[string]interface{}{
@@ -72,3 +81,14 @@ func (ac *AdminManagementController) DeleteDoctor(c echo.Context) error {
 		"doctor_id": doctor.ID,
 	})
 }
+
+func deleteErrorResponse(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, usecase.ErrAccountNotFound):
+		return helper.JSONErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrAccountHasConsultations):
+		return helper.JSONErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		return helper.JSONErrorResponse(c, http.StatusInternalServerError, fallback)
+	}
+}
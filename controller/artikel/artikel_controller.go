@@ -4,12 +4,16 @@ import (
 	"calmind/helper"
 	"calmind/model"
 	"calmind/service"
+	"calmind/service/upload"
 	usecase "calmind/usecase/artikel"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -19,76 +23,106 @@ type AdminResponse struct {
 	Username string `json:"username"`
 }
 
+type ArtikelCategoryResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type ArtikelTagResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
 type ArtikelResponse struct {
-	ID        int           `json:"id"`
-	Judul     string        `json:"judul"`
-	Gambar    string        `json:"gambar"`
-	Isi       string        `json:"isi"`
-	CreatedAt string        `json:"created_at"`
-	UpdatedAt string        `json:"updated_at"`
-	Admin     AdminResponse `json:"admin"`
+	ID              int                      `json:"id"`
+	Judul           string                   `json:"judul"`
+	Slug            string                   `json:"slug"`
+	Ringkasan       string                   `json:"ringkasan"`
+	Gambar          string                   `json:"gambar"`
+	GambarThumbnail string                   `json:"gambar_thumbnail"`
+	Isi             string                   `json:"isi"`
+	Status          string                   `json:"status"`
+	PublishedAt     *string                  `json:"published_at"`
+	ReadingMinutes  int                      `json:"reading_minutes"`
+	Category        *ArtikelCategoryResponse `json:"category"`
+	Tags            []ArtikelTagResponse     `json:"tags"`
+	CreatedAt       string                   `json:"created_at"`
+	UpdatedAt       string                   `json:"updated_at"`
+	Admin           AdminResponse            `json:"admin"`
+}
+
+// Hasil pencarian, kata kunci pada judul_highlight dan snippet dibungkus <mark>
+type ArtikelSearchResponse struct {
+	ArtikelResponse
+	JudulHighlight string `json:"judul_highlight"`
+	Snippet        string `json:"snippet"`
+}
+
+// Isi hanya dikirim pada detail revisi, tidak pada daftar
+type ArtikelRevisionResponse struct {
+	Number          int           `json:"number"`
+	Note            string        `json:"note"`
+	Judul           string        `json:"judul"`
+	Slug            string        `json:"slug"`
+	Ringkasan       string        `json:"ringkasan"`
+	Isi             string        `json:"isi,omitempty"`
+	Gambar          string        `json:"gambar"`
+	GambarThumbnail string        `json:"gambar_thumbnail"`
+	Status          string        `json:"status"`
+	PublishedAt     *string       `json:"published_at"`
+	CategoryID      *int          `json:"category_id"`
+	Tags            []string      `json:"tags"`
+	CreatedAt       string        `json:"created_at"`
+	Admin           AdminResponse `json:"admin"`
 }
 
 type ArtikelController struct {
 	Usecase usecase.ArtikelUsecase
+	Images  upload.ImagePipeline
 }
 
-func NewArtikelController(usecase usecase.ArtikelUsecase) *ArtikelController {
-	return &ArtikelController{Usecase: usecase}
+func NewArtikelController(usecase usecase.ArtikelUsecase, images upload.ImagePipeline) *ArtikelController {
+	return &ArtikelController{Usecase: usecase, Images: images}
 }
 
-// CreateArtikel - Membuat artikel baru
-func (c *ArtikelController) CreateArtikel(ctx echo.Context) error {
-	var artikel model.Artikel
+type artikelCategoryRequest struct {
+	Name string `json:"name"`
+}
 
+// CreateArtikel - Membuat artikel baru, default berstatus draft
+func (c *ArtikelController) CreateArtikel(ctx echo.Context) error {
 	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
 	if !ok || claims == nil {
 		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Klaim JWT tidak valid atau tidak ditemukan")
 	}
 
 	// Validasi input
-	if err := ctx.Bind(&artikel); err != nil {
+	var input usecase.ArtikelInput
+	if err := ctx.Bind(&input); err != nil {
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid input: "+err.Error())
 	}
 
 	// Memanggil usecase untuk membuat artikel
-	err := c.Usecase.CreateArtikel(claims.UserID, &artikel)
+	artikel, err := c.Usecase.CreateArtikel(claims.UserID, input)
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return artikelErrorResponse(ctx, err)
 	}
 
-	// Berhasil
-	return helper.JSONSuccessResponse(ctx, "berhasil update artikel")
+	return helper.JSONSuccessResponse(ctx, toArtikelResponse(*artikel))
 }
 
-// GetAllArtikel - Mengambil semua artikel
+// GetAllArtikel - Mengambil semua artikel untuk admin, termasuk draft dan arsip
 func (c *ArtikelController) GetAllArtikel(ctx echo.Context) error {
-	artikels, err := c.Usecase.GetAllArtikel()
+	artikels, page, err := c.Usecase.GetAllArtikel(helper.ParseQueryOptions(ctx, "admin_id", "status", "category_id"))
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, err.Error())
-	}
-
-	// Transform artikels ke DTO
-	var responses []ArtikelResponse
-	for _, artikel := range artikels {
-		responses = append(responses, ArtikelResponse{
-			ID:        artikel.ID,
-			Judul:     artikel.Judul,
-			Gambar:    artikel.Gambar,
-			Isi:       artikel.Isi,
-			CreatedAt: artikel.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: artikel.UpdatedAt.Format("2006-01-02 15:04:05"),
-			Admin: AdminResponse{
-				ID:       artikel.Admin.ID,
-				Username: artikel.Admin.Username,
-			},
-		})
+		return artikelErrorResponse(ctx, err)
 	}
 
-	return helper.JSONSuccessResponse(ctx, responses)
+	return helper.JSONPaginatedResponse(ctx, toArtikelResponses(artikels), page)
 }
 
-// GetArtikelByID - Mengambil artikel berdasarkan ID
+// GetArtikelByID - Mengambil artikel berdasarkan ID untuk admin
 func (c *ArtikelController) GetArtikelByID(ctx echo.Context) error {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
@@ -97,23 +131,10 @@ func (c *ArtikelController) GetArtikelByID(ctx echo.Context) error {
 
 	artikel, err := c.Usecase.GetArtikelByID(id)
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return artikelErrorResponse(ctx, err)
 	}
 
-	response := ArtikelResponse{
-		ID:        artikel.ID,
-		Judul:     artikel.Judul,
-		Gambar:    artikel.Gambar,
-		Isi:       artikel.Isi,
-		CreatedAt: artikel.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: artikel.UpdatedAt.Format("2006-01-02 15:04:05"),
-		Admin: AdminResponse{
-			ID:       artikel.Admin.ID,
-			Username: artikel.Admin.Username,
-		},
-	}
-
-	return helper.JSONSuccessResponse(ctx, response)
+	return helper.JSONSuccessResponse(ctx, toArtikelResponse(*artikel))
 }
 
 func (c *ArtikelController) UpdateArtikel(ctx echo.Context) error {
@@ -122,29 +143,22 @@ func (c *ArtikelController) UpdateArtikel(ctx echo.Context) error {
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid artikel ID")
 	}
 
-	var artikel model.Artikel
-	if err := ctx.Bind(&artikel); err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid input: "+err.Error())
-	}
-
-	// Validasi agar admin_id tidak diubah melalui payload
-	existingArtikel, err := c.Usecase.GetArtikelByID(id)
-	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusNotFound, "Artikel not found")
+	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Klaim JWT tidak valid atau tidak ditemukan")
 	}
 
-	if existingArtikel.AdminID != artikel.AdminID && artikel.AdminID != 0 {
-		return helper.JSONErrorResponse(ctx, http.StatusForbidden, "Cannot change Admin ID")
+	var input usecase.ArtikelInput
+	if err := ctx.Bind(&input); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid input: "+err.Error())
 	}
 
-	artikel.ID = id
-	artikel.AdminID = existingArtikel.AdminID // Tetapkan admin_id dari data asli
-	err = c.Usecase.UpdateArtikel(&artikel)
+	artikel, err := c.Usecase.UpdateArtikel(claims.UserID, id, input)
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return artikelErrorResponse(ctx, err)
 	}
 
-	return helper.JSONSuccessResponse(ctx, map[string]string{"message": "Artikel updated successfully"})
+	return helper.JSONSuccessResponse(ctx, toArtikelResponse(*artikel))
 }
 
 // DeleteArtikel - Menghapus artikel berdasarkan ID
@@ -161,44 +175,290 @@ func (c *ArtikelController) DeleteArtikel(ctx echo.Context) error {
 
 	err = c.Usecase.DeleteArtikel(claims.UserID, id)
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return artikelErrorResponse(ctx, err)
 	}
 
 	return helper.JSONSuccessResponse(ctx, map[string]string{"message": "Artikel deleted successfully"})
 }
 
+// GetArtikelRevisions - Riwayat revisi artikel, terbaru di depan
+func (c *ArtikelController) GetArtikelRevisions(ctx echo.Context) error {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid artikel ID")
+	}
+
+	revisions, page, err := c.Usecase.GetRevisions(id, helper.ParseQueryOptions(ctx, "admin_id"))
+	if err != nil {
+		return artikelErrorResponse(ctx, err)
+	}
+
+	responses := make([]ArtikelRevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		response := toArtikelRevisionResponse(revision)
+		response.Isi = ""
+		responses = append(responses, response)
+	}
+	return helper.JSONPaginatedResponse(ctx, responses, page)
+}
+
+// GetArtikelRevision - Detail satu revisi termasuk isinya
+func (c *ArtikelController) GetArtikelRevision(ctx echo.Context) error {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid artikel ID")
+	}
+	number, err := strconv.Atoi(ctx.Param("number"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Nomor revisi tidak valid")
+	}
+
+	revision, err := c.Usecase.GetRevision(id, number)
+	if err != nil {
+		return artikelErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, toArtikelRevisionResponse(*revision))
+}
+
+// DiffArtikelRevisions - Perbedaan revisi ?from= ke ?to=
+func (c *ArtikelController) DiffArtikelRevisions(ctx echo.Context) error {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid artikel ID")
+	}
+	from, errFrom := strconv.Atoi(ctx.QueryParam("from"))
+	to, errTo := strconv.Atoi(ctx.QueryParam("to"))
+	if errFrom != nil || errTo != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Parameter from dan to wajib berisi nomor revisi")
+	}
+
+	diff, err := c.Usecase.DiffRevisions(id, from, to)
+	if err != nil {
+		return artikelErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, diff)
+}
+
+// RestoreArtikelRevision - Menjadikan konten revisi sebagai versi terkini
+func (c *ArtikelController) RestoreArtikelRevision(ctx echo.Context) error {
+	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Klaim JWT tidak valid atau tidak ditemukan")
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid artikel ID")
+	}
+	number, err := strconv.Atoi(ctx.Param("number"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Nomor revisi tidak valid")
+	}
+
+	artikel, err := c.Usecase.RestoreRevision(claims.UserID, id, number)
+	if err != nil {
+		return artikelErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, toArtikelResponse(*artikel))
+}
+
+// GetPublishedArtikels - Artikel yang sudah tayang, bisa difilter ?tag= (nama) dan ?category= (slug)
+func (c *ArtikelController) GetPublishedArtikels(ctx echo.Context) error {
+	opts := helper.ParseQueryOptions(ctx, "category_id")
+	artikels, page, err := c.Usecase.GetPublishedArtikels(ctx.QueryParam("tag"), ctx.QueryParam("category"), opts)
+	if err != nil {
+		return artikelErrorResponse(ctx, err)
+	}
+
+	return helper.JSONPaginatedResponse(ctx, toArtikelResponses(artikels), page)
+}
+
+// GetPublishedArtikel - Detail artikel yang sudah tayang berdasarkan ID atau slug
+func (c *ArtikelController) GetPublishedArtikel(ctx echo.Context) error {
+	artikel, err := c.Usecase.GetPublishedArtikel(ctx.Param("id"))
+	if err != nil {
+		return artikelErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, toArtikelResponse(*artikel))
+}
+
+// SearchArtikel - Pencarian judul dan isi artikel yang sudah tayang, urut relevansi.
+// Filter: ?category= (slug), ?category_id=, ?tag=, ?from=YYYY-MM-DD, ?to=YYYY-MM-DD (inklusif)
 func (c *ArtikelController) SearchArtikel(ctx echo.Context) error {
 	query := ctx.QueryParam("query")
+	if query == "" {
+		query = ctx.QueryParam("q")
+	}
 	if query == "" {
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Query tidak boleh kosong")
 	}
 
-	// Panggil usecase untuk mencari artikel
-	artikels, err := c.Usecase.SearchArtikel(query)
+	filter := usecase.ArtikelSearchFilter{CategorySlug: ctx.QueryParam("category"), Tag: ctx.QueryParam("tag")}
+	if value := ctx.QueryParam("from"); value != "" {
+		from, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Format tanggal from harus YYYY-MM-DD")
+		}
+		filter.From = &from
+	}
+	if value := ctx.QueryParam("to"); value != "" {
+		to, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Format tanggal to harus YYYY-MM-DD")
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	// Kata kunci dipakai untuk pencarian FULLTEXT, bukan filter q bawaan daftar
+	opts := helper.ParseQueryOptions(ctx, "category_id")
+	opts.Search = ""
+
+	results, page, err := c.Usecase.SearchArtikel(query, filter, opts)
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mencari artikel: "+err.Error())
+		return artikelErrorResponse(ctx, err)
 	}
 
-	// Transform hasil pencarian artikel ke dalam DTO
-	var responses []ArtikelResponse
-	for _, artikel := range artikels {
-		responses = append(responses, ArtikelResponse{
-			ID:        artikel.ID,
-			Judul:     artikel.Judul,
-			Gambar:    artikel.Gambar,
-			Isi:       artikel.Isi,
-			CreatedAt: artikel.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: artikel.UpdatedAt.Format("2006-01-02 15:04:05"),
-			Admin: AdminResponse{
-				ID:       artikel.Admin.ID,
-				Username: artikel.Admin.Username,
-			},
+	responses := make([]ArtikelSearchResponse, 0, len(results))
+	for _, result := range results {
+		responses = append(responses, ArtikelSearchResponse{
+			ArtikelResponse: toArtikelResponse(result.Artikel),
+			JudulHighlight:  result.JudulHighlight,
+			Snippet:         result.Snippet,
 		})
 	}
+	return helper.JSONPaginatedResponse(ctx, responses, page)
+}
+
+// Kategori artikel urut nama
+func (c *ArtikelController) GetCategories(ctx echo.Context) error {
+	categories, err := c.Usecase.GetCategories()
+	if err != nil {
+		return artikelErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, categories)
+}
+
+func (c *ArtikelController) CreateCategory(ctx echo.Context) error {
+	var request artikelCategoryRequest
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid.")
+	}
+
+	category, err := c.Usecase.CreateCategory(request.Name)
+	if err != nil {
+		return artikelErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, category)
+}
+
+func (c *ArtikelController) UpdateCategory(ctx echo.Context) error {
+	categoryID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID kategori tidak valid.")
+	}
+
+	var request artikelCategoryRequest
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid.")
+	}
+
+	category, err := c.Usecase.UpdateCategory(categoryID, request.Name)
+	if err != nil {
+		return artikelErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, category)
+}
+
+func (c *ArtikelController) DeleteCategory(ctx echo.Context) error {
+	categoryID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID kategori tidak valid.")
+	}
+
+	if err := c.Usecase.DeleteCategory(categoryID); err != nil {
+		return artikelErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Kategori artikel berhasil dihapus.")
+}
 
-	// Kirim respons sukses dengan data artikel yang diformat
-	return helper.JSONSuccessResponse(ctx, responses)
+func toArtikelRevisionResponse(revision model.ArtikelRevision) ArtikelRevisionResponse {
+	response := ArtikelRevisionResponse{
+		Number:          revision.Number,
+		Note:            revision.Note,
+		Judul:           revision.Judul,
+		Slug:            revision.Slug,
+		Ringkasan:       revision.Ringkasan,
+		Isi:             revision.Isi,
+		Gambar:          revision.Gambar,
+		GambarThumbnail: revision.GambarThumbnail,
+		Status:          revision.Status,
+		CategoryID:      revision.CategoryID,
+		Tags:            revision.TagNames,
+		CreatedAt:       revision.CreatedAt.Format("2006-01-02 15:04:05"),
+		Admin: AdminResponse{
+			ID:       revision.Admin.ID,
+			Username: revision.Admin.Username,
+		},
+	}
+	if revision.PublishedAt != nil {
+		publishedAt := revision.PublishedAt.Format("2006-01-02 15:04:05")
+		response.PublishedAt = &publishedAt
+	}
+	if response.Tags == nil {
+		response.Tags = []string{}
+	}
+	return response
+}
+
+func toArtikelResponses(artikels []model.Artikel) []ArtikelResponse {
+	responses := make([]ArtikelResponse, 0, len(artikels))
+	for _, artikel := range artikels {
+		responses = append(responses, toArtikelResponse(artikel))
+	}
+	return responses
+}
+
+func toArtikelResponse(artikel model.Artikel) ArtikelResponse {
+	response := ArtikelResponse{
+		ID:              artikel.ID,
+		Judul:           artikel.Judul,
+		Slug:            artikel.Slug,
+		Ringkasan:       artikel.Ringkasan,
+		Gambar:          artikel.Gambar,
+		GambarThumbnail: artikel.GambarThumbnail,
+		Isi:             artikel.Isi,
+		Status:          artikel.Status,
+		ReadingMinutes:  artikel.ReadingMinutes,
+		Tags:            make([]ArtikelTagResponse, 0, len(artikel.Tags)),
+		CreatedAt:       artikel.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:       artikel.UpdatedAt.Format("2006-01-02 15:04:05"),
+		Admin: AdminResponse{
+			ID:       artikel.Admin.ID,
+			Username: artikel.Admin.Username,
+		},
+	}
+	if artikel.PublishedAt != nil {
+		publishedAt := artikel.PublishedAt.Format("2006-01-02 15:04:05")
+		response.PublishedAt = &publishedAt
+	}
+	if artikel.Category != nil {
+		response.Category = &ArtikelCategoryResponse{ID: artikel.Category.ID, Name: artikel.Category.Name, Slug: artikel.Category.Slug}
+	}
+	for _, tag := range artikel.Tags {
+		response.Tags = append(response.Tags, ArtikelTagResponse{ID: tag.ID, Name: tag.Name})
+	}
+	return response
 }
+
 func (c *ArtikelController) UploadArtikelImage(ctx echo.Context) error {
 	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
 	if !ok {
@@ -213,17 +473,6 @@ func (c *ArtikelController) UploadArtikelImage(ctx echo.Context) error {
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Gagal mendapatkan file: "+err.Error())
 	}
 
-	// Validasi ukuran file (maksimal 5 MB)
-	if file.Size > 5*1024*1024 {
-		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Ukuran file maksimal 5 MB")
-	}
-
-	// Validasi ekstensi file
-	ext := filepath.Ext(file.Filename)
-	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
-		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Hanya file dengan format .jpg, .jpeg, atau .png yang diperbolehkan")
-	}
-
 	// Buka file untuk proses upload
 	src, err := file.Open()
 	if err != nil {
@@ -231,17 +480,22 @@ func (c *ArtikelController) UploadArtikelImage(ctx echo.Context) error {
 	}
 	defer src.Close()
 
-	// Gunakan helper untuk upload ke Cloudinary
-	newFileName := fmt.Sprintf("admin_%d_%s", adminID, file.Filename)
-	imageURL, _, err := helper.UploadFileToCloudinary(src, newFileName)
+	// Tipe dan ukuran diperiksa dari isi file, metadata EXIF dihapus, lalu gambar di-resize dan dibuat thumbnail
+	baseName := helper.Slugify(strings.TrimSuffix(filepath.Base(file.Filename), filepath.Ext(file.Filename)))
+	if baseName == "" {
+		baseName = "gambar"
+	}
+	newFileName := fmt.Sprintf("artikel/admin_%d_%d_%s", adminID, time.Now().Unix(), baseName)
+	result, err := c.Images.Process(ctx.Request().Context(), upload.ArticleImage, newFileName, src)
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mengunggah file ke Cloudinary")
+		return imageUploadErrorResponse(ctx, err)
 	}
 
 	// Kirim respons sukses dengan URL gambar
 	return helper.JSONSuccessResponse(ctx, map[string]string{
-		"message":  "Gambar artikel berhasil diunggah",
-		"imageUrl": imageURL,
+		"message":      "Gambar artikel berhasil diunggah",
+		"imageUrl":     result.Image.URL,
+		"thumbnailUrl": result.Thumbnail.URL,
 	})
 }
 
@@ -257,21 +511,46 @@ func (c *ArtikelController) DeleteArtikelImage(ctx echo.Context) error {
 		return helper.JSONErrorResponse(ctx, http.StatusForbidden, "Akses ditolak atau artikel tidak ditemukan")
 	}
 
-	// Ekstrak public_id dari URL gambar
-	parts := strings.Split(artikel.Gambar, "/")
-	publicID := strings.TrimSuffix(parts[len(parts)-1], filepath.Ext(artikel.Gambar))
-
-	// Hapus file dari Cloudinary menggunakan helper
-	if err := helper.DeleteFileFromCloudinary(publicID); err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal menghapus gambar di Cloudinary")
+	// Gambar beserta thumbnail dihapus, URL dari penyimpanan lain cukup dikosongkan di database
+	if err := c.Images.Delete(ctx.Request().Context(), artikel.Gambar); err != nil {
+		log.Printf("Gagal menghapus gambar artikel %d: %v", artikel.ID, err)
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal menghapus gambar artikel")
 	}
 
 	// Update database
-	artikel.Gambar = ""
-	err = c.Usecase.UpdateArtikel(artikel)
-	if err != nil {
+	if _, err := c.Usecase.ClearArtikelImage(claims.UserID, artikel.ID); err != nil {
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mengupdate database")
 	}
 
 	return helper.JSONSuccessResponse(ctx, map[string]string{"message": "Gambar berhasil dihapus"})
 }
+
+// Kesalahan validasi file dari pipeline upload ditanggapi 400/413, selain itu 500
+func imageUploadErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, upload.ErrUnsupportedType), errors.Is(err, upload.ErrCorruptImage), errors.Is(err, upload.ErrImageTooLarge):
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, upload.ErrFileTooLarge):
+		return helper.JSONErrorResponse(ctx, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mengunggah gambar")
+	}
+}
+
+func artikelErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrArtikelNotFound), errors.Is(err, usecase.ErrArtikelCategoryNotFound), errors.Is(err, usecase.ErrTagNotFound),
+		errors.Is(err, usecase.ErrRevisionNotFound):
+		return helper.JSONErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrInvalidArtikel), errors.Is(err, usecase.ErrInvalidArtikelStatus),
+		errors.Is(err, usecase.ErrInvalidSlug), errors.Is(err, usecase.ErrInvalidCategoryName), errors.Is(err, usecase.ErrInvalidArtikelSearch),
+		helper.IsInvalidQuery(err):
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, usecase.ErrArtikelForbidden):
+		return helper.JSONErrorResponse(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, usecase.ErrSlugTaken), errors.Is(err, usecase.ErrCategoryExists):
+		return helper.JSONErrorResponse(ctx, http.StatusConflict, err.Error())
+	default:
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -12,10 +12,11 @@ import (
 
 type AdminAuthController struct {
 	AdminUsecase usecase.AdminAuthUsecase
+	Sessions     usecase.SessionUsecase
 }
 
-func NewAdminAuthController(usecase usecase.AdminAuthUsecase) *AdminAuthController {
-	return &AdminAuthController{AdminUsecase: usecase}
+func NewAdminAuthController(usecase usecase.AdminAuthUsecase, sessions usecase.SessionUsecase) *AdminAuthController {
+	return &AdminAuthController{AdminUsecase: usecase, Sessions: sessions}
 }
 
 func (c *AdminAuthController) LoginAdmin(ctx echo.Context) error {
@@ -24,17 +25,23 @@ func (c *AdminAuthController) LoginAdmin(ctx echo.Context) error {
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Gagal mendapatkan data: "+err.Error())
 	}
 
-	token, err := c.AdminUsecase.LoginAdmin(user.Email, user.Password)
+	tokens, err := c.AdminUsecase.LoginAdmin(user.Email, user.Password)
 	if err != nil {
 		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Login gagal: "+err.Error())
 	}
 
-	// Kembalikan token dalam respons
-	return helper.JSONSuccessResponse(ctx, map[string]string{
-		"token": token,
-	})
+	// Kembalikan access token dan refresh token dalam respons
+	return helper.JSONSuccessResponse(ctx, tokens)
 }
 
 func (c *AdminAuthController) LogoutAdmin(ctx echo.Context) error {
-	return helper.JSONSuccessResponse(ctx, "Logout berhasil")
+	return logoutSession(ctx, c.Sessions, "admin")
+}
+
+func (c *AdminAuthController) LogoutAllAdmin(ctx echo.Context) error {
+	return logoutAllSessions(ctx, c.Sessions, "admin")
+}
+
+func (c *AdminAuthController) RefreshAdminToken(ctx echo.Context) error {
+	return refreshSession(ctx, c.Sessions, "admin")
 }
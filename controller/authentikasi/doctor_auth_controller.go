@@ -12,12 +12,11 @@ import (
 
 type DoctorAuthController struct {
 	DoctorUsecase usecase.DoctorUsecase
+	Sessions      usecase.SessionUsecase
 }
 
-func NewDoctorAuthController(doctorUsecase usecase.DoctorUsecase) *DoctorAuthController {
-	return &DoctorAuthController{
-		DoctorUsecase: doctorUsecase,
-	}
+func NewDoctorAuthController(doctorUsecase usecase.DoctorUsecase, sessions usecase.SessionUsecase) *DoctorAuthController {
+	return &DoctorAuthController{DoctorUsecase: doctorUsecase, Sessions: sessions}
 }
 
 func (c *DoctorAuthController) RegisterDoctor(ctx echo.Context) error {
@@ -47,15 +46,13 @@ func (c *DoctorAuthController) LoginDoctor(ctx echo.Context) error {
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Email dan password wajib diisi.")
 	}
 
-	token, err := c.DoctorUsecase.Login(doctor.Email, doctor.Password)
+	tokens, err := c.DoctorUsecase.Login(doctor.Email, doctor.Password)
 	if err != nil {
 		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Login gagal: "+err.Error())
 	}
 
-	// Kembalikan token dalam respons
-	return helper.JSONSuccessResponse(ctx, map[string]string{
-		"token": token,
-	})
+	// Kembalikan access token dan refresh token dalam respons
+	return helper.JSONSuccessResponse(ctx, tokens)
 }
 
 func (c *DoctorAuthController) VerifyOtp(ctx echo.Context) error {
@@ -77,7 +74,15 @@ func (c *DoctorAuthController) VerifyOtp(ctx echo.Context) error {
 }
 
 func (c *DoctorAuthController) LogoutDoctor(ctx echo.Context) error {
-	return helper.JSONSuccessResponse(ctx, "Logout berhasil")
+	return logoutSession(ctx, c.Sessions, "doctor")
+}
+
+func (c *DoctorAuthController) LogoutAllDoctor(ctx echo.Context) error {
+	return logoutAllSessions(ctx, c.Sessions, "doctor")
+}
+
+func (c *DoctorAuthController) RefreshDoctorToken(ctx echo.Context) error {
+	return refreshSession(ctx, c.Sessions, "doctor")
 }
 
 func (c *DoctorAuthController) ResendOtp(ctx echo.Context) error {
@@ -100,3 +105,52 @@ func (c *DoctorAuthController) ResendOtp(ctx echo.Context) error {
 
 	return helper.JSONSuccessResponse(ctx, "Kode OTP berhasil dikirim ulang.")
 }
+
+func (c *DoctorAuthController) ForgotPassword(ctx echo.Context) error {
+	var request struct {
+		Email string `json:"email"`
+	}
+
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid: "+err.Error())
+	}
+
+	if request.Email == "" {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Email wajib diisi.")
+	}
+
+	err := c.DoctorUsecase.ForgotPassword(request.Email)
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal memproses reset password: "+err.Error())
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Jika email terdaftar, kode OTP dan tautan reset password telah dikirim.")
+}
+
+func (c *DoctorAuthController) ResetPassword(ctx echo.Context) error {
+	var request struct {
+		Email       string `json:"email"`
+		Code        string `json:"code"`
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	}
+
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid: "+err.Error())
+	}
+
+	if request.Email == "" || request.NewPassword == "" {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Email dan password baru wajib diisi.")
+	}
+
+	if request.Code == "" && request.Token == "" {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Kode OTP atau token reset password wajib diisi.")
+	}
+
+	err := c.DoctorUsecase.ResetPassword(request.Email, request.Code, request.Token, request.NewPassword)
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Reset password gagal: "+err.Error())
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Password berhasil diganti. Silakan login kembali.")
+}
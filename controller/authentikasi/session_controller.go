@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"calmind/helper"
+	"calmind/service"
+	usecase "calmind/usecase/authentikasi"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type sessionRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Menukar refresh token dengan access token baru untuk role tertentu
+func refreshSession(ctx echo.Context, sessions usecase.SessionUsecase, role string) error {
+	var request sessionRequest
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid: "+err.Error())
+	}
+
+	if request.RefreshToken == "" {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Refresh token wajib diisi.")
+	}
+
+	tokens, err := sessions.Refresh(role, request.RefreshToken)
+	if errors.Is(err, usecase.ErrInvalidRefreshToken) {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, err.Error())
+	}
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.JSONSuccessResponse(ctx, tokens)
+}
+
+// Mencabut token yang sedang dipakai, refresh token di body bersifat opsional
+func logoutSession(ctx echo.Context, sessions usecase.SessionUsecase, claimsKey string) error {
+	claims, ok := ctx.Get(claimsKey).(*service.JwtCustomClaims)
+	if !ok {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Token tidak valid")
+	}
+
+	var request sessionRequest
+	if ctx.Request().ContentLength > 0 {
+		if err := ctx.Bind(&request); err != nil {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid: "+err.Error())
+		}
+	}
+
+	if err := sessions.Logout(claims, request.RefreshToken); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Logout berhasil.")
+}
+
+func logoutAllSessions(ctx echo.Context, sessions usecase.SessionUsecase, claimsKey string) error {
+	claims, ok := ctx.Get(claimsKey).(*service.JwtCustomClaims)
+	if !ok {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Token tidak valid")
+	}
+
+	if err := sessions.LogoutAll(claims); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Berhasil keluar dari semua perangkat.")
+}
@@ -12,12 +12,11 @@ import (
 
 type AuthController struct {
 	AuthUsecase usecase.UserUsecase
+	Sessions    usecase.SessionUsecase
 }
 
-func NewAuthController(authUsecase usecase.UserUsecase) *AuthController {
-	return &AuthController{
-		AuthUsecase: authUsecase,
-	}
+func NewAuthController(authUsecase usecase.UserUsecase, sessions usecase.SessionUsecase) *AuthController {
+	return &AuthController{AuthUsecase: authUsecase, Sessions: sessions}
 }
 
 func (c *AuthController) RegisterUser(ctx echo.Context) error {
@@ -49,14 +48,12 @@ func (c *AuthController) LoginUser(ctx echo.Context) error {
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Email dan password wajib diisi.")
 	}
 
-	token, err := c.AuthUsecase.Login(request.Email, request.Password)
+	tokens, err := c.AuthUsecase.Login(request.Email, request.Password)
 	if err != nil {
 		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Login gagal: "+err.Error())
 	}
 
-	return helper.JSONSuccessResponse(ctx, map[string]string{
-		"token": token,
-	})
+	return helper.JSONSuccessResponse(ctx, tokens)
 }
 
 func (c *AuthController) VerifyOtp(ctx echo.Context) error {
@@ -82,7 +79,15 @@ func (c *AuthController) VerifyOtp(ctx echo.Context) error {
 }
 
 func (c *AuthController) LogoutUser(ctx echo.Context) error {
-	return helper.JSONSuccessResponse(ctx, "Logout berhasil.")
+	return logoutSession(ctx, c.Sessions, "user")
+}
+
+func (c *AuthController) LogoutAllUser(ctx echo.Context) error {
+	return logoutAllSessions(ctx, c.Sessions, "user")
+}
+
+func (c *AuthController) RefreshUserToken(ctx echo.Context) error {
+	return refreshSession(ctx, c.Sessions, "user")
 }
 
 func (c *AuthController) ResendOtp(ctx echo.Context) error {
@@ -105,3 +110,52 @@ func (c *AuthController) ResendOtp(ctx echo.Context) error {
 
 	return helper.JSONSuccessResponse(ctx, "Kode OTP berhasil dikirim ulang.")
 }
+
+func (c *AuthController) ForgotPassword(ctx echo.Context) error {
+	var request struct {
+		Email string `json:"email"`
+	}
+
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid: "+err.Error())
+	}
+
+	if request.Email == "" {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Email wajib diisi.")
+	}
+
+	err := c.AuthUsecase.ForgotPassword(request.Email)
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal memproses reset password: "+err.Error())
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Jika email terdaftar, kode OTP dan tautan reset password telah dikirim.")
+}
+
+func (c *AuthController) ResetPassword(ctx echo.Context) error {
+	var request struct {
+		Email       string `json:"email"`
+		Code        string `json:"code"`
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	}
+
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid: "+err.Error())
+	}
+
+	if request.Email == "" || request.NewPassword == "" {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Email dan password baru wajib diisi.")
+	}
+
+	if request.Code == "" && request.Token == "" {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Kode OTP atau token reset password wajib diisi.")
+	}
+
+	err := c.AuthUsecase.ResetPassword(request.Email, request.Code, request.Token, request.NewPassword)
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Reset password gagal: "+err.Error())
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Password berhasil diganti. Silakan login kembali.")
+}
@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"calmind/helper"
+	"calmind/service"
+	"calmind/service/lifecycle"
+	usecase "calmind/usecase/chat"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = (pongWait * 9) / 10
+	maxMessageSize = 8 * 1024
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// Koneksi sudah diautentikasi dengan JWT, origin tidak dibatasi agar aplikasi mobile bisa terhubung
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+type ChatController struct {
+	ChatUsecase usecase.ChatUsecase
+	Hub         service.ChatHub
+}
+
+func NewChatController(chatUsecase usecase.ChatUsecase, hub service.ChatHub) *ChatController {
+	return &ChatController{ChatUsecase: chatUsecase, Hub: hub}
+}
+
+// Pesan yang dikirim dan diterima melalui WebSocket
+type wsIncoming struct {
+	Content string `json:"content"`
+}
+
+type wsOutgoing struct {
+	Type    string      `json:"type"` // message atau error
+	Data    interface{} `json:"data,omitempty"`
+	Message string      `json:"message,omitempty"`
+}
+
+// Membuka koneksi chat untuk user
+func (c *ChatController) UserChatWS(ctx echo.Context) error {
+	claims, ok := ctx.Get("user").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+	return c.serveWS(ctx, lifecycle.ActorUser, claims.UserID)
+}
+
+// Membuka koneksi chat untuk dokter
+func (c *ChatController) DoctorChatWS(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+	return c.serveWS(ctx, lifecycle.ActorDoctor, claims.UserID)
+}
+
+// Melihat riwayat chat konsultasi untuk user
+func (c *ChatController) GetUserMessages(ctx echo.Context) error {
+	claims, ok := ctx.Get("user").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+	return c.getMessages(ctx, lifecycle.ActorUser, claims.UserID)
+}
+
+// Melihat riwayat chat konsultasi untuk dokter
+func (c *ChatController) GetDoctorMessages(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+	return c.getMessages(ctx, lifecycle.ActorDoctor, claims.UserID)
+}
+
+func (c *ChatController) getMessages(ctx echo.Context, role string, actorID int) error {
+	consultationID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid consultation ID.")
+	}
+
+	beforeID, _ := strconv.Atoi(ctx.QueryParam("before_id"))
+	limit, _ := strconv.Atoi(ctx.QueryParam("limit"))
+
+	messages, err := c.ChatUsecase.GetMessages(consultationID, role, actorID, beforeID, limit)
+	if err != nil {
+		return chatErrorResponse(ctx, err)
+	}
+
+	nextBeforeID := 0
+	if len(messages) > 0 {
+		nextBeforeID = messages[0].ID
+	}
+
+	return helper.JSONSuccessResponse(ctx, map[string]interface{}{
+		"messages":       messages,
+		"next_before_id": nextBeforeID,
+	})
+}
+
+func (c *ChatController) serveWS(ctx echo.Context, role string, actorID int) error {
+	consultationID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid consultation ID.")
+	}
+
+	// Validasi peserta sebelum upgrade agar error bisa dikembalikan sebagai HTTP biasa
+	consultation, err := c.ChatUsecase.Authorize(consultationID, role, actorID)
+	if err != nil {
+		return chatErrorResponse(ctx, err)
+	}
+	if err := c.ChatUsecase.CanSend(consultation); err != nil {
+		return chatErrorResponse(ctx, err)
+	}
+
+	conn, err := upgrader.Upgrade(ctx.Response(), ctx.Request(), nil)
+	if err != nil {
+		log.Printf("Failed to upgrade websocket for consultation %d: %v", consultationID, err)
+		return nil
+	}
+
+	client := &service.ChatClient{Role: role, ID: actorID, Send: make(chan []byte, 16)}
+	c.Hub.Join(consultationID, client)
+
+	go writePump(conn, client)
+	c.readPump(conn, consultationID, client)
+	return nil
+}
+
+// Membaca pesan dari klien, menyimpannya, lalu meneruskan ke semua peserta
+func (c *ChatController) readPump(conn *websocket.Conn, consultationID int, client *service.ChatClient) {
+	defer func() {
+		c.Hub.Leave(consultationID, client)
+		conn.Close()
+	}()
+
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var incoming wsIncoming
+		if err := conn.ReadJSON(&incoming); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("Websocket read error for consultation %d: %v", consultationID, err)
+			}
+			return
+		}
+
+		message, err := c.ChatUsecase.SendMessage(consultationID, client.Role, client.ID, incoming.Content)
+		if err != nil {
+			payload, _ := json.Marshal(wsOutgoing{Type: "error", Message: err.Error()})
+			select {
+			case client.Send <- payload:
+			default:
+			}
+			if errors.Is(err, usecase.ErrChatClosed) {
+				return
+			}
+			continue
+		}
+
+		payload, _ := json.Marshal(wsOutgoing{Type: "message", Data: message})
+		c.Hub.Broadcast(consultationID, payload)
+	}
+}
+
+// Mengirim pesan dari hub ke klien dan menjaga koneksi dengan ping berkala
+func writePump(conn *websocket.Conn, client *service.ChatClient) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case payload, ok := <-client.Send:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func chatErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrNotParticipant):
+		return helper.JSONErrorResponse(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, usecase.ErrChatClosed):
+		return helper.JSONErrorResponse(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, usecase.ErrInvalidMessage):
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	default:
+		return helper.JSONErrorResponse(ctx, http.StatusNotFound, "Consultation not found.")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"calmind/helper"
 	"calmind/service"
 	usecase "calmind/usecase/chatbot_ai"
+	"errors"
+	"strconv"
 
 	"net/http"
 
@@ -18,24 +20,161 @@ func NewChatbotController(chatbotUsecase usecase.ChatbotUsecase) *ChatbotControl
 	return &ChatbotController{ChatbotUsecase: chatbotUsecase}
 }
 
+// Mengirim pesan ke chatbot, session_id kosong akan membuat sesi baru
 func (c *ChatbotController) GetChatResponse(ctx echo.Context) error {
 	claims, _ := ctx.Get("user").(*service.JwtCustomClaims)
 
 	var request struct {
-		Message string `json:"message"`
+		SessionID int    `json:"session_id"`
+		Message   string `json:"message"`
 	}
 
 	if err := ctx.Bind(&request); err != nil {
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid")
 	}
 
-	response, err := c.ChatbotUsecase.GenerateResponse(claims.UserID, request.Message)
+	reply, err := c.ChatbotUsecase.GenerateResponse(claims.UserID, request.SessionID, request.Message)
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal memproses permintaan: "+err.Error())
+		return chatbotErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, reply)
+}
+
+// Versi streaming dari GetChatResponse melalui server-sent events.
+// Event: "chunk" {text} selama model menulis, lalu "done" berisi jawaban final (ganti teks jika flagged), atau "error".
+func (c *ChatbotController) StreamChatResponse(ctx echo.Context) error {
+	claims, _ := ctx.Get("user").(*service.JwtCustomClaims)
+
+	var request struct {
+		SessionID int    `json:"session_id"`
+		Message   string `json:"message"`
+	}
+
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid")
+	}
+
+	stream := helper.NewSSEWriter(ctx)
+	reqCtx := ctx.Request().Context()
+	reply, err := c.ChatbotUsecase.StreamResponse(reqCtx, claims.UserID, request.SessionID, request.Message, func(chunk string) error {
+		return stream.Send("chunk", map[string]string{"text": chunk})
+	})
+	if err != nil {
+		if reqCtx.Err() != nil {
+			return nil // Klien terputus, permintaan ke model sudah dibatalkan
+		}
+		if !stream.Started() {
+			return chatbotErrorResponse(ctx, err)
+		}
+		return stream.Send("error", map[string]string{"message": "Gagal memproses permintaan: " + err.Error()})
+	}
+
+	return stream.Send("done", reply)
+}
+
+func (c *ChatbotController) GetSessions(ctx echo.Context) error {
+	claims, _ := ctx.Get("user").(*service.JwtCustomClaims)
+
+	sessions, page, err := c.ChatbotUsecase.ListSessions(claims.UserID, helper.ParseQueryOptions(ctx))
+	if err != nil {
+		return chatbotErrorResponse(ctx, err)
+	}
+
+	return helper.JSONPaginatedResponse(ctx, sessions, page)
+}
+
+func (c *ChatbotController) CreateSession(ctx echo.Context) error {
+	claims, _ := ctx.Get("user").(*service.JwtCustomClaims)
+
+	var request struct {
+		Title string `json:"title"`
+	}
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid")
+	}
+
+	session, err := c.ChatbotUsecase.CreateSession(claims.UserID, request.Title)
+	if err != nil {
+		return chatbotErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, session)
+}
+
+func (c *ChatbotController) RenameSession(ctx echo.Context) error {
+	claims, _ := ctx.Get("user").(*service.JwtCustomClaims)
+
+	sessionID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID sesi tidak valid")
+	}
+
+	var request struct {
+		Title string `json:"title"`
+	}
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid")
+	}
+
+	session, err := c.ChatbotUsecase.RenameSession(claims.UserID, sessionID, request.Title)
+	if err != nil {
+		return chatbotErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, session)
+}
+
+func (c *ChatbotController) DeleteSession(ctx echo.Context) error {
+	claims, _ := ctx.Get("user").(*service.JwtCustomClaims)
+
+	sessionID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID sesi tidak valid")
+	}
+
+	if err := c.ChatbotUsecase.DeleteSession(claims.UserID, sessionID); err != nil {
+		return chatbotErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Sesi percakapan berhasil dihapus")
+}
+
+// Riwayat pesan sebuah sesi, halaman berikutnya memakai ?before_id=
+func (c *ChatbotController) GetSessionMessages(ctx echo.Context) error {
+	claims, _ := ctx.Get("user").(*service.JwtCustomClaims)
+
+	sessionID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID sesi tidak valid")
+	}
+
+	beforeID, _ := strconv.Atoi(ctx.QueryParam("before_id"))
+	limit, _ := strconv.Atoi(ctx.QueryParam("limit"))
+
+	messages, err := c.ChatbotUsecase.GetSessionHistory(claims.UserID, sessionID, beforeID, limit)
+	if err != nil {
+		return chatbotErrorResponse(ctx, err)
+	}
+
+	nextBeforeID := 0
+	if len(messages) > 0 {
+		nextBeforeID = messages[0].ID
 	}
 
 	return helper.JSONSuccessResponse(ctx, map[string]interface{}{
-		"message":  request.Message,
-		"response": response,
+		"messages":       messages,
+		"next_before_id": nextBeforeID,
 	})
 }
+
+func chatbotErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrSessionNotFound):
+		return helper.JSONErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrInvalidTitle), errors.Is(err, usecase.ErrEmptyMessage), helper.IsInvalidQuery(err):
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	default:
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal memproses permintaan: "+err.Error())
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"calmind/helper"
 	"calmind/service"
 	usecase "calmind/usecase/chatbot_ai_doctor"
-	"strings"
+	"errors"
+	"strconv"
 
 	"net/http"
 
@@ -33,7 +34,7 @@ func (c *DoctorChatbotController) GetDoctorRecommendation(ctx echo.Context) erro
 	// Panggil usecase untuk mendapatkan rekomendasi
 	response, err := c.ChatbotUsecase.GenerateDoctorRecommendation(claims.UserID, request.Message)
 	if err != nil {
-		if strings.Contains(err.Error(), "pesan tidak terkait dengan rekomendasi perawatan") {
+		if errors.Is(err, usecase.ErrNotRecommendationRequest) {
 			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error()) // Menggunakan 400 Bad Request
 		}
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "gagal memproses permintaan: "+err.Error())
@@ -44,3 +45,68 @@ func (c *DoctorChatbotController) GetDoctorRecommendation(ctx echo.Context) erro
 	})
 }
 
+// Versi streaming dari GetDoctorRecommendation melalui server-sent events ("chunk", lalu "done" atau "error")
+func (c *DoctorChatbotController) StreamDoctorRecommendation(ctx echo.Context) error {
+	claims, _ := ctx.Get("doctor").(*service.JwtCustomClaims)
+
+	var request struct {
+		Message string `json:"message"`
+	}
+
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "input tidak valid")
+	}
+
+	stream := helper.NewSSEWriter(ctx)
+	reqCtx := ctx.Request().Context()
+	response, err := c.ChatbotUsecase.StreamDoctorRecommendation(reqCtx, claims.UserID, request.Message, func(chunk string) error {
+		return stream.Send("chunk", map[string]string{"text": chunk})
+	})
+	if err != nil {
+		if reqCtx.Err() != nil {
+			return nil // Klien terputus, permintaan ke model sudah dibatalkan
+		}
+		if !stream.Started() {
+			if errors.Is(err, usecase.ErrNotRecommendationRequest) {
+				return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+			}
+			return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "gagal memproses permintaan: "+err.Error())
+		}
+		return stream.Send("error", map[string]string{"message": "gagal memproses permintaan: " + err.Error()})
+	}
+
+	return stream.Send("done", map[string]interface{}{
+		"response": response,
+	})
+}
+
+// Membuat draf rekomendasi untuk konsultasi milik dokter, body opsional: {"question": "..."}
+func (c *DoctorChatbotController) DraftRecommendation(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	consultationID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid consultation ID.")
+	}
+
+	var request struct {
+		Question string `json:"question"`
+	}
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "input tidak valid")
+	}
+
+	draft, err := c.ChatbotUsecase.DraftRecommendation(ctx.Request().Context(), claims.UserID, consultationID, request.Question)
+	if err != nil {
+		if errors.Is(err, usecase.ErrConsultationNotFound) {
+			return helper.JSONErrorResponse(ctx, http.StatusNotFound, err.Error())
+		}
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "gagal memproses permintaan: "+err.Error())
+	}
+
+	return helper.JSONSuccessResponse(ctx, draft)
+}
+
@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"calmind/helper"
+	"calmind/model"
+	"calmind/service"
+	usecase "calmind/usecase/credential"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CredentialController struct {
+	CredentialUsecase usecase.CredentialUsecase
+}
+
+func NewCredentialController(credentialUsecase usecase.CredentialUsecase) *CredentialController {
+	return &CredentialController{CredentialUsecase: credentialUsecase}
+}
+
+//  -- doctor --
+
+// Melihat status verifikasi dan dokumen yang sudah diunggah
+func (c *CredentialController) GetCredentials(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	credential, err := c.CredentialUsecase.GetDoctorCredential(claims.UserID)
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mengambil data kredensial: "+err.Error())
+	}
+
+	return helper.JSONSuccessResponse(ctx, credential)
+}
+
+// Mengunggah dokumen STR atau sertifikat (form field: type, file)
+func (c *CredentialController) UploadCredential(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	// Jenis dan ukuran file diperiksa dari isinya di usecase
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "File tidak valid")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal membuka file")
+	}
+	defer src.Close()
+
+	credential, err := c.CredentialUsecase.AddCredential(claims.UserID, ctx.FormValue("type"), file.Filename, src)
+	if err != nil {
+		return credentialErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, credential)
+}
+
+// Menghapus dokumen sebelum diajukan
+func (c *CredentialController) DeleteCredential(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	credentialID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID dokumen tidak valid.")
+	}
+
+	if err := c.CredentialUsecase.DeleteCredential(claims.UserID, credentialID); err != nil {
+		return credentialErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Dokumen kredensial berhasil dihapus.")
+}
+
+// Mengunduh dokumen milik dokter yang sedang login
+func (c *CredentialController) DownloadCredential(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	credentialID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID dokumen tidak valid.")
+	}
+
+	return c.sendCredentialFile(ctx, claims.UserID, credentialID)
+}
+
+// Mengajukan dokumen untuk direview admin
+func (c *CredentialController) SubmitCredentials(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	if err := c.CredentialUsecase.Submit(claims.UserID); err != nil {
+		return credentialErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Kredensial berhasil diajukan dan menunggu review admin.")
+}
+
+//  -- admin --
+
+// Antrian review kredensial, filter opsional ?status=pending|approved|rejected|unsubmitted
+func (c *CredentialController) GetVerificationQueue(ctx echo.Context) error {
+	status := ctx.QueryParam("status")
+	switch status {
+	case "", model.CredentialStatusPending, model.CredentialStatusApproved, model.CredentialStatusRejected, model.CredentialStatusUnsubmitted:
+	default:
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Status kredensial tidak valid.")
+	}
+
+	queue, page, err := c.CredentialUsecase.GetReviewQueue(status, helper.ParseQueryOptions(ctx, "title_id"))
+	if err != nil {
+		if helper.IsInvalidQuery(err) {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mengambil antrian verifikasi: "+err.Error())
+	}
+
+	return helper.JSONPaginatedResponse(ctx, queue, page)
+}
+
+// Detail pengajuan kredensial seorang dokter beserta riwayat review
+func (c *CredentialController) GetVerificationDetail(ctx echo.Context) error {
+	doctorID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID dokter tidak valid.")
+	}
+
+	credential, err := c.CredentialUsecase.GetDoctorCredential(doctorID)
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusNotFound, "Dokter tidak ditemukan.")
+	}
+
+	return helper.JSONSuccessResponse(ctx, credential)
+}
+
+// Mengunduh dokumen pengajuan seorang dokter untuk direview
+func (c *CredentialController) DownloadVerificationDocument(ctx echo.Context) error {
+	doctorID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID dokter tidak valid.")
+	}
+	credentialID, err := strconv.Atoi(ctx.Param("document_id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID dokumen tidak valid.")
+	}
+
+	return c.sendCredentialFile(ctx, doctorID, credentialID)
+}
+
+func (c *CredentialController) ApproveCredential(ctx echo.Context) error {
+	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	doctorID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID dokter tidak valid.")
+	}
+
+	if err := c.CredentialUsecase.Approve(claims.UserID, doctorID); err != nil {
+		return credentialErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Kredensial dokter berhasil disetujui.")
+}
+
+func (c *CredentialController) RejectCredential(ctx echo.Context) error {
+	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	doctorID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID dokter tidak valid.")
+	}
+
+	var request struct {
+		Reason string `json:"reason"`
+	}
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid.")
+	}
+
+	if err := c.CredentialUsecase.Reject(claims.UserID, doctorID, request.Reason); err != nil {
+		return credentialErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Kredensial dokter ditolak.")
+}
+
+// Dokumen dikirim sebagai lampiran dan tidak boleh ditafsirkan browser sebagai tipe lain
+func (c *CredentialController) sendCredentialFile(ctx echo.Context, doctorID, credentialID int) error {
+	file, err := c.CredentialUsecase.OpenCredential(doctorID, credentialID)
+	if err != nil {
+		return credentialErrorResponse(ctx, err)
+	}
+	if file.Body == nil {
+		return ctx.Redirect(http.StatusFound, file.URL)
+	}
+	defer file.Body.Close()
+
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": file.Name}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Cache-Control", "private, no-store")
+	return ctx.Stream(http.StatusOK, file.ContentType, file.Body)
+}
+
+func credentialErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrCredentialLocked), errors.Is(err, usecase.ErrNotPendingReview):
+		return helper.JSONErrorResponse(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, usecase.ErrInvalidCredentialType), errors.Is(err, usecase.ErrSTRNumberRequired),
+		errors.Is(err, usecase.ErrSTRDocumentRequired), errors.Is(err, usecase.ErrRejectReasonRequired),
+		errors.Is(err, usecase.ErrUnsupportedFileType):
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, usecase.ErrFileTooLarge):
+		return helper.JSONErrorResponse(ctx, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, usecase.ErrCredentialNotFound):
+		return helper.JSONErrorResponse(ctx, http.StatusNotFound, err.Error())
+	default:
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -2,24 +2,29 @@ package controller
 
 import (
 	"calmind/helper"
+	repository "calmind/repository/customer_service"
+	"calmind/service"
 	usecase "calmind/usecase/customer_service"
+	usecase_faq "calmind/usecase/faq"
+	"errors"
+	"strconv"
 
 	"net/http"
-	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
 type CustServiceController struct {
 	CustServiceUsecase usecase.CustServiceUsecase
+	FaqUsecase         usecase_faq.FaqUsecase
 }
 
-func NewCustServiceController(custServiceUsecase usecase.CustServiceUsecase) *CustServiceController {
-	return &CustServiceController{CustServiceUsecase: custServiceUsecase}
+func NewCustServiceController(custServiceUsecase usecase.CustServiceUsecase, faqUsecase usecase_faq.FaqUsecase) *CustServiceController {
+	return &CustServiceController{CustServiceUsecase: custServiceUsecase, FaqUsecase: faqUsecase}
 }
 
+// Menjawab pertanyaan dari FAQ. Pesan berupa nomor FAQ ("1", "2", ...) atau teks bebas.
 func (c *CustServiceController) GetResponse(ctx echo.Context) error {
-
 	var request struct {
 		Message string `json:"message"`
 	}
@@ -28,59 +33,218 @@ func (c *CustServiceController) GetResponse(ctx echo.Context) error {
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid")
 	}
 
-	// Static FAQ responses
-	standardResponses := map[string]string{
-		"1": "Metode pembayaran yang bisa digunakan Kartu kredit/debit E-wallet (GoPay, OVO, dll.) Transfer bank Silahkan pilih metode yang paling nyaman bagi Anda 😊",
-		"2": "Setelah memilih dokter dan metode pembayaran, Anda akan diarahkan ke halaman pembayaran. Ikuti petunjuk di layar untuk menyelesaikan pembayaran. Pilih dokter dan klik buat janji Isi keluhan yang sedang dialami Pilih metode pembayaran Ikuti petunjuk di layar untuk menyelesaikan pembayaran.",
-		"3": "Anda harus melakukan konsultasi jika mengalami gejala-gejala berikut: Kesulitan mengelola emosi atau stres. Memiliki pikiran negatif yang mengganggu aktivitas sehari-hari. Mengalami perubahan perilaku yang signifikan. Ingin mengembangkan diri atau meningkatkan kualitas hidup.",
-		"4": "Konsultasi bisa dilakukan dengan cara sebagai berikut: Pilih dokter yang ingin dihubungi. Isi form keluhan sesuai dengan keadaan yang dialami. Lakukan pembayaran Anda akan terhubung dengan dokter.",
-		"5": "Mendaftar akun CalmMind sangat mudah. Klik tombol 'Daftar' pada halaman awal. Isi data pada form sesuai instruksi yang diberikan. Konfirmasi pada email yang terdaftar. Akun berhasil didaftarkan.",
-		"6": "Jika tidak bisa masuk ke akun Anda, ada beberapa hal yang harus Anda lakukan. Periksa kembali koneksi internet. Pastikan nama pengguna dan kata sandi yang dimasukkan sudah benar. Jika masih mengalami masalah silahkan reset kata sandi dan hubungi Admin.",
-		"7": "Jangan khawatir, Anda bisa mereset kata sandi dengan mudah. Klik tombol 'Lupa Kata Sandi' pada halaman masuk. Ikuti petunjuk yang diberikan. Anda akan menerima email berisi tautan untuk mengatur ulang kata sandi.",
+	answer, err := c.FaqUsecase.Answer(ctx.Request().Context(), ctx.RealIP(), request.Message)
+	if err != nil {
+		if errors.Is(err, usecase_faq.ErrEmptyQuestion) || errors.Is(err, usecase_faq.ErrQuestionTooLong) {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal memproses pertanyaan: "+err.Error())
 	}
 
-	lowerMessage := strings.ToLower(request.Message)
-	if response, exists := standardResponses[lowerMessage]; exists {
-		return helper.JSONSuccessResponse(ctx, map[string]interface{}{
-			"message":  request.Message,
-			"response": response,
-		})
+	return helper.JSONSuccessResponse(ctx, answer)
+}
+
+// Daftar pertanyaan FAQ dengan nomor FAQ sebagai kunci
+func (c *CustServiceController) GetQuestion(ctx echo.Context) error {
+	questions, err := c.FaqUsecase.GetQuestions()
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mengambil pertanyaan: "+err.Error())
 	}
 
-	return helper.JSONSuccessResponse(ctx, map[string]interface{}{
-		"message":  request.Message,
-		"response": "Jawaban atas pertanyaan anda tidak tersedia. Silahkan hubungi Admin melalui kontak berikut https://wa.me/+6283820440747",
-	})
+	return helper.JSONSuccessResponse(ctx, questions)
 }
 
-func (c *CustServiceController) AnswerMessage(ctx echo.Context) error {
+// Membuka tiket bantuan baru (body: subject, message)
+func (c *CustServiceController) OpenTicket(ctx echo.Context) error {
+	claims, _ := ctx.Get("user").(*service.JwtCustomClaims)
+
 	var request struct {
-		ID     int    `json:"id"`
-		Answer string `json:"answer"`
+		Subject string `json:"subject"`
+		Message string `json:"message"`
+	}
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid")
+	}
+
+	ticket, err := c.CustServiceUsecase.OpenTicket(claims.UserID, request.Subject, request.Message)
+	if err != nil {
+		return ticketErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, ticket)
+}
+
+func (c *CustServiceController) GetUserTickets(ctx echo.Context) error {
+	claims, _ := ctx.Get("user").(*service.JwtCustomClaims)
+
+	tickets, page, err := c.CustServiceUsecase.GetUserTickets(claims.UserID, helper.ParseQueryOptions(ctx, "status"))
+	if err != nil {
+		return ticketErrorResponse(ctx, err)
+	}
+
+	return helper.JSONPaginatedResponse(ctx, tickets, page)
+}
+
+// Detail tiket milik user beserta balasan
+func (c *CustServiceController) GetUserTicket(ctx echo.Context) error {
+	claims, _ := ctx.Get("user").(*service.JwtCustomClaims)
+
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID tiket tidak valid")
+	}
+
+	ticket, err := c.CustServiceUsecase.GetUserTicket(claims.UserID, ticketID)
+	if err != nil {
+		return ticketErrorResponse(ctx, err)
 	}
 
+	return helper.JSONSuccessResponse(ctx, ticket)
+}
+
+func (c *CustServiceController) ReplyAsUser(ctx echo.Context) error {
+	claims, _ := ctx.Get("user").(*service.JwtCustomClaims)
+
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID tiket tidak valid")
+	}
+
+	var request struct {
+		Message string `json:"message"`
+	}
 	if err := ctx.Bind(&request); err != nil {
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid")
 	}
 
-	err := c.CustServiceUsecase.AnswerMessage(request.ID, request.Answer)
+	reply, err := c.CustServiceUsecase.ReplyAsUser(claims.UserID, ticketID, request.Message)
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal menjawab pesan")
+		return ticketErrorResponse(ctx, err)
 	}
 
-	return helper.JSONSuccessResponse(ctx, "Pesan berhasil dijawab.")
+	return helper.JSONSuccessResponse(ctx, reply)
 }
 
-func (c *CustServiceController) GetQuestion(ctx echo.Context) error {
-	standardResponses := map[string]string{
-		"1": "Metode pembayaran?",
-		"2": "Proses pembayaran?",
-		"3": "Kapan harus melakukan konsultasi?",
-		"4": "Bagaimana cara melakukan konsultasi?",
-		"5": "Bagaimana cara mendaftar akun?",
-		"6": "Apa yang harus dilakukan jika tidak bisa masuk?",
-		"7": "Bagaimana jika lupa kata sandi?",
+func (c *CustServiceController) CloseTicket(ctx echo.Context) error {
+	claims, _ := ctx.Get("user").(*service.JwtCustomClaims)
+
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID tiket tidak valid")
+	}
+
+	if err := c.CustServiceUsecase.CloseTicket(claims.UserID, ticketID); err != nil {
+		return ticketErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Tiket berhasil ditutup")
+}
+
+// Inbox tiket admin, filter opsional ?status=&priority=&assigned=me|none|<admin_id>
+func (c *CustServiceController) GetTickets(ctx echo.Context) error {
+	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	filter := repository.TicketFilter{
+		Status:   ctx.QueryParam("status"),
+		Priority: ctx.QueryParam("priority"),
+	}
+	switch assigned := ctx.QueryParam("assigned"); assigned {
+	case "":
+	case "me":
+		filter.AssignedAdminID = claims.UserID
+	case "none":
+		filter.Unassigned = true
+	default:
+		adminID, err := strconv.Atoi(assigned)
+		if err != nil || adminID <= 0 {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Filter assigned tidak valid.")
+		}
+		filter.AssignedAdminID = adminID
+	}
+
+	tickets, page, err := c.CustServiceUsecase.GetTickets(filter, helper.ParseQueryOptions(ctx))
+	if err != nil {
+		return ticketErrorResponse(ctx, err)
+	}
+
+	return helper.JSONPaginatedResponse(ctx, tickets, page)
+}
+
+func (c *CustServiceController) GetTicket(ctx echo.Context) error {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID tiket tidak valid.")
+	}
+
+	ticket, err := c.CustServiceUsecase.GetTicket(ticketID)
+	if err != nil {
+		return ticketErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, ticket)
+}
+
+// Menjawab tiket (body: answer), user menerima email pemberitahuan
+func (c *CustServiceController) AnswerTicket(ctx echo.Context) error {
+	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID tiket tidak valid.")
 	}
 
-	return helper.JSONSuccessResponse(ctx, standardResponses)
+	var request struct {
+		Answer string `json:"answer"`
+	}
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid.")
+	}
+
+	reply, err := c.CustServiceUsecase.AnswerTicket(claims.UserID, ticketID, request.Answer)
+	if err != nil {
+		return ticketErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, reply)
+}
+
+// Mengubah status, prioritas, atau penugasan tiket (body: status, priority, assigned_admin_id)
+func (c *CustServiceController) UpdateTicket(ctx echo.Context) error {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID tiket tidak valid.")
+	}
+
+	var request usecase.TicketUpdate
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid.")
+	}
+
+	ticket, err := c.CustServiceUsecase.UpdateTicket(ticketID, request)
+	if err != nil {
+		return ticketErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, ticket)
+}
+
+func ticketErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrTicketNotFound), errors.Is(err, usecase.ErrAdminNotFound):
+		return helper.JSONErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrEmptyTicketMessage), errors.Is(err, usecase.ErrSubjectTooLong),
+		errors.Is(err, usecase.ErrInvalidTicketState), errors.Is(err, usecase.ErrInvalidPriority),
+		helper.IsInvalidQuery(err):
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, usecase.ErrTicketClosed):
+		return helper.JSONErrorResponse(ctx, http.StatusConflict, err.Error())
+	default:
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal memproses tiket: "+err.Error())
+	}
 }
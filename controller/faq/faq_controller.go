@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"calmind/helper"
+	usecase "calmind/usecase/faq"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type FaqController struct {
+	FaqUsecase usecase.FaqUsecase
+}
+
+func NewFaqController(faqUsecase usecase.FaqUsecase) *FaqController {
+	return &FaqController{FaqUsecase: faqUsecase}
+}
+
+type faqCategoryRequest struct {
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+// Kategori FAQ beserta pertanyaan dan jawabannya
+func (c *FaqController) GetCategories(ctx echo.Context) error {
+	categories, err := c.FaqUsecase.GetCategories()
+	if err != nil {
+		return faqErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, categories)
+}
+
+func (c *FaqController) CreateCategory(ctx echo.Context) error {
+	var request faqCategoryRequest
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid.")
+	}
+
+	category, err := c.FaqUsecase.CreateCategory(request.Name, request.Position)
+	if err != nil {
+		return faqErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, category)
+}
+
+func (c *FaqController) UpdateCategory(ctx echo.Context) error {
+	categoryID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID kategori tidak valid.")
+	}
+
+	var request faqCategoryRequest
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid.")
+	}
+
+	category, err := c.FaqUsecase.UpdateCategory(categoryID, request.Name, request.Position)
+	if err != nil {
+		return faqErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, category)
+}
+
+func (c *FaqController) DeleteCategory(ctx echo.Context) error {
+	categoryID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID kategori tidak valid.")
+	}
+
+	if err := c.FaqUsecase.DeleteCategory(categoryID); err != nil {
+		return faqErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Kategori FAQ berhasil dihapus.")
+}
+
+func (c *FaqController) GetFaqs(ctx echo.Context) error {
+	faqs, err := c.FaqUsecase.GetFaqs()
+	if err != nil {
+		return faqErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, faqs)
+}
+
+// Menambah FAQ (body: category_id, question, answer, keywords, position)
+func (c *FaqController) CreateFaq(ctx echo.Context) error {
+	var request usecase.FaqInput
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid.")
+	}
+
+	faq, err := c.FaqUsecase.CreateFaq(request)
+	if err != nil {
+		return faqErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, faq)
+}
+
+func (c *FaqController) UpdateFaq(ctx echo.Context) error {
+	faqID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID FAQ tidak valid.")
+	}
+
+	var request usecase.FaqInput
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid.")
+	}
+
+	faq, err := c.FaqUsecase.UpdateFaq(faqID, request)
+	if err != nil {
+		return faqErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, faq)
+}
+
+func (c *FaqController) DeleteFaq(ctx echo.Context) error {
+	faqID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID FAQ tidak valid.")
+	}
+
+	if err := c.FaqUsecase.DeleteFaq(faqID); err != nil {
+		return faqErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, "FAQ berhasil dihapus.")
+}
+
+func faqErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrFaqNotFound), errors.Is(err, usecase.ErrFaqCategoryNotFound):
+		return helper.JSONErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrInvalidFaq), errors.Is(err, usecase.ErrInvalidCategoryName):
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, usecase.ErrCategoryNotEmpty):
+		return helper.JSONErrorResponse(ctx, http.StatusConflict, err.Error())
+	default:
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"calmind/helper"
+	"calmind/model"
+	"calmind/service"
+	usecase "calmind/usecase/jadwal"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type JadwalController struct {
+	JadwalUsecase usecase.JadwalUsecase
+}
+
+func NewJadwalController(jadwalUsecase usecase.JadwalUsecase) *JadwalController {
+	return &JadwalController{JadwalUsecase: jadwalUsecase}
+}
+
+//  -- dokter --
+
+// Melihat jadwal mingguan dan pengecualian milik dokter
+func (c *JadwalController) GetSchedule(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access")
+	}
+
+	schedules, exceptions, err := c.JadwalUsecase.GetDoctorSchedule(claims.UserID)
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mengambil jadwal: "+err.Error())
+	}
+
+	return helper.JSONSuccessResponse(ctx, map[string]interface{}{
+		"weekly":     schedules,
+		"exceptions": exceptions,
+	})
+}
+
+// Menambahkan jadwal mingguan
+func (c *JadwalController) AddSchedule(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access")
+	}
+
+	var schedule model.DoctorSchedule
+	if err := ctx.Bind(&schedule); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Gagal memproses data: "+err.Error())
+	}
+
+	if err := c.JadwalUsecase.AddSchedule(claims.UserID, &schedule); err != nil {
+		return scheduleErrorResponse(ctx, "Gagal menambahkan jadwal: ", err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, schedule)
+}
+
+// Memperbarui jadwal mingguan
+func (c *JadwalController) UpdateSchedule(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access")
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID jadwal tidak valid")
+	}
+
+	var schedule model.DoctorSchedule
+	if err := ctx.Bind(&schedule); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Gagal memproses data: "+err.Error())
+	}
+
+	updated, err := c.JadwalUsecase.UpdateSchedule(claims.UserID, id, &schedule)
+	if err != nil {
+		return scheduleErrorResponse(ctx, "Gagal memperbarui jadwal: ", err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, updated)
+}
+
+// Menghapus jadwal mingguan
+func (c *JadwalController) DeleteSchedule(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access")
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID jadwal tidak valid")
+	}
+
+	if err := c.JadwalUsecase.DeleteSchedule(claims.UserID, id); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusNotFound, "Gagal menghapus jadwal: "+err.Error())
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Jadwal berhasil dihapus")
+}
+
+// Menambahkan pengecualian jadwal (libur, blokir jam, atau jam tambahan)
+func (c *JadwalController) AddException(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access")
+	}
+
+	var exception model.DoctorScheduleException
+	if err := ctx.Bind(&exception); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Gagal memproses data: "+err.Error())
+	}
+
+	if err := c.JadwalUsecase.AddException(claims.UserID, &exception); err != nil {
+		return scheduleErrorResponse(ctx, "Gagal menambahkan pengecualian jadwal: ", err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, exception)
+}
+
+// Menghapus pengecualian jadwal
+func (c *JadwalController) DeleteException(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access")
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID pengecualian tidak valid")
+	}
+
+	if err := c.JadwalUsecase.DeleteException(claims.UserID, id); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusNotFound, "Gagal menghapus pengecualian jadwal: "+err.Error())
+	}
+
+	return helper.JSONSuccessResponse(ctx, "Pengecualian jadwal berhasil dihapus")
+}
+
+//  -- user --
+
+// Melihat slot yang masih bisa dipesan pada dokter tertentu
+func (c *JadwalController) GetDoctorSlots(ctx echo.Context) error {
+	doctorID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID dokter tidak valid")
+	}
+
+	from := time.Now()
+	if value := ctx.QueryParam("from"); value != "" {
+		from, err = time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Parameter 'from' harus berformat YYYY-MM-DD")
+		}
+	}
+
+	to := from.AddDate(0, 0, 6)
+	if value := ctx.QueryParam("to"); value != "" {
+		to, err = time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Parameter 'to' harus berformat YYYY-MM-DD")
+		}
+	}
+
+	slots, err := c.JadwalUsecase.GetAvailableSlots(doctorID, from, to)
+	if err != nil {
+		return scheduleErrorResponse(ctx, "Gagal mengambil slot jadwal: ", err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, slots)
+}
+
+func scheduleErrorResponse(ctx echo.Context, prefix string, err error) error {
+	if errors.Is(err, usecase.ErrInvalidSchedule) {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, prefix+err.Error())
+	}
+	return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, prefix+err.Error())
+}
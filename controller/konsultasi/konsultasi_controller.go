@@ -3,8 +3,11 @@ package controller
 import (
 	"calmind/helper"
 	"calmind/model"
+	repository "calmind/repository/konsultasi"
 	"calmind/service"
+	"calmind/service/lifecycle"
 	usecase "calmind/usecase/konsultasi"
+	"errors"
 
 	"net/http"
 	"strconv"
@@ -21,6 +24,9 @@ func NewConsultationController(consultationUsecase *usecase.ConsultationUsecaseI
 	return &ConsultationController{ConsultationUsecase: consultationUsecase}
 }
 
+// Filter daftar konsultasi yang bisa dipakai admin
+var adminConsultationFilters = []string{"status", "payment_status", "user_id", "doctor_id"}
+
 //  -- user --
 
 // Melihat semua konsultasi user
@@ -30,17 +36,21 @@ func (c *ConsultationController) GetUserConsultations(ctx echo.Context) error {
 		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
 	}
 
-	consultations, err := c.ConsultationUsecase.GetUserConsultations(claims.UserID)
+	opts := helper.ParseQueryOptions(ctx, "status", "payment_status", "doctor_id")
+	consultations, page, err := c.ConsultationUsecase.GetUserConsultations(claims.UserID, opts)
 	if err != nil {
+		if helper.IsInvalidQuery(err) {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve consultations.")
 	}
 
-	var response []model.ConsultationDTO
+	response := make([]model.ConsultationDTO, 0, len(consultations))
 	for _, cons := range consultations {
 		response = append(response, mapConsultationToDTO(cons))
 	}
 
-	return helper.JSONSuccessResponse(ctx, response)
+	return helper.JSONPaginatedResponse(ctx, response, page)
 }
 
 // Melihat detail konsultasi user
@@ -78,14 +88,26 @@ func (c *ConsultationController) CreateConsultation(ctx echo.Context) error {
 		DoctorID    int    `json:"doctor_id"`
 		Title       string `json:"title"`
 		Description string `json:"description"`
+		StartTime   string `json:"start_time"` // Slot yang dipilih (RFC3339)
 	}
 
 	if err := ctx.Bind(&request); err != nil {
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid input.")
 	}
 
-	paymentURL, consultation, err := c.ConsultationUsecase.CreateConsultation(claims.UserID, request.DoctorID, request.Title, request.Description, claims.Email)
+	startTime, err := time.Parse(time.RFC3339, request.StartTime)
 	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Field 'start_time' is required and must be an RFC3339 slot time.")
+	}
+
+	paymentURL, consultation, err := c.ConsultationUsecase.CreateConsultation(claims.UserID, request.DoctorID, request.Title, request.Description, claims.Email, startTime)
+	if err != nil {
+		if errors.Is(err, usecase.ErrSlotUnavailable) {
+			return helper.JSONErrorResponse(ctx, http.StatusConflict, "Selected slot is not available, please choose another time.")
+		}
+		if errors.Is(err, usecase.ErrDoctorNotBookable) {
+			return helper.JSONErrorResponse(ctx, http.StatusForbidden, "Doctor is not verified yet and cannot be booked.")
+		}
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Failed to create consultation: "+err.Error())
 	}
 
@@ -97,6 +119,7 @@ func (c *ConsultationController) CreateConsultation(ctx echo.Context) error {
 		Duration:      consultation.Duration,
 		Status:        consultation.Status,
 		PaymentStatus: consultation.PaymentStatus,
+		Amount:        consultation.Amount,
 		StartTime:     consultation.StartTime.Format(time.RFC3339),
 		OrderID:       consultation.OrderID,
 		PaymentURL:    paymentURL,
@@ -111,19 +134,22 @@ func (c *ConsultationController) GetAllConsultationsForDoctor(ctx echo.Context)
 		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
 	}
 
-	consultations, err := c.ConsultationUsecase.GetAllConsultationsForDoctor(claims.UserID)
+	opts := helper.ParseQueryOptions(ctx, "status", "user_id")
+	consultations, page, err := c.ConsultationUsecase.GetAllConsultationsForDoctor(claims.UserID, opts)
 	if err != nil {
+		if helper.IsInvalidQuery(err) {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve consultations.")
 	}
 
-	var response []model.ConsultationDTO
+	// Repository hanya mengembalikan konsultasi yang sudah dibayar
+	response := make([]model.ConsultationDTO, 0, len(consultations))
 	for _, cons := range consultations {
-		if cons.Status == "paid" || cons.Status == "approved" || cons.Status == "expired" { // Tambahkan "approved"
-			response = append(response, mapConsultationToDTO(cons))
-		}
+		response = append(response, mapConsultationToDTO(cons))
 	}
 
-	return helper.JSONSuccessResponse(ctx, response)
+	return helper.JSONPaginatedResponse(ctx, response, page)
 }
 
 // Mendapatkan daftar konsultasi untuk dokter (search by name)
@@ -141,18 +167,22 @@ func (c *ConsultationController) SearchConsultationsByName(ctx echo.Context) err
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Query parameter 'nama' is required.")
 	}
 
-	consultations, err := c.ConsultationUsecase.SearchConsultationsByName(doctorID, searchName)
+	opts := helper.ParseQueryOptions(ctx, "status")
+	consultations, page, err := c.ConsultationUsecase.SearchConsultationsByName(doctorID, searchName, opts)
 	if err != nil {
+		if helper.IsInvalidQuery(err) {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve consultations.")
 	}
 
 	// Konversi ke DTO sebelum dikembalikan
-	var response []model.ConsultationDTO
+	response := make([]model.ConsultationDTO, 0, len(consultations))
 	for _, cons := range consultations {
 		response = append(response, mapConsultationToDTO(cons))
 	}
 
-	return helper.JSONSuccessResponse(ctx, response)
+	return helper.JSONPaginatedResponse(ctx, response, page)
 }
 
 // Melihat detail konsultasi pasien
@@ -196,6 +226,12 @@ func (c *ConsultationController) AddRecommendation(ctx echo.Context) error {
 	}
 
 	err = c.ConsultationUsecase.AddRecommendation(claims.UserID, consultationID, request.Recommendation)
+	if errors.Is(err, usecase.ErrEmptyRecommendation) {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, usecase.ErrConsultationNotFound) {
+		return helper.JSONErrorResponse(ctx, http.StatusNotFound, "Consultation not found.")
+	}
 	if err != nil {
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Failed to add recommendation: "+err.Error())
 	}
@@ -212,17 +248,21 @@ func (c *ConsultationController) GetPendingConsultations(ctx echo.Context) error
 		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
 	}
 
-	consultations, err := c.ConsultationUsecase.GetPendingConsultations()
+	opts := helper.ParseQueryOptions(ctx, adminConsultationFilters...)
+	consultations, page, err := c.ConsultationUsecase.GetPendingConsultations(opts)
 	if err != nil {
+		if helper.IsInvalidQuery(err) {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve pending consultations.")
 	}
 
-	var response []model.ConsultationDTO
+	response := make([]model.ConsultationDTO, 0, len(consultations))
 	for _, cons := range consultations {
 		response = append(response, mapConsultationToDTO(cons))
 	}
 
-	return helper.JSONSuccessResponse(ctx, response)
+	return helper.JSONPaginatedResponse(ctx, response, page)
 }
 func (c *ConsultationController) GetAproveConsultations(ctx echo.Context) error {
 	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
@@ -230,17 +270,21 @@ func (c *ConsultationController) GetAproveConsultations(ctx echo.Context) error
 		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
 	}
 
-	consultations, err := c.ConsultationUsecase.GetApprovedConsultations()
+	opts := helper.ParseQueryOptions(ctx, adminConsultationFilters...)
+	consultations, page, err := c.ConsultationUsecase.GetApprovedConsultations(opts)
 	if err != nil {
+		if helper.IsInvalidQuery(err) {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve pending consultations.")
 	}
 
-	var response []model.ConsultationDTO
+	response := make([]model.ConsultationDTO, 0, len(consultations))
 	for _, cons := range consultations {
 		response = append(response, mapConsultationToDTO(cons))
 	}
 
-	return helper.JSONSuccessResponse(ctx, response)
+	return helper.JSONPaginatedResponse(ctx, response, page)
 }
 func (c *ConsultationController) GetAllStatusConsultations(ctx echo.Context) error {
 	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
@@ -248,17 +292,21 @@ func (c *ConsultationController) GetAllStatusConsultations(ctx echo.Context) err
 		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
 	}
 
-	consultations, err := c.ConsultationUsecase.GetAllStatusConsultations()
+	opts := helper.ParseQueryOptions(ctx, adminConsultationFilters...)
+	consultations, page, err := c.ConsultationUsecase.GetAllStatusConsultations(opts)
 	if err != nil {
+		if helper.IsInvalidQuery(err) {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve pending consultations.")
 	}
 
-	var response []model.ConsultationDTO
+	response := make([]model.ConsultationDTO, 0, len(consultations))
 	for _, cons := range consultations {
 		response = append(response, mapConsultationToDTO(cons))
 	}
 
-	return helper.JSONSuccessResponse(ctx, response)
+	return helper.JSONPaginatedResponse(ctx, response, page)
 }
 
 // Melihat detail konsultasi untuk persetujuan
@@ -300,8 +348,11 @@ func (c *ConsultationController) ApprovePaymentAndConsultation(ctx echo.Context)
 	}
 
 	// Panggil usecase untuk menyetujui pembayaran dan konsultasi
-	err = c.ConsultationUsecase.ApprovePaymentAndConsultation(consultationID, request.Status)
+	err = c.ConsultationUsecase.ApprovePaymentAndConsultation(claims.UserID, consultationID, request.Status)
 	if err != nil {
+		if errors.Is(err, lifecycle.ErrIllegalTransition) || errors.Is(err, repository.ErrStatusConflict) {
+			return helper.JSONErrorResponse(ctx, http.StatusConflict, "Failed to approve consultation: "+err.Error())
+		}
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Failed to approve consultation: "+err.Error())
 	}
 
@@ -310,6 +361,69 @@ func (c *ConsultationController) ApprovePaymentAndConsultation(ctx echo.Context)
 	})
 }
 
+// -- riwayat status --
+
+// Melihat riwayat status konsultasi milik user
+func (c *ConsultationController) GetUserConsultationHistory(ctx echo.Context) error {
+	claims, ok := ctx.Get("user").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	return c.consultationHistory(ctx, func(consultation *model.Consultation) bool {
+		return consultation.UserID == claims.UserID
+	})
+}
+
+// Melihat riwayat status konsultasi pasien dokter
+func (c *ConsultationController) GetDoctorConsultationHistory(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	return c.consultationHistory(ctx, func(consultation *model.Consultation) bool {
+		return consultation.DoctorID == claims.UserID
+	})
+}
+
+// Melihat riwayat status konsultasi untuk admin
+func (c *ConsultationController) GetAdminConsultationHistory(ctx echo.Context) error {
+	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	return c.consultationHistory(ctx, func(*model.Consultation) bool { return true })
+}
+
+func (c *ConsultationController) consultationHistory(ctx echo.Context, canAccess func(*model.Consultation) bool) error {
+	consultationID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid consultation ID.")
+	}
+
+	consultation, err := c.ConsultationUsecase.GetConsultationByID(consultationID)
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusNotFound, "Consultation not found.")
+	}
+
+	if !canAccess(consultation) {
+		return helper.JSONErrorResponse(ctx, http.StatusForbidden, "You do not have access to this consultation.")
+	}
+
+	histories, err := c.ConsultationUsecase.GetStatusHistory(consultationID)
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve consultation history.")
+	}
+
+	return helper.JSONSuccessResponse(ctx, map[string]interface{}{
+		"consultation_id": consultation.ID,
+		"status":          consultation.Status,
+		"history":         histories,
+	})
+}
+
 // -- dto respon --
 
 func mapConsultationToDTO(consultation model.Consultation) model.ConsultationDTO {
@@ -320,6 +434,7 @@ func mapConsultationToDTO(consultation model.Consultation) model.ConsultationDTO
 		Duration:      consultation.Duration,
 		Status:        consultation.Status,
 		PaymentStatus: consultation.PaymentStatus,
+		Amount:        consultation.Amount,
 		StartTime:     consultation.StartTime.Format(time.RFC3339),
 		OrderID:       consultation.OrderID, // Include OrderID
 		CreatedAt:     consultation.CreatedAt,
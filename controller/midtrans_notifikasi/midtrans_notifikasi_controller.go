@@ -1,7 +1,11 @@
 package controller
 
 import (
+	"calmind/model"
 	usecase "calmind/usecase/konsultasi"
+	"encoding/json"
+	"errors"
+	"io"
 
 	"log"
 	"net/http"
@@ -18,30 +22,45 @@ func NewMidtransNotificationController(konsultasi *usecase.ConsultationUsecaseIm
 }
 
 func (c *MidtransNotificationController) MidtransNotification(ctx echo.Context) error {
-	var notification map[string]interface{}
-	if err := ctx.Bind(&notification); err != nil {
-		log.Printf("Failed to bind notification: %v", err)
+	// Baca body mentah agar payload asli bisa disimpan di log payment event
+	body, err := io.ReadAll(io.LimitReader(ctx.Request().Body, 1<<20))
+	if err != nil {
+		log.Printf("Failed to read notification body: %v", err)
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid payload"})
 	}
 
-	// Log payload yang diterima
-	log.Printf("Received Midtrans Notification: %+v\n", notification)
-
-	transactionStatus, ok := notification["transaction_status"].(string)
-	orderID, ok := notification["order_id"].(string)
+	var notification model.MidtransNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		log.Printf("Failed to bind notification: %v", err)
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid payload"})
+	}
 
-	if !ok || transactionStatus == "" || orderID == "" {
+	if notification.OrderID == "" || notification.TransactionStatus == "" {
 		log.Println("Invalid notification data")
 		return ctx.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid notification data"})
 	}
 
-	// Update payment status di database
-	err := c.ConsultationUsecase.UpdatePaymentStatus(orderID, transactionStatus)
-	if err != nil {
-		log.Printf("Failed to update payment status for order_id=%s: %v", orderID, err)
+	log.Printf("Received Midtrans Notification: order_id=%s status=%s", notification.OrderID, notification.TransactionStatus)
+
+	err = c.ConsultationUsecase.HandleMidtransNotification(notification, string(body))
+	switch {
+	case err == nil:
+	case errors.Is(err, usecase.ErrDuplicateNotification):
+		// Midtrans mengirim ulang notifikasi yang sama, cukup dibalas 200 agar tidak dikirim lagi
+		return ctx.JSON(http.StatusOK, map[string]string{"message": "Notification already processed"})
+	case errors.Is(err, usecase.ErrInvalidSignature):
+		log.Printf("Rejected notification with invalid signature for order_id=%s", notification.OrderID)
+		return ctx.JSON(http.StatusForbidden, map[string]string{"message": "Invalid signature"})
+	case errors.Is(err, usecase.ErrAmountMismatch):
+		log.Printf("Rejected notification with mismatched amount for order_id=%s", notification.OrderID)
+		return ctx.JSON(http.StatusBadRequest, map[string]string{"message": "Gross amount mismatch"})
+	case errors.Is(err, usecase.ErrOrderNotFound):
+		return ctx.JSON(http.StatusNotFound, map[string]string{"message": "Order not found"})
+	default:
+		log.Printf("Failed to update payment status for order_id=%s: %v", notification.OrderID, err)
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to update payment status"})
 	}
 
-	log.Printf("Payment status updated successfully for order_id=%s", orderID)
+	log.Printf("Payment status updated successfully for order_id=%s", notification.OrderID)
 	return ctx.JSON(http.StatusOK, map[string]string{"message": "Notification processed successfully"})
 }
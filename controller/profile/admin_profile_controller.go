@@ -3,21 +3,21 @@ package controller
 import (
 	"calmind/helper"
 	"calmind/service"
+	"calmind/service/upload"
 	usecase "calmind/usecase/profile"
-	"fmt"
 
 	"net/http"
-	"path/filepath"
 
 	"github.com/labstack/echo/v4"
 )
 
 type AdminController struct {
 	AdminUsecase usecase.AdminProfileUseCase
+	Images       upload.ImagePipeline
 }
 
-func NewAdminController(adminUsecase usecase.AdminProfileUseCase) *AdminController {
-	return &AdminController{AdminUsecase: adminUsecase}
+func NewAdminController(adminUsecase usecase.AdminProfileUseCase, images upload.ImagePipeline) *AdminController {
+	return &AdminController{AdminUsecase: adminUsecase, Images: images}
 }
 
 // Get Admin Profile
@@ -50,15 +50,10 @@ func (c *AdminController) UploadAdminAvatar(ctx echo.Context) error {
 	}
 	adminID := claims.UserID
 
-	// Ambil file dari form
+	// Ambil file dari form, tipe dan ukuran diperiksa dari isi file oleh pipeline upload
 	file, err := ctx.FormFile("avatar")
-	if err != nil || file.Size > 5*1024*1024 {
-		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "File tidak valid atau ukuran melebihi 5 MB")
-	}
-
-	ext := filepath.Ext(file.Filename)
-	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
-		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Format file tidak didukung")
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "File tidak valid")
 	}
 
 	src, err := file.Open()
@@ -67,21 +62,27 @@ func (c *AdminController) UploadAdminAvatar(ctx echo.Context) error {
 	}
 	defer src.Close()
 
-	// Upload ke Cloudinary
-	fileName := fmt.Sprintf("admin_%d_avatar%s", adminID, ext)
-	avatarURL, _, err := helper.UploadFileToCloudinary(src, fileName)
+	previous := ""
+	if admin, err := c.AdminUsecase.GetAdminProfile(adminID); err == nil {
+		previous = admin.Avatar
+	}
+
+	// Validasi, hapus metadata EXIF, resize, dan buat thumbnail sebelum disimpan
+	result, err := c.Images.Process(ctx.Request().Context(), upload.Avatar, avatarKey("admin", adminID), src)
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal upload ke Cloudinary")
+		return imageUploadErrorResponse(ctx, err)
 	}
 
 	// Update database
-	if err := c.AdminUsecase.UploadAdminAvatar(adminID, avatarURL, ""); err != nil {
+	if err := c.AdminUsecase.UploadAdminAvatar(adminID, result.Image.URL, ""); err != nil {
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mengupdate database")
 	}
+	deletePreviousAvatar(ctx.Request().Context(), c.Images, previous, result.Image.URL)
 
 	return helper.JSONSuccessResponse(ctx, map[string]string{
-		"message":   "Avatar berhasil diupload",
-		"avatarUrl": avatarURL,
+		"message":      "Avatar berhasil diupload",
+		"avatarUrl":    result.Image.URL,
+		"thumbnailUrl": result.Thumbnail.URL,
 	})
 }
 
@@ -92,7 +93,7 @@ func (c *AdminController) DeleteAdminAvatar(ctx echo.Context) error {
 	}
 	adminID := claims.UserID
 
-	// Hapus avatar dari Cloudinary dan database
+	// Hapus avatar dari penyimpanan file dan database
 	if err := c.AdminUsecase.DeleteAdminAvatar(adminID); err != nil {
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, err.Error())
 	}
@@ -4,21 +4,21 @@ import (
 	"calmind/helper"
 	"calmind/model"
 	"calmind/service"
+	"calmind/service/upload"
 	usecase "calmind/usecase/profile"
-	"fmt"
+	"log"
 	"net/http"
-	"path/filepath"
-	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
 type DoctorProfileController struct {
 	DoctorProfileUsecase usecase.DoctorProfileUseCase
+	Images               upload.ImagePipeline
 }
 
-func NewDoctorProfileController(DoctorProfileUsecase usecase.DoctorProfileUseCase) *DoctorProfileController {
-	return &DoctorProfileController{DoctorProfileUsecase: DoctorProfileUsecase}
+func NewDoctorProfileController(DoctorProfileUsecase usecase.DoctorProfileUseCase, images upload.ImagePipeline) *DoctorProfileController {
+	return &DoctorProfileController{DoctorProfileUsecase: DoctorProfileUsecase, Images: images}
 }
 
 func (c *DoctorProfileController) GetProfile(ctx echo.Context) error {
@@ -44,25 +44,26 @@ func (c *DoctorProfileController) GetProfile(ctx echo.Context) error {
 	}
 
 	type DoctorResponse struct {
-		ID           int            `json:"id"`
-		Username     string         `json:"username"`
-		NoHp         string         `json:"no_hp"`
-		Email        string         `json:"email"`
-		Avatar       string         `json:"avatar"`
-		DateOfBirth  string         `json:"date_of_birth"`
-		Address      string         `json:"address"`
-		Schedule     string         `json:"schedule"`
-		IsVerified   bool           `json:"is_verified"`
-		IsActive     bool           `json:"is_active"`
-		Price        float64        `json:"price"`
-		Experience   int            `json:"experience"`
-		STRNumber    string         `json:"str_number"`
-		About        string         `json:"about"`
-		JenisKelamin string         `json:"jenis_kelamin"`
-		Title        TitleResponse  `json:"title"`
-		Tags         []TagsResponse `json:"tags"`
-		CreatedAt    string         `json:"created_at"`
-		UpdatedAt    string         `json:"updated_at"`
+		ID               int            `json:"id"`
+		Username         string         `json:"username"`
+		NoHp             string         `json:"no_hp"`
+		Email            string         `json:"email"`
+		Avatar           string         `json:"avatar"`
+		DateOfBirth      string         `json:"date_of_birth"`
+		Address          string         `json:"address"`
+		Schedule         string         `json:"schedule"`
+		IsVerified       bool           `json:"is_verified"`
+		IsActive         bool           `json:"is_active"`
+		Price            float64        `json:"price"`
+		Experience       int            `json:"experience"`
+		STRNumber        string         `json:"str_number"`
+		CredentialStatus string         `json:"credential_status"`
+		About            string         `json:"about"`
+		JenisKelamin     string         `json:"jenis_kelamin"`
+		Title            TitleResponse  `json:"title"`
+		Tags             []TagsResponse `json:"tags"`
+		CreatedAt        string         `json:"created_at"`
+		UpdatedAt        string         `json:"updated_at"`
 	}
 
 	var tagsResponse []TagsResponse
@@ -74,21 +75,22 @@ func (c *DoctorProfileController) GetProfile(ctx echo.Context) error {
 	}
 
 	doctorProfile := DoctorResponse{
-		ID:           doctor.ID,
-		Username:     doctor.Username,
-		NoHp:         doctor.NoHp,
-		Email:        doctor.Email,
-		Avatar:       doctor.Avatar,
-		DateOfBirth:  doctor.DateOfBirth,
-		Address:      doctor.Address,
-		Schedule:     doctor.Schedule,
-		IsVerified:   doctor.IsVerified,
-		IsActive:     doctor.IsActive,
-		Price:        doctor.Price,
-		Experience:   doctor.Experience,
-		STRNumber:    doctor.STRNumber,
-		About:        doctor.About,
-		JenisKelamin: doctor.JenisKelamin,
+		ID:               doctor.ID,
+		Username:         doctor.Username,
+		NoHp:             doctor.NoHp,
+		Email:            doctor.Email,
+		Avatar:           doctor.Avatar,
+		DateOfBirth:      doctor.DateOfBirth,
+		Address:          doctor.Address,
+		Schedule:         doctor.Schedule,
+		IsVerified:       doctor.IsVerified,
+		IsActive:         doctor.IsActive,
+		Price:            doctor.Price,
+		Experience:       doctor.Experience,
+		STRNumber:        doctor.STRNumber,
+		CredentialStatus: doctor.CredentialStatus,
+		About:            doctor.About,
+		JenisKelamin:     doctor.JenisKelamin,
 		Title: TitleResponse{
 			ID:   doctor.Title.ID,
 			Name: doctor.Title.Name,
@@ -194,15 +196,10 @@ func (c *DoctorProfileController) UploadAvatar(ctx echo.Context) error {
 	}
 	doctorID := claims.UserID
 
-	// Ambil file dari form
+	// Ambil file dari form, tipe dan ukuran diperiksa dari isi file oleh pipeline upload
 	file, err := ctx.FormFile("avatar")
-	if err != nil || file.Size > 5*1024*1024 {
-		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "File tidak valid atau ukuran melebihi 5 MB")
-	}
-
-	ext := filepath.Ext(file.Filename)
-	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
-		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Format file tidak didukung")
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "File tidak valid")
 	}
 
 	src, err := file.Open()
@@ -211,24 +208,30 @@ func (c *DoctorProfileController) UploadAvatar(ctx echo.Context) error {
 	}
 	defer src.Close()
 
-	// Upload ke Cloudinary
-	fileName := fmt.Sprintf("doctor_%d_avatar%s", doctorID, ext)
-	avatarURL, publicID, err := helper.UploadFileToCloudinary(src, fileName)
+	previous := ""
+	if doctor, err := c.DoctorProfileUsecase.GetDoctorProfile(doctorID); err == nil {
+		previous = doctor.Avatar
+	}
+
+	// Validasi, hapus metadata EXIF, resize, dan buat thumbnail sebelum disimpan
+	result, err := c.Images.Process(ctx.Request().Context(), upload.Avatar, avatarKey("doctor", doctorID), src)
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal upload ke Cloudinary")
+		return imageUploadErrorResponse(ctx, err)
 	}
 
 	// Update database
-	doctor := model.Doctor{Avatar: avatarURL}
+	doctor := model.Doctor{Avatar: result.Image.URL}
 	_, err = c.DoctorProfileUsecase.UpdateDoctorProfile(doctorID, &doctor)
 	if err != nil {
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mengupdate profil dokter")
 	}
+	deletePreviousAvatar(ctx.Request().Context(), c.Images, previous, result.Image.URL)
 
 	return helper.JSONSuccessResponse(ctx, map[string]string{
-		"message":   "Avatar berhasil diupload",
-		"avatarUrl": avatarURL,
-		"publicID":  publicID,
+		"message":      "Avatar berhasil diupload",
+		"avatarUrl":    result.Image.URL,
+		"thumbnailUrl": result.Thumbnail.URL,
+		"publicID":     result.Image.Key,
 	})
 }
 
@@ -245,13 +248,10 @@ func (c *DoctorProfileController) DeleteAvatar(ctx echo.Context) error {
 		return helper.JSONErrorResponse(ctx, http.StatusNotFound, "Avatar tidak ditemukan")
 	}
 
-	// Ekstrak public_id dari URL
-	parts := strings.Split(doctor.Avatar, "/")
-	publicID := strings.TrimSuffix(parts[len(parts)-1], filepath.Ext(doctor.Avatar))
-
-	// Hapus dari Cloudinary
-	if err := helper.DeleteFileFromCloudinary(publicID); err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal menghapus avatar di Cloudinary")
+	// Gambar beserta thumbnail dihapus, URL dari penyimpanan lain cukup dikosongkan di database
+	if err := c.Images.Delete(ctx.Request().Context(), doctor.Avatar); err != nil {
+		log.Printf("Gagal menghapus avatar dokter %d: %v", doctorID, err)
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal menghapus avatar")
 	}
 
 	// Update database
@@ -4,21 +4,25 @@ import (
 	"calmind/helper"
 	"calmind/model"
 	"calmind/service"
+	"calmind/service/upload"
 	usecase "calmind/usecase/profile"
+	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
-	"path/filepath"
-	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
 type ProfilController struct {
 	ProfilUsecase usecase.UserProfileUseCase
+	Images        upload.ImagePipeline
 }
 
-func NewProfilController(ProfilUsecase usecase.UserProfileUseCase) *ProfilController {
-	return &ProfilController{ProfilUsecase: ProfilUsecase}
+func NewProfilController(ProfilUsecase usecase.UserProfileUseCase, images upload.ImagePipeline) *ProfilController {
+	return &ProfilController{ProfilUsecase: ProfilUsecase, Images: images}
 }
 
 func (c *ProfilController) GetProfile(ctx echo.Context) error {
@@ -81,15 +85,10 @@ func (c *ProfilController) UploadAvatar(ctx echo.Context) error {
 	}
 	userID := claims.UserID
 
-	// Ambil file dari form
+	// Ambil file dari form, tipe dan ukuran diperiksa dari isi file oleh pipeline upload
 	file, err := ctx.FormFile("avatar")
-	if err != nil || file.Size > 5*1024*1024 {
-		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "File tidak valid atau ukuran melebihi 5 MB")
-	}
-
-	ext := filepath.Ext(file.Filename)
-	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
-		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Format file tidak didukung")
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "File tidak valid")
 	}
 
 	// Buka file untuk upload
@@ -99,24 +98,30 @@ func (c *ProfilController) UploadAvatar(ctx echo.Context) error {
 	}
 	defer src.Close()
 
-	// Upload ke Cloudinary
-	fileName := fmt.Sprintf("user_%d_avatar%s", userID, ext)
-	avatarURL, publicID, err := helper.UploadFileToCloudinary(src, fileName)
+	previous := ""
+	if user, err := c.ProfilUsecase.GetUserProfile(userID); err == nil {
+		previous = user.Avatar
+	}
+
+	// Validasi, hapus metadata EXIF, resize, dan buat thumbnail sebelum disimpan
+	result, err := c.Images.Process(ctx.Request().Context(), upload.Avatar, avatarKey("user", userID), src)
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal upload ke Cloudinary")
+		return imageUploadErrorResponse(ctx, err)
 	}
 
 	// Update avatar di database
-	user := model.User{Avatar: avatarURL}
+	user := model.User{Avatar: result.Image.URL}
 	_, err = c.ProfilUsecase.UpdateUserProfile(userID, &user)
 	if err != nil {
 		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mengupdate avatar: "+err.Error())
 	}
+	deletePreviousAvatar(ctx.Request().Context(), c.Images, previous, result.Image.URL)
 
 	return helper.JSONSuccessResponse(ctx, map[string]string{
-		"message":   "Avatar berhasil diupload",
-		"avatarUrl": avatarURL,
-		"publicID":  publicID,
+		"message":      "Avatar berhasil diupload",
+		"avatarUrl":    result.Image.URL,
+		"thumbnailUrl": result.Thumbnail.URL,
+		"publicID":     result.Image.Key,
 	})
 }
 
@@ -133,13 +138,10 @@ func (c *ProfilController) DeleteAvatar(ctx echo.Context) error {
 		return helper.JSONErrorResponse(ctx, http.StatusNotFound, "Avatar tidak ditemukan")
 	}
 
-	// Ekstrak public_id dari URL
-	parts := strings.Split(user.Avatar, "/")
-	publicID := strings.TrimSuffix(parts[len(parts)-1], filepath.Ext(user.Avatar))
-
-	// Hapus dari Cloudinary
-	if err := helper.DeleteFileFromCloudinary(publicID); err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal menghapus avatar dari Cloudinary")
+	// Gambar beserta thumbnail dihapus, URL dari penyimpanan lain cukup dikosongkan di database
+	if err := c.Images.Delete(ctx.Request().Context(), user.Avatar); err != nil {
+		log.Printf("Gagal menghapus avatar user %d: %v", userID, err)
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal menghapus avatar")
 	}
 
 	// Update avatar di database menjadi kosong
@@ -151,3 +153,31 @@ func (c *ProfilController) DeleteAvatar(ctx echo.Context) error {
 
 	return helper.JSONSuccessResponse(ctx, "Avatar berhasil dihapus")
 }
+
+// Key avatar diberi versi agar setiap upload menghasilkan URL baru dan cache tidak menyajikan avatar lama
+func avatarKey(owner string, id int) string {
+	return fmt.Sprintf("avatars/%s_%d_avatar_%d", owner, id, time.Now().UnixNano())
+}
+
+// Avatar lama beserta thumbnail dihapus setelah database menunjuk avatar baru. Kegagalan hanya dicatat
+// karena avatar baru sudah tersimpan.
+func deletePreviousAvatar(ctx context.Context, images upload.ImagePipeline, previousURL, currentURL string) {
+	if previousURL == "" || previousURL == currentURL {
+		return
+	}
+	if err := images.Delete(ctx, previousURL); err != nil {
+		log.Printf("Gagal menghapus avatar lama %s: %v", previousURL, err)
+	}
+}
+
+// Kesalahan validasi file dari pipeline upload ditanggapi 400/413, selain itu 500
+func imageUploadErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, upload.ErrUnsupportedType), errors.Is(err, upload.ErrCorruptImage), errors.Is(err, upload.ErrImageTooLarge):
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, upload.ErrFileTooLarge):
+		return helper.JSONErrorResponse(ctx, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mengunggah gambar")
+	}
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"calmind/helper"
+	"calmind/service"
+	"calmind/service/lifecycle"
+	usecase "calmind/usecase/refund"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RefundController struct {
+	RefundUsecase usecase.RefundUsecase
+}
+
+func NewRefundController(refundUsecase usecase.RefundUsecase) *RefundController {
+	return &RefundController{RefundUsecase: refundUsecase}
+}
+
+type cancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+//  -- user --
+
+// Membatalkan konsultasi milik user
+func (c *RefundController) CancelByUser(ctx echo.Context) error {
+	claims, ok := ctx.Get("user").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	consultationID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid consultation ID.")
+	}
+
+	var request cancelRequest
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid input.")
+	}
+
+	refund, err := c.RefundUsecase.CancelByUser(claims.UserID, consultationID, request.Reason)
+	if err != nil {
+		return refundErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, refund)
+}
+
+// Melihat daftar refund milik user
+func (c *RefundController) GetUserRefunds(ctx echo.Context) error {
+	claims, ok := ctx.Get("user").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	refunds, page, err := c.RefundUsecase.GetUserRefunds(claims.UserID, helper.ParseQueryOptions(ctx, "status", "consultation_id"))
+	if err != nil {
+		if helper.IsInvalidQuery(err) {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve refunds.")
+	}
+
+	return helper.JSONPaginatedResponse(ctx, refunds, page)
+}
+
+//  -- admin --
+
+// Membatalkan konsultasi atas nama dokter
+func (c *RefundController) CancelByAdmin(ctx echo.Context) error {
+	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	consultationID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid consultation ID.")
+	}
+
+	var request cancelRequest
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid input.")
+	}
+
+	refund, err := c.RefundUsecase.CancelByAdmin(claims.UserID, consultationID, request.Reason)
+	if err != nil {
+		return refundErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, refund)
+}
+
+// Melihat semua refund (?status=pending|succeeded|failed|skipped, ?method=, ?requested_by=)
+func (c *RefundController) GetAllRefunds(ctx echo.Context) error {
+	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	opts := helper.ParseQueryOptions(ctx, "status", "method", "requested_by", "consultation_id")
+	refunds, page, err := c.RefundUsecase.GetAllRefunds(opts)
+	if err != nil {
+		if helper.IsInvalidQuery(err) {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve refunds.")
+	}
+
+	return helper.JSONPaginatedResponse(ctx, refunds, page)
+}
+
+// Memproses ulang refund yang gagal
+func (c *RefundController) RetryRefund(ctx echo.Context) error {
+	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	refundID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Invalid refund ID.")
+	}
+
+	refund, err := c.RefundUsecase.RetryRefund(claims.UserID, refundID)
+	if err != nil {
+		return refundErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, refund)
+}
+
+func refundErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrNotOwner):
+		return helper.JSONErrorResponse(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, usecase.ErrCannotCancel), errors.Is(err, usecase.ErrSessionStarted),
+		errors.Is(err, usecase.ErrRefundNotRetryable), errors.Is(err, lifecycle.ErrIllegalTransition):
+		return helper.JSONErrorResponse(ctx, http.StatusConflict, err.Error())
+	default:
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Failed to process cancellation: "+err.Error())
+	}
+}
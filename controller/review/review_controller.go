@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"calmind/helper"
+	"calmind/service"
+	usecase "calmind/usecase/review"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReviewController struct {
+	ReviewUsecase usecase.ReviewUsecase
+}
+
+func NewReviewController(reviewUsecase usecase.ReviewUsecase) *ReviewController {
+	return &ReviewController{ReviewUsecase: reviewUsecase}
+}
+
+//  -- user --
+
+// Memberi ulasan untuk konsultasi yang sudah selesai (body: rating, comment)
+func (c *ReviewController) CreateReview(ctx echo.Context) error {
+	claims, ok := ctx.Get("user").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	consultationID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID konsultasi tidak valid.")
+	}
+
+	var request struct {
+		Rating  int    `json:"rating"`
+		Comment string `json:"comment"`
+	}
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid.")
+	}
+
+	review, err := c.ReviewUsecase.CreateReview(claims.UserID, consultationID, request.Rating, request.Comment)
+	if err != nil {
+		return reviewErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, review)
+}
+
+// Ulasan dokter yang tampil (?rating=&sort=-created_at|rating)
+func (c *ReviewController) GetDoctorReviews(ctx echo.Context) error {
+	doctorID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID dokter tidak valid.")
+	}
+
+	reviews, page, err := c.ReviewUsecase.GetDoctorReviews(doctorID, helper.ParseQueryOptions(ctx, "rating"))
+	if err != nil {
+		return reviewErrorResponse(ctx, err)
+	}
+
+	return helper.JSONPaginatedResponse(ctx, reviews, page)
+}
+
+//  -- dokter --
+
+// Semua ulasan untuk dokter yang login, termasuk yang disembunyikan admin
+func (c *ReviewController) GetMyReviews(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	reviews, page, err := c.ReviewUsecase.GetReviewsForDoctor(claims.UserID, helper.ParseQueryOptions(ctx, "rating", "status"))
+	if err != nil {
+		return reviewErrorResponse(ctx, err)
+	}
+
+	return helper.JSONPaginatedResponse(ctx, reviews, page)
+}
+
+// Membalas ulasan (body: reply)
+func (c *ReviewController) ReplyReview(ctx echo.Context) error {
+	claims, ok := ctx.Get("doctor").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	reviewID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID ulasan tidak valid.")
+	}
+
+	var request struct {
+		Reply string `json:"reply"`
+	}
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid.")
+	}
+
+	review, err := c.ReviewUsecase.ReplyReview(claims.UserID, reviewID, request.Reply)
+	if err != nil {
+		return reviewErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, review)
+}
+
+//  -- admin --
+
+// Semua ulasan, filter opsional ?status=visible|hidden&doctor_id=&user_id=&rating=
+func (c *ReviewController) GetReviews(ctx echo.Context) error {
+	reviews, page, err := c.ReviewUsecase.GetReviews(helper.ParseQueryOptions(ctx, "status", "doctor_id", "user_id", "rating"))
+	if err != nil {
+		return reviewErrorResponse(ctx, err)
+	}
+
+	return helper.JSONPaginatedResponse(ctx, reviews, page)
+}
+
+// Menyembunyikan atau menampilkan kembali ulasan (body: status, note)
+func (c *ReviewController) ModerateReview(ctx echo.Context) error {
+	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	reviewID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID ulasan tidak valid.")
+	}
+
+	var request struct {
+		Status string `json:"status"`
+		Note   string `json:"note"`
+	}
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid.")
+	}
+
+	review, err := c.ReviewUsecase.ModerateReview(claims.UserID, reviewID, request.Status, request.Note)
+	if err != nil {
+		return reviewErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, review)
+}
+
+func reviewErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrReviewNotFound), errors.Is(err, usecase.ErrConsultationNotFound):
+		return helper.JSONErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrInvalidRating), errors.Is(err, usecase.ErrReviewTextTooLong),
+		errors.Is(err, usecase.ErrEmptyReply), errors.Is(err, usecase.ErrInvalidReviewStatus),
+		helper.IsInvalidQuery(err):
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, usecase.ErrNotReviewable), errors.Is(err, usecase.ErrAlreadyReviewed):
+		return helper.JSONErrorResponse(ctx, http.StatusConflict, err.Error())
+	default:
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal memproses ulasan: "+err.Error())
+	}
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"calmind/helper"
+	"calmind/model"
+	"calmind/service"
+	usecase "calmind/usecase/safety"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SafetyAlertController struct {
+	SafetyAlertUsecase usecase.SafetyAlertUsecase
+}
+
+func NewSafetyAlertController(safetyAlertUsecase usecase.SafetyAlertUsecase) *SafetyAlertController {
+	return &SafetyAlertController{SafetyAlertUsecase: safetyAlertUsecase}
+}
+
+// Daftar peringatan, filter opsional ?status=open|acknowledged|resolved, ?risk_level=, ?source=, ?user_id=
+func (c *SafetyAlertController) GetAlerts(ctx echo.Context) error {
+	opts := helper.ParseQueryOptions(ctx, "status", "risk_level", "source", "user_id")
+	switch opts.Filters["status"] {
+	case "", model.SafetyAlertStatusOpen, model.SafetyAlertStatusAcknowledged, model.SafetyAlertStatusResolved:
+	default:
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Status peringatan tidak valid.")
+	}
+
+	alerts, page, err := c.SafetyAlertUsecase.GetAlerts(opts)
+	if err != nil {
+		if helper.IsInvalidQuery(err) {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mengambil peringatan: "+err.Error())
+	}
+
+	return helper.JSONPaginatedResponse(ctx, alerts, page)
+}
+
+func (c *SafetyAlertController) GetAlert(ctx echo.Context) error {
+	alertID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID peringatan tidak valid.")
+	}
+
+	alert, err := c.SafetyAlertUsecase.GetAlert(alertID)
+	if err != nil {
+		return safetyErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, alert)
+}
+
+// Menandai peringatan sedang ditangani atau sudah selesai (body: status, note)
+func (c *SafetyAlertController) UpdateAlert(ctx echo.Context) error {
+	claims, ok := ctx.Get("admin").(*service.JwtCustomClaims)
+	if !ok || claims == nil {
+		return helper.JSONErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized access.")
+	}
+
+	alertID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "ID peringatan tidak valid.")
+	}
+
+	var request struct {
+		Status string `json:"status"`
+		Note   string `json:"note"`
+	}
+	if err := ctx.Bind(&request); err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Input tidak valid.")
+	}
+
+	alert, err := c.SafetyAlertUsecase.UpdateStatus(claims.UserID, alertID, request.Status, request.Note)
+	if err != nil {
+		return safetyErrorResponse(ctx, err)
+	}
+
+	return helper.JSONSuccessResponse(ctx, alert)
+}
+
+func safetyErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrAlertNotFound):
+		return helper.JSONErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrInvalidAlertStatus):
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, usecase.ErrAlertResolved):
+		return helper.JSONErrorResponse(ctx, http.StatusConflict, err.Error())
+	default:
+		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
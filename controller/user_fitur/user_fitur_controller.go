@@ -2,9 +2,13 @@ package controller
 
 import (
 	"calmind/helper"
+	"calmind/model"
+	repository "calmind/repository/user_fitur"
 	usecase "calmind/usecase/user_fitur"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
@@ -19,29 +23,33 @@ func NewUserFiturController(UserFiturUsecase usecase.UserFiturUsecase) *UserFitu
 
 // Struct untuk respons dokter dalam daftar
 type DoctorResponse struct {
-	ID         int     `json:"id"`
-	Username   string  `json:"username"`
-	Title      string  `json:"title"`
-	Experience int     `json:"experience"`
-	Price      float64 `json:"price"`
-	Avatar     string  `json:"avatar"`
+	ID            int     `json:"id"`
+	Username      string  `json:"username"`
+	Title         string  `json:"title"`
+	Experience    int     `json:"experience"`
+	Price         float64 `json:"price"`
+	Avatar        string  `json:"avatar"`
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int     `json:"rating_count"`
 }
 
 // Struct untuk respons detail dokter
 type DoctorDetailResponse struct {
-	ID          int            `json:"id"`
-	Username    string         `json:"username"`
-	Avatar      string         `json:"avatar"`
-	DateOfBirth string         `json:"date_of_birth"`
-	Address     string         `json:"address"`
-	Schedule    string         `json:"schedule"`
-	Title       string         `json:"title"`
-	Price       float64        `json:"price"`
-	Experience  int            `json:"experience"`
-	STRNumber   string         `json:"str_number"`
-	About       string         `json:"about"`
-	IsActive    bool           `json:"is_active"`
-	Tags        []TagsResponse `json:"tags"`
+	ID            int            `json:"id"`
+	Username      string         `json:"username"`
+	Avatar        string         `json:"avatar"`
+	DateOfBirth   string         `json:"date_of_birth"`
+	Address       string         `json:"address"`
+	Schedule      string         `json:"schedule"`
+	Title         string         `json:"title"`
+	Price         float64        `json:"price"`
+	Experience    int            `json:"experience"`
+	STRNumber     string         `json:"str_number"`
+	About         string         `json:"about"`
+	IsActive      bool           `json:"is_active"`
+	RatingAverage float64        `json:"rating_average"`
+	RatingCount   int            `json:"rating_count"`
+	Tags          []TagsResponse `json:"tags"`
 }
 
 type TagsResponse struct {
@@ -54,33 +62,17 @@ type TitleResponse struct {
 	Name string `json:"name"`
 }
 
+// Filter tambahan yang bisa dipakai di semua daftar dokter
+var doctorListFilters = []string{"jenis_kelamin"}
+
 // Endpoint untuk mendapatkan daftar semua dokter
 func (c *UserFiturController) GetDoctors(ctx echo.Context) error {
-	doctors, err := c.UserFiturUsecase.GetAllDoctors()
+	doctors, page, err := c.UserFiturUsecase.GetAllDoctors(helper.ParseQueryOptions(ctx, doctorListFilters...))
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mendapatkan daftar dokter: "+err.Error())
-	}
-
-	if len(doctors) == 0 {
-		return helper.JSONSuccessResponse(ctx, map[string]interface{}{
-			"data":    nil,
-			"success": true,
-		})
+		return doctorListErrorResponse(ctx, http.StatusInternalServerError, "Gagal mendapatkan daftar dokter: ", err)
 	}
 
-	var doctorList []DoctorResponse
-	for _, doctor := range doctors {
-		doctorList = append(doctorList, DoctorResponse{
-			ID:         doctor.ID,
-			Username:   doctor.Username,
-			Title:      doctor.Title.Name, // Ambil Name dari objek Title
-			Experience: doctor.Experience,
-			Price:      doctor.Price,
-			Avatar:     doctor.Avatar,
-		})
-	}
-
-	return helper.JSONSuccessResponse(ctx, doctorList)
+	return helper.JSONPaginatedResponse(ctx, toDoctorResponses(doctors), page)
 }
 
 // Endpoint untuk mendapatkan daftar dokter berdasarkan tag
@@ -90,32 +82,12 @@ func (c *UserFiturController) GetDoctorsByTag(ctx echo.Context) error {
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Tag tidak boleh kosong")
 	}
 
-	doctors, err := c.UserFiturUsecase.GetDoctorsByTag(tag)
+	doctors, page, err := c.UserFiturUsecase.GetDoctorsByTag(tag, helper.ParseQueryOptions(ctx, doctorListFilters...))
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return doctorListErrorResponse(ctx, http.StatusNotFound, "", err)
 	}
 
-	var doctorList []DoctorResponse
-	for _, doctor := range doctors {
-		doctorList = append(doctorList, DoctorResponse{
-			ID:         doctor.ID,
-			Username:   doctor.Username,
-			Title:      doctor.Title.Name,
-			Experience: doctor.Experience,
-			Price:      doctor.Price,
-			Avatar:     doctor.Avatar,
-		})
-	}
-
-	if len(doctorList) == 0 {
-		return helper.JSONSuccessResponse(ctx, map[string]interface{}{
-			"data":    nil,
-			"success": true,
-			"message": "No doctors found for the specified tag.",
-		})
-	}
-
-	return helper.JSONSuccessResponse(ctx, doctorList)
+	return helper.JSONPaginatedResponse(ctx, toDoctorResponses(doctors), page)
 }
 
 // Endpoint untuk mendapatkan daftar dokter berdasarkan status
@@ -126,51 +98,40 @@ func (c *UserFiturController) GetDoctorsByStatus(ctx echo.Context) error {
 	}
 
 	isActive := (status == "active")
-	doctors, err := c.UserFiturUsecase.GetDoctorsByStatus(isActive)
+	doctors, page, err := c.UserFiturUsecase.GetDoctorsByStatus(isActive, helper.ParseQueryOptions(ctx, doctorListFilters...))
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mendapatkan dokter berdasarkan status: "+err.Error())
-	}
-
-	var doctorList []DoctorResponse
-	for _, doctor := range doctors {
-		doctorList = append(doctorList, DoctorResponse{
-			ID:         doctor.ID,
-			Username:   doctor.Username,
-			Title:      doctor.Title.Name,
-			Experience: doctor.Experience,
-			Price:      doctor.Price,
-			Avatar:     doctor.Avatar,
-		})
+		return doctorListErrorResponse(ctx, http.StatusInternalServerError, "Gagal mendapatkan dokter berdasarkan status: ", err)
 	}
 
-	return helper.JSONSuccessResponse(ctx, doctorList)
+	return helper.JSONPaginatedResponse(ctx, toDoctorResponses(doctors), page)
 }
 
-// Endpoint untuk mencari dokter berdasarkan query
+// Endpoint pencarian dokter gabungan:
+// ?q=&tags=a,b&title=&jenis_kelamin=&min_price=&max_price=&min_experience=&max_experience=&is_active=&available_now=true
+// &sort=-relevance|-rating|price|-price|experience
 func (c *UserFiturController) SearchDoctors(ctx echo.Context) error {
-	query := ctx.QueryParam("query")
-	if query == "" {
-		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Query tidak boleh kosong")
+	opts := helper.ParseQueryOptions(ctx, doctorListFilters...)
+	filter, err := parseDoctorSearchFilter(ctx)
+	if err != nil {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
 	}
 
-	doctors, err := c.UserFiturUsecase.SearchDoctors(query)
-	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusInternalServerError, "Gagal mencari dokter: "+err.Error())
+	// Teks pencarian diproses oleh filter, bukan pencarian LIKE bawaan daftar
+	filter.Query = opts.Search
+	if filter.Query == "" {
+		filter.Query = ctx.QueryParam("query") // Parameter lama
 	}
+	opts.Search = ""
 
-	var doctorList []DoctorResponse
-	for _, doctor := range doctors {
-		doctorList = append(doctorList, DoctorResponse{
-			ID:         doctor.ID,
-			Username:   doctor.Username,
-			Title:      doctor.Title.Name,
-			Experience: doctor.Experience,
-			Price:      doctor.Price,
-			Avatar:     doctor.Avatar,
-		})
+	doctors, page, err := c.UserFiturUsecase.SearchDoctors(filter, opts)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidDoctorSearch) {
+			return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
+		return doctorListErrorResponse(ctx, http.StatusInternalServerError, "Gagal mencari dokter: ", err)
 	}
 
-	return helper.JSONSuccessResponse(ctx, doctorList)
+	return helper.JSONPaginatedResponse(ctx, toDoctorResponses(doctors), page)
 }
 
 // Endpoint untuk mendapatkan detail dokter berdasarkan ID
@@ -194,19 +155,21 @@ func (c *UserFiturController) GetDoctorDetail(ctx echo.Context) error {
 	}
 
 	doctorDetail := DoctorDetailResponse{
-		ID:          doctor.ID,
-		Username:    doctor.Username,
-		Avatar:      doctor.Avatar,
-		DateOfBirth: doctor.DateOfBirth,
-		Address:     doctor.Address,
-		Schedule:    doctor.Schedule,
-		Title:       doctor.Title.Name, // Ambil Name dari objek Title
-		Price:       doctor.Price,
-		Experience:  doctor.Experience,
-		STRNumber:   doctor.STRNumber,
-		About:       doctor.About,
-		IsActive:    doctor.IsActive,
-		Tags:        tagsResponse,
+		ID:            doctor.ID,
+		Username:      doctor.Username,
+		Avatar:        doctor.Avatar,
+		DateOfBirth:   doctor.DateOfBirth,
+		Address:       doctor.Address,
+		Schedule:      doctor.Schedule,
+		Title:         doctor.Title.Name, // Ambil Name dari objek Title
+		Price:         doctor.Price,
+		Experience:    doctor.Experience,
+		STRNumber:     doctor.STRNumber,
+		About:         doctor.About,
+		IsActive:      doctor.IsActive,
+		RatingAverage: doctor.RatingAverage,
+		RatingCount:   doctor.RatingCount,
+		Tags:          tagsResponse,
 	}
 
 	return helper.JSONSuccessResponse(ctx, doctorDetail)
@@ -247,30 +210,84 @@ func (c *UserFiturController) GetDoctorsByTitle(ctx echo.Context) error {
 		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, "Title tidak boleh kosong")
 	}
 
-	doctors, err := c.UserFiturUsecase.GetDoctorsByTitle(title)
+	doctors, page, err := c.UserFiturUsecase.GetDoctorsByTitle(title, helper.ParseQueryOptions(ctx, doctorListFilters...))
 	if err != nil {
-		return helper.JSONErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return doctorListErrorResponse(ctx, http.StatusNotFound, "", err)
 	}
 
-	var doctorList []DoctorResponse
+	return helper.JSONPaginatedResponse(ctx, toDoctorResponses(doctors), page)
+}
+
+func toDoctorResponses(doctors []model.Doctor) []DoctorResponse {
+	doctorList := make([]DoctorResponse, 0, len(doctors))
 	for _, doctor := range doctors {
 		doctorList = append(doctorList, DoctorResponse{
-			ID:         doctor.ID,
-			Username:   doctor.Username,
-			Title:      doctor.Title.Name,
-			Experience: doctor.Experience,
-			Price:      doctor.Price,
-			Avatar:     doctor.Avatar,
+			ID:            doctor.ID,
+			Username:      doctor.Username,
+			Title:         doctor.Title.Name, // Ambil Name dari objek Title
+			Experience:    doctor.Experience,
+			Price:         doctor.Price,
+			Avatar:        doctor.Avatar,
+			RatingAverage: doctor.RatingAverage,
+			RatingCount:   doctor.RatingCount,
 		})
 	}
+	return doctorList
+}
 
-	if len(doctorList) == 0 {
-		return helper.JSONSuccessResponse(ctx, map[string]interface{}{
-			"data":    nil,
-			"success": true,
-			"message": "No doctors found for the specified title.",
-		})
+// Membaca kriteria pencarian dari query string, angka atau boolean yang salah format ditolak
+func parseDoctorSearchFilter(ctx echo.Context) (repository.DoctorSearchFilter, error) {
+	filter := repository.DoctorSearchFilter{Title: ctx.QueryParam("title")}
+	for _, value := range ctx.QueryParams()["tags"] {
+		filter.Tags = append(filter.Tags, strings.Split(value, ",")...)
+	}
+	if tag := ctx.QueryParam("tag"); tag != "" {
+		filter.Tags = append(filter.Tags, tag)
+	}
+
+	floats := map[string]*float64{"min_price": &filter.MinPrice, "max_price": &filter.MaxPrice}
+	for name, dest := range floats {
+		if value := ctx.QueryParam(name); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return filter, errors.New(name + " harus berupa angka")
+			}
+			*dest = parsed
+		}
+	}
+
+	ints := map[string]*int{"min_experience": &filter.MinExperience, "max_experience": &filter.MaxExperience}
+	for name, dest := range ints {
+		if value := ctx.QueryParam(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return filter, errors.New(name + " harus berupa bilangan bulat")
+			}
+			*dest = parsed
+		}
 	}
 
-	return helper.JSONSuccessResponse(ctx, doctorList)
+	if value := ctx.QueryParam("is_active"); value != "" {
+		isActive, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, errors.New("is_active harus 'true' atau 'false'")
+		}
+		filter.IsActive = &isActive
+	}
+	if value := ctx.QueryParam("available_now"); value != "" {
+		availableNow, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, errors.New("available_now harus 'true' atau 'false'")
+		}
+		filter.AvailableNow = availableNow
+	}
+	return filter, nil
+}
+
+// Opsi sort/filter yang salah ditanggapi 400, selain itu memakai status bawaan endpoint
+func doctorListErrorResponse(ctx echo.Context, status int, prefix string, err error) error {
+	if helper.IsInvalidQuery(err) {
+		return helper.JSONErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+	return helper.JSONErrorResponse(ctx, status, prefix+err.Error())
 }
@@ -0,0 +1,73 @@
+package helper
+
+import (
+	"calmind/model"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Membaca ?page=&limit=&cursor=&sort=&q= serta filter yang diizinkan endpoint dari query string
+func ParseQueryOptions(ctx echo.Context, filters ...string) model.QueryOptions {
+	page, _ := strconv.Atoi(ctx.QueryParam("page"))
+	limit, _ := strconv.Atoi(ctx.QueryParam("limit"))
+	cursor, _ := strconv.Atoi(ctx.QueryParam("cursor"))
+
+	opts := model.QueryOptions{
+		Page:    page,
+		Limit:   limit,
+		Cursor:  cursor,
+		Sort:    ctx.QueryParam("sort"),
+		Filters: map[string]string{},
+		Search:  ctx.QueryParam("q"),
+	}
+	for _, name := range filters {
+		if value := ctx.QueryParam(name); value != "" {
+			opts.Filters[name] = value
+		}
+	}
+	return opts.Normalized()
+}
+
+// Respons daftar: format JSONSuccessResponse ditambah informasi halaman dan tautan halaman berikutnya/sebelumnya
+func JSONPaginatedResponse(ctx echo.Context, data interface{}, page model.PageInfo) error {
+	// Daftar kosong dikirim sebagai [] agar klien tidak perlu menangani null
+	if value := reflect.ValueOf(data); value.Kind() == reflect.Slice && value.IsNil() {
+		data = reflect.MakeSlice(value.Type(), 0, 0).Interface()
+	}
+
+	cursorMode := ctx.QueryParam("cursor") != ""
+	if cursorMode {
+		if page.NextCursor > 0 {
+			page.Next = pageLink(ctx, "cursor", page.NextCursor)
+		}
+	} else {
+		if page.Page < page.TotalPages {
+			page.Next = pageLink(ctx, "page", page.Page+1)
+		}
+		if page.Page > 1 {
+			page.Prev = pageLink(ctx, "page", page.Page-1)
+		}
+	}
+
+	body := successBody(data)
+	body["pagination"] = page
+	return ctx.JSON(http.StatusOK, body)
+}
+
+// URL permintaan saat ini dengan satu parameter halaman diganti
+func pageLink(ctx echo.Context, param string, value int) string {
+	url := *ctx.Request().URL
+	query := url.Query()
+	query.Set(param, strconv.Itoa(value))
+	url.RawQuery = query.Encode()
+	return url.RequestURI()
+}
+
+// Kesalahan opsi daftar (sort, filter, cursor) yang dikirim klien, ditanggapi dengan 400
+func IsInvalidQuery(err error) bool {
+	return errors.Is(err, model.ErrInvalidSort) || errors.Is(err, model.ErrInvalidFilter) || errors.Is(err, model.ErrInvalidCursor)
+}
@@ -0,0 +1,126 @@
+package helper
+
+import (
+	"calmind/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestContext(target string) (echo.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	return echo.New().NewContext(httptest.NewRequest(http.MethodGet, target, nil), rec), rec
+}
+
+func TestParseQueryOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		opts   model.QueryOptions
+	}{
+		{
+			name:   "default",
+			target: "/artikel",
+			opts:   model.QueryOptions{Page: 1, Limit: model.DefaultPageLimit, Filters: map[string]string{}},
+		},
+		{
+			name:   "semua parameter",
+			target: "/artikel?page=2&limit=15&sort=-created_at&q=cemas&status=published",
+			opts:   model.QueryOptions{Page: 2, Limit: 15, Sort: "-created_at", Search: "cemas", Filters: map[string]string{"status": "published"}},
+		},
+		{
+			name:   "limit dibatasi dan nilai tidak valid",
+			target: "/artikel?page=abc&limit=1000&cursor=25",
+			opts:   model.QueryOptions{Page: 1, Limit: model.MaxPageLimit, Cursor: 25, Filters: map[string]string{}},
+		},
+		{
+			name:   "filter di luar daftar endpoint diabaikan",
+			target: "/artikel?role=admin&status=",
+			opts:   model.QueryOptions{Page: 1, Limit: model.DefaultPageLimit, Filters: map[string]string{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := newTestContext(tt.target)
+			assert.Equal(t, tt.opts, ParseQueryOptions(ctx, "status"))
+		})
+	}
+}
+
+func TestJSONPaginatedResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		page       model.PageInfo
+		next, prev string
+	}{
+		{
+			name:   "halaman pertama",
+			target: "/artikel?limit=10",
+			page:   model.PageInfo{Page: 1, Limit: 10, Total: 25, TotalPages: 3},
+			next:   "/artikel?limit=10&page=2",
+		},
+		{
+			name:   "halaman tengah",
+			target: "/artikel?limit=10&page=2&sort=-id",
+			page:   model.PageInfo{Page: 2, Limit: 10, Total: 25, TotalPages: 3},
+			next:   "/artikel?limit=10&page=3&sort=-id",
+			prev:   "/artikel?limit=10&page=1&sort=-id",
+		},
+		{
+			name:   "halaman terakhir",
+			target: "/artikel?limit=10&page=3",
+			page:   model.PageInfo{Page: 3, Limit: 10, Total: 25, TotalPages: 3},
+			prev:   "/artikel?limit=10&page=2",
+		},
+		{
+			name:   "cursor",
+			target: "/artikel?cursor=40&sort=-id",
+			page:   model.PageInfo{Page: 1, Limit: 20, Total: 90, TotalPages: 5, NextCursor: 20},
+			next:   "/artikel?cursor=20&sort=-id",
+		},
+		{
+			name:   "cursor halaman terakhir",
+			target: "/artikel?cursor=20&sort=-id",
+			page:   model.PageInfo{Page: 1, Limit: 20, Total: 90, TotalPages: 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, rec := newTestContext(tt.target)
+			assert.NoError(t, JSONPaginatedResponse(ctx, []string{"a"}, tt.page))
+
+			var body struct {
+				Success    bool           `json:"success"`
+				Data       []string       `json:"data"`
+				Pagination model.PageInfo `json:"pagination"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.True(t, body.Success)
+			assert.Equal(t, []string{"a"}, body.Data)
+			assert.Equal(t, tt.next, body.Pagination.Next)
+			assert.Equal(t, tt.prev, body.Pagination.Prev)
+			assert.Equal(t, tt.page.TotalPages, body.Pagination.TotalPages)
+		})
+	}
+}
+
+func TestJSONPaginatedResponse_EmptyList(t *testing.T) {
+	ctx, rec := newTestContext("/artikel")
+	var items []string
+	assert.NoError(t, JSONPaginatedResponse(ctx, items, model.NewPageInfo(model.QueryOptions{}, 0)))
+	assert.Contains(t, rec.Body.String(), `"data":[]`)
+}
+
+func TestIsInvalidQuery(t *testing.T) {
+	for _, err := range []error{model.ErrInvalidSort, model.ErrInvalidFilter, fmt.Errorf("daftar artikel: %w", model.ErrInvalidCursor)} {
+		assert.True(t, IsInvalidQuery(err), err.Error())
+	}
+	assert.False(t, IsInvalidQuery(errors.New("koneksi terputus")))
+}
@@ -7,8 +7,13 @@ import (
 )
 
 func JSONSuccessResponse(ctx echo.Context, data interface{}) error {
-	return ctx.JSON(http.StatusOK, map[string]interface{}{
+	return ctx.JSON(http.StatusOK, successBody(data))
+}
+
+// Isi respons sukses, dipakai bersama oleh respons biasa dan respons daftar
+func successBody(data interface{}) map[string]interface{} {
+	return map[string]interface{}{
 		"success": true,
 		"data":    data,
-	})
+	}
 }
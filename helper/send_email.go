@@ -2,22 +2,15 @@ package helper
 
 import (
 	"fmt"
+	"html"
 	"os"
+	"strings"
 
 	"gopkg.in/gomail.v2"
 )
 
 // SendEmail mengirimkan email verifikasi dengan kode OTP ke alamat email yang diberikan.
 func SendEmail(email, otpCode string) error {
-	// Informasi pengirim diambil dari variabel lingkungan
-	from := os.Getenv("SMTP_EMAIL")
-	password := os.Getenv("SMTP_PASSWORD")
-
-	// Pastikan variabel lingkungan SMTP_EMAIL dan SMTP_PASSWORD telah diatur
-	if from == "" || password == "" {
-		return fmt.Errorf("SMTP credentials are not set in environment variables")
-	}
-
 	// Subjek email
 	subject := "Verifikasi Akun Anda - OTP Calmind"
 
@@ -79,6 +72,144 @@ func SendEmail(email, otpCode string) error {
 		</html>
 	`
 
+	return sendMail(email, subject, body)
+}
+
+// SendPasswordResetEmail mengirimkan kode OTP dan tautan (opsional) untuk mengatur ulang password.
+func SendPasswordResetEmail(email, otpCode, resetLink string) error {
+	subject := "Reset Password Akun Calmind"
+
+	link := ""
+	if resetLink != "" {
+		link = `<p>Atau klik tautan berikut untuk membuat password baru:</p>
+				<p style="text-align: center;"><a href="` + html.EscapeString(resetLink) + `">Reset Password</a></p>`
+	}
+
+	body := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<title>Reset Password</title>
+			<style>
+				body {
+					font-family: Arial, sans-serif;
+					line-height: 1.6;
+					color: #333;
+					background-color: #f9f9f9;
+					padding: 20px;
+				}
+				.container {
+					max-width: 600px;
+					margin: 0 auto;
+					background: #fff;
+					border-radius: 8px;
+					box-shadow: 0 4px 8px rgba(0, 0, 0, 0.1);
+					padding: 20px;
+				}
+				.header {
+					text-align: center;
+					color: #4CAF50;
+					margin-bottom: 20px;
+				}
+				.otp {
+					font-size: 24px;
+					font-weight: bold;
+					text-align: center;
+					color: #4CAF50;
+					margin: 20px 0;
+				}
+				.footer {
+					margin-top: 30px;
+					text-align: center;
+					font-size: 12px;
+					color: #aaa;
+				}
+			</style>
+		</head>
+		<body>
+			<div class="container">
+				<h2 class="header">Reset Password</h2>
+				<p>Kami menerima permintaan untuk mengatur ulang password akun <strong>Calmind</strong> Anda. Gunakan kode OTP berikut:</p>
+				<div class="otp">` + otpCode + `</div>
+				` + link + `
+				<p>Kode dan tautan ini hanya berlaku selama <strong>15 menit</strong>. Setelah password diganti, Anda akan keluar dari semua perangkat. Jika Anda tidak meminta reset password, silakan abaikan email ini.</p>
+				<p>Terima kasih,</p>
+				<p><strong>Tim Calmind</strong></p>
+			</div>
+			<div class="footer">
+				&copy; 2024 Calmind. All rights reserved.
+			</div>
+		</body>
+		</html>
+	`
+
+	return sendMail(email, subject, body)
+}
+
+// SendNotificationEmail mengirimkan email pemberitahuan umum (pengingat, status konsultasi, dan sebagainya).
+func SendNotificationEmail(email, subject, heading, message string) error {
+	body := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<title>` + html.EscapeString(heading) + `</title>
+			<style>
+				body {
+					font-family: Arial, sans-serif;
+					line-height: 1.6;
+					color: #333;
+					background-color: #f9f9f9;
+					padding: 20px;
+				}
+				.container {
+					max-width: 600px;
+					margin: 0 auto;
+					background: #fff;
+					border-radius: 8px;
+					box-shadow: 0 4px 8px rgba(0, 0, 0, 0.1);
+					padding: 20px;
+				}
+				.header {
+					text-align: center;
+					color: #4CAF50;
+					margin-bottom: 20px;
+				}
+				.footer {
+					margin-top: 30px;
+					text-align: center;
+					font-size: 12px;
+					color: #aaa;
+				}
+			</style>
+		</head>
+		<body>
+			<div class="container">
+				<h2 class="header">` + html.EscapeString(heading) + `</h2>
+				<p>` + strings.ReplaceAll(html.EscapeString(message), "\n", "<br>") + `</p>
+				<p>Terima kasih,</p>
+				<p><strong>Tim Calmind</strong></p>
+			</div>
+			<div class="footer">
+				&copy; 2024 Calmind. All rights reserved.
+			</div>
+		</body>
+		</html>
+	`
+
+	return sendMail(email, subject, body)
+}
+
+// sendMail mengirim email HTML melalui SMTP Gmail menggunakan kredensial dari variabel lingkungan.
+func sendMail(to, subject, body string) error {
+	// Informasi pengirim diambil dari variabel lingkungan
+	from := os.Getenv("SMTP_EMAIL")
+	password := os.Getenv("SMTP_PASSWORD")
+
+	// Pastikan variabel lingkungan SMTP_EMAIL dan SMTP_PASSWORD telah diatur
+	if from == "" || password == "" {
+		return fmt.Errorf("SMTP credentials are not set in environment variables")
+	}
+
 	// Konfigurasi SMTP Gmail
 	smtpHost := "smtp.gmail.com"
 	smtpPort := 587
@@ -86,7 +217,7 @@ func SendEmail(email, otpCode string) error {
 	// Membuat pesan email
 	m := gomail.NewMessage()
 	m.SetHeader("From", from)
-	m.SetHeader("To", email)
+	m.SetHeader("To", to)
 	m.SetHeader("Subject", subject)
 	m.SetBody("text/html", body)
 
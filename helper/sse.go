@@ -0,0 +1,47 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SSEWriter mengirim event server-sent events ke klien dan langsung mem-flush setiap event.
+type SSEWriter struct {
+	ctx     echo.Context
+	started bool
+}
+
+func NewSSEWriter(ctx echo.Context) *SSEWriter {
+	return &SSEWriter{ctx: ctx}
+}
+
+// Send menulis satu event. Data di-encode ke JSON agar baris baru pada teks tidak memutus event.
+func (w *SSEWriter) Send(event string, data interface{}) error {
+	if !w.started {
+		header := w.ctx.Response().Header()
+		header.Set(echo.HeaderContentType, "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no") // Mencegah buffering di reverse proxy nginx
+		w.ctx.Response().WriteHeader(http.StatusOK)
+		w.started = true
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w.ctx.Response(), "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	w.ctx.Response().Flush()
+	return nil
+}
+
+// Started menandakan header sudah terkirim, error setelahnya harus dikirim sebagai event
+func (w *SSEWriter) Started() bool {
+	return w.started
+}
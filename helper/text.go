@@ -0,0 +1,65 @@
+package helper
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Kecepatan baca rata-rata yang dipakai untuk estimasi waktu baca
+const WordsPerMinute = 200
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// Mengubah teks menjadi slug URL: huruf kecil, aksen dilepas, selain huruf/angka menjadi "-"
+func Slugify(text string) string {
+	var builder strings.Builder
+	dash := false
+	for _, r := range norm.NFKD.String(strings.ToLower(text)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			builder.WriteRune(r)
+			dash = false
+		case builder.Len() > 0 && !dash:
+			builder.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(builder.String(), "-")
+}
+
+// Isi tanpa tag HTML dengan spasi dirapikan
+func PlainText(content string) string {
+	text := html.UnescapeString(htmlTagPattern.ReplaceAllString(content, " "))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Potongan awal teks maksimal maxRunes karakter, dipotong di batas kata
+func Summarize(content string, maxRunes int) string {
+	text := []rune(PlainText(content))
+	if len(text) <= maxRunes {
+		return string(text)
+	}
+	cut := maxRunes
+	for i := maxRunes; i > maxRunes/2; i-- {
+		if unicode.IsSpace(text[i]) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRight(string(text[:cut]), " ,.;:") + "…"
+}
+
+// Estimasi menit baca, minimal 1 untuk isi yang tidak kosong
+func ReadingMinutes(content string) int {
+	words := len(strings.Fields(PlainText(content)))
+	if words == 0 {
+		return 0
+	}
+	return (words + WordsPerMinute - 1) / WordsPerMinute
+}
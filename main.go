@@ -2,46 +2,74 @@ package main
 
 import (
 	"calmind/config"
-	"calmind/helper"
+	"calmind/config/migration"
 	"calmind/middlewares"
 	repository_management "calmind/repository/admin_management"
 	repository_artikel "calmind/repository/artikel"
 	repository_authentikasi "calmind/repository/authentikasi"
+	repository_chat "calmind/repository/chat"
 	repository_chatbot_ai "calmind/repository/chatbot_ai"
 	repository_chatbot_ai_doctor "calmind/repository/chatbot_ai_doctor"
+	repository_credential "calmind/repository/credential"
 	repository_customer_service "calmind/repository/customer_service"
+	repository_faq "calmind/repository/faq"
+	repository_jadwal "calmind/repository/jadwal"
 	repository_konsultasi "calmind/repository/konsultasi"
 	repository_profile "calmind/repository/profile"
+	repository_refund "calmind/repository/refund"
+	repository_review "calmind/repository/review"
+	repository_safety "calmind/repository/safety"
+	repository_scheduler "calmind/repository/scheduler"
 	repository_statistik "calmind/repository/statistik"
 	repository_user_fitur "calmind/repository/user_fitur"
 
 	usecase_management "calmind/usecase/admin_management"
 	usecase_artikel "calmind/usecase/artikel"
 	usecase_authentikasi "calmind/usecase/authentikasi"
+	usecase_chat "calmind/usecase/chat"
 	usecase_chatbot_ai "calmind/usecase/chatbot_ai"
 	usecase_chatbot_ai_doctor "calmind/usecase/chatbot_ai_doctor"
+	usecase_credential "calmind/usecase/credential"
 	usecase_customer_service "calmind/usecase/customer_service"
+	usecase_faq "calmind/usecase/faq"
+	usecase_jadwal "calmind/usecase/jadwal"
 	usecase_konsultasi "calmind/usecase/konsultasi"
 	usecase_profile "calmind/usecase/profile"
+	usecase_refund "calmind/usecase/refund"
+	usecase_review "calmind/usecase/review"
+	usecase_safety "calmind/usecase/safety"
+	usecase_scheduler "calmind/usecase/scheduler"
 	usecase_statistik "calmind/usecase/statistik"
 	usecase_user_fitur "calmind/usecase/user_fitur"
 
 	controller_management "calmind/controller/admin_management"
 	controller_artikel "calmind/controller/artikel"
 	controller_authentikasi "calmind/controller/authentikasi"
+	controller_chat "calmind/controller/chat"
 	controller_chatbot_ai "calmind/controller/chatbot_ai"
 	controller_chatbot_ai_doctor "calmind/controller/chatbot_ai_doctor"
+	controller_credential "calmind/controller/credential"
 	controller_customer_service "calmind/controller/customer_service"
+	controller_faq "calmind/controller/faq"
+	controller_jadwal "calmind/controller/jadwal"
 	controller_konsultasi "calmind/controller/konsultasi"
 	controller_notifikasi "calmind/controller/midtrans_notifikasi"
 	controller_profile "calmind/controller/profile"
+	controller_refund "calmind/controller/refund"
+	controller_review "calmind/controller/review"
+	controller_safety "calmind/controller/safety"
 	controller_statistik "calmind/controller/statistik"
 	controller_user_fitur "calmind/controller/user_fitur"
 
 	"calmind/routes"
 	"calmind/service"
+	"calmind/service/safety"
+	"calmind/service/storage"
+	"calmind/service/upload"
+	"flag"
 	"log"
 	"net/http"
+	"os"
 
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
@@ -53,27 +81,76 @@ func main() {
 		log.Fatal("Gagal memuat file .env")
 	}
 
+	// -migrate menjalankan migrasi tertunda sebelum server start (default dari AUTO_MIGRATE=true)
+	autoMigrate := flag.Bool("migrate", os.Getenv("AUTO_MIGRATE") == "true", "jalankan migrasi database yang tertunda saat startup")
+	flag.Parse()
+
 	// Inisialisasi database
 	DB, err := config.InitDB()
 	if err != nil {
 		log.Fatalf("Gagal menginisialisasi database: %v", err)
 	}
 
+	// Subcommand: ./main migrate up|down [jumlah]|status
+	if flag.Arg(0) == "migrate" {
+		if err := migration.Run(DB, flag.Args()[1:], os.Stdout); err != nil {
+			log.Fatalf("Migrasi gagal: %v", err)
+		}
+		return
+	}
+	if *autoMigrate {
+		applied, err := migration.Up(DB)
+		if err != nil {
+			log.Fatalf("Migrasi gagal: %v", err)
+		}
+		log.Printf("%d migrasi diterapkan.", len(applied))
+	}
+
 	// Konfigurasi JWT
 	jwtSecret := config.NewJWTConfig()
 	jwtService := service.NewJWTService(jwtSecret)
 	otpService := service.NewOtpService()
+	midtransService := service.NewMidtransService(os.Getenv("MIDTRANS_SERVER_KEY"))
+
+	// Penyedia model bahasa untuk chatbot (LLM_PROVIDER=gemini|openai|fake)
+	llmProvider, err := service.NewLLMProvider(config.NewLLMConfig())
+	if err != nil {
+		log.Fatalf("Gagal menginisialisasi penyedia LLM: %v", err)
+	}
+
+	// Penyimpanan file upload (STORAGE_DRIVER=cloudinary|local|s3), local disajikan lewat STORAGE_LOCAL_PUBLIC_URL
+	storageConfig := config.NewStorageConfig()
+	fileStorage, err := storage.New(storageConfig)
+	if err != nil {
+		log.Fatalf("Gagal menginisialisasi penyimpanan file: %v", err)
+	}
+	// Dokumen kredensial dokter tidak pernah disajikan publik, hanya lewat endpoint dokter dan admin
+	privateStorage, err := storage.NewPrivate(storageConfig)
+	if err != nil {
+		log.Fatalf("Gagal menginisialisasi penyimpanan privat: %v", err)
+	}
+	// Avatar dan gambar artikel divalidasi, dibersihkan dari EXIF, di-resize, dan dibuat thumbnail sebelum disimpan
+	imagePipeline := upload.NewImagePipeline(fileStorage)
+
+	//    Repositori dan usecase untuk job terjadwal
+	schedulerRepo := repository_scheduler.NewSchedulerRepository(DB)
+	schedulerUsecase := usecase_scheduler.NewSchedulerUsecase(schedulerRepo)
+
+	// Repositori dan usecase untuk sesi login (refresh token dan pencabutan token)
+	sessionRepo := repository_authentikasi.NewSessionRepository(DB)
+	sessionUsecase := usecase_authentikasi.NewSessionUsecase(sessionRepo, jwtService, schedulerUsecase)
+	sessionUsecase.RegisterJobs()
 
 	// Repositori, usecase, dan controller untuk User
 	userRepo := repository_authentikasi.NewAuthRepository(DB)
 	otpRepo := repository_authentikasi.NewOtpRepository(DB)
-	userUsecase := usecase_authentikasi.NewAuthUsecase(userRepo, jwtService, otpRepo, otpService)
-	userController := controller_authentikasi.NewAuthController(userUsecase)
+	userUsecase := usecase_authentikasi.NewAuthUsecase(userRepo, jwtService, otpRepo, otpService, sessionUsecase)
+	userController := controller_authentikasi.NewAuthController(userUsecase, sessionUsecase)
 
 	// Repositori, usecase, dan controller untuk Admin
 	adminRepo := repository_authentikasi.NewAdminAuthRepository(DB)
-	adminUsecase := usecase_authentikasi.NewAdminAuthUsecase(adminRepo, jwtService)
-	adminController := controller_authentikasi.NewAdminAuthController(adminUsecase)
+	adminUsecase := usecase_authentikasi.NewAdminAuthUsecase(adminRepo, jwtService, sessionUsecase)
+	adminController := controller_authentikasi.NewAdminAuthController(adminUsecase, sessionUsecase)
 
 	// Repositori, usecase, dan controller untuk Admin management
 	adminRepoManagement := repository_management.NewAdminManagementRepo(DB)
@@ -82,13 +159,13 @@ func main() {
 
 	// Repositori, usecase, dan controller untuk dokter
 	doctorRepoManagement := repository_authentikasi.NewDoctorAuthRepository(DB)
-	doctorUsecaseManagement := usecase_authentikasi.NewDoctorAuthUsecase(doctorRepoManagement, jwtService, otpRepo, otpService)
-	doctorControllerManagement := controller_authentikasi.NewDoctorAuthController(doctorUsecaseManagement)
+	doctorUsecaseManagement := usecase_authentikasi.NewDoctorAuthUsecase(doctorRepoManagement, jwtService, otpRepo, otpService, sessionUsecase)
+	doctorControllerManagement := controller_authentikasi.NewDoctorAuthController(doctorUsecaseManagement, sessionUsecase)
 
 	//	Repositori, usecase, dan controller untuk Profil User
 	userProfilRepo := repository_profile.NewUserProfilRepository(DB)
 	userProfilUsecase := usecase_profile.NewUserProfileUseCase(userProfilRepo)
-	userProfilController := controller_profile.NewProfilController(userProfilUsecase)
+	userProfilController := controller_profile.NewProfilController(userProfilUsecase, imagePipeline)
 
 	//	Repositori, usecase, dan controller untuk Fitur User
 	userFiturRepo := repository_user_fitur.NewUserFiturRepository(DB)
@@ -98,39 +175,86 @@ func main() {
 	//	Repositori, usecase, dan controller untuk Profil doctor
 	doctorProfilRepo := repository_profile.NewDoctorProfilRepository(DB)
 	doctorProfilUsecase := usecase_profile.NewDoctorProfileUseCase(doctorProfilRepo)
-	doctorProfilController := controller_profile.NewDoctorProfileController(doctorProfilUsecase)
+	doctorProfilController := controller_profile.NewDoctorProfileController(doctorProfilUsecase, imagePipeline)
+
+	//    Repositori, usecase, dan controller untuk Jadwal dokter
+	jadwalRepo := repository_jadwal.NewJadwalRepository(DB)
+	jadwalUsecase := usecase_jadwal.NewJadwalUsecase(jadwalRepo)
+	jadwalController := controller_jadwal.NewJadwalController(jadwalUsecase)
 
 	//    Repositori, usecase, dan controller untuk Consultasi
 	consultationRepo := repository_konsultasi.NewConsultationRepositoryImpl(DB)
-	consultationUsecase := usecase_konsultasi.NewConsultationUsecaseImpl(consultationRepo)
+	consultationUsecase := usecase_konsultasi.NewConsultationUsecaseImpl(consultationRepo, jadwalUsecase, midtransService, schedulerUsecase)
 	consultationController := controller_konsultasi.NewConsultationController(consultationUsecase)
 
-	helper.StartExpiredConsultationJob(consultationUsecase.MarkExpiredConsultations)
+	consultationUsecase.RegisterJobs()
+	if err := consultationUsecase.CatchUpScheduledJobs(); err != nil {
+		log.Printf("Gagal menjadwalkan ulang job konsultasi: %v", err)
+	}
+
+	//    Repositori dan usecase customer service (email jawaban tiket dikirim lewat scheduler)
+	cs := repository_customer_service.NewCustServiceRepository(DB)
+	csusecase := usecase_customer_service.NewCustServiceUsecase(cs, schedulerUsecase)
+	csusecase.RegisterJobs()
+	schedulerUsecase.Start()
+
+	//    Repositori, usecase, dan controller untuk verifikasi kredensial dokter
+	credentialRepo := repository_credential.NewCredentialRepository(DB)
+	credentialUsecase := usecase_credential.NewCredentialUsecase(credentialRepo, privateStorage, fileStorage)
+	credentialController := controller_credential.NewCredentialController(credentialUsecase)
+
+	//    Repositori, usecase, dan controller untuk pembatalan dan refund
+	refundRepo := repository_refund.NewRefundRepository(DB)
+	refundUsecase := usecase_refund.NewRefundUsecase(refundRepo, consultationUsecase, midtransService, schedulerUsecase)
+	refundController := controller_refund.NewRefundController(refundUsecase)
+
+	//    Repositori, usecase, dan controller untuk ulasan dokter
+	reviewRepo := repository_review.NewReviewRepository(DB)
+	reviewUsecase := usecase_review.NewReviewUsecase(reviewRepo)
+	reviewController := controller_review.NewReviewController(reviewUsecase)
+
+	//    Repositori, usecase, dan controller untuk chat konsultasi
+	chatRepo := repository_chat.NewChatRepository(DB)
+	chatUsecase := usecase_chat.NewChatUsecase(chatRepo, consultationUsecase)
+	chatController := controller_chat.NewChatController(chatUsecase, service.NewChatHub())
 
 	//    Repositori, usecase, dan controller untuk Consultasi
 	artikelonRepo := repository_artikel.NewArtikelRepository(DB)
 	artikelUsecase := usecase_artikel.NewArtikelUsecase(artikelonRepo)
-	artikelController := controller_artikel.NewArtikelController(artikelUsecase)
+	artikelController := controller_artikel.NewArtikelController(artikelUsecase, imagePipeline)
+
+	// Penyaring risiko bunuh diri dan menyakiti diri, pemeriksaan model opsional (SAFETY_MODEL_CHECK=true)
+	var safetyModel service.LLMProvider
+	if os.Getenv("SAFETY_MODEL_CHECK") == "true" {
+		safetyModel = llmProvider
+	}
+	safetyClassifier := safety.NewClassifier(safetyModel)
 
 	//    Repositori, usecase, dan controller untuk chatbot ai
 	chatbotRepo := repository_chatbot_ai.NewChatLogRepository(DB)
-	chatbotUsecase := usecase_chatbot_ai.NewChatbotUsecase(chatbotRepo)
+	chatbotUsecase := usecase_chatbot_ai.NewChatbotUsecase(chatbotRepo, llmProvider, safetyClassifier)
 	chatbotController := controller_chatbot_ai.NewChatbotController(chatbotUsecase)
 
+	//    Repositori, usecase, dan controller untuk peringatan keamanan chatbot
+	safetyRepo := repository_safety.NewSafetyAlertRepository(DB)
+	safetyUsecase := usecase_safety.NewSafetyAlertUsecase(safetyRepo)
+	safetyController := controller_safety.NewSafetyAlertController(safetyUsecase)
+
 	// 	  Repository, usecase, dan controller untuk chatbot ai doctor
 	chatbotDoctorRepo := repository_chatbot_ai_doctor.NewDoctorChatbotRepository(DB)
-	chatbotDoctorUsecase := usecase_chatbot_ai_doctor.NewDoctorChatbotUsecase(chatbotDoctorRepo)
+	chatbotDoctorUsecase := usecase_chatbot_ai_doctor.NewDoctorChatbotUsecase(chatbotDoctorRepo, llmProvider)
 	chatbotDoctorController := controller_chatbot_ai_doctor.NewDoctorChatbotController(chatbotDoctorUsecase)
 
 	//    Repositori, usecase, dan controller untuk profil admin
 	adminprofil := repository_profile.NewAdminProfileRepository(DB)
-	adminusecase := usecase_profile.NewAdminProfileUseCase(adminprofil)
-	admincontroller := controller_profile.NewAdminController(adminusecase)
+	adminusecase := usecase_profile.NewAdminProfileUseCase(adminprofil, imagePipeline)
+	admincontroller := controller_profile.NewAdminController(adminusecase, imagePipeline)
 
-	// customer service
-	cs := repository_customer_service.NewCustServiceRepository(DB)
-	csusecase := usecase_customer_service.NewCustServiceUsecase(cs)
-	cscontroller := controller_customer_service.NewCustServiceController(csusecase)
+	// customer service dan FAQ
+	faqRepo := repository_faq.NewFaqRepository(DB)
+	faqUsecase := usecase_faq.NewFaqUsecase(faqRepo, llmProvider)
+	faqController := controller_faq.NewFaqController(faqUsecase)
+	cscontroller := controller_customer_service.NewCustServiceController(csusecase, faqUsecase)
 
 	// notifikasi midtrans
 	midtrans_notifikasi := controller_notifikasi.NewMidtransNotificationController(consultationUsecase)
@@ -141,10 +265,14 @@ func main() {
 	statsController := controller_statistik.NewStatsController(statsUsecase)
 
 	// Middleware
-	jwtMiddleware := middlewares.NewJWTMiddleware(jwtSecret)
+	jwtMiddleware := middlewares.NewJWTMiddleware(jwtSecret, sessionRepo)
 
 	e := echo.New()
-	e.Static("/uploads", "uploads")
+	// X-Forwarded-For hanya dipercaya dari proxy di jaringan privat agar batas per IP tidak bisa dipalsukan klien
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	if storageConfig.Driver == "local" {
+		e.Static(storageConfig.LocalStaticPath(), storageConfig.LocalDir)
+	}
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     []string{"https://jovial-mooncake-23a3d0.netlify.app", "https://calmind6.netlify.app", "http://localhost:5173", "http://localhost:5174", "http://127.0.0.1:5500"},
 		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
@@ -163,19 +291,38 @@ func main() {
 
 	// Group User
 	userGroup := e.Group("/user", jwtMiddleware.HandlerUser)
+	routes.UserSessionRoutes(userGroup, userController)
 	routes.UserProfil(userGroup, userProfilController, userFiturController, consultationController, artikelController)
 	routes.UserChatbotRoutes(userGroup, chatbotController)
+	routes.UserScheduleRoutes(userGroup, jadwalController)
+	routes.UserConsultationChatRoutes(userGroup, chatController)
+	routes.UserRefundRoutes(userGroup, refundController)
+	routes.UserTicketRoutes(userGroup, cscontroller)
+	routes.UserReviewRoutes(userGroup, reviewController)
 
 	// Group Admin
 	adminGroup := e.Group("/admin", jwtMiddleware.HandlerAdmin)
+	routes.AdminSessionRoutes(adminGroup, adminController)
 	routes.AdminManagementRoutes(adminGroup, adminControllerManagement, artikelController, consultationController, admincontroller, statsController)
+	routes.AdminRefundRoutes(adminGroup, refundController)
+	routes.AdminCredentialRoutes(adminGroup, credentialController)
+	routes.AdminSafetyRoutes(adminGroup, safetyController)
+	routes.AdminTicketRoutes(adminGroup, cscontroller)
+	routes.AdminFaqRoutes(adminGroup, faqController)
+	routes.AdminReviewRoutes(adminGroup, reviewController)
 
 	// Group Doctor
 	doctorGroup := e.Group("/doctor", jwtMiddleware.HandlerDoctor)
+	routes.DoctorSessionRoutes(doctorGroup, doctorControllerManagement)
 	routes.DoctorProfil(doctorGroup, doctorProfilController, artikelController, consultationController, userFiturController)
 	routes.DoctorChatbotRoutes(doctorGroup, chatbotDoctorController)
+	routes.DoctorScheduleRoutes(doctorGroup, jadwalController)
+	routes.DoctorConsultationChatRoutes(doctorGroup, chatController)
+	routes.DoctorCredentialRoutes(doctorGroup, credentialController)
+	routes.DoctorReviewRoutes(doctorGroup, reviewController)
 
 	routes.UserCustServiceRoutes(e, cscontroller)
+	routes.FaqRoutes(e, faqController)
 
 	routes.WebhookRoutes(e, midtrans_notifikasi)
 
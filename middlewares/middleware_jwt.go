@@ -3,7 +3,9 @@ package middlewares
 import (
 	"calmind/config"
 	"calmind/helper"
+	repository "calmind/repository/authentikasi"
 	"calmind/service"
+	"log"
 	"net/http"
 	"strings"
 
@@ -12,16 +14,25 @@ import (
 )
 
 type JWTMiddleware struct {
-	config *config.JWTConfig
+	config      *config.JWTConfig
+	sessionRepo repository.SessionRepository
 }
 
-func NewJWTMiddleware(cfg *config.JWTConfig) *JWTMiddleware {
-	return &JWTMiddleware{config: cfg}
+func NewJWTMiddleware(cfg *config.JWTConfig, sessionRepo repository.SessionRepository) *JWTMiddleware {
+	return &JWTMiddleware{config: cfg, sessionRepo: sessionRepo}
 }
 
 // Fungsi untuk memvalidasi token dari header Authorization
 func (m *JWTMiddleware) validateToken(c echo.Context) (*service.JwtCustomClaims, error) {
 	authHeader := c.Request().Header.Get("Authorization")
+
+	// Browser tidak bisa mengirim header Authorization saat membuka WebSocket, token dikirim lewat query
+	if authHeader == "" && isWebSocketUpgrade(c.Request()) {
+		if token := c.QueryParam("token"); token != "" {
+			authHeader = "Bearer " + token
+		}
+	}
+
 	if authHeader == "" {
 		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Token tidak ditemukan")
 	}
@@ -43,9 +54,50 @@ func (m *JWTMiddleware) validateToken(c echo.Context) (*service.JwtCustomClaims,
 		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Token tidak valid")
 	}
 
+	if err := m.checkSession(claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
 
+// Menolak token yang sudah di-logout (jti dicabut) atau diterbitkan sebelum sesi akun dicabut
+// (misalnya setelah reset password atau keluar dari semua perangkat)
+func (m *JWTMiddleware) checkSession(claims *service.JwtCustomClaims) error {
+	if m.sessionRepo == nil {
+		return nil
+	}
+
+	// Token lama tanpa jti tidak bisa dicabut, pengguna harus login ulang
+	if claims.ID == "" {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Sesi sudah berakhir, silakan login kembali")
+	}
+
+	revoked, err := m.sessionRepo.IsAccessTokenRevoked(claims.ID)
+	if err != nil {
+		log.Printf("Gagal memeriksa pencabutan token %s %d: %v", claims.Role, claims.UserID, err)
+		return echo.NewHTTPError(http.StatusUnauthorized, "Gagal memvalidasi sesi")
+	}
+	if revoked {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Sesi sudah berakhir, silakan login kembali")
+	}
+
+	revokedAt, err := m.sessionRepo.GetSessionsRevokedAt(claims.Role, claims.UserID)
+	if err != nil {
+		log.Printf("Gagal memeriksa sesi %s %d: %v", claims.Role, claims.UserID, err)
+		return echo.NewHTTPError(http.StatusUnauthorized, "Gagal memvalidasi sesi")
+	}
+
+	if revokedAt != nil && (claims.IssuedAt == nil || claims.IssuedAt.Time.Before(*revokedAt)) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Sesi sudah berakhir, silakan login kembali")
+	}
+	return nil
+}
+
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
 // Middleware untuk Admin
 func (m *JWTMiddleware) HandlerAdmin(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
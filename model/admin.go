@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type Admin struct {
 	ID       int       `gorm:"primaryKey;autoIncrement" json:"id"`
 	Username string    `gorm:"not null" json:"username"`
@@ -8,4 +10,6 @@ type Admin struct {
 	Password string    `gorm:"not null" json:"password"`
 	Role     string    `gorm:"not null" json:"role"`
 	Artikels []Artikel `gorm:"foreignKey:AdminID" json:"artikels"`
+
+	SessionsRevokedAt *time.Time `json:"-"` // Token yang diterbitkan sebelum waktu ini ditolak
 }
@@ -2,14 +2,64 @@ package model
 
 import "time"
 
+// Status artikel. Artikel published dengan PublishedAt di masa depan berarti terjadwal dan belum tampil.
+const (
+	ArtikelStatusDraft     = "draft"
+	ArtikelStatusPublished = "published"
+	ArtikelStatusArchived  = "archived"
+)
+
 type Artikel struct {
+	ID              int              `gorm:"primaryKey;autoIncrement" json:"id"`
+	AdminID         int              `gorm:"not null" json:"admin_id"`
+	Admin           Admin            `gorm:"foreignKey:AdminID" json:"admin"`
+	Judul           string           `gorm:"not null" json:"judul"`
+	Slug            string           `gorm:"type:varchar(191);uniqueIndex" json:"slug"`
+	Ringkasan       string           `gorm:"type:text" json:"ringkasan"`
+	DeleteURL       string           `json:"delete_url"`
+	Gambar          string           `json:"gambar"`
+	GambarThumbnail string           `json:"gambar_thumbnail"` // Dihasilkan pipeline upload bersama Gambar
+	Isi             string           `gorm:"not null" json:"isi"`
+	Status          string           `gorm:"type:varchar(20);not null;default:'draft';index" json:"status"`
+	PublishedAt     *time.Time       `gorm:"index" json:"published_at"`
+	CategoryID      *int             `gorm:"index" json:"category_id"`
+	Category        *ArtikelCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Tags            []Tags           `gorm:"many2many:artikel_tags" json:"tags"`
+	ReadingMinutes  int              `gorm:"not null;default:0" json:"reading_minutes"` // Estimasi waktu baca dari jumlah kata Isi
+
+	// Kata dasar judul dan isi tanpa kata umum untuk indeks FULLTEXT, diisi ulang setiap kali artikel disimpan
+	SearchJudul string `gorm:"type:text;index:idx_artikels_search_judul,class:FULLTEXT;index:idx_artikels_search,class:FULLTEXT,priority:1" json:"-"`
+	SearchIsi   string `gorm:"type:longtext;index:idx_artikels_search,class:FULLTEXT,priority:2" json:"-"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type ArtikelCategory struct {
 	ID        int       `gorm:"primaryKey;autoIncrement" json:"id"`
-	AdminID   int       `gorm:"not null" json:"admin_id"`
-	Admin     Admin     `gorm:"foreignKey:AdminID" json:"admin"`
-	Judul     string    `gorm:"not null" json:"judul"`
-	DeleteURL string    `json:"delete_url"`
-	Gambar    string    `json:"gambar"`
-	Isi       string    `gorm:"not null" json:"isi"`
+	Name      string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	Slug      string    `gorm:"type:varchar(120);uniqueIndex;not null" json:"slug"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
+
+// Salinan artikel setiap kali disimpan. Tidak pernah diubah atau dihapus selain ikut terhapus bersama artikelnya.
+type ArtikelRevision struct {
+	ID              int        `gorm:"primaryKey;autoIncrement" json:"id"`
+	ArtikelID       int        `gorm:"not null;uniqueIndex:idx_artikel_revisions_number" json:"artikel_id"`
+	Number          int        `gorm:"not null;uniqueIndex:idx_artikel_revisions_number" json:"number"` // Urutan revisi per artikel, mulai dari 1
+	AdminID         int        `gorm:"not null;index" json:"admin_id"`                                  // Admin yang menyimpan
+	Admin           Admin      `gorm:"foreignKey:AdminID" json:"admin"`
+	Note            string     `gorm:"type:varchar(255)" json:"note"`
+	Judul           string     `gorm:"not null" json:"judul"`
+	Slug            string     `gorm:"type:varchar(191)" json:"slug"`
+	Ringkasan       string     `gorm:"type:text" json:"ringkasan"`
+	Isi             string     `gorm:"type:longtext;not null" json:"isi"`
+	Gambar          string     `json:"gambar"`
+	GambarThumbnail string     `json:"gambar_thumbnail"`
+	Status          string     `gorm:"type:varchar(20);not null" json:"status"`
+	PublishedAt     *time.Time `json:"published_at"`
+	CategoryID      *int       `json:"category_id"`
+	TagNames        []string   `gorm:"type:text;serializer:json" json:"tags"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
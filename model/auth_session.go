@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Refresh token yang tersimpan di server, hanya hash-nya yang disimpan.
+// Setiap rotasi membuat baris baru dengan FamilyID yang sama; token lama yang dipakai ulang mencabut seluruh family.
+type RefreshToken struct {
+	ID         int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	FamilyID   string     `json:"family_id" gorm:"type:varchar(64);not null;index"`
+	TokenHash  string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Role       string     `json:"role" gorm:"type:varchar(10);not null;index:idx_refresh_tokens_account"`
+	AccountID  int        `json:"account_id" gorm:"not null;index:idx_refresh_tokens_account"`
+	Email      string     `json:"email"`
+	IsVerified bool       `json:"is_verified"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"index"`
+	UsedAt     *time.Time `json:"used_at"`    // Diisi saat token dirotasi
+	RevokedAt  *time.Time `json:"revoked_at"` // Diisi saat logout atau terdeteksi dipakai ulang
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Daftar access token (jti) yang dicabut sebelum masa berlakunya habis
+type RevokedToken struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	JTI       string    `json:"jti" gorm:"column:jti;type:varchar(64);not null;uniqueIndex"`
+	Role      string    `json:"role" gorm:"type:varchar(10)"`
+	AccountID int       `json:"account_id"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"` // Baris boleh dihapus setelah token kedaluwarsa
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
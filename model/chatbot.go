@@ -3,9 +3,24 @@ package model
 import "time"
 
 type ChatLog struct {
-	ID        int       `gorm:"primaryKey;autoIncrement"`
-	UserID    int       `gorm:"not null"`
-	Message   string    `gorm:"type:text;not null"` // Pesan dari user
-	Response  string    `gorm:"type:text;not null"` // Respons chatbot
-	CreatedAt time.Time `gorm:"autoCreateTime"`     // Waktu percakapan
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    int       `json:"user_id" gorm:"not null"`
+	SessionID int       `json:"session_id" gorm:"index"`                           // 0 untuk log lama sebelum ada sesi
+	Message   string    `json:"message" gorm:"type:text;not null"`                 // Pesan dari user
+	Response  string    `json:"response" gorm:"type:text;not null"`                // Respons chatbot
+	RiskLevel string    `json:"risk_level" gorm:"type:varchar(10);default:'none'"` // Hasil penyaringan keamanan
+	Flagged   bool      `json:"flagged" gorm:"default:false;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"` // Waktu percakapan
+}
+
+// Sesi percakapan chatbot AI milik user
+type ChatSession struct {
+	ID                int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID            int        `json:"user_id" gorm:"not null;index"`
+	Title             string     `json:"title" gorm:"type:varchar(100);not null"`
+	Summary           string     `json:"-" gorm:"type:text"` // Ringkasan berjalan untuk pesan yang sudah keluar dari jendela konteks
+	SummarizedUntilID int        `json:"-"`                  // ID ChatLog terakhir yang sudah masuk ringkasan
+	LastMessageAt     *time.Time `json:"last_message_at"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// Riwayat perubahan status konsultasi
+type ConsultationStatusHistory struct {
+	ID             int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConsultationID int       `json:"consultation_id" gorm:"not null;index"`
+	FromStatus     string    `json:"from_status" gorm:"type:varchar(20)"`
+	ToStatus       string    `json:"to_status" gorm:"type:varchar(20);not null"`
+	ActorRole      string    `json:"actor_role" gorm:"type:varchar(10);not null"` // user, doctor, admin, system
+	ActorID        int       `json:"actor_id"`                                    // 0 untuk system
+	Note           string    `json:"note"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
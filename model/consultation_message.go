@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Pesan chat antara user dan dokter dalam satu konsultasi
+type ConsultationMessage struct {
+	ID             int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConsultationID int       `json:"consultation_id" gorm:"not null;index"`
+	SenderRole     string    `json:"sender_role" gorm:"type:varchar(10);not null"` // user atau doctor
+	SenderID       int       `json:"sender_id" gorm:"not null"`
+	Content        string    `json:"content" gorm:"type:text;not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
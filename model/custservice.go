@@ -2,12 +2,52 @@ package model
 
 import "time"
 
+// Status tiket bantuan
+const (
+	TicketStatusOpen       = "open"        // Menunggu jawaban admin
+	TicketStatusInProgress = "in_progress" // Sedang ditangani admin
+	TicketStatusAnswered   = "answered"    // Sudah dijawab, menunggu tanggapan user
+	TicketStatusClosed     = "closed"
+)
+
+// Prioritas tiket bantuan
+const (
+	TicketPriorityLow    = "low"
+	TicketPriorityNormal = "normal"
+	TicketPriorityHigh   = "high"
+	TicketPriorityUrgent = "urgent"
+)
+
+// Penulis balasan tiket
+const (
+	TicketAuthorUser  = "user"
+	TicketAuthorAdmin = "admin"
+)
+
+// Tiket bantuan customer service. Message adalah pesan pertama, Answer jawaban admin terakhir.
 type CustService struct {
+	ID              int                `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID          int                `json:"user_id" gorm:"index"`
+	User            User               `json:"-" gorm:"foreignKey:UserID"`
+	Subject         string             `json:"subject" gorm:"type:varchar(150)"`
+	Message         string             `json:"message" gorm:"type:text"`
+	Answer          string             `json:"answer" gorm:"type:text"`
+	IsAnswered      bool               `json:"is_answered"`
+	Status          string             `json:"status" gorm:"type:varchar(20);default:'open';index"`
+	Priority        string             `json:"priority" gorm:"type:varchar(10);default:'normal';index"`
+	AssignedAdminID *int               `json:"assigned_admin_id" gorm:"index"`
+	Replies         []CustServiceReply `json:"replies,omitempty" gorm:"foreignKey:TicketID"`
+	CreatedAt       time.Time          `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time          `json:"updated_at" gorm:"autoUpdateTime"`
+	AnsweredAt      *time.Time         `json:"answered_at"`
+}
+
+// Balasan pada tiket dari user atau admin
+type CustServiceReply struct {
 	ID         int       `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserID     int       `json:"user_id"`
-	Message    string    `json:"message"`
-	Answer     string    `json:"answer"`
-	IsAnswered bool      `json:"is_answered"`
+	TicketID   int       `json:"ticket_id" gorm:"not null;index"`
+	AuthorRole string    `json:"author_role" gorm:"type:varchar(10)"`
+	AuthorID   int       `json:"author_id"`
+	Message    string    `json:"message" gorm:"type:text;not null"`
 	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
-	AnsweredAt time.Time `json:"answered_at" gorm:"autoUpdateTime"`
 }
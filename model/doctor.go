@@ -3,31 +3,38 @@ package model
 import "time"
 
 type Doctor struct {
-	ID              int            `json:"id" gorm:"primaryKey;autoIncrement"`
-	Username        string         `gorm:"not null" json:"username"`
-	NoHp            string         `gorm:"not null" json:"no_hp"`
-	Email           string         `gorm:"unique;not null" json:"email"`
-	Password        string         `gorm:"not null" json:"password"`
-	Role            string         `gorm:"not null" json:"role"`
-	Avatar          string         `json:"avatar"`
-	DateOfBirth     string         `json:"date_of_birth"`
-	Address         string         `json:"address"`
-	Schedule        string         `json:"schedule"`
-	IsVerified      bool           `json:"is_verified" gorm:"default:false"`
-	IsActive        bool           `json:"is_active" gorm:"default:true"`
-	Price           float64        `json:"price" gorm:"default:100000"`
-	Experience      int            `json:"experience"`
-	STRNumber       string         `json:"str_number"`
-	About           string         `json:"about"`
-	JenisKelamin    string         `gorm:"type:enum('Laki-laki', 'Perempuan')" json:"jenis_kelamin"`
-	TitleID         int            `json:"title_id"`
-	DeleteURL       string         `json:"delete_url"`
-	Title           Title          `json:"title" gorm:"foreignKey:TitleID"`
-	Tags            []Tags         `json:"tags" gorm:"many2many:doctor_tags"`
-	CreatedAt       time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt       time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	Consultations   []Consultation `json:"consultations" gorm:"foreignKey:DoctorID"` // Perbaiki relasi ini
-	Recommendations []Rekomendasi  `gorm:"foreignKey:DoctorID"`
+	ID                   int                `json:"id" gorm:"primaryKey;autoIncrement"`
+	Username             string             `gorm:"not null" json:"username"`
+	NoHp                 string             `gorm:"not null" json:"no_hp"`
+	Email                string             `gorm:"unique;not null" json:"email"`
+	Password             string             `gorm:"not null" json:"password"`
+	Role                 string             `gorm:"not null" json:"role"`
+	Avatar               string             `json:"avatar"`
+	DateOfBirth          string             `json:"date_of_birth"`
+	Address              string             `json:"address"`
+	Schedule             string             `json:"schedule"`
+	IsVerified           bool               `json:"is_verified" gorm:"default:false"`
+	IsActive             bool               `json:"is_active" gorm:"default:true"`
+	SessionsRevokedAt    *time.Time         `json:"-"` // Token yang diterbitkan sebelum waktu ini ditolak
+	Price                float64            `json:"price" gorm:"default:100000"`
+	Experience           int                `json:"experience"`
+	STRNumber            string             `json:"str_number"`
+	CredentialStatus     string             `json:"credential_status" gorm:"type:varchar(20);default:'unsubmitted';index"`
+	CredentialNote       string             `json:"credential_note"` // Alasan penolakan terakhir dari admin
+	CredentialReviewedAt *time.Time         `json:"credential_reviewed_at"`
+	Credentials          []DoctorCredential `json:"credentials,omitempty" gorm:"foreignKey:DoctorID"`
+	RatingAverage        float64            `json:"rating_average" gorm:"default:0"` // Rata-rata ulasan yang tampil, diperbarui setiap ulasan berubah
+	RatingCount          int                `json:"rating_count" gorm:"default:0"`
+	About                string             `json:"about"`
+	JenisKelamin         string             `gorm:"type:enum('Laki-laki', 'Perempuan')" json:"jenis_kelamin"`
+	TitleID              int                `json:"title_id"`
+	DeleteURL            string             `json:"delete_url"`
+	Title                Title              `json:"title" gorm:"foreignKey:TitleID"`
+	Tags                 []Tags             `json:"tags" gorm:"many2many:doctor_tags"`
+	CreatedAt            time.Time          `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt            time.Time          `json:"updated_at" gorm:"autoUpdateTime"`
+	Consultations        []Consultation     `json:"consultations" gorm:"foreignKey:DoctorID"` // Perbaiki relasi ini
+	Recommendations      []Rekomendasi      `gorm:"foreignKey:DoctorID"`
 }
 
 type Tags struct {
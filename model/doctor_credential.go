@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Status verifikasi kredensial profesi dokter
+const (
+	CredentialStatusUnsubmitted = "unsubmitted" // Dokumen belum diajukan
+	CredentialStatusPending     = "pending"     // Menunggu review admin
+	CredentialStatusApproved    = "approved"    // Dokter tampil di daftar dan bisa dipesan
+	CredentialStatusRejected    = "rejected"    // Ditolak, dokter perlu memperbaiki dokumen
+)
+
+// Jenis dokumen kredensial
+const (
+	CredentialTypeSTR         = "str"
+	CredentialTypeCertificate = "certificate"
+)
+
+// Dokumen STR atau sertifikat yang diunggah dokter
+type DoctorCredential struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	DoctorID  int       `json:"doctor_id" gorm:"not null;index"`
+	Type      string    `json:"type" gorm:"type:varchar(20);not null"`
+	FileName  string    `json:"file_name"`
+	FileURL   string    `json:"file_url,omitempty" gorm:"not null"` // Hanya dokumen lama; dokumen baru diunduh lewat endpoint kredensial
+	PublicID  string    `json:"-"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Riwayat keputusan admin atas pengajuan kredensial
+type DoctorCredentialReview struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	DoctorID  int       `json:"doctor_id" gorm:"not null;index"`
+	AdminID   int       `json:"admin_id"`
+	Decision  string    `json:"decision" gorm:"type:varchar(20);not null"` // approved atau rejected
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Jadwal mingguan dokter yang berulang setiap minggu
+type DoctorSchedule struct {
+	ID           int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	DoctorID     int       `json:"doctor_id" gorm:"not null;index"`
+	DayOfWeek    int       `json:"day_of_week" gorm:"not null"`                // 0 = Minggu, 6 = Sabtu
+	StartTime    string    `json:"start_time" gorm:"type:varchar(5);not null"` // Format HH:MM
+	EndTime      string    `json:"end_time" gorm:"type:varchar(5);not null"`   // Format HH:MM
+	SlotDuration int       `json:"slot_duration" gorm:"not null;default:60"`   // Durasi satu slot (menit)
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Pengecualian jadwal pada tanggal tertentu (libur, blokir jam, atau jam tambahan)
+type DoctorScheduleException struct {
+	ID           int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	DoctorID     int       `json:"doctor_id" gorm:"not null;index"`
+	Date         string    `json:"date" gorm:"type:varchar(10);not null;index"` // Format YYYY-MM-DD
+	IsAvailable  bool      `json:"is_available" gorm:"default:false"`           // true = jam tambahan, false = tidak tersedia
+	StartTime    string    `json:"start_time" gorm:"type:varchar(5)"`           // Kosong = sepanjang hari (libur)
+	EndTime      string    `json:"end_time" gorm:"type:varchar(5)"`
+	SlotDuration int       `json:"slot_duration" gorm:"default:60"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Slot waktu yang bisa dipesan user
+type TimeSlot struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Duration int       `json:"duration"`
+}
@@ -40,6 +40,7 @@ type ConsultationDTO struct {
 	User          *UserDTO            `json:"user,omitempty"`
 	Doctor        *DoctorDTO          `json:"doctor,omitempty"`
 	PaymentStatus string              `json:"payment_status" gorm:"type:varchar(20)"` // pending, completed, failed
+	Amount        float64             `json:"amount"`
 	PaymentURL    string              `json:"payment_url,omitempty"`
 	CreatedAt     time.Time           `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time           `json:"updated_at" gorm:"autoUpdateTime"`
@@ -47,13 +48,14 @@ type ConsultationDTO struct {
 }
 
 type SimpleConsultationDTO struct {
-	ID            int    `json:"id"`
-	Title         string `json:"title"`
-	Description   string `json:"description"`
-	Duration      int    `json:"duration"`
-	Status        string `json:"status"`
-	PaymentStatus string `json:"payment_status" gorm:"type:varchar(20)"` // pending, completed, failed
-	StartTime     string `json:"start_time"`
-	OrderID       string `json:"order_id"`
-	PaymentURL    string `json:"payment_url"`
+	ID            int     `json:"id"`
+	Title         string  `json:"title"`
+	Description   string  `json:"description"`
+	Duration      int     `json:"duration"`
+	Status        string  `json:"status"`
+	PaymentStatus string  `json:"payment_status" gorm:"type:varchar(20)"` // pending, completed, failed
+	Amount        float64 `json:"amount"`
+	StartTime     string  `json:"start_time"`
+	OrderID       string  `json:"order_id"`
+	PaymentURL    string  `json:"payment_url"`
 }
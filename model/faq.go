@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Kategori FAQ customer service, diurutkan berdasarkan Position
+type FaqCategory struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string    `json:"name" gorm:"type:varchar(100);unique;not null"`
+	Position  int       `json:"position"`
+	Faqs      []Faq     `json:"faqs,omitempty" gorm:"foreignKey:CategoryID"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Pertanyaan dan jawaban FAQ. Keywords berisi sinonim dipisah koma untuk membantu pencarian.
+type Faq struct {
+	ID         int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	CategoryID int       `json:"category_id" gorm:"not null;index"`
+	Question   string    `json:"question" gorm:"type:varchar(255);not null"`
+	Answer     string    `json:"answer" gorm:"type:text;not null"`
+	Keywords   string    `json:"keywords" gorm:"type:varchar(255)"`
+	Position   int       `json:"position"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
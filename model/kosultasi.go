@@ -10,17 +10,21 @@ type Consultation struct {
 	Doctor        Doctor        `json:"doctor" gorm:"foreignKey:DoctorID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
 	Title         string        `json:"title" gorm:"not null"`
 	Description   string        `json:"description" gorm:"type:text;not null"`
-	Duration      int           `json:"duration" gorm:"default:120"`                            // Durasi (default: 2 jam)
-	IsApproved    bool          `json:"is_approved" gorm:"default:false"`                       // Status persetujuan admin
-	PaymentStatus string        `json:"payment_status" gorm:"type:varchar(20)"`                 // pending, completed, failed
-	OrderID       string        `json:"order_id"`                                               // ID pembayaran (Midtrans)
-	Status        string        `json:"status" gorm:"type:varchar(20);default:'pending';index"` // Indeks Status
+	Duration      int           `json:"duration" gorm:"default:120"`                                    // Durasi (default: 2 jam)
+	IsApproved    bool          `json:"is_approved" gorm:"default:false"`                               // Status persetujuan admin
+	PaymentStatus string        `json:"payment_status" gorm:"type:varchar(20)"`                         // pending, completed, failed
+	Amount        float64       `json:"amount" gorm:"not null;default:0"`                               // Harga dokter saat dipesan, nominal yang ditagihkan
+	OrderID       string        `json:"order_id"`                                                       // ID pembayaran (Midtrans)
+	Status        string        `json:"status" gorm:"type:varchar(20);default:'pending_payment';index"` // Lihat service/lifecycle
 	StartTime     time.Time     `json:"start_time"`
 	CreatedAt     time.Time     `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
 	Rekomendasi   []Rekomendasi `json:"rekomendasi" gorm:"foreignKey:ConsultationID"`
 }
 
+// Status konsultasi yang masih menempati slot jadwal dokter
+var BookedConsultationStatuses = []string{"pending_payment", "paid", "approved", "in_session"}
+
 type Rekomendasi struct {
 	ID             int       `json:"id" gorm:"primaryKey;autoIncrement"`
 	ConsultationID int       `json:"consultation_id" gorm:"not null;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
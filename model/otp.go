@@ -2,10 +2,18 @@ package model
 
 import "time"
 
+// Kegunaan kode OTP
+const (
+	OtpPurposeVerification  = "verification"
+	OtpPurposePasswordReset = "password_reset" // Disimpan dengan akhiran peran, contoh: password_reset:doctor
+)
+
 type Otp struct {
 	ID        int       `json:"id" gorm:"primaryKey"`
 	Email     string    `json:"email"`
 	Code      string    `json:"code"`
+	Purpose   string    `json:"purpose" gorm:"type:varchar(30);default:'verification';index"`
+	Attempts  int       `json:"attempts" gorm:"default:0"` // Jumlah percobaan kode yang salah
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"` // otomatis saat data dibuat
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"` // otomatis saat data diupdate
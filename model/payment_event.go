@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Log setiap notifikasi Midtrans yang diterima webhook
+type PaymentEvent struct {
+	ID                int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TransactionID     string    `json:"transaction_id" gorm:"type:varchar(64);index"`
+	OrderID           string    `json:"order_id" gorm:"type:varchar(64);index"`
+	TransactionStatus string    `json:"transaction_status" gorm:"type:varchar(32)"`
+	StatusCode        string    `json:"status_code" gorm:"type:varchar(8)"`
+	GrossAmount       string    `json:"gross_amount" gorm:"type:varchar(32)"`
+	FraudStatus       string    `json:"fraud_status" gorm:"type:varchar(32)"`
+	PaymentType       string    `json:"payment_type" gorm:"type:varchar(32)"`
+	DedupKey          *string   `json:"-" gorm:"type:varchar(100);uniqueIndex"` // transaction_id:transaction_status, hanya diisi untuk notifikasi valid
+	Outcome           string    `json:"outcome" gorm:"type:varchar(32);index"`  // processed, rejected_signature, rejected_amount, rejected_order
+	Note              string    `json:"note"`
+	RawPayload        string    `json:"raw_payload" gorm:"type:text"`
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Payload notifikasi HTTP dari Midtrans
+type MidtransNotification struct {
+	TransactionID     string `json:"transaction_id"`
+	OrderID           string `json:"order_id"`
+	TransactionStatus string `json:"transaction_status"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	SignatureKey      string `json:"signature_key"`
+	FraudStatus       string `json:"fraud_status"`
+	PaymentType       string `json:"payment_type"`
+}
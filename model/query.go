@@ -0,0 +1,63 @@
+package model
+
+import "errors"
+
+// Jumlah item per halaman pada endpoint daftar
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var (
+	ErrInvalidSort   = errors.New("field pengurutan tidak didukung")
+	ErrInvalidFilter = errors.New("filter tidak didukung")
+	ErrInvalidCursor = errors.New("cursor hanya bisa dipakai dengan pengurutan berdasarkan id")
+)
+
+// Opsi daftar yang diterima repository: halaman, cursor, urutan, filter kesamaan, dan pencarian.
+// Sort berisi nama field dengan awalan "-" untuk urutan menurun, misalnya "-created_at".
+type QueryOptions struct {
+	Page    int
+	Limit   int
+	Cursor  int // ID item terakhir halaman sebelumnya, menggantikan Page jika diisi
+	Sort    string
+	Filters map[string]string
+	Search  string // Pencarian teks bebas pada kolom yang ditentukan repository
+}
+
+// Batas halaman dan jumlah item yang sudah dirapikan
+func (o QueryOptions) Normalized() QueryOptions {
+	if o.Page < 1 {
+		o.Page = 1
+	}
+	if o.Limit < 1 {
+		o.Limit = DefaultPageLimit
+	}
+	if o.Limit > MaxPageLimit {
+		o.Limit = MaxPageLimit
+	}
+	return o
+}
+
+func (o QueryOptions) Offset() int {
+	o = o.Normalized()
+	return (o.Page - 1) * o.Limit
+}
+
+// Informasi halaman yang dikirim bersama data daftar
+type PageInfo struct {
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Total      int64  `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor int    `json:"next_cursor,omitempty"` // Hanya diisi jika diurutkan berdasarkan id
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+}
+
+// Membuat PageInfo dari opsi dan total item
+func NewPageInfo(opts QueryOptions, total int64) PageInfo {
+	opts = opts.Normalized()
+	totalPages := int((total + int64(opts.Limit) - 1) / int64(opts.Limit))
+	return PageInfo{Page: opts.Page, Limit: opts.Limit, Total: total, TotalPages: totalPages}
+}
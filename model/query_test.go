@@ -0,0 +1,53 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryOptions_Normalized(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        QueryOptions
+		page, limit int
+		offset      int
+	}{
+		{"kosong", QueryOptions{}, 1, DefaultPageLimit, 0},
+		{"negatif", QueryOptions{Page: -3, Limit: -1}, 1, DefaultPageLimit, 0},
+		{"halaman ketiga", QueryOptions{Page: 3, Limit: 10}, 3, 10, 20},
+		{"limit dibatasi", QueryOptions{Page: 2, Limit: 500}, 2, MaxPageLimit, MaxPageLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts.Normalized()
+			assert.Equal(t, tt.page, opts.Page)
+			assert.Equal(t, tt.limit, opts.Limit)
+			assert.Equal(t, tt.offset, tt.opts.Offset())
+		})
+	}
+}
+
+func TestNewPageInfo(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       QueryOptions
+		total      int64
+		totalPages int
+	}{
+		{"tanpa data", QueryOptions{Page: 1, Limit: 10}, 0, 0},
+		{"kurang dari satu halaman", QueryOptions{Page: 1, Limit: 10}, 7, 1},
+		{"tepat satu halaman", QueryOptions{Page: 1, Limit: 10}, 10, 1},
+		{"sisa di halaman terakhir", QueryOptions{Page: 2, Limit: 10}, 21, 3},
+		{"limit dibatasi", QueryOptions{Limit: 1000}, 250, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := NewPageInfo(tt.opts, tt.total)
+			assert.Equal(t, tt.totalPages, page.TotalPages)
+			assert.Equal(t, tt.total, page.Total)
+			assert.Equal(t, tt.opts.Normalized().Page, page.Page)
+			assert.Equal(t, tt.opts.Normalized().Limit, page.Limit)
+		})
+	}
+}
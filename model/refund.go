@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Status refund
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+	RefundStatusSkipped   = "skipped" // Tidak ada dana yang dikembalikan (0% atau belum dibayar)
+)
+
+// Catatan pembatalan dan pengembalian dana konsultasi
+type Refund struct {
+	ID             int          `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConsultationID int          `json:"consultation_id" gorm:"not null;index"`
+	Consultation   Consultation `json:"-" gorm:"foreignKey:ConsultationID"`
+	OrderID        string       `json:"order_id" gorm:"type:varchar(64)"`
+	RefundKey      string       `json:"refund_key" gorm:"type:varchar(100);uniqueIndex"`
+	Method         string       `json:"method" gorm:"type:varchar(10)"` // cancel (belum dibayar) atau refund
+	PaidAmount     float64      `json:"paid_amount"`
+	Percentage     int          `json:"percentage"`
+	Amount         float64      `json:"amount"`
+	Reason         string       `json:"reason" gorm:"type:text"`
+	RequestedBy    string       `json:"requested_by" gorm:"type:varchar(10)"` // user atau admin
+	RequestedByID  int          `json:"requested_by_id"`
+	Status         string       `json:"status" gorm:"type:varchar(20);index"`
+	ErrorMessage   string       `json:"error_message" gorm:"type:text"`
+	CreatedAt      time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Status ulasan dokter
+const (
+	ReviewStatusVisible = "visible"
+	ReviewStatusHidden  = "hidden" // Disembunyikan admin, tidak dihitung dalam rating
+)
+
+// Ulasan user untuk satu konsultasi yang sudah selesai
+type DoctorReview struct {
+	ID             int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConsultationID int        `json:"consultation_id" gorm:"not null;uniqueIndex"`
+	UserID         int        `json:"user_id" gorm:"not null;index"`
+	User           User       `json:"-" gorm:"foreignKey:UserID"`
+	DoctorID       int        `json:"doctor_id" gorm:"not null;index"`
+	Rating         int        `json:"rating" gorm:"not null"` // 1 sampai 5
+	Comment        string     `json:"comment" gorm:"type:text"`
+	Reply          string     `json:"reply" gorm:"type:text"` // Balasan dokter
+	RepliedAt      *time.Time `json:"replied_at"`
+	Status         string     `json:"status" gorm:"type:varchar(20);default:'visible';index"`
+	ModeratedBy    *int       `json:"moderated_by,omitempty"` // ID admin
+	ModerationNote string     `json:"moderation_note,omitempty" gorm:"type:text"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Status tindak lanjut peringatan keamanan
+const (
+	SafetyAlertStatusOpen         = "open"
+	SafetyAlertStatusAcknowledged = "acknowledged" // Sedang ditangani admin
+	SafetyAlertStatusResolved     = "resolved"
+)
+
+// Peringatan untuk admin ketika percakapan chatbot menunjukkan risiko bunuh diri atau menyakiti diri
+type SafetyAlert struct {
+	ID         int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID     int        `json:"user_id" gorm:"not null;index"`
+	User       User       `json:"-" gorm:"foreignKey:UserID"`
+	ChatLogID  int        `json:"chat_log_id" gorm:"index"`
+	SessionID  int        `json:"session_id"`
+	RiskLevel  string     `json:"risk_level" gorm:"type:varchar(10)"`
+	Source     string     `json:"source" gorm:"type:varchar(10)"`     // rule atau model
+	FlaggedOn  string     `json:"flagged_on" gorm:"type:varchar(10)"` // message atau response
+	Matches    string     `json:"matches" gorm:"type:text"`
+	Message    string     `json:"message" gorm:"type:text"` // Salinan pesan user, tetap ada walau sesi dihapus
+	Status     string     `json:"status" gorm:"type:varchar(20);default:'open';index"`
+	HandledBy  *int       `json:"handled_by"` // ID admin
+	Note       string     `json:"note" gorm:"type:text"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
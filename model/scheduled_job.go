@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Status job terjadwal
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusDone      = "done"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// Job terjadwal yang disimpan di database agar tetap berjalan setelah server restart
+type ScheduledJob struct {
+	ID          int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	Type        string     `json:"type" gorm:"type:varchar(50);not null;index"`
+	UniqueKey   *string    `json:"unique_key" gorm:"type:varchar(100);uniqueIndex"` // Contoh: consultation_expire:12
+	Payload     string     `json:"payload" gorm:"type:text"`                        // JSON
+	Status      string     `json:"status" gorm:"type:varchar(20);not null;default:'pending';index:idx_scheduled_jobs_due,priority:1"`
+	RunAt       time.Time  `json:"run_at" gorm:"not null;index:idx_scheduled_jobs_due,priority:2"`
+	Attempts    int        `json:"attempts" gorm:"default:0"`
+	MaxAttempts int        `json:"max_attempts" gorm:"default:5"`
+	LastError   string     `json:"last_error" gorm:"type:text"`
+	LockedBy    string     `json:"locked_by" gorm:"type:varchar(100)"`
+	LockedAt    *time.Time `json:"locked_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -2,17 +2,37 @@ package repository
 
 import (
 	"calmind/model"
+	"calmind/repository/query"
+	"errors"
 
+	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 )
 
+// Akun masih dirujuk konsultasi (foreign key ON DELETE RESTRICT) sehingga tidak bisa dihapus
+var ErrHasConsultations = errors.New("akun masih memiliki riwayat konsultasi")
+
 type AdminManagementRepo interface {
 	FindAllUsers() ([]*model.User, error)
 	FindAllDoctors() ([]*model.Doctor, error)
 	DeleteUser(id int) (*model.User, error)
 	DeleteDoctor(id int) (*model.Doctor, error)
-	FindAllUsersWithLastConsultation() ([]*model.User, error)
-	FindAllDoctorsWithLastConsultation() ([]*model.Doctor, error)
+	FindAllUsersWithLastConsultation(opts model.QueryOptions) ([]*model.User, model.PageInfo, error)
+	FindAllDoctorsWithLastConsultation(opts model.QueryOptions) ([]*model.Doctor, model.PageInfo, error)
+}
+
+var userListSpec = query.Spec{
+	SortFields:  map[string]string{"id": "id", "username": "username", "created_at": "created_at"},
+	DefaultSort: "-created_at",
+	Filters:     map[string]string{"is_verified": "is_verified", "jenis_kelamin": "jenis_kelamin"},
+	Search:      []string{"username", "email", "no_hp"},
+}
+
+var doctorListSpec = query.Spec{
+	SortFields:  map[string]string{"id": "id", "username": "username", "created_at": "created_at", "price": "price", "experience": "experience"},
+	DefaultSort: "-created_at",
+	Filters:     map[string]string{"is_active": "is_active", "is_verified": "is_verified", "credential_status": "credential_status", "title_id": "title_id"},
+	Search:      []string{"username", "email", "no_hp", "str_number"},
 }
 
 type AdminManagementRepoImpl struct {
@@ -44,37 +64,36 @@ func (ar *AdminManagementRepoImpl) FindAllDoctors() ([]*model.Doctor, error) {
 }
 
 // Find all users with their last consultation
-// Find all users with their last consultation and recommendation
-func (ar *AdminManagementRepoImpl) FindAllUsersWithLastConsultation() ([]*model.User, error) {
+func (ar *AdminManagementRepoImpl) FindAllUsersWithLastConsultation(opts model.QueryOptions) ([]*model.User, model.PageInfo, error) {
 	var users []*model.User
-	err := ar.DB.
+	db := ar.DB.
 		Preload("Consultations", func(db *gorm.DB) *gorm.DB {
-			return db.Order("created_at DESC").Limit(1)
-		}).
-		Find(&users).Error
+			return db.Where("consultations.id IN (SELECT MAX(id) FROM consultations GROUP BY user_id)")
+		})
+	page, err := query.Paginate(db, opts, userListSpec, &users)
 	if err != nil {
-		return nil, err
+		return nil, model.PageInfo{}, err
 	}
-	return users, nil
+	return users, page, nil
 }
 
 // Find all doctors with their last consultation and recommendation
-func (ar *AdminManagementRepoImpl) FindAllDoctorsWithLastConsultation() ([]*model.Doctor, error) {
+func (ar *AdminManagementRepoImpl) FindAllDoctorsWithLastConsultation(opts model.QueryOptions) ([]*model.Doctor, model.PageInfo, error) {
 	var doctors []*model.Doctor
-	err := ar.DB.
+	db := ar.DB.
 		Preload("Consultations", func(db *gorm.DB) *gorm.DB {
-			return db.Order("created_at DESC").Limit(1)
+			return db.Where("consultations.id IN (SELECT MAX(id) FROM consultations GROUP BY doctor_id)")
 		}).
 		Preload("Recommendations", func(db *gorm.DB) *gorm.DB {
-			return db.Order("created_at DESC").Limit(1)
+			return db.Where("rekomendasis.id IN (SELECT MAX(id) FROM rekomendasis GROUP BY doctor_id)")
 		}).
 		Preload("Title").
-		Preload("Tags").
-		Find(&doctors).Error
+		Preload("Tags")
+	page, err := query.Paginate(db, opts, doctorListSpec, &doctors)
 	if err != nil {
-		return nil, err
+		return nil, model.PageInfo{}, err
 	}
-	return doctors, nil
+	return doctors, page, nil
 }
 
 // Delete user by ID
@@ -86,6 +105,9 @@ func (ar *AdminManagementRepoImpl) DeleteUser(id int) (*model.User, error) {
 	}
 
 	err = ar.DB.Delete(&user).Error
+	if isReferenced(err) {
+		return nil, ErrHasConsultations
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -102,9 +124,18 @@ func (ar *AdminManagementRepoImpl) DeleteDoctor(id int) (*model.Doctor, error) {
 	}
 
 	err = ar.DB.Delete(&doctor).Error
+	if isReferenced(err) {
+		return nil, ErrHasConsultations
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return &doctor, nil
 }
+
+// Error MySQL 1451: baris masih dirujuk foreign key dari tabel lain
+func isReferenced(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1451
+}
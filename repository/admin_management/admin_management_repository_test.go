@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsReferenced(t *testing.T) {
+	restricted := &mysql.MySQLError{Number: 1451, Message: "Cannot delete or update a parent row: a foreign key constraint fails"}
+
+	assert.True(t, isReferenced(restricted))
+	assert.True(t, isReferenced(fmt.Errorf("delete user: %w", restricted)))
+	assert.False(t, isReferenced(&mysql.MySQLError{Number: 1062}))
+	assert.False(t, isReferenced(errors.New("connection refused")))
+	assert.False(t, isReferenced(nil))
+}
@@ -2,17 +2,79 @@ package repository
 
 import (
 	"calmind/model"
+	"calmind/repository/query"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ArtikelRepository interface {
-	Create(artikel *model.Artikel) error
-	GetAll() ([]model.Artikel, error)
+	Create(artikel *model.Artikel, revision *model.ArtikelRevision) error
+	GetAll(filter ArtikelFilter, opts model.QueryOptions) ([]model.Artikel, model.PageInfo, error)
+	Search(filter ArtikelFilter, opts model.QueryOptions) ([]model.Artikel, model.PageInfo, error)
 	GetByID(id int) (*model.Artikel, error)
-	Update(artikel *model.Artikel) error
+	GetBySlug(slug string) (*model.Artikel, error)
+	SlugExists(slug string, excludeID int) (bool, error)
+	Update(artikel *model.Artikel, revision *model.ArtikelRevision) error
 	Delete(id int) error
-	SearchArtikel(query string) ([]model.Artikel, error)
+	GetRevisions(artikelID int, opts model.QueryOptions) ([]model.ArtikelRevision, model.PageInfo, error)
+	GetRevision(artikelID int, number int) (*model.ArtikelRevision, error)
+	FindTagsByName(names []string) ([]model.Tags, error)
+
+	GetCategories() ([]model.ArtikelCategory, error)
+	GetCategoryByID(id int) (*model.ArtikelCategory, error)
+	CreateCategory(category *model.ArtikelCategory) error
+	UpdateCategory(category *model.ArtikelCategory) error
+	DeleteCategory(id int) error
+}
+
+// Batasan tambahan daftar artikel, nilai kosong berarti tidak difilter
+type ArtikelFilter struct {
+	PublishedBy   *time.Time // Hanya artikel published yang waktu tayangnya sudah lewat
+	PublishedFrom *time.Time // Batas bawah published_at (inklusif)
+	PublishedTo   *time.Time // Batas atas published_at (eksklusif)
+	Keyword       string     // Dicocokkan dengan LIKE pada judul
+	Terms         []string   // Kata dasar untuk pencarian FULLTEXT, hanya dipakai Search
+	Tag           string     // Nama tag
+	CategorySlug  string
+}
+
+// Urutan dan filter daftar artikel, default terbaru di depan
+var artikelListSpec = query.Spec{
+	SortFields: map[string]string{
+		"id": "id", "judul": "judul", "created_at": "created_at", "updated_at": "updated_at",
+		"published_at": "published_at", "reading_minutes": "reading_minutes",
+	},
+	DefaultSort: "-created_at",
+	Filters:     map[string]string{"admin_id": "admin_id", "status": "status", "category_id": "category_id"},
+	Search:      []string{"judul", "isi"},
+	IDColumn:    "id",
+}
+
+// Riwayat revisi, default terbaru di depan
+var revisionListSpec = query.Spec{
+	SortFields:  map[string]string{"number": "number", "created_at": "created_at"},
+	DefaultSort: "-number",
+	Filters:     map[string]string{"admin_id": "admin_id"},
+	IDColumn:    "id",
+}
+
+// Sama dengan daftar artikel ditambah urutan "relevance" dari skor FULLTEXT
+var artikelSearchSpec = query.Spec{
+	SortFields:  withSortField(artikelListSpec.SortFields, "relevance", "relevance"),
+	DefaultSort: "-relevance",
+	Filters:     artikelListSpec.Filters,
+	IDColumn:    "id",
+}
+
+func withSortField(fields map[string]string, name, column string) map[string]string {
+	merged := map[string]string{name: column}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return merged
 }
 
 type artikelRepository struct {
@@ -23,40 +85,193 @@ func NewArtikelRepository(db *gorm.DB) ArtikelRepository {
 	return &artikelRepository{db: db}
 }
 
-func (r *artikelRepository) Create(artikel *model.Artikel) error {
-	return r.db.Create(artikel).Error
+// Tag yang sudah ada hanya direferensikan lewat artikel_tags, tidak ikut dibuat. Revisi pertama disimpan bersama artikel.
+func (r *artikelRepository) Create(artikel *model.Artikel, revision *model.ArtikelRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Category", "Tags.*").Create(artikel).Error; err != nil {
+			return err
+		}
+		revision.ArtikelID = artikel.ID
+		revision.Number = 1
+		return tx.Omit("Admin").Create(revision).Error
+	})
 }
 
-func (r *artikelRepository) GetAll() ([]model.Artikel, error) {
+func (r *artikelRepository) withRelations() *gorm.DB {
+	return r.db.Preload("Admin").Preload("Category").Preload("Tags")
+}
+
+// Artikel yang tampil untuk pembaca: published dan waktu tayangnya sudah lewat
+func publishedBy(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("status = ? AND (published_at IS NULL OR published_at <= ?)", model.ArtikelStatusPublished, now)
+}
+
+func (r *artikelRepository) GetAll(filter ArtikelFilter, opts model.QueryOptions) ([]model.Artikel, model.PageInfo, error) {
 	var artikels []model.Artikel
-	err := r.db.Preload("Admin").Find(&artikels).Error
-	return artikels, err
+	page, err := query.Paginate(r.filtered(r.withRelations(), filter), opts, artikelListSpec, &artikels)
+	return artikels, page, err
+}
+
+// Pencarian FULLTEXT boolean mode atas kolom kata dasar; kecocokan di judul berbobot dua kali lipat.
+// Skor dihitung di subquery agar bisa dipakai untuk urutan dan tetap bisa dihitung totalnya.
+func (r *artikelRepository) Search(filter ArtikelFilter, opts model.QueryOptions) ([]model.Artikel, model.PageInfo, error) {
+	words := make([]string, 0, len(filter.Terms))
+	for _, term := range filter.Terms {
+		words = append(words, term+"*")
+	}
+	against := strings.Join(words, " ")
+
+	scored := r.filtered(r.db.Model(&model.Artikel{}), filter).
+		Select("artikels.*, "+
+			"MATCH(search_judul) AGAINST (? IN BOOLEAN MODE) * 2 + "+
+			"MATCH(search_judul, search_isi) AGAINST (? IN BOOLEAN MODE) AS relevance", against, against).
+		Where("MATCH(search_judul, search_isi) AGAINST (? IN BOOLEAN MODE)", against)
+	db := r.db.Table("(?) AS artikels", scored).Preload("Admin").Preload("Category").Preload("Tags")
+
+	var artikels []model.Artikel
+	page, err := query.Paginate(db, opts, artikelSearchSpec, &artikels)
+	return artikels, page, err
+}
+
+func (r *artikelRepository) filtered(db *gorm.DB, filter ArtikelFilter) *gorm.DB {
+	if filter.PublishedBy != nil {
+		db = publishedBy(db, *filter.PublishedBy)
+	}
+	if filter.Keyword != "" {
+		db = db.Where("judul LIKE ?", "%"+query.EscapeLike(filter.Keyword)+"%")
+	}
+	if filter.Tag != "" {
+		db = db.Where("id IN (?)", r.db.Table("artikel_tags").Select("artikel_tags.artikel_id").
+			Joins("JOIN tags ON tags.id = artikel_tags.tags_id").Where("tags.name = ?", filter.Tag))
+	}
+	if filter.CategorySlug != "" {
+		db = db.Where("category_id IN (?)", r.db.Model(&model.ArtikelCategory{}).Select("id").Where("slug = ?", filter.CategorySlug))
+	}
+	if filter.PublishedFrom != nil {
+		db = db.Where("published_at >= ?", *filter.PublishedFrom)
+	}
+	if filter.PublishedTo != nil {
+		db = db.Where("published_at < ?", *filter.PublishedTo)
+	}
+	return db
 }
 
 func (r *artikelRepository) GetByID(id int) (*model.Artikel, error) {
 	var artikel model.Artikel
-	err := r.db.Preload("Admin").First(&artikel, id).Error
+	err := r.withRelations().First(&artikel, id).Error
 	return &artikel, err
 }
 
-func (r *artikelRepository) Update(artikel *model.Artikel) error {
-	return r.db.Model(&model.Artikel{}).Where("id = ?", artikel.ID).
-		Updates(map[string]interface{}{
-			"judul":      artikel.Judul,
-			"gambar":     artikel.Gambar,
-			"isi":        artikel.Isi,
-			"updated_at": artikel.UpdatedAt,
-		}).Error
+func (r *artikelRepository) GetBySlug(slug string) (*model.Artikel, error) {
+	var artikel model.Artikel
+	err := r.withRelations().Where("slug = ?", slug).First(&artikel).Error
+	return &artikel, err
+}
+
+func (r *artikelRepository) SlugExists(slug string, excludeID int) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Artikel{}).Where("slug = ? AND id <> ?", slug, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// Menyimpan seluruh field yang bisa diubah editor beserta daftar tag, lalu mencatatnya sebagai revisi berikutnya
+func (r *artikelRepository) Update(artikel *model.Artikel, revision *model.ArtikelRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Baris artikel dikunci agar dua penyimpanan bersamaan tidak mendapat nomor revisi yang sama
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model.Artikel{}, artikel.ID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&model.Artikel{}).Where("id = ?", artikel.ID).
+			Updates(map[string]interface{}{
+				"judul":            artikel.Judul,
+				"slug":             artikel.Slug,
+				"ringkasan":        artikel.Ringkasan,
+				"gambar":           artikel.Gambar,
+				"gambar_thumbnail": artikel.GambarThumbnail,
+				"isi":              artikel.Isi,
+				"status":           artikel.Status,
+				"published_at":     artikel.PublishedAt,
+				"category_id":      artikel.CategoryID,
+				"reading_minutes":  artikel.ReadingMinutes,
+				"search_judul":     artikel.SearchJudul,
+				"search_isi":       artikel.SearchIsi,
+				"updated_at":       artikel.UpdatedAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+		association := tx.Model(&model.Artikel{ID: artikel.ID}).Association("Tags")
+		if len(artikel.Tags) == 0 {
+			err = association.Clear()
+		} else {
+			err = association.Replace(artikel.Tags)
+		}
+		if err != nil {
+			return err
+		}
+
+		var last int
+		if err := tx.Model(&model.ArtikelRevision{}).Where("artikel_id = ?", artikel.ID).
+			Select("COALESCE(MAX(number), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		revision.ArtikelID = artikel.ID
+		revision.Number = last + 1
+		return tx.Omit("Admin").Create(revision).Error
+	})
 }
 
 func (r *artikelRepository) Delete(id int) error {
 	return r.db.Delete(&model.Artikel{}, id).Error
 }
 
-func (r *artikelRepository) SearchArtikel(query string) ([]model.Artikel, error) {
-	var artikel []model.Artikel
-	err := r.db.Preload("Admin").
-		Where("judul LIKE ?", "%"+query+"%").
-		Find(&artikel).Error
-	return artikel, err
+func (r *artikelRepository) GetRevisions(artikelID int, opts model.QueryOptions) ([]model.ArtikelRevision, model.PageInfo, error) {
+	var revisions []model.ArtikelRevision
+	db := r.db.Preload("Admin").Where("artikel_id = ?", artikelID)
+	page, err := query.Paginate(db, opts, revisionListSpec, &revisions)
+	return revisions, page, err
+}
+
+func (r *artikelRepository) GetRevision(artikelID int, number int) (*model.ArtikelRevision, error) {
+	var revision model.ArtikelRevision
+	err := r.db.Preload("Admin").Where("artikel_id = ? AND number = ?", artikelID, number).First(&revision).Error
+	return &revision, err
+}
+
+// Tag dengan nama yang cocok, nama yang tidak ada tidak ikut dikembalikan
+func (r *artikelRepository) FindTagsByName(names []string) ([]model.Tags, error) {
+	var tags []model.Tags
+	if len(names) == 0 {
+		return tags, nil
+	}
+	err := r.db.Where("name IN ?", names).Find(&tags).Error
+	return tags, err
+}
+
+func (r *artikelRepository) GetCategories() ([]model.ArtikelCategory, error) {
+	var categories []model.ArtikelCategory
+	err := r.db.Order("name").Find(&categories).Error
+	return categories, err
+}
+
+func (r *artikelRepository) GetCategoryByID(id int) (*model.ArtikelCategory, error) {
+	var category model.ArtikelCategory
+	if err := r.db.First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *artikelRepository) CreateCategory(category *model.ArtikelCategory) error {
+	return r.db.Create(category).Error
+}
+
+func (r *artikelRepository) UpdateCategory(category *model.ArtikelCategory) error {
+	return r.db.Model(category).Updates(map[string]interface{}{"name": category.Name, "slug": category.Slug}).Error
+}
+
+// Artikel dalam kategori ini tetap ada tanpa kategori (ON DELETE SET NULL)
+func (r *artikelRepository) DeleteCategory(id int) error {
+	return r.db.Delete(&model.ArtikelCategory{}, id).Error
 }
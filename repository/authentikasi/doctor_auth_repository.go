@@ -3,6 +3,7 @@ package repository
 import (
 	"calmind/model"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,6 +12,7 @@ type DoctorRepository interface {
 	GetByEmail(email string) (*model.Doctor, error)
 	CreateDoctor(*model.Doctor) error
 	UpdateDokterVerificationStatus(email string, isVerified bool) error
+	UpdatePassword(id int, hashedPassword string, revokedAt time.Time) error
 }
 
 type doctorRepositoryState struct {
@@ -51,3 +53,11 @@ func (r *doctorRepositoryState) GetByEmail(email string) (*model.Doctor, error)
 func (r *doctorRepositoryState) UpdateDokterVerificationStatus(email string, isVerified bool) error {
 	return r.DB.Model(&model.Doctor{}).Where("email = ?", email).Update("is_verified", isVerified).Error
 }
+
+// Mengganti password sekaligus mencabut semua sesi yang diterbitkan sebelum revokedAt
+func (r *doctorRepositoryState) UpdatePassword(id int, hashedPassword string, revokedAt time.Time) error {
+	return r.DB.Model(&model.Doctor{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"password":            hashedPassword,
+		"sessions_revoked_at": revokedAt,
+	}).Error
+}
@@ -13,6 +13,12 @@ type OtpRepository interface {
 	GetOtpByEmail(email string) (*model.Otp, error)
 	DeleteOtpByEmail(email string) error
 	ResendOtp(email string, code string, expiresAt time.Time) error // Tambahkan ini
+
+	// OTP dengan kegunaan selain verifikasi akun (misalnya reset password)
+	SaveOtp(email string, code string, purpose string, expiresAt time.Time) error
+	GetOtp(email string, purpose string) (*model.Otp, error)
+	DeleteOtp(email string, purpose string) error
+	IncrementOtpAttempts(id int) error
 }
 
 type OtpRepositoryImpl struct {
@@ -27,28 +33,24 @@ func (r *OtpRepositoryImpl) GenerateOtp(email string, code string, expiresAt tim
 	otp := &model.Otp{
 		Email:     email,
 		Code:      code,
+		Purpose:   model.OtpPurposeVerification,
 		ExpiresAt: expiresAt,
 	}
 	return r.DB.Create(otp).Error
 }
 
 func (r *OtpRepositoryImpl) GetOtpByEmail(email string) (*model.Otp, error) {
-	var otp model.Otp
-	err := r.DB.Where("email = ?", email).First(&otp).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, nil
-	}
-	return &otp, err
+	return r.GetOtp(email, model.OtpPurposeVerification)
 }
 
 func (r *OtpRepositoryImpl) DeleteOtpByEmail(email string) error {
-	return r.DB.Where("email = ?", email).Delete(&model.Otp{}).Error
+	return r.DeleteOtp(email, model.OtpPurposeVerification)
 }
 
 func (r *OtpRepositoryImpl) ResendOtp(email string, code string, expiresAt time.Time) error {
 	// Perbarui OTP jika sudah ada
 	err := r.DB.Model(&model.Otp{}).
-		Where("email = ?", email).
+		Where("email = ? AND purpose = ?", email, model.OtpPurposeVerification).
 		Updates(map[string]interface{}{
 			"code":       code,
 			"expires_at": expiresAt,
@@ -61,3 +63,36 @@ func (r *OtpRepositoryImpl) ResendOtp(email string, code string, expiresAt time.
 
 	return err
 }
+
+// Mengganti OTP lama dengan kegunaan yang sama sehingga hanya kode terbaru yang berlaku
+func (r *OtpRepositoryImpl) SaveOtp(email string, code string, purpose string, expiresAt time.Time) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("email = ? AND purpose = ?", email, purpose).Delete(&model.Otp{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.Otp{
+			Email:     email,
+			Code:      code,
+			Purpose:   purpose,
+			ExpiresAt: expiresAt,
+		}).Error
+	})
+}
+
+func (r *OtpRepositoryImpl) GetOtp(email string, purpose string) (*model.Otp, error) {
+	var otp model.Otp
+	err := r.DB.Where("email = ? AND purpose = ?", email, purpose).Order("id DESC").First(&otp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &otp, err
+}
+
+func (r *OtpRepositoryImpl) DeleteOtp(email string, purpose string) error {
+	return r.DB.Where("email = ? AND purpose = ?", email, purpose).Delete(&model.Otp{}).Error
+}
+
+func (r *OtpRepositoryImpl) IncrementOtpAttempts(id int) error {
+	return r.DB.Model(&model.Otp{}).Where("id = ?", id).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1")).Error
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"calmind/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Refresh token sudah pernah dirotasi atau dicabut, kemungkinan dicuri
+var ErrRefreshTokenReused = errors.New("refresh token sudah digunakan")
+
+// Menyimpan sesi login (refresh token) dan daftar access token yang dicabut
+type SessionRepository interface {
+	GetSessionsRevokedAt(role string, id int) (*time.Time, error)
+	IsAccessTokenRevoked(jti string) (bool, error)
+	RevokeAccessToken(token *model.RevokedToken) error
+
+	CreateRefreshToken(token *model.RefreshToken) error
+	GetRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error)
+	RotateRefreshToken(current *model.RefreshToken, next *model.RefreshToken, now time.Time) error
+	RevokeRefreshTokenFamily(familyID string, now time.Time) error
+	RevokeAllSessions(role string, id int, now time.Time) error
+	DeleteExpiredTokens(before time.Time) error
+}
+
+type sessionRepositoryState struct {
+	DB *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepositoryState{DB: db}
+}
+
+func accountModel(role string) interface{} {
+	switch role {
+	case "user":
+		return &model.User{}
+	case "doctor":
+		return &model.Doctor{}
+	case "admin":
+		return &model.Admin{}
+	}
+	return nil
+}
+
+// Mengembalikan nil jika sesi akun belum pernah dicabut
+func (r *sessionRepositoryState) GetSessionsRevokedAt(role string, id int) (*time.Time, error) {
+	table := accountModel(role)
+	if table == nil {
+		return nil, nil
+	}
+
+	var revokedAt []*time.Time
+	if err := r.DB.Model(table).Where("id = ?", id).Limit(1).Pluck("sessions_revoked_at", &revokedAt).Error; err != nil {
+		return nil, err
+	}
+	if len(revokedAt) == 0 {
+		return nil, nil
+	}
+	return revokedAt[0], nil
+}
+
+func (r *sessionRepositoryState) IsAccessTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.DB.Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+// Logout yang diulang untuk token yang sama tidak dianggap error
+func (r *sessionRepositoryState) RevokeAccessToken(token *model.RevokedToken) error {
+	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (r *sessionRepositoryState) CreateRefreshToken(token *model.RefreshToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *sessionRepositoryState) GetRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.DB.Where("token_hash = ?", tokenHash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &token, err
+}
+
+// Menandai token lama sudah dipakai lalu menyimpan penggantinya dalam satu transaksi.
+// Jika dua permintaan memakai token yang sama bersamaan, hanya satu yang berhasil.
+func (r *sessionRepositoryState) RotateRefreshToken(current *model.RefreshToken, next *model.RefreshToken, now time.Time) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", current.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+		return tx.Create(next).Error
+	})
+}
+
+func (r *sessionRepositoryState) RevokeRefreshTokenFamily(familyID string, now time.Time) error {
+	return r.DB.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error
+}
+
+// Mencabut semua refresh token akun dan menolak access token yang diterbitkan sebelum now
+func (r *sessionRepositoryState) RevokeAllSessions(role string, id int, now time.Time) error {
+	table := accountModel(role)
+	if table == nil {
+		return errors.New("role tidak dikenal")
+	}
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(table).Where("id = ?", id).Update("sessions_revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.RefreshToken{}).
+			Where("role = ? AND account_id = ? AND revoked_at IS NULL", role, id).
+			Update("revoked_at", now).Error
+	})
+}
+
+// Membersihkan token yang sudah kedaluwarsa karena tidak lagi perlu diperiksa
+func (r *sessionRepositoryState) DeleteExpiredTokens(before time.Time) error {
+	if err := r.DB.Where("expires_at < ?", before).Delete(&model.RevokedToken{}).Error; err != nil {
+		return err
+	}
+	return r.DB.Where("expires_at < ?", before).Delete(&model.RefreshToken{}).Error
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"calmind/model"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,6 +12,7 @@ type UserRepository interface {
 	GetByUsername(email string) (*model.User, error)
 	CreateUser(*model.User) error
 	UpdateUserVerificationStatus(email string, isVerified bool) error
+	UpdatePassword(id int, hashedPassword string, revokedAt time.Time) error
 }
 
 type userRepositorystate struct {
@@ -44,3 +46,11 @@ func (r *userRepositorystate) GetByUsername(email string) (*model.User, error) {
 func (r *userRepositorystate) UpdateUserVerificationStatus(email string, isVerified bool) error {
 	return r.DB.Model(&model.User{}).Where("email = ?", email).Update("is_verified", isVerified).Error
 }
+
+// Mengganti password sekaligus mencabut semua sesi yang diterbitkan sebelum revokedAt
+func (r *userRepositorystate) UpdatePassword(id int, hashedPassword string, revokedAt time.Time) error {
+	return r.DB.Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"password":            hashedPassword,
+		"sessions_revoked_at": revokedAt,
+	}).Error
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"calmind/model"
+
+	"gorm.io/gorm"
+)
+
+type ChatRepository interface {
+	GetConsultationByID(consultationID int) (*model.Consultation, error)
+	SaveMessage(message *model.ConsultationMessage) error
+	GetMessages(consultationID, beforeID, limit int) ([]model.ConsultationMessage, error)
+}
+
+type ChatRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewChatRepository(db *gorm.DB) ChatRepository {
+	return &ChatRepositoryImpl{DB: db}
+}
+
+func (r *ChatRepositoryImpl) GetConsultationByID(consultationID int) (*model.Consultation, error) {
+	var consultation model.Consultation
+	if err := r.DB.First(&consultation, consultationID).Error; err != nil {
+		return nil, err
+	}
+	return &consultation, nil
+}
+
+func (r *ChatRepositoryImpl) SaveMessage(message *model.ConsultationMessage) error {
+	return r.DB.Create(message).Error
+}
+
+// Mendapatkan pesan sebelum beforeID (0 = pesan terbaru), diurutkan dari yang paling lama
+func (r *ChatRepositoryImpl) GetMessages(consultationID, beforeID, limit int) ([]model.ConsultationMessage, error) {
+	var messages []model.ConsultationMessage
+	query := r.DB.Where("consultation_id = ?", consultationID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	if err := query.Order("id DESC").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
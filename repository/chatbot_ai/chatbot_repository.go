@@ -2,13 +2,38 @@ package repository
 
 import (
 	"calmind/model"
+	"calmind/repository/query"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// Urutan daftar sesi, default yang terakhir dipakai di depan
+var sessionListSpec = query.Spec{
+	SortFields: map[string]string{
+		"id":              "id",
+		"created_at":      "created_at",
+		"last_message_at": "COALESCE(last_message_at, created_at)",
+	},
+	DefaultSort: "-last_message_at",
+	Search:      []string{"title"},
+}
+
 type ChatLogRepository interface {
-	SaveLog(chatLog *model.ChatLog) error
-	GetLogsByUserID(userID int) ([]model.ChatLog, error)
+	SaveLog(session *model.ChatSession, chatLog *model.ChatLog) error
+	SaveFlaggedLog(session *model.ChatSession, chatLog *model.ChatLog, alert *model.SafetyAlert) error
+	GetLogsAfter(sessionID, afterID int) ([]model.ChatLog, error)
+	GetSessionLogs(sessionID, beforeID, limit int) ([]model.ChatLog, error)
+
+	CreateSession(session *model.ChatSession) error
+	GetSessionsByUserID(userID int, opts model.QueryOptions) ([]model.ChatSession, model.PageInfo, error)
+	GetSessionByID(userID, sessionID int) (*model.ChatSession, error)
+	UpdateSessionTitle(sessionID int, title string) error
+	UpdateSessionSummary(sessionID int, summary string, summarizedUntilID int) error
+	DeleteSession(sessionID int) error
+
+	CountOrphanLogs(userID int) (int64, error)
+	AssignOrphanLogs(userID, sessionID int) error
 }
 
 type ChatLogRepositoryImpl struct {
@@ -19,12 +44,117 @@ func NewChatLogRepository(db *gorm.DB) ChatLogRepository {
 	return &ChatLogRepositoryImpl{DB: db}
 }
 
-func (r *ChatLogRepositoryImpl) SaveLog(chatLog *model.ChatLog) error {
-	return r.DB.Create(chatLog).Error
+// Menyimpan log sekaligus memperbarui waktu pesan terakhir pada sesi
+func (r *ChatLogRepositoryImpl) SaveLog(session *model.ChatSession, chatLog *model.ChatLog) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := touchSession(tx, session); err != nil {
+			return err
+		}
+		chatLog.SessionID = session.ID
+		return tx.Create(chatLog).Error
+	})
+}
+
+// Menyimpan log berisiko beserta peringatan untuk admin dalam satu transaksi
+func (r *ChatLogRepositoryImpl) SaveFlaggedLog(session *model.ChatSession, chatLog *model.ChatLog, alert *model.SafetyAlert) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := touchSession(tx, session); err != nil {
+			return err
+		}
+		chatLog.SessionID = session.ID
+		if err := tx.Create(chatLog).Error; err != nil {
+			return err
+		}
+		alert.SessionID = session.ID
+		alert.ChatLogID = chatLog.ID
+		return tx.Create(alert).Error
+	})
+}
+
+// Sesi baru (ID 0) baru dibuat bersama log pertamanya, jadi pesan yang gagal dijawab tidak meninggalkan sesi kosong.
+// Sesi yang sudah ada cukup diperbarui waktu pesan terakhirnya.
+func touchSession(tx *gorm.DB, session *model.ChatSession) error {
+	now := time.Now()
+	if session.ID == 0 {
+		session.LastMessageAt = &now
+		return tx.Create(session).Error
+	}
+	return tx.Model(&model.ChatSession{}).Where("id = ?", session.ID).Update("last_message_at", now).Error
 }
 
-func (r *ChatLogRepositoryImpl) GetLogsByUserID(userID int) ([]model.ChatLog, error) {
+// Log sesi yang belum masuk ringkasan, urut dari yang terlama
+func (r *ChatLogRepositoryImpl) GetLogsAfter(sessionID, afterID int) ([]model.ChatLog, error) {
 	var logs []model.ChatLog
-	err := r.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&logs).Error
+	err := r.DB.Where("session_id = ? AND id > ?", sessionID, afterID).Order("id ASC").Find(&logs).Error
 	return logs, err
 }
+
+// Riwayat sesi per halaman, beforeID = 0 berarti mulai dari log terbaru. Hasil diurutkan dari yang terlama.
+func (r *ChatLogRepositoryImpl) GetSessionLogs(sessionID, beforeID, limit int) ([]model.ChatLog, error) {
+	query := r.DB.Where("session_id = ?", sessionID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var logs []model.ChatLog
+	if err := query.Order("id DESC").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+		logs[i], logs[j] = logs[j], logs[i]
+	}
+	return logs, nil
+}
+
+func (r *ChatLogRepositoryImpl) CreateSession(session *model.ChatSession) error {
+	return r.DB.Create(session).Error
+}
+
+func (r *ChatLogRepositoryImpl) GetSessionsByUserID(userID int, opts model.QueryOptions) ([]model.ChatSession, model.PageInfo, error) {
+	var sessions []model.ChatSession
+	page, err := query.Paginate(r.DB.Where("user_id = ?", userID), opts, sessionListSpec, &sessions)
+	return sessions, page, err
+}
+
+func (r *ChatLogRepositoryImpl) GetSessionByID(userID, sessionID int) (*model.ChatSession, error) {
+	var session model.ChatSession
+	err := r.DB.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *ChatLogRepositoryImpl) UpdateSessionTitle(sessionID int, title string) error {
+	return r.DB.Model(&model.ChatSession{}).Where("id = ?", sessionID).Update("title", title).Error
+}
+
+func (r *ChatLogRepositoryImpl) UpdateSessionSummary(sessionID int, summary string, summarizedUntilID int) error {
+	return r.DB.Model(&model.ChatSession{}).Where("id = ?", sessionID).Updates(map[string]interface{}{
+		"summary":             summary,
+		"summarized_until_id": summarizedUntilID,
+	}).Error
+}
+
+// Menghapus sesi beserta seluruh lognya
+func (r *ChatLogRepositoryImpl) DeleteSession(sessionID int) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", sessionID).Delete(&model.ChatLog{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.ChatSession{}, sessionID).Error
+	})
+}
+
+// Log lama yang dibuat sebelum fitur sesi ada
+func (r *ChatLogRepositoryImpl) CountOrphanLogs(userID int) (int64, error) {
+	var count int64
+	err := r.DB.Model(&model.ChatLog{}).Where("user_id = ? AND session_id = 0", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *ChatLogRepositoryImpl) AssignOrphanLogs(userID, sessionID int) error {
+	return r.DB.Model(&model.ChatLog{}).Where("user_id = ? AND session_id = 0", userID).
+		Update("session_id", sessionID).Error
+}
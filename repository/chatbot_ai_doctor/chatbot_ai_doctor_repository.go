@@ -9,6 +9,8 @@ import (
 type DoctorChatbotRepository interface {
 	GetLogsByDoctorID(doctorID int) ([]model.Chatbot, error)
 	SaveLog(log *model.Chatbot) error
+	GetConsultationForDoctor(consultationID, doctorID int) (*model.Consultation, error)
+	GetLogsByConsultation(doctorID, consultationID int) ([]model.Chatbot, error)
 }
 
 type DoctorChatbotRepositoryImpl struct {
@@ -19,10 +21,10 @@ func NewDoctorChatbotRepository(db *gorm.DB) DoctorChatbotRepository {
 	return &DoctorChatbotRepositoryImpl{DB: db}
 }
 
-// GetLogsByDoctorID mengambil semua log percakapan untuk dokter tertentu berdasarkan ID.
+// GetLogsByDoctorID mengambil log percakapan umum (di luar konsultasi) untuk dokter tertentu berdasarkan ID.
 func (repo *DoctorChatbotRepositoryImpl) GetLogsByDoctorID(doctorID int) ([]model.Chatbot, error) {
 	var logs []model.Chatbot
-	err := repo.DB.Where("user_id = ?", doctorID).Find(&logs).Error
+	err := repo.DB.Where("user_id = ? AND consultation_id = 0", doctorID).Find(&logs).Error
 	if err != nil {
 		return nil, err
 	}
@@ -33,3 +35,24 @@ func (repo *DoctorChatbotRepositoryImpl) GetLogsByDoctorID(doctorID int) ([]mode
 func (repo *DoctorChatbotRepositoryImpl) SaveLog(log *model.Chatbot) error {
 	return repo.DB.Create(log).Error
 }
+
+// GetConsultationForDoctor mengambil konsultasi milik dokter beserta data pasien dan rekomendasi sebelumnya.
+func (repo *DoctorChatbotRepositoryImpl) GetConsultationForDoctor(consultationID, doctorID int) (*model.Consultation, error) {
+	var consultation model.Consultation
+	err := repo.DB.Preload("User").
+		Preload("Rekomendasi", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Where("id = ? AND doctor_id = ?", consultationID, doctorID).
+		First(&consultation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &consultation, nil
+}
+
+// GetLogsByConsultation mengambil draf asisten sebelumnya untuk satu konsultasi.
+func (repo *DoctorChatbotRepositoryImpl) GetLogsByConsultation(doctorID, consultationID int) ([]model.Chatbot, error) {
+	var logs []model.Chatbot
+	err := repo.DB.Where("user_id = ? AND consultation_id = ?", doctorID, consultationID).
+		Order("id ASC").Find(&logs).Error
+	return logs, err
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"calmind/model"
+	"calmind/repository/query"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status kredensial sudah berubah sejak dibaca (misalnya diputuskan admin lain)
+var ErrCredentialStatusConflict = errors.New("status kredensial sudah berubah")
+
+type CredentialRepository interface {
+	GetDoctorByID(doctorID int) (*model.Doctor, error)
+	GetCredentials(doctorID int) ([]model.DoctorCredential, error)
+	GetCredentialByID(doctorID, credentialID int) (*model.DoctorCredential, error)
+	CreateCredential(credential *model.DoctorCredential) error
+	DeleteCredential(credential *model.DoctorCredential) error
+	UpdateCredentialStatus(doctorID int, fromStatuses []string, toStatus string) error
+
+	GetDoctorsByCredentialStatus(status string, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error)
+	SaveReview(doctorID int, review *model.DoctorCredentialReview, toStatus string, reviewedAt time.Time) error
+	GetReviews(doctorID int) ([]model.DoctorCredentialReview, error)
+}
+
+// Urutan dan filter antrian verifikasi kredensial
+var credentialQueueSpec = query.Spec{
+	SortFields:  map[string]string{"id": "id", "updated_at": "updated_at", "username": "username"},
+	DefaultSort: "updated_at",
+	Filters:     map[string]string{"title_id": "title_id"},
+	Search:      []string{"username", "email", "str_number"},
+}
+
+type CredentialRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewCredentialRepository(db *gorm.DB) CredentialRepository {
+	return &CredentialRepositoryImpl{DB: db}
+}
+
+func (r *CredentialRepositoryImpl) GetDoctorByID(doctorID int) (*model.Doctor, error) {
+	var doctor model.Doctor
+	err := r.DB.Preload("Title").Preload("Credentials").Where("id = ?", doctorID).First(&doctor).Error
+	if err != nil {
+		return nil, err
+	}
+	return &doctor, nil
+}
+
+func (r *CredentialRepositoryImpl) GetCredentials(doctorID int) ([]model.DoctorCredential, error) {
+	var credentials []model.DoctorCredential
+	err := r.DB.Where("doctor_id = ?", doctorID).Order("created_at ASC").Find(&credentials).Error
+	return credentials, err
+}
+
+func (r *CredentialRepositoryImpl) GetCredentialByID(doctorID, credentialID int) (*model.DoctorCredential, error) {
+	var credential model.DoctorCredential
+	err := r.DB.Where("id = ? AND doctor_id = ?", credentialID, doctorID).First(&credential).Error
+	if err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (r *CredentialRepositoryImpl) CreateCredential(credential *model.DoctorCredential) error {
+	return r.DB.Create(credential).Error
+}
+
+func (r *CredentialRepositoryImpl) DeleteCredential(credential *model.DoctorCredential) error {
+	return r.DB.Delete(credential).Error
+}
+
+// Mengubah status kredensial hanya jika status saat ini termasuk fromStatuses
+func (r *CredentialRepositoryImpl) UpdateCredentialStatus(doctorID int, fromStatuses []string, toStatus string) error {
+	result := r.DB.Model(&model.Doctor{}).
+		Where("id = ? AND credential_status IN ?", doctorID, fromStatuses).
+		Update("credential_status", toStatus)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCredentialStatusConflict
+	}
+	return nil
+}
+
+// Antrian review admin, default pengajuan terlama di depan
+func (r *CredentialRepositoryImpl) GetDoctorsByCredentialStatus(status string, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
+	var doctors []model.Doctor
+	db := r.DB.Preload("Title").Preload("Credentials").
+		Where("credential_status = ?", status)
+	page, err := query.Paginate(db, opts, credentialQueueSpec, &doctors)
+	return doctors, page, err
+}
+
+// Menyimpan keputusan admin. Hanya pengajuan yang masih pending yang bisa diputuskan.
+func (r *CredentialRepositoryImpl) SaveReview(doctorID int, review *model.DoctorCredentialReview, toStatus string, reviewedAt time.Time) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Doctor{}).
+			Where("id = ? AND credential_status = ?", doctorID, model.CredentialStatusPending).
+			Updates(map[string]interface{}{
+				"credential_status":      toStatus,
+				"credential_note":        review.Reason,
+				"credential_reviewed_at": reviewedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCredentialStatusConflict
+		}
+		return tx.Create(review).Error
+	})
+}
+
+func (r *CredentialRepositoryImpl) GetReviews(doctorID int) ([]model.DoctorCredentialReview, error) {
+	var reviews []model.DoctorCredentialReview
+	err := r.DB.Where("doctor_id = ?", doctorID).Order("created_at DESC").Find(&reviews).Error
+	return reviews, err
+}
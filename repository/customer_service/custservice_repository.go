@@ -2,14 +2,42 @@ package repository
 
 import (
 	"calmind/model"
+	"calmind/repository/query"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// Filter daftar tiket untuk admin, nilai kosong berarti tidak difilter
+type TicketFilter struct {
+	Status          string
+	Priority        string
+	AssignedAdminID int  // > 0 berarti hanya tiket milik admin tersebut
+	Unassigned      bool // Hanya tiket yang belum ditugaskan
+}
+
+// Urutan daftar tiket. "priority" mengurutkan dari yang paling mendesak.
+var ticketListSpec = query.Spec{
+	SortFields: map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+		"priority":   "FIELD(priority, 'urgent', 'high', 'normal', 'low')",
+	},
+	DefaultSort: "-updated_at",
+	Filters:     map[string]string{"status": "status", "priority": "priority"},
+	Search:      []string{"subject", "message"},
+}
+
 type CustServiceRepository interface {
 	SaveCustService(custService *model.CustService) error
 	AnswerMessage(id int, answer string) error
+	GetTicketByID(id int) (*model.CustService, error)
+	GetTicketsByUserID(userID int, opts model.QueryOptions) ([]model.CustService, model.PageInfo, error)
+	GetTickets(filter TicketFilter, opts model.QueryOptions) ([]model.CustService, model.PageInfo, error)
+	UpdateTicket(ticket *model.CustService) error
+	SaveReply(reply *model.CustServiceReply, status string) error
+	AdminExists(adminID int) (bool, error)
 }
 
 type CustServiceRepositoryImpl struct {
@@ -28,6 +56,81 @@ func (r *CustServiceRepositoryImpl) AnswerMessage(id int, answer string) error {
 	return r.DB.Model(&model.CustService{}).Where("id = ?", id).Updates(map[string]interface{}{
 		"answer":      answer,
 		"is_answered": true,
+		"status":      model.TicketStatusAnswered,
 		"answered_at": time.Now(),
 	}).Error
 }
+
+// Detail tiket beserta pemilik dan seluruh balasan, urut dari yang terlama
+func (r *CustServiceRepositoryImpl) GetTicketByID(id int) (*model.CustService, error) {
+	var ticket model.CustService
+	err := r.DB.Preload("User").
+		Preload("Replies", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&ticket, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// Tiket user, default yang terakhir diperbarui di depan
+func (r *CustServiceRepositoryImpl) GetTicketsByUserID(userID int, opts model.QueryOptions) ([]model.CustService, model.PageInfo, error) {
+	var tickets []model.CustService
+	page, err := query.Paginate(r.DB.Where("user_id = ?", userID), opts, ticketListSpec, &tickets)
+	return tickets, page, err
+}
+
+// Inbox admin, default tiket paling mendesak di depan lalu yang paling lama masuk
+func (r *CustServiceRepositoryImpl) GetTickets(filter TicketFilter, opts model.QueryOptions) ([]model.CustService, model.PageInfo, error) {
+	db := r.DB.Preload("User")
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.Priority != "" {
+		db = db.Where("priority = ?", filter.Priority)
+	}
+	if filter.AssignedAdminID > 0 {
+		db = db.Where("assigned_admin_id = ?", filter.AssignedAdminID)
+	} else if filter.Unassigned {
+		db = db.Where("assigned_admin_id IS NULL")
+	}
+
+	if opts.Sort == "" {
+		opts.Sort = "priority"
+	}
+
+	var tickets []model.CustService
+	page, err := query.Paginate(db, opts, ticketListSpec, &tickets)
+	return tickets, page, err
+}
+
+func (r *CustServiceRepositoryImpl) UpdateTicket(ticket *model.CustService) error {
+	return r.DB.Model(&model.CustService{}).Where("id = ?", ticket.ID).Updates(map[string]interface{}{
+		"status":            ticket.Status,
+		"priority":          ticket.Priority,
+		"assigned_admin_id": ticket.AssignedAdminID,
+	}).Error
+}
+
+// Menyimpan balasan sekaligus memperbarui status tiket. Balasan admin juga menjadi jawaban terakhir tiket.
+func (r *CustServiceRepositoryImpl) SaveReply(reply *model.CustServiceReply, status string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(reply).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"status": status}
+		if reply.AuthorRole == model.TicketAuthorAdmin {
+			updates["answer"] = reply.Message
+			updates["is_answered"] = true
+			updates["answered_at"] = time.Now()
+		}
+		return tx.Model(&model.CustService{}).Where("id = ?", reply.TicketID).Updates(updates).Error
+	})
+}
+
+func (r *CustServiceRepositoryImpl) AdminExists(adminID int) (bool, error) {
+	var count int64
+	err := r.DB.Model(&model.Admin{}).Where("id = ?", adminID).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"calmind/model"
+
+	"gorm.io/gorm"
+)
+
+type FaqRepository interface {
+	GetCategories() ([]model.FaqCategory, error)
+	GetCategoryByID(id int) (*model.FaqCategory, error)
+	CreateCategory(category *model.FaqCategory) error
+	UpdateCategory(category *model.FaqCategory) error
+	DeleteCategory(id int) error
+	CountFaqsInCategory(categoryID int) (int64, error)
+	GetFaqs() ([]model.Faq, error)
+	GetFaqByID(id int) (*model.Faq, error)
+	CreateFaq(faq *model.Faq) error
+	UpdateFaq(faq *model.Faq) error
+	DeleteFaq(id int) error
+}
+
+type FaqRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewFaqRepository(db *gorm.DB) FaqRepository {
+	return &FaqRepositoryImpl{DB: db}
+}
+
+// Kategori beserta FAQ di dalamnya, keduanya diurutkan berdasarkan position
+func (r *FaqRepositoryImpl) GetCategories() ([]model.FaqCategory, error) {
+	var categories []model.FaqCategory
+	err := r.DB.Preload("Faqs", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC, id ASC") }).
+		Order("position ASC, id ASC").Find(&categories).Error
+	return categories, err
+}
+
+func (r *FaqRepositoryImpl) GetCategoryByID(id int) (*model.FaqCategory, error) {
+	var category model.FaqCategory
+	if err := r.DB.First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *FaqRepositoryImpl) CreateCategory(category *model.FaqCategory) error {
+	return r.DB.Create(category).Error
+}
+
+func (r *FaqRepositoryImpl) UpdateCategory(category *model.FaqCategory) error {
+	return r.DB.Model(&model.FaqCategory{}).Where("id = ?", category.ID).Updates(map[string]interface{}{
+		"name":     category.Name,
+		"position": category.Position,
+	}).Error
+}
+
+func (r *FaqRepositoryImpl) DeleteCategory(id int) error {
+	return r.DB.Delete(&model.FaqCategory{}, id).Error
+}
+
+func (r *FaqRepositoryImpl) CountFaqsInCategory(categoryID int) (int64, error) {
+	var count int64
+	err := r.DB.Model(&model.Faq{}).Where("category_id = ?", categoryID).Count(&count).Error
+	return count, err
+}
+
+// Semua FAQ mengikuti urutan kategori lalu urutan di dalam kategori
+func (r *FaqRepositoryImpl) GetFaqs() ([]model.Faq, error) {
+	var faqs []model.Faq
+	err := r.DB.Joins("JOIN faq_categories ON faq_categories.id = faqs.category_id").
+		Order("faq_categories.position ASC, faqs.position ASC, faqs.id ASC").Find(&faqs).Error
+	return faqs, err
+}
+
+func (r *FaqRepositoryImpl) GetFaqByID(id int) (*model.Faq, error) {
+	var faq model.Faq
+	if err := r.DB.First(&faq, id).Error; err != nil {
+		return nil, err
+	}
+	return &faq, nil
+}
+
+func (r *FaqRepositoryImpl) CreateFaq(faq *model.Faq) error {
+	return r.DB.Create(faq).Error
+}
+
+func (r *FaqRepositoryImpl) UpdateFaq(faq *model.Faq) error {
+	return r.DB.Model(&model.Faq{}).Where("id = ?", faq.ID).Updates(map[string]interface{}{
+		"category_id": faq.CategoryID,
+		"question":    faq.Question,
+		"answer":      faq.Answer,
+		"keywords":    faq.Keywords,
+		"position":    faq.Position,
+	}).Error
+}
+
+func (r *FaqRepositoryImpl) DeleteFaq(id int) error {
+	return r.DB.Delete(&model.Faq{}, id).Error
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"calmind/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type JadwalRepository interface {
+	GetSchedulesByDoctor(doctorID int) ([]model.DoctorSchedule, error)
+	GetScheduleByID(doctorID, id int) (*model.DoctorSchedule, error)
+	CreateSchedule(schedule *model.DoctorSchedule) error
+	UpdateSchedule(schedule *model.DoctorSchedule) error
+	DeleteSchedule(doctorID, id int) error
+	GetExceptionsByDoctor(doctorID int, fromDate, toDate string) ([]model.DoctorScheduleException, error)
+	CreateException(exception *model.DoctorScheduleException) error
+	DeleteException(doctorID, id int) error
+	GetBookedConsultations(doctorID int, from, to time.Time) ([]model.Consultation, error)
+}
+
+type JadwalRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewJadwalRepository(db *gorm.DB) JadwalRepository {
+	return &JadwalRepositoryImpl{DB: db}
+}
+
+// Mendapatkan semua jadwal mingguan dokter
+func (r *JadwalRepositoryImpl) GetSchedulesByDoctor(doctorID int) ([]model.DoctorSchedule, error) {
+	var schedules []model.DoctorSchedule
+	err := r.DB.Where("doctor_id = ?", doctorID).
+		Order("day_of_week ASC, start_time ASC").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// Mendapatkan jadwal mingguan berdasarkan ID milik dokter tertentu
+func (r *JadwalRepositoryImpl) GetScheduleByID(doctorID, id int) (*model.DoctorSchedule, error) {
+	var schedule model.DoctorSchedule
+	err := r.DB.Where("id = ? AND doctor_id = ?", id, doctorID).First(&schedule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("schedule with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to fetch schedule: %v", err)
+	}
+	return &schedule, nil
+}
+
+func (r *JadwalRepositoryImpl) CreateSchedule(schedule *model.DoctorSchedule) error {
+	return r.DB.Create(schedule).Error
+}
+
+func (r *JadwalRepositoryImpl) UpdateSchedule(schedule *model.DoctorSchedule) error {
+	return r.DB.Save(schedule).Error
+}
+
+func (r *JadwalRepositoryImpl) DeleteSchedule(doctorID, id int) error {
+	result := r.DB.Where("id = ? AND doctor_id = ?", id, doctorID).Delete(&model.DoctorSchedule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("schedule with ID %d not found", id)
+	}
+	return nil
+}
+
+// Mendapatkan pengecualian jadwal dokter dalam rentang tanggal (inklusif)
+func (r *JadwalRepositoryImpl) GetExceptionsByDoctor(doctorID int, fromDate, toDate string) ([]model.DoctorScheduleException, error) {
+	var exceptions []model.DoctorScheduleException
+	err := r.DB.Where("doctor_id = ? AND date BETWEEN ? AND ?", doctorID, fromDate, toDate).
+		Order("date ASC, start_time ASC").
+		Find(&exceptions).Error
+	return exceptions, err
+}
+
+func (r *JadwalRepositoryImpl) CreateException(exception *model.DoctorScheduleException) error {
+	return r.DB.Create(exception).Error
+}
+
+func (r *JadwalRepositoryImpl) DeleteException(doctorID, id int) error {
+	result := r.DB.Where("id = ? AND doctor_id = ?", id, doctorID).Delete(&model.DoctorScheduleException{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("schedule exception with ID %d not found", id)
+	}
+	return nil
+}
+
+// Mendapatkan konsultasi yang masih menempati slot dokter dalam rentang waktu
+func (r *JadwalRepositoryImpl) GetBookedConsultations(doctorID int, from, to time.Time) ([]model.Consultation, error) {
+	var consultations []model.Consultation
+	err := r.DB.
+		Where("doctor_id = ? AND start_time < ? AND status IN ? AND (payment_status IS NULL OR payment_status != ?)",
+			doctorID, to, model.BookedConsultationStatuses, "failed").
+		Where("DATE_ADD(start_time, INTERVAL duration MINUTE) > ?", from).
+		Find(&consultations).Error
+	return consultations, err
+}
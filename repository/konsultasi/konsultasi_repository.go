@@ -2,31 +2,62 @@ package repository
 
 import (
 	"calmind/model"
+	"calmind/repository/query"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrSlotAlreadyBooked = errors.New("slot dokter sudah dipesan")
+
 type ConsultationRepository interface {
 	CreateConsultation(*model.Consultation) (int, error)
 	GetConsultationsForDoctor(doctorID int) ([]model.Consultation, error)
-	FindConsultationsByDoctorAndName(doctorID int, searchName string, consultations *[]model.Consultation) error
+	FindConsultationsByDoctorAndName(doctorID int, searchName string, opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error)
 	GetConsultationDetails(consultationID, doctorID int) (*model.Consultation, error)
 	AddRecommendation(recommendation *model.Rekomendasi) error
 	GetConsultationByID(consultationID int) (*model.Consultation, error)
 	UpdateConsultation(consultation *model.Consultation) error
-	GetConsultationsWithDoctors(userID int) ([]model.Consultation, error)
-	GetPendingConsultations() ([]model.Consultation, error)
+	GetConsultationsWithDoctors(userID int, opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error)
+	GetPendingConsultations(opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error)
 	GetDoctorByID(doctorID int) (*model.Doctor, error)
 	GetActiveConsultations() ([]model.Consultation, error)
-	GetAllStatusConsultations() ([]model.Consultation, error)
-	GetApprovedConsultations() ([]model.Consultation, error)
+	GetAllStatusConsultations(opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error)
+	GetApprovedConsultations(opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error)
 	ValidateUserAndDoctor(userID, doctorID int) error
 	GetConsultationByOrderID(orderID string) (*model.Consultation, error)
 	GetValidConsultations(userID, doctorID int) ([]model.Consultation, error)
-	GetAllConsultationsForDoctor(doctorID int) ([]model.Consultation, error)
+	GetAllConsultationsForDoctor(doctorID int, opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error)
+	GetConsultationWithDoctorByOrderID(orderID string) (*model.Consultation, error)
+	LogPaymentEvent(event *model.PaymentEvent) error
+	ApplyPaymentEvent(event *model.PaymentEvent, consultation *model.Consultation, fromStatus string, histories []model.ConsultationStatusHistory) (bool, error)
+	SaveStatusTransition(consultation *model.Consultation, fromStatus string, histories []model.ConsultationStatusHistory) error
+	GetStatusHistory(consultationID int) ([]model.ConsultationStatusHistory, error)
+	GetConsultationsByStatus(statuses []string) ([]model.Consultation, error)
+}
+
+// Urutan dan filter daftar konsultasi. Kolom diberi nama tabel karena pencarian nama pasien memakai join ke users.
+var consultationListSpec = query.Spec{
+	SortFields: map[string]string{
+		"id":         "consultations.id",
+		"start_time": "consultations.start_time",
+		"created_at": "consultations.created_at",
+		"updated_at": "consultations.updated_at",
+		"status":     "consultations.status",
+	},
+	DefaultSort: "-start_time",
+	Filters: map[string]string{
+		"status":         "consultations.status",
+		"payment_status": "consultations.payment_status",
+		"user_id":        "consultations.user_id",
+		"doctor_id":      "consultations.doctor_id",
+	},
+	Search:   []string{"consultations.title", "consultations.description"},
+	IDColumn: "consultations.id",
 }
 
 type ConsultationRepositoryImpl struct {
@@ -50,8 +81,45 @@ func (r *ConsultationRepositoryImpl) GetValidConsultations(userID, doctorID int)
 }
 
 func (r *ConsultationRepositoryImpl) CreateConsultation(consultation *model.Consultation) (int, error) {
-	// Simpan konsultasi
-	if err := r.DB.Create(consultation).Error; err != nil {
+	endTime := consultation.StartTime.Add(time.Duration(consultation.Duration) * time.Minute)
+
+	// Simpan konsultasi dalam transaksi agar slot yang sama tidak bisa dipesan dua kali
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci baris dokter supaya pemesanan paralel pada dokter yang sama berjalan berurutan
+		var doctor model.Doctor
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&doctor, consultation.DoctorID).Error; err != nil {
+			return fmt.Errorf("doctor not found with ID %d", consultation.DoctorID)
+		}
+
+		var overlapping int64
+		if err := tx.Model(&model.Consultation{}).
+			Where("doctor_id = ? AND status IN ? AND (payment_status IS NULL OR payment_status != ?)",
+				consultation.DoctorID, model.BookedConsultationStatuses, "failed").
+			Where("start_time < ? AND DATE_ADD(start_time, INTERVAL duration MINUTE) > ?", endTime, consultation.StartTime).
+			Count(&overlapping).Error; err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return ErrSlotAlreadyBooked
+		}
+
+		if err := tx.Create(consultation).Error; err != nil {
+			return err
+		}
+
+		// Riwayat awal konsultasi
+		return tx.Create(&model.ConsultationStatusHistory{
+			ConsultationID: consultation.ID,
+			ToStatus:       consultation.Status,
+			ActorRole:      "user",
+			ActorID:        consultation.UserID,
+			Note:           "Konsultasi dibuat",
+		}).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrSlotAlreadyBooked) {
+			return 0, err
+		}
 		return 0, fmt.Errorf("failed to create consultation: %w", err)
 	}
 
@@ -80,20 +148,20 @@ func (r *ConsultationRepositoryImpl) ValidateUserAndDoctor(userID, doctorID int)
 }
 
 // Mendapatkan daftar konsultasi untuk dokter
-func (r *ConsultationRepositoryImpl) GetAllConsultationsForDoctor(doctorID int) ([]model.Consultation, error) {
+func (r *ConsultationRepositoryImpl) GetAllConsultationsForDoctor(doctorID int, opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error) {
 	var consultations []model.Consultation
-	if err := r.DB.Preload("User").Preload("Doctor").Preload("Rekomendasi").
-		Where("doctor_id = ? AND status IN ?", doctorID, []string{"paid", "approved", "expired"}).
-		Find(&consultations).Error; err != nil {
-		return nil, err
+	db := r.DB.Preload("User").Preload("Doctor").Preload("Rekomendasi").
+		Where("doctor_id = ? AND status IN ?", doctorID, []string{"paid", "approved", "in_session", "completed", "expired"})
+	page, err := query.Paginate(db, opts, consultationListSpec, &consultations)
+	if err != nil {
+		return nil, model.PageInfo{}, err
 	}
-	fmt.Printf("Consultations found: %+v\n", consultations) // Logging untuk debug
-	return consultations, nil
+	return consultations, page, nil
 }
 func (r *ConsultationRepositoryImpl) GetConsultationsForDoctor(doctorID int) ([]model.Consultation, error) {
 	var consultations []model.Consultation
 	if err := r.DB.Preload("User").Preload("Doctor").Preload("Rekomendasi").
-		Where("doctor_id = ? AND status IN ?", doctorID, []string{"paid", "approved"}).
+		Where("doctor_id = ? AND status IN ?", doctorID, []string{"paid", "approved", "in_session"}).
 		Find(&consultations).Error; err != nil {
 		return nil, err
 	}
@@ -102,21 +170,23 @@ func (r *ConsultationRepositoryImpl) GetConsultationsForDoctor(doctorID int) ([]
 }
 
 // Mendapatkan konsultasi berdasarkan doctorID dan nama user
-func (r *ConsultationRepositoryImpl) FindConsultationsByDoctorAndName(doctorID int, searchName string, consultations *[]model.Consultation) error {
-	query := r.DB.Preload("User").Preload("Doctor").
-		Where("doctor_id = ?", doctorID)
+func (r *ConsultationRepositoryImpl) FindConsultationsByDoctorAndName(doctorID int, searchName string, opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error) {
+	db := r.DB.Preload("User").Preload("Doctor").
+		Where("consultations.doctor_id = ?", doctorID)
 
 	// Gunakan sub-query atau join untuk mencocokkan nama user
 	if searchName != "" {
-		query = query.Joins("JOIN users ON users.id = consultations.user_id").
+		db = db.Joins("JOIN users ON users.id = consultations.user_id").
 			Where("users.username LIKE ?", "%"+searchName+"%")
 	}
 
-	err := query.Find(consultations).Error
+	var consultations []model.Consultation
+	page, err := query.Paginate(db, opts, consultationListSpec, &consultations)
 	if err != nil {
 		log.Printf("Query error in FindConsultationsByDoctorAndName: %v", err) // Logging untuk debug
+		return nil, model.PageInfo{}, err
 	}
-	return err
+	return consultations, page, nil
 }
 
 // Mendapatkan detail konsultasi tertentu untuk dokter
@@ -135,10 +205,10 @@ func (r *ConsultationRepositoryImpl) AddRecommendation(recommendation *model.Rek
 	return r.DB.Create(recommendation).Error
 }
 
-// Mendapatkan konsultasi berdasarkan ID
+// Mendapatkan konsultasi yang sudah dimulai (approved atau in_session)
 func (r *ConsultationRepositoryImpl) GetActiveConsultations() ([]model.Consultation, error) {
 	var consultations []model.Consultation
-	err := r.DB.Where("status = ? AND start_time <= ?", "approved", time.Now()).
+	err := r.DB.Where("status IN ? AND start_time <= ?", []string{"approved", "in_session"}, time.Now()).
 		Find(&consultations).Error
 	if err != nil {
 		return nil, err
@@ -146,6 +216,15 @@ func (r *ConsultationRepositoryImpl) GetActiveConsultations() ([]model.Consultat
 	return consultations, nil
 }
 
+// Mendapatkan konsultasi berdasarkan daftar status
+func (r *ConsultationRepositoryImpl) GetConsultationsByStatus(statuses []string) ([]model.Consultation, error) {
+	var consultations []model.Consultation
+	if err := r.DB.Where("status IN ?", statuses).Find(&consultations).Error; err != nil {
+		return nil, err
+	}
+	return consultations, nil
+}
+
 func (r *ConsultationRepositoryImpl) UpdateConsultation(consultation *model.Consultation) error {
 	return r.DB.Save(consultation).Error
 }
@@ -160,46 +239,50 @@ func (r *ConsultationRepositoryImpl) GetConsultationByID(consultationID int) (*m
 }
 
 // Mendapatkan daftar konsultasi untuk user tertentu
-func (r *ConsultationRepositoryImpl) GetConsultationsWithDoctors(userID int) ([]model.Consultation, error) {
+func (r *ConsultationRepositoryImpl) GetConsultationsWithDoctors(userID int, opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error) {
 	var consultations []model.Consultation
-	if err := r.DB.
+	db := r.DB.
 		Preload("Doctor.Title"). // Preload relasi Doctor
 		Preload("Rekomendasi").  // Preload rekomendasi
 		Preload("User").         // Preload relasi User
-		Where("user_id = ?", userID).
-		Find(&consultations).Error; err != nil {
-		return nil, err
+		Where("user_id = ?", userID)
+	page, err := query.Paginate(db, opts, consultationListSpec, &consultations)
+	if err != nil {
+		return nil, model.PageInfo{}, err
 	}
-	return consultations, nil
+	return consultations, page, nil
 }
 
-// Mendapatkan konsultasi yang menunggu persetujuan admin
-func (r *ConsultationRepositoryImpl) GetPendingConsultations() ([]model.Consultation, error) {
+// Mendapatkan konsultasi yang menunggu pembayaran atau persetujuan admin
+func (r *ConsultationRepositoryImpl) GetPendingConsultations(opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error) {
 	var consultations []model.Consultation
-	if err := r.DB.Preload("User").Preload("Doctor.Title").
-		Where("status = ?", "pending").
-		Find(&consultations).Error; err != nil {
-		return nil, err
+	db := r.DB.Preload("User").Preload("Doctor.Title").
+		Where("status IN ?", []string{"pending_payment", "paid"})
+	page, err := query.Paginate(db, opts, consultationListSpec, &consultations)
+	if err != nil {
+		return nil, model.PageInfo{}, err
 	}
-	return consultations, nil
+	return consultations, page, nil
 }
-func (r *ConsultationRepositoryImpl) GetApprovedConsultations() ([]model.Consultation, error) {
+func (r *ConsultationRepositoryImpl) GetApprovedConsultations(opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error) {
 	var consultations []model.Consultation
-	if err := r.DB.Preload("User").Preload("Doctor").
-		Where("status = ?", "approved").
-		Find(&consultations).Error; err != nil {
-		return nil, err
+	db := r.DB.Preload("User").Preload("Doctor").
+		Where("status = ?", "approved")
+	page, err := query.Paginate(db, opts, consultationListSpec, &consultations)
+	if err != nil {
+		return nil, model.PageInfo{}, err
 	}
-	return consultations, nil
+	return consultations, page, nil
 }
-func (r *ConsultationRepositoryImpl) GetAllStatusConsultations() ([]model.Consultation, error) {
+func (r *ConsultationRepositoryImpl) GetAllStatusConsultations(opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error) {
 	var consultations []model.Consultation
-	if err := r.DB.Preload("User").Preload("Doctor").
-		Where("status IN ?", []string{"approved", "pending"}). // Menampilkan status approved dan pending
-		Find(&consultations).Error; err != nil {
-		return nil, err
+	db := r.DB.Preload("User").Preload("Doctor").
+		Where("status IN ?", []string{"pending_payment", "paid", "approved", "in_session"}) // Menampilkan konsultasi yang masih berjalan
+	page, err := query.Paginate(db, opts, consultationListSpec, &consultations)
+	if err != nil {
+		return nil, model.PageInfo{}, err
 	}
-	return consultations, nil
+	return consultations, page, nil
 }
 
 func (r *ConsultationRepositoryImpl) GetConsultationByOrderID(orderID string) (*model.Consultation, error) {
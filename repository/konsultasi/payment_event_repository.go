@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"calmind/model"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Mencatat notifikasi yang ditolak (signature/nominal tidak valid) tanpa kunci dedup
+func (r *ConsultationRepositoryImpl) LogPaymentEvent(event *model.PaymentEvent) error {
+	event.DedupKey = nil
+	return r.DB.Create(event).Error
+}
+
+// Menyimpan notifikasi valid dan memperbarui status pembayaran dalam satu transaksi.
+// Mengembalikan duplicate = true jika notifikasi dengan kunci yang sama sudah pernah diproses.
+// Jika histories kosong, hanya payment_status yang diperbarui.
+func (r *ConsultationRepositoryImpl) ApplyPaymentEvent(event *model.PaymentEvent, consultation *model.Consultation, fromStatus string, histories []model.ConsultationStatusHistory) (bool, error) {
+	duplicate := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var existing model.PaymentEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("dedup_key = ?", event.DedupKey).
+			First(&existing).Error
+		if err == nil {
+			duplicate = true
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := tx.Create(event).Error; err != nil {
+			// Notifikasi yang sama datang bersamaan dan sudah disimpan oleh request lain
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+				duplicate = true
+				return nil
+			}
+			return err
+		}
+
+		if len(histories) > 0 {
+			return applyStatusTransition(tx, consultation, fromStatus, histories)
+		}
+
+		// Status paid tidak boleh diturunkan kembali menjadi pending atau failed
+		return tx.Model(&model.Consultation{}).
+			Where("id = ? AND (payment_status IS NULL OR payment_status != ?)", consultation.ID, "paid").
+			Update("payment_status", consultation.PaymentStatus).Error
+	})
+	return duplicate, err
+}
+
+// Mendapatkan konsultasi beserta data dokter berdasarkan order_id
+func (r *ConsultationRepositoryImpl) GetConsultationWithDoctorByOrderID(orderID string) (*model.Consultation, error) {
+	var consultation model.Consultation
+	if err := r.DB.Preload("Doctor").Where("order_id = ?", orderID).First(&consultation).Error; err != nil {
+		return nil, err
+	}
+	return &consultation, nil
+}
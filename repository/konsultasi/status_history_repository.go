@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"calmind/model"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Status konsultasi sudah diubah oleh proses lain sejak dibaca
+var ErrStatusConflict = errors.New("status konsultasi sudah berubah, silakan coba lagi")
+
+// Menyimpan perubahan status konsultasi beserta riwayatnya dalam satu transaksi
+func (r *ConsultationRepositoryImpl) SaveStatusTransition(consultation *model.Consultation, fromStatus string, histories []model.ConsultationStatusHistory) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return applyStatusTransition(tx, consultation, fromStatus, histories)
+	})
+}
+
+// Mendapatkan riwayat status konsultasi, diurutkan dari yang paling lama
+func (r *ConsultationRepositoryImpl) GetStatusHistory(consultationID int) ([]model.ConsultationStatusHistory, error) {
+	var histories []model.ConsultationStatusHistory
+	if err := r.DB.Where("consultation_id = ?", consultationID).
+		Order("created_at ASC, id ASC").
+		Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
+// Update bersyarat pada status lama agar dua perubahan paralel tidak saling menimpa
+func applyStatusTransition(tx *gorm.DB, consultation *model.Consultation, fromStatus string, histories []model.ConsultationStatusHistory) error {
+	result := tx.Model(&model.Consultation{}).
+		Where("id = ? AND status = ?", consultation.ID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":         consultation.Status,
+			"payment_status": consultation.PaymentStatus,
+			"is_approved":    consultation.IsApproved,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStatusConflict
+	}
+
+	for i := range histories {
+		histories[i].ConsultationID = consultation.ID
+		if err := tx.Create(&histories[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ type DoctorProfilRepository interface {
 	UpdateTagsByName(doctorID int, tagNames []string) error
 	GetDoctorTitleByID(doctorID int) (*model.Title, error)
 	UpdateDoctorTitleByName(doctorID int, titleName string) error
+	RequestCredentialReview(doctorID int) error
 }
 
 type DoctorProfilRepositoryImpl struct {
@@ -192,3 +193,10 @@ func (r *DoctorProfilRepositoryImpl) UpdateDoctorTitleByName(doctorID int, title
 
 	return nil
 }
+
+// Kredensial yang sudah disetujui dikembalikan ke antrian review admin
+func (r *DoctorProfilRepositoryImpl) RequestCredentialReview(doctorID int) error {
+	return r.DB.Model(&model.Doctor{}).
+		Where("id = ? AND credential_status = ?", doctorID, model.CredentialStatusApproved).
+		Update("credential_status", model.CredentialStatusPending).Error
+}
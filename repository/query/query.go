@@ -0,0 +1,131 @@
+package query
+
+import (
+	"calmind/model"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Field yang boleh dipakai untuk mengurutkan dan memfilter sebuah daftar, dipetakan ke kolom tabel
+type Spec struct {
+	SortFields  map[string]string // Nama field di API -> kolom
+	DefaultSort string            // Contoh: "-created_at"
+	Filters     map[string]string // Nama filter di API -> kolom, dicocokkan dengan "="
+	Search      []string          // Kolom yang dicocokkan dengan LIKE untuk opts.Search
+	IDColumn    string            // Kolom untuk cursor, default "id"
+}
+
+// Meloloskan karakter wildcard LIKE (%, _) dan backslash agar teks dari user dicocokkan apa adanya
+func EscapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Menerapkan filter, urutan, dan halaman pada db lalu mengisi dest (pointer ke slice).
+// Total dihitung sebelum limit sehingga klien tahu jumlah halaman.
+func Paginate(db *gorm.DB, opts model.QueryOptions, spec Spec, dest interface{}) (model.PageInfo, error) {
+	opts = opts.Normalized()
+
+	for name, value := range opts.Filters {
+		if value == "" {
+			continue
+		}
+		column, ok := spec.Filters[name]
+		if !ok {
+			return model.PageInfo{}, model.ErrInvalidFilter
+		}
+		db = db.Where(column+" = ?", filterValue(value))
+	}
+
+	if search := strings.TrimSpace(opts.Search); search != "" && len(spec.Search) > 0 {
+		conditions := make([]string, 0, len(spec.Search))
+		args := make([]interface{}, 0, len(spec.Search))
+		for _, column := range spec.Search {
+			conditions = append(conditions, column+" LIKE ?")
+			args = append(args, "%"+EscapeLike(search)+"%")
+		}
+		db = db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+
+	sort := opts.Sort
+	if sort == "" {
+		sort = spec.DefaultSort
+	}
+	desc := strings.HasPrefix(sort, "-")
+	column, ok := spec.SortFields[strings.TrimPrefix(sort, "-")]
+	if !ok {
+		return model.PageInfo{}, model.ErrInvalidSort
+	}
+
+	idColumn := spec.IDColumn
+	if idColumn == "" {
+		idColumn = "id"
+	}
+	if opts.Cursor > 0 && column != idColumn {
+		return model.PageInfo{}, model.ErrInvalidCursor
+	}
+
+	db = db.Session(&gorm.Session{})
+	var total int64
+	if err := db.Model(dest).Count(&total).Error; err != nil {
+		return model.PageInfo{}, err
+	}
+
+	direction := " ASC"
+	if desc {
+		direction = " DESC"
+	}
+	query := db.Order(column + direction)
+	if column != idColumn {
+		query = query.Order(idColumn + direction) // Urutan stabil untuk nilai yang sama
+	}
+
+	if opts.Cursor > 0 {
+		if desc {
+			query = query.Where(idColumn+" < ?", opts.Cursor)
+		} else {
+			query = query.Where(idColumn+" > ?", opts.Cursor)
+		}
+	} else {
+		query = query.Offset(opts.Offset())
+	}
+
+	if err := query.Limit(opts.Limit).Find(dest).Error; err != nil {
+		return model.PageInfo{}, err
+	}
+
+	page := model.NewPageInfo(opts, total)
+	if column == idColumn {
+		page.NextCursor = lastID(dest, opts.Limit)
+	}
+	return page, nil
+}
+
+// "true"/"false" diubah menjadi boolean agar cocok dengan kolom tinyint MySQL
+func filterValue(value string) interface{} {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return value
+}
+
+// ID item terakhir jika halaman penuh, 0 jika tidak ada halaman berikutnya
+func lastID(dest interface{}, limit int) int {
+	items := reflect.Indirect(reflect.ValueOf(dest))
+	if items.Kind() != reflect.Slice || items.Len() < limit || items.Len() == 0 {
+		return 0
+	}
+
+	last := reflect.Indirect(items.Index(items.Len() - 1))
+	id := last.FieldByName("ID")
+	if !id.IsValid() || !id.CanInt() {
+		return 0
+	}
+	return int(id.Int())
+}
@@ -0,0 +1,100 @@
+package query
+
+import (
+	"calmind/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type testItem struct {
+	ID        int
+	IsActive  bool
+	CreatedAt time.Time
+}
+
+var testSpec = Spec{
+	SortFields:  map[string]string{"id": "id", "created_at": "created_at"},
+	DefaultSort: "-created_at",
+	Filters:     map[string]string{"is_active": "is_active"},
+	Search:      []string{"judul"},
+}
+
+// Koneksi dry run yang mencatat query terakhir (query data setelah query hitung) tanpa menjalankannya
+func newDryRunDB(t *testing.T) (*gorm.DB, *string) {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "test@tcp(localhost:3306)/calmind", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: logger.Discard})
+	assert.NoError(t, err)
+
+	var last string
+	err = db.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		last = tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+	})
+	assert.NoError(t, err)
+	return db, &last
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  model.QueryOptions
+		query string
+		err   error
+	}{
+		{
+			name:  "urutan default dan halaman",
+			opts:  model.QueryOptions{Page: 3, Limit: 10},
+			query: "SELECT * FROM `test_items` ORDER BY created_at DESC,id DESC LIMIT 10 OFFSET 20",
+		},
+		{
+			name:  "limit dibatasi",
+			opts:  model.QueryOptions{Limit: 500, Sort: "id"},
+			query: "SELECT * FROM `test_items` ORDER BY id ASC LIMIT 100",
+		},
+		{
+			name:  "cursor menurun",
+			opts:  model.QueryOptions{Cursor: 40, Sort: "-id", Page: 5},
+			query: "SELECT * FROM `test_items` WHERE id < 40 ORDER BY id DESC LIMIT 20",
+		},
+		{
+			name:  "cursor menaik",
+			opts:  model.QueryOptions{Cursor: 40, Sort: "id"},
+			query: "SELECT * FROM `test_items` WHERE id > 40 ORDER BY id ASC LIMIT 20",
+		},
+		{
+			name:  "filter dan pencarian",
+			opts:  model.QueryOptions{Sort: "id", Filters: map[string]string{"is_active": "true"}, Search: " 50%_off "},
+			query: "SELECT * FROM `test_items` WHERE is_active = true AND (judul LIKE '%50" + `\%\_` + "off%') ORDER BY id ASC LIMIT 20",
+		},
+		{name: "field urutan tidak dikenal", opts: model.QueryOptions{Sort: "-password"}, err: model.ErrInvalidSort},
+		{name: "filter tidak dikenal", opts: model.QueryOptions{Filters: map[string]string{"role": "admin"}}, err: model.ErrInvalidFilter},
+		{name: "cursor tanpa urutan id", opts: model.QueryOptions{Cursor: 40}, err: model.ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, last := newDryRunDB(t)
+			var items []testItem
+			page, err := Paginate(db, tt.opts, testSpec, &items)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Empty(t, *last)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.query, *last)
+			assert.Equal(t, tt.opts.Normalized().Limit, page.Limit)
+		})
+	}
+}
+
+func TestLastID(t *testing.T) {
+	items := []testItem{{ID: 9}, {ID: 7}, {ID: 4}}
+
+	assert.Equal(t, 4, lastID(&items, 3))
+	assert.Equal(t, 0, lastID(&items, 5), "halaman tidak penuh berarti tidak ada halaman berikutnya")
+	assert.Equal(t, 0, lastID(&[]testItem{}, 3))
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"calmind/model"
+	"calmind/repository/query"
+
+	"gorm.io/gorm"
+)
+
+type RefundRepository interface {
+	GetConsultationByID(consultationID int) (*model.Consultation, error)
+	CreateRefund(refund *model.Refund) error
+	UpdateRefund(refund *model.Refund) error
+	GetRefundByID(id int) (*model.Refund, error)
+	GetRefundByConsultation(consultationID int) (*model.Refund, error)
+	GetRefundsByUser(userID int, opts model.QueryOptions) ([]model.Refund, model.PageInfo, error)
+	GetAllRefunds(opts model.QueryOptions) ([]model.Refund, model.PageInfo, error)
+}
+
+// Urutan dan filter daftar refund, kolom diberi nama tabel karena daftar user memakai join ke consultations
+var refundListSpec = query.Spec{
+	SortFields: map[string]string{
+		"id":         "refunds.id",
+		"created_at": "refunds.created_at",
+		"amount":     "refunds.amount",
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]string{
+		"status":          "refunds.status",
+		"method":          "refunds.method",
+		"requested_by":    "refunds.requested_by",
+		"consultation_id": "refunds.consultation_id",
+	},
+	IDColumn: "refunds.id",
+}
+
+type RefundRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) RefundRepository {
+	return &RefundRepositoryImpl{DB: db}
+}
+
+// Mendapatkan konsultasi beserta harga dokter
+func (r *RefundRepositoryImpl) GetConsultationByID(consultationID int) (*model.Consultation, error) {
+	var consultation model.Consultation
+	if err := r.DB.Preload("Doctor").Preload("User").First(&consultation, consultationID).Error; err != nil {
+		return nil, err
+	}
+	return &consultation, nil
+}
+
+func (r *RefundRepositoryImpl) CreateRefund(refund *model.Refund) error {
+	return r.DB.Create(refund).Error
+}
+
+func (r *RefundRepositoryImpl) UpdateRefund(refund *model.Refund) error {
+	return r.DB.Save(refund).Error
+}
+
+func (r *RefundRepositoryImpl) GetRefundByID(id int) (*model.Refund, error) {
+	var refund model.Refund
+	if err := r.DB.First(&refund, id).Error; err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (r *RefundRepositoryImpl) GetRefundByConsultation(consultationID int) (*model.Refund, error) {
+	var refund model.Refund
+	if err := r.DB.Where("consultation_id = ?", consultationID).First(&refund).Error; err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// Mendapatkan refund milik user berdasarkan konsultasinya
+func (r *RefundRepositoryImpl) GetRefundsByUser(userID int, opts model.QueryOptions) ([]model.Refund, model.PageInfo, error) {
+	var refunds []model.Refund
+	db := r.DB.Joins("JOIN consultations ON consultations.id = refunds.consultation_id").
+		Where("consultations.user_id = ?", userID)
+	page, err := query.Paginate(db, opts, refundListSpec, &refunds)
+	return refunds, page, err
+}
+
+// Mendapatkan semua refund, bisa difilter berdasarkan status
+func (r *RefundRepositoryImpl) GetAllRefunds(opts model.QueryOptions) ([]model.Refund, model.PageInfo, error) {
+	var refunds []model.Refund
+	page, err := query.Paginate(r.DB, opts, refundListSpec, &refunds)
+	return refunds, page, err
+}
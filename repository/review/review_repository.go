@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"calmind/model"
+	"calmind/repository/query"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Konsultasi sudah memiliki ulasan (index unik consultation_id), misalnya dari request yang datang bersamaan
+var ErrDuplicateReview = errors.New("konsultasi ini sudah memiliki ulasan")
+
+type ReviewRepository interface {
+	GetConsultationByID(consultationID int) (*model.Consultation, error)
+	CreateReview(review *model.DoctorReview) error
+	GetReviewByID(id int) (*model.DoctorReview, error)
+	GetReviewByConsultation(consultationID int) (*model.DoctorReview, error)
+	GetReviews(opts model.QueryOptions) ([]model.DoctorReview, model.PageInfo, error)
+	GetReviewsByDoctor(doctorID int, visibleOnly bool, opts model.QueryOptions) ([]model.DoctorReview, model.PageInfo, error)
+	UpdateReview(review *model.DoctorReview) error
+}
+
+// Urutan dan filter daftar ulasan, default terbaru di depan
+var reviewListSpec = query.Spec{
+	SortFields:  map[string]string{"id": "id", "created_at": "created_at", "rating": "rating"},
+	DefaultSort: "-created_at",
+	Filters: map[string]string{
+		"status":    "status",
+		"rating":    "rating",
+		"doctor_id": "doctor_id",
+		"user_id":   "user_id",
+	},
+	Search: []string{"comment"},
+}
+
+type ReviewRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &ReviewRepositoryImpl{DB: db}
+}
+
+func (r *ReviewRepositoryImpl) GetConsultationByID(consultationID int) (*model.Consultation, error) {
+	var consultation model.Consultation
+	if err := r.DB.First(&consultation, consultationID).Error; err != nil {
+		return nil, err
+	}
+	return &consultation, nil
+}
+
+// Menyimpan ulasan sekaligus memperbarui rating dokter
+func (r *ReviewRepositoryImpl) CreateReview(review *model.DoctorReview) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			if isDuplicate(err) {
+				return ErrDuplicateReview
+			}
+			return err
+		}
+		return refreshDoctorRating(tx, review.DoctorID)
+	})
+}
+
+func (r *ReviewRepositoryImpl) GetReviewByID(id int) (*model.DoctorReview, error) {
+	var review model.DoctorReview
+	if err := r.DB.Preload("User").First(&review, id).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *ReviewRepositoryImpl) GetReviewByConsultation(consultationID int) (*model.DoctorReview, error) {
+	var review model.DoctorReview
+	if err := r.DB.Where("consultation_id = ?", consultationID).First(&review).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *ReviewRepositoryImpl) GetReviews(opts model.QueryOptions) ([]model.DoctorReview, model.PageInfo, error) {
+	var reviews []model.DoctorReview
+	page, err := query.Paginate(r.DB.Preload("User"), opts, reviewListSpec, &reviews)
+	return reviews, page, err
+}
+
+func (r *ReviewRepositoryImpl) GetReviewsByDoctor(doctorID int, visibleOnly bool, opts model.QueryOptions) ([]model.DoctorReview, model.PageInfo, error) {
+	db := r.DB.Preload("User").Where("doctor_id = ?", doctorID)
+	if visibleOnly {
+		db = db.Where("status = ?", model.ReviewStatusVisible)
+	}
+
+	var reviews []model.DoctorReview
+	page, err := query.Paginate(db, opts, reviewListSpec, &reviews)
+	return reviews, page, err
+}
+
+// Balasan dan moderasi dapat mengubah ulasan yang dihitung, jadi rating dokter dihitung ulang
+func (r *ReviewRepositoryImpl) UpdateReview(review *model.DoctorReview) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.DoctorReview{}).Where("id = ?", review.ID).Updates(map[string]interface{}{
+			"reply":           review.Reply,
+			"replied_at":      review.RepliedAt,
+			"status":          review.Status,
+			"moderated_by":    review.ModeratedBy,
+			"moderation_note": review.ModerationNote,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return refreshDoctorRating(tx, review.DoctorID)
+	})
+}
+
+// Rata-rata dan jumlah ulasan yang tampil disimpan di tabel doctors agar daftar dokter bisa diurutkan tanpa join.
+// Baris dokter dikunci dulu agar ulasan yang disimpan bersamaan tidak saling menimpa hasil hitungan yang lama.
+func refreshDoctorRating(tx *gorm.DB, doctorID int) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model.Doctor{}, doctorID).Error; err != nil {
+		return err
+	}
+
+	var summary struct {
+		Average float64
+		Count   int
+	}
+	err := tx.Model(&model.DoctorReview{}).
+		Select("COALESCE(ROUND(AVG(rating), 2), 0) AS average, COUNT(*) AS count").
+		Where("doctor_id = ? AND status = ?", doctorID, model.ReviewStatusVisible).
+		Scan(&summary).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&model.Doctor{}).Where("id = ?", doctorID).Updates(map[string]interface{}{
+		"rating_average": summary.Average,
+		"rating_count":   summary.Count,
+	}).Error
+}
+
+// Error MySQL 1062: nilai index unik sudah dipakai
+func isDuplicate(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRefreshDoctorRating_LocksDoctorFirst(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "test@tcp(localhost:3306)/calmind", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true, Logger: logger.Discard})
+	assert.NoError(t, err)
+	var statements []string
+	record := func(tx *gorm.DB) { statements = append(statements, tx.Statement.SQL.String()) }
+	assert.NoError(t, db.Callback().Query().After("gorm:query").Register("test:record", record))
+	assert.NoError(t, db.Callback().Row().After("gorm:row").Register("test:record", record))
+
+	// Scan tidak didukung dry run, yang diperiksa hanya urutan query
+	refreshDoctorRating(db, 20)
+	if assert.Len(t, statements, 2) {
+		assert.True(t, strings.HasSuffix(statements[0], "FOR UPDATE"), statements[0])
+		assert.Contains(t, statements[1], "AVG(rating)")
+	}
+}
+
+func TestIsDuplicate(t *testing.T) {
+	duplicate := &mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'idx_doctor_reviews_consultation_id'"}
+
+	assert.True(t, isDuplicate(duplicate))
+	assert.True(t, isDuplicate(fmt.Errorf("create review: %w", duplicate)))
+	assert.False(t, isDuplicate(&mysqldriver.MySQLError{Number: 1451}))
+	assert.False(t, isDuplicate(errors.New("connection refused")))
+	assert.False(t, isDuplicate(nil))
+}
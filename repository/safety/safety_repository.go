@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"calmind/model"
+	"calmind/repository/query"
+
+	"gorm.io/gorm"
+)
+
+type SafetyAlertRepository interface {
+	GetAlerts(opts model.QueryOptions) ([]model.SafetyAlert, model.PageInfo, error)
+	GetAlertByID(id int) (*model.SafetyAlert, error)
+	UpdateAlert(alert *model.SafetyAlert) error
+	GetConversation(sessionID, chatLogID, limit int) ([]model.ChatLog, error)
+}
+
+// Urutan dan filter daftar peringatan, default terbaru di depan
+var alertListSpec = query.Spec{
+	SortFields:  map[string]string{"id": "id", "created_at": "created_at", "updated_at": "updated_at"},
+	DefaultSort: "-created_at",
+	Filters: map[string]string{
+		"status":     "status",
+		"risk_level": "risk_level",
+		"source":     "source",
+		"user_id":    "user_id",
+	},
+	Search: []string{"message", "matches"},
+}
+
+type SafetyAlertRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewSafetyAlertRepository(db *gorm.DB) SafetyAlertRepository {
+	return &SafetyAlertRepositoryImpl{DB: db}
+}
+
+func (r *SafetyAlertRepositoryImpl) GetAlerts(opts model.QueryOptions) ([]model.SafetyAlert, model.PageInfo, error) {
+	var alerts []model.SafetyAlert
+	page, err := query.Paginate(r.DB.Preload("User"), opts, alertListSpec, &alerts)
+	return alerts, page, err
+}
+
+func (r *SafetyAlertRepositoryImpl) GetAlertByID(id int) (*model.SafetyAlert, error) {
+	var alert model.SafetyAlert
+	if err := r.DB.Preload("User").First(&alert, id).Error; err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (r *SafetyAlertRepositoryImpl) UpdateAlert(alert *model.SafetyAlert) error {
+	return r.DB.Model(&model.SafetyAlert{}).Where("id = ?", alert.ID).Updates(map[string]interface{}{
+		"status":      alert.Status,
+		"handled_by":  alert.HandledBy,
+		"note":        alert.Note,
+		"resolved_at": alert.ResolvedAt,
+	}).Error
+}
+
+// Potongan percakapan sampai log yang memicu peringatan, urut dari yang terlama
+func (r *SafetyAlertRepositoryImpl) GetConversation(sessionID, chatLogID, limit int) ([]model.ChatLog, error) {
+	var logs []model.ChatLog
+	err := r.DB.Where("session_id = ? AND id <= ?", sessionID, chatLogID).
+		Order("id DESC").Limit(limit).Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+		logs[i], logs[j] = logs[j], logs[i]
+	}
+	return logs, nil
+}
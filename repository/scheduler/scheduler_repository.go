@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"calmind/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SchedulerRepository interface {
+	UpsertJob(job *model.ScheduledJob) error
+	CancelJob(uniqueKey string) error
+	ClaimDueJobs(workerID string, now, staleBefore time.Time, limit int) ([]model.ScheduledJob, error)
+	MarkDone(id int, workerID string) error
+	MarkRetry(id int, workerID string, runAt time.Time, lastError string) error
+	MarkFailed(id int, workerID string, lastError string) error
+}
+
+type SchedulerRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewSchedulerRepository(db *gorm.DB) SchedulerRepository {
+	return &SchedulerRepositoryImpl{DB: db}
+}
+
+// Status job yang boleh dijadwalkan ulang lewat UpsertJob
+var reschedulableJobStatuses = []string{model.JobStatusPending, model.JobStatusFailed, model.JobStatusCancelled}
+
+// Menyimpan job baru, atau menjadwalkan ulang job dengan unique_key yang sama.
+// Job yang sedang running atau sudah done dibiarkan agar tidak dijalankan dua kali.
+func (r *SchedulerRepositoryImpl) UpsertJob(job *model.ScheduledJob) error {
+	// ON DUPLICATE KEY UPDATE tidak mendukung WHERE, jadi setiap kolom memeriksa status sendiri.
+	// MySQL menilai assignment dari kiri, maka status harus diubah paling akhir.
+	assign := func(column string, value interface{}) clause.Assignment {
+		return clause.Assignment{
+			Column: clause.Column{Name: column},
+			Value:  gorm.Expr("IF(status IN ?, ?, "+column+")", reschedulableJobStatuses, value),
+		}
+	}
+	return r.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "unique_key"}},
+		DoUpdates: clause.Set{
+			assign("type", job.Type),
+			assign("payload", job.Payload),
+			assign("run_at", job.RunAt),
+			assign("attempts", 0),
+			assign("last_error", ""),
+			assign("locked_by", ""),
+			assign("locked_at", nil),
+			assign("status", model.JobStatusPending),
+		},
+	}).Create(job).Error
+}
+
+// Membatalkan job yang belum dijalankan
+func (r *SchedulerRepositoryImpl) CancelJob(uniqueKey string) error {
+	return r.DB.Model(&model.ScheduledJob{}).
+		Where("unique_key = ? AND status = ?", uniqueKey, model.JobStatusPending).
+		Update("status", model.JobStatusCancelled).Error
+}
+
+// Mengambil job yang sudah jatuh tempo dan menguncinya untuk worker ini.
+// Job running yang kuncinya lebih lama dari staleBefore dianggap ditinggalkan worker yang mati.
+func (r *SchedulerRepositoryImpl) ClaimDueJobs(workerID string, now, staleBefore time.Time, limit int) ([]model.ScheduledJob, error) {
+	var jobs []model.ScheduledJob
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		// SKIP LOCKED agar beberapa replika tidak mengambil job yang sama
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)",
+				model.JobStatusPending, now, model.JobStatusRunning, staleBefore).
+			Order("run_at ASC").
+			Limit(limit).
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		ids := make([]int, len(jobs))
+		for i := range jobs {
+			ids[i] = jobs[i].ID
+			jobs[i].Status = model.JobStatusRunning
+			jobs[i].Attempts++
+			jobs[i].LockedBy = workerID
+			jobs[i].LockedAt = &now
+		}
+
+		return tx.Model(&model.ScheduledJob{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":    model.JobStatusRunning,
+				"attempts":  gorm.Expr("attempts + 1"),
+				"locked_by": workerID,
+				"locked_at": now,
+			}).Error
+	})
+	return jobs, err
+}
+
+// Perubahan hanya berlaku jika job masih dikunci worker yang sama
+func (r *SchedulerRepositoryImpl) MarkDone(id int, workerID string) error {
+	return r.DB.Model(&model.ScheduledJob{}).Where("id = ? AND locked_by = ?", id, workerID).
+		Updates(map[string]interface{}{
+			"status":     model.JobStatusDone,
+			"last_error": "",
+			"locked_by":  "",
+			"locked_at":  nil,
+		}).Error
+}
+
+// Mengembalikan job ke antrean untuk dicoba lagi pada runAt
+func (r *SchedulerRepositoryImpl) MarkRetry(id int, workerID string, runAt time.Time, lastError string) error {
+	return r.DB.Model(&model.ScheduledJob{}).Where("id = ? AND locked_by = ?", id, workerID).
+		Updates(map[string]interface{}{
+			"status":     model.JobStatusPending,
+			"run_at":     runAt,
+			"last_error": lastError,
+			"locked_by":  "",
+			"locked_at":  nil,
+		}).Error
+}
+
+func (r *SchedulerRepositoryImpl) MarkFailed(id int, workerID string, lastError string) error {
+	return r.DB.Model(&model.ScheduledJob{}).Where("id = ? AND locked_by = ?", id, workerID).
+		Updates(map[string]interface{}{
+			"status":     model.JobStatusFailed,
+			"last_error": lastError,
+			"locked_by":  "",
+			"locked_at":  nil,
+		}).Error
+}
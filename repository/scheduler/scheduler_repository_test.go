@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"calmind/model"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// UpsertJob bergantung pada ON DUPLICATE KEY UPDATE MySQL, jadi diuji terhadap database sungguhan.
+// Dijalankan hanya jika TEST_DATABASE_DSN diatur, contoh: user:pass@tcp(localhost:3306)/calmind_test?parseTime=true
+func newTestRepo(t *testing.T) *SchedulerRepositoryImpl {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN tidak diatur")
+	}
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gagal terhubung ke database: %v", err)
+	}
+	if err := db.AutoMigrate(&model.ScheduledJob{}); err != nil {
+		t.Fatalf("gagal membuat tabel scheduled_jobs: %v", err)
+	}
+	t.Cleanup(func() { db.Where("unique_key LIKE ?", "test_recurring:%").Delete(&model.ScheduledJob{}) })
+	return &SchedulerRepositoryImpl{DB: db}
+}
+
+func upsert(t *testing.T, repo *SchedulerRepositoryImpl, key string, runAt time.Time) {
+	job := &model.ScheduledJob{Type: "test_recurring", UniqueKey: &key, Status: model.JobStatusPending, RunAt: runAt, MaxAttempts: 5}
+	assert.NoError(t, repo.UpsertJob(job))
+}
+
+func findJob(t *testing.T, repo *SchedulerRepositoryImpl, key string) model.ScheduledJob {
+	var job model.ScheduledJob
+	assert.NoError(t, repo.DB.Where("unique_key = ?", key).First(&job).Error)
+	return job
+}
+
+// Alur job berulang: job yang sedang berjalan menjadwalkan putaran berikutnya dengan key lain
+func TestUpsertJob_RecurringJob(t *testing.T) {
+	repo := newTestRepo(t)
+	day1 := time.Now().Add(-time.Minute).Truncate(time.Second)
+	day2 := day1.Add(24 * time.Hour)
+	key1, key2 := "test_recurring:1", "test_recurring:2"
+
+	upsert(t, repo, key1, day1)
+	jobs, err := repo.ClaimDueJobs("worker-1", time.Now(), time.Now().Add(-time.Hour), 100)
+	assert.NoError(t, err)
+	var claimed *model.ScheduledJob
+	for i := range jobs {
+		if *jobs[i].UniqueKey == key1 {
+			claimed = &jobs[i]
+		}
+	}
+	if !assert.NotNil(t, claimed) {
+		return
+	}
+
+	// Job running tidak ditimpa, putaran berikutnya tersimpan sebagai job baru
+	upsert(t, repo, key1, day2)
+	upsert(t, repo, key2, day2)
+	assert.Equal(t, model.JobStatusRunning, findJob(t, repo, key1).Status)
+	assert.NoError(t, repo.MarkDone(claimed.ID, "worker-1"))
+
+	// Job done tidak dihidupkan lagi, job pending dengan waktu sama tetap pending
+	upsert(t, repo, key1, day2)
+	upsert(t, repo, key2, day2)
+	assert.Equal(t, model.JobStatusDone, findJob(t, repo, key1).Status)
+	next := findJob(t, repo, key2)
+	assert.Equal(t, model.JobStatusPending, next.Status)
+	assert.True(t, next.RunAt.Equal(day2))
+}
+
+// Job yang gagal atau dibatalkan dijadwalkan ulang dari awal
+func TestUpsertJob_ReschedulesFailed(t *testing.T) {
+	repo := newTestRepo(t)
+	key := "test_recurring:failed"
+	runAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	upsert(t, repo, key, runAt)
+	assert.NoError(t, repo.DB.Model(&model.ScheduledJob{}).Where("unique_key = ?", key).
+		Updates(map[string]interface{}{"status": model.JobStatusFailed, "attempts": 5, "last_error": "smtp"}).Error)
+
+	upsert(t, repo, key, runAt.Add(time.Hour))
+	job := findJob(t, repo, key)
+	assert.Equal(t, model.JobStatusPending, job.Status)
+	assert.Equal(t, 0, job.Attempts)
+	assert.Empty(t, job.LastError)
+	assert.True(t, job.RunAt.Equal(runAt.Add(time.Hour)))
+}
+
+// Tanpa database: status harus diubah paling akhir agar kolom lain masih membaca status lama
+func TestUpsertJob_SQL(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "test@tcp(localhost:3306)/calmind", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true, Logger: logger.Discard})
+	assert.NoError(t, err)
+
+	var sql string
+	err = db.Callback().Create().After("gorm:create").Register("test:record", func(tx *gorm.DB) {
+		sql = tx.Statement.SQL.String()
+	})
+	assert.NoError(t, err)
+
+	key := "auth_token_cleanup:2024-12-02"
+	repo := &SchedulerRepositoryImpl{DB: db}
+	assert.NoError(t, repo.UpsertJob(&model.ScheduledJob{Type: "auth_token_cleanup", UniqueKey: &key, Status: model.JobStatusPending, RunAt: time.Now()}))
+
+	assert.Contains(t, sql, "ON DUPLICATE KEY UPDATE `type`=IF(status IN (?,?,?), ?, type)")
+	assert.Contains(t, sql, "`run_at`=IF(status IN (?,?,?), ?, run_at)")
+	assert.True(t, strings.HasSuffix(sql, "`status`=IF(status IN (?,?,?), ?, status)"), sql)
+}
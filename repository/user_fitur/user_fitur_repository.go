@@ -2,21 +2,60 @@ package repository
 
 import (
 	"calmind/model"
+	"calmind/repository/query"
 	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type UserFiturRepository interface {
-	GetAllDoctors() ([]model.Doctor, error)
-	GetDoctorsByTag(tag string) ([]model.Doctor, error)
-	GetDoctorsByStatus(isActive bool) ([]model.Doctor, error)
-	SearchDoctors(query string) ([]model.Doctor, error)
+	GetAllDoctors(opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error)
+	GetDoctorsByTag(tag string, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error)
+	GetDoctorsByStatus(isActive bool, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error)
+	SearchDoctors(filter DoctorSearchFilter, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error)
 	GetDoctorByID(id int) (*model.Doctor, error)
 	GetTags() ([]model.Tags, error)
 	GetTitles() ([]model.Title, error)
-	GetDoctorsByTitle(title string) ([]model.Doctor, error)
+	GetDoctorsByTitle(title string, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error)
+}
+
+// Hanya dokter dengan kredensial yang sudah disetujui admin yang tampil ke user
+const approvedCredential = "doctors.credential_status = '" + model.CredentialStatusApproved + "'"
+
+// Kriteria umum dokter yang tampil di daftar: terverifikasi dan profil harga serta pengalamannya sudah diisi
+const listedDoctor = "doctors.price > 0 AND doctors.experience > 0 AND doctors.is_verified = true"
+
+// Urutan dan filter daftar dokter untuk user
+var doctorListSpec = query.Spec{
+	SortFields:  map[string]string{"id": "doctors.id", "username": "doctors.username", "price": "doctors.price", "experience": "doctors.experience", "created_at": "doctors.created_at", "rating": "doctors.rating_average", "rating_count": "doctors.rating_count"},
+	DefaultSort: "id",
+	Filters:     map[string]string{"jenis_kelamin": "doctors.jenis_kelamin"},
+	Search:      []string{"doctors.username"},
+	IDColumn:    "doctors.id",
+}
+
+// Kriteria pencarian dokter gabungan, nilai kosong berarti tidak difilter
+type DoctorSearchFilter struct {
+	Query         string   // Dicocokkan dengan nama, about, dan nama tag
+	Tags          []string // Nama tag, dokter cukup memiliki salah satunya
+	Title         string   // Nama title
+	MinPrice      float64
+	MaxPrice      float64
+	MinExperience int
+	MaxExperience int
+	IsActive      *bool
+	AvailableNow  bool      // Aktif, sedang dalam jam praktik, dan tidak sedang melayani konsultasi
+	Now           time.Time // Acuan waktu untuk AvailableNow
+}
+
+// Sama dengan daftar dokter biasa ditambah urutan "relevance" yang dihitung di query pencarian
+var doctorSearchSpec = query.Spec{
+	SortFields:  withSortField(doctorListSpec.SortFields, "relevance", "doctors.relevance"),
+	DefaultSort: "-relevance",
+	Filters:     doctorListSpec.Filters,
+	IDColumn:    "doctors.id",
 }
 
 type UserFiturRepositoryImpl struct {
@@ -28,87 +67,146 @@ func NewUserFiturRepository(db *gorm.DB) UserFiturRepository {
 }
 
 // Mendapatkan semua dokter yang memenuhi kriteria umum
-func (r *UserFiturRepositoryImpl) GetAllDoctors() ([]model.Doctor, error) {
+func (r *UserFiturRepositoryImpl) GetAllDoctors(opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
 	var doctors []model.Doctor
-	err := r.DB.
+	db := r.DB.
 		Preload("Tags").
 		Preload("Title").
-		Where("price > 0 AND experience > 0 AND is_verified = true").
-		Find(&doctors).Error
+		Where(listedDoctor).
+		Where(approvedCredential)
 
-	// Logging data untuk debugging
+	page, err := query.Paginate(db, opts, doctorListSpec, &doctors)
 	if err != nil {
 		fmt.Printf("Error fetching doctors: %v\n", err)
-		return nil, err
-	}
-	if len(doctors) == 0 {
-		fmt.Println("No doctors found.")
-		return doctors, nil
+		return nil, model.PageInfo{}, err
 	}
-
-	return doctors, nil
+	return doctors, page, nil
 }
 
 // Mendapatkan dokter berdasarkan Tag
-func (r *UserFiturRepositoryImpl) GetDoctorsByTag(tag string) ([]model.Doctor, error) {
+func (r *UserFiturRepositoryImpl) GetDoctorsByTag(tag string, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
 	var tags model.Tags
 	if err := r.DB.Where("name = ?", tag).First(&tags).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("tag '%s' not found", tag)
+			return nil, model.PageInfo{}, fmt.Errorf("tag '%s' not found", tag)
 		}
-		return nil, fmt.Errorf("failed to fetch tag: %v", err)
+		return nil, model.PageInfo{}, fmt.Errorf("failed to fetch tag: %v", err)
 	}
 
 	var doctors []model.Doctor
-	err := r.DB.
+	db := r.DB.
 		Joins("JOIN doctor_tags ON doctors.id = doctor_tags.doctor_id").
-		Joins("JOIN tags ON doctor_tags.tags_id = tags.id").
-		Where("tags.name = ?", tag).
+		Where("doctor_tags.tags_id = ?", tags.ID).
+		Where(approvedCredential).
 		Preload("Tags").
-		Preload("Title").
-		Find(&doctors).Error
+		Preload("Title")
 
-	return doctors, err
+	page, err := query.Paginate(db, opts, doctorListSpec, &doctors)
+	return doctors, page, err
 }
 
-// Mendapatkan dokter berdasarkan status (aktif atau tidak)
-func (r *UserFiturRepositoryImpl) GetDoctorsByStatus(isActive bool) ([]model.Doctor, error) {
+// Mendapatkan dokter berdasarkan status (aktif atau tidak) yang datanya sudah lengkap
+func (r *UserFiturRepositoryImpl) GetDoctorsByStatus(isActive bool, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
 	var doctors []model.Doctor
-	err := r.DB.
+	db := r.DB.
 		Where("is_active = ? AND username != '' AND no_hp != '' AND email != '' AND price > 0 AND experience > 0 AND is_verified = true", isActive).
+		Where("title_id IN (SELECT id FROM titles)").
+		Where(approvedCredential).
 		Preload("Tags").
-		Preload("Title").
-		Find(&doctors).Error
+		Preload("Title")
 
+	page, err := query.Paginate(db, opts, doctorListSpec, &doctors)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch doctors by status: %v", err)
+		return nil, model.PageInfo{}, fmt.Errorf("failed to fetch doctors by status: %w", err)
 	}
+	return doctors, page, nil
+}
 
-	// Periksa apakah data lengkap
-	completeDoctors := []model.Doctor{}
-	for _, doctor := range doctors {
-		if doctor.Username != "" && doctor.NoHp != "" && doctor.Email != "" &&
-			doctor.Price > 0 && doctor.Experience > 0 && doctor.Title.ID != 0 {
-			completeDoctors = append(completeDoctors, doctor)
-		}
+// Mencari dokter dengan teks, tag, title, harga, pengalaman, dan ketersediaan.
+// Skor relevansi dihitung di subquery agar bisa dipakai untuk urutan dan tetap bisa dihitung totalnya.
+func (r *UserFiturRepositoryImpl) SearchDoctors(filter DoctorSearchFilter, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
+	scored := r.DB.Model(&model.Doctor{}).Where(listedDoctor).Where(approvedCredential)
+
+	if filter.Query != "" {
+		escaped := query.EscapeLike(filter.Query)
+		like := "%" + escaped + "%"
+		tagMatch := "EXISTS (SELECT 1 FROM doctor_tags JOIN tags ON tags.id = doctor_tags.tags_id WHERE doctor_tags.doctor_id = doctors.id AND tags.name LIKE ?)"
+		scored = scored.
+			Select("doctors.*, "+
+				"(CASE WHEN doctors.username = ? THEN 10 WHEN doctors.username LIKE ? THEN 6 WHEN doctors.username LIKE ? THEN 4 ELSE 0 END) + "+
+				"(CASE WHEN "+tagMatch+" THEN 3 ELSE 0 END) + "+
+				"(CASE WHEN doctors.about LIKE ? THEN 1 ELSE 0 END) + "+
+				"doctors.rating_average / 10 AS relevance",
+				filter.Query, escaped+"%", like, like, like).
+			Where("(doctors.username LIKE ? OR doctors.about LIKE ? OR "+tagMatch+")", like, like, like)
+	} else {
+		// Tanpa teks pencarian relevansi hanya ditentukan rating
+		scored = scored.Select("doctors.*, doctors.rating_average / 10 AS relevance")
 	}
 
-	return completeDoctors, nil
-}
+	if len(filter.Tags) > 0 {
+		scored = scored.Where("doctors.id IN (SELECT doctor_tags.doctor_id FROM doctor_tags JOIN tags ON tags.id = doctor_tags.tags_id WHERE tags.name IN ?)", filter.Tags)
+	}
+	if filter.Title != "" {
+		scored = scored.Where("doctors.title_id IN (SELECT id FROM titles WHERE name = ?)", filter.Title)
+	}
+	if filter.MinPrice > 0 {
+		scored = scored.Where("doctors.price >= ?", filter.MinPrice)
+	}
+	if filter.MaxPrice > 0 {
+		scored = scored.Where("doctors.price <= ?", filter.MaxPrice)
+	}
+	if filter.MinExperience > 0 {
+		scored = scored.Where("doctors.experience >= ?", filter.MinExperience)
+	}
+	if filter.MaxExperience > 0 {
+		scored = scored.Where("doctors.experience <= ?", filter.MaxExperience)
+	}
+	if filter.IsActive != nil {
+		scored = scored.Where("doctors.is_active = ?", *filter.IsActive)
+	}
+	if filter.AvailableNow {
+		scored = whereAvailableAt(scored, filter.Now)
+	}
 
-// Mencari dokter berdasarkan kueri di beberapa kolom
-func (r *UserFiturRepositoryImpl) SearchDoctors(query string) ([]model.Doctor, error) {
 	var doctors []model.Doctor
-	err := r.DB.Preload("Title").
-		Where("username LIKE ?", "%"+query+"%").
-		Find(&doctors).Error
-	return doctors, err
+	db := r.DB.Table("(?) AS doctors", scored).Preload("Tags").Preload("Title")
+	page, err := query.Paginate(db, opts, doctorSearchSpec, &doctors)
+	return doctors, page, err
+}
+
+// Dokter aktif yang jadwal mingguan atau jam tambahannya mencakup waktu tersebut,
+// tidak sedang libur atau diblokir, dan tidak sedang memiliki konsultasi berjalan
+func whereAvailableAt(db *gorm.DB, now time.Time) *gorm.DB {
+	now = now.In(time.Local)
+	date := now.Format("2006-01-02")
+	clock := now.Format("15:04")
+
+	return db.
+		Where("doctors.is_active = ?", true).
+		Where("((EXISTS (SELECT 1 FROM doctor_schedules s WHERE s.doctor_id = doctors.id AND s.day_of_week = ? AND s.start_time <= ? AND s.end_time > ?)"+
+			" AND NOT EXISTS (SELECT 1 FROM doctor_schedule_exceptions e WHERE e.doctor_id = doctors.id AND e.date = ? AND e.is_available = false"+
+			" AND (e.start_time IS NULL OR e.start_time = '' OR (e.start_time <= ? AND e.end_time > ?))))"+
+			" OR EXISTS (SELECT 1 FROM doctor_schedule_exceptions e WHERE e.doctor_id = doctors.id AND e.date = ? AND e.is_available = true AND e.start_time <= ? AND e.end_time > ?))",
+			int(now.Weekday()), clock, clock, date, clock, clock, date, clock, clock).
+		Where("NOT EXISTS (SELECT 1 FROM consultations c WHERE c.doctor_id = doctors.id AND c.status IN ? AND (c.payment_status IS NULL OR c.payment_status != ?)"+
+			" AND c.start_time <= ? AND DATE_ADD(c.start_time, INTERVAL c.duration MINUTE) > ?)",
+			model.BookedConsultationStatuses, "failed", now, now)
+}
+
+func withSortField(fields map[string]string, name, column string) map[string]string {
+	result := make(map[string]string, len(fields)+1)
+	for key, value := range fields {
+		result[key] = value
+	}
+	result[name] = column
+	return result
 }
 
 // Mendapatkan dokter berdasarkan ID, termasuk informasi Tags
 func (r *UserFiturRepositoryImpl) GetDoctorByID(id int) (*model.Doctor, error) {
 	var doctor model.Doctor
-	err := r.DB.Preload("Tags").Preload("Title").Where("id = ?", id).First(&doctor).Error
+	err := r.DB.Preload("Tags").Preload("Title").Where("id = ?", id).Where(approvedCredential).First(&doctor).Error
 	if err != nil {
 		return nil, err
 	}
@@ -129,27 +227,27 @@ func (r *UserFiturRepositoryImpl) GetTitles() ([]model.Title, error) {
 }
 
 // Mendapatkan dokter berdasarkan Title
-func (r *UserFiturRepositoryImpl) GetDoctorsByTitle(title string) ([]model.Doctor, error) {
+func (r *UserFiturRepositoryImpl) GetDoctorsByTitle(title string, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
 	// Validasi apakah title ada di database
 	var existingTitle model.Title
 	if err := r.DB.Where("name = ?", title).First(&existingTitle).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("title '%s' not found", title)
+			return nil, model.PageInfo{}, fmt.Errorf("title '%s' not found", title)
 		}
-		return nil, fmt.Errorf("failed to fetch title: %v", err)
+		return nil, model.PageInfo{}, fmt.Errorf("failed to fetch title: %v", err)
 	}
 
 	// Jika title ditemukan, ambil dokter dengan title tersebut
 	var doctors []model.Doctor
-	err := r.DB.
+	db := r.DB.
 		Where("title_id = ?", existingTitle.ID).
+		Where(approvedCredential).
 		Preload("Title").
-		Preload("Tags").
-		Find(&doctors).Error
+		Preload("Tags")
 
+	page, err := query.Paginate(db, opts, doctorListSpec, &doctors)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch doctors for title '%s': %v", title, err)
+		return nil, model.PageInfo{}, fmt.Errorf("failed to fetch doctors for title '%s': %w", title, err)
 	}
-
-	return doctors, nil
+	return doctors, page, nil
 }
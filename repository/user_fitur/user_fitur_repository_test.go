@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"calmind/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Koneksi dry run yang mencatat setiap query dokter tanpa menjalankannya
+func newDryRunRepo(t *testing.T) (*UserFiturRepositoryImpl, *[]string) {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "test@tcp(localhost:3306)/calmind", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: logger.Discard})
+	assert.NoError(t, err)
+
+	var statements []string
+	err = db.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		if tx.Statement.Table == "doctors" || strings.Contains(tx.Statement.SQL.String(), "`doctors`") {
+			statements = append(statements, tx.Statement.SQL.String())
+		}
+	})
+	assert.NoError(t, err)
+	return &UserFiturRepositoryImpl{DB: db}, &statements
+}
+
+func TestDoctorListings_OnlyApprovedCredentials(t *testing.T) {
+	repo, statements := newDryRunRepo(t)
+	opts := model.QueryOptions{Page: 1, Limit: 10}
+
+	repo.GetAllDoctors(opts)
+	repo.GetDoctorsByTag("Stres", opts)
+	repo.GetDoctorsByStatus(true, opts)
+	repo.SearchDoctors(DoctorSearchFilter{Query: "cemas", AvailableNow: true, Now: time.Now()}, opts)
+	repo.GetDoctorsByTitle("Psikolog", opts)
+	repo.GetDoctorByID(1)
+
+	assert.NotEmpty(t, *statements)
+	for _, statement := range *statements {
+		assert.Contains(t, statement, "credential_status = 'approved'", statement)
+	}
+}
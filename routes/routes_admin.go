@@ -13,8 +13,15 @@ import (
 
 // Routes untuk Admin
 func AdminAuthRoutes(e *echo.Echo, authController *controller_auth.AdminAuthController) {
-	e.POST("/admin/login", authController.LoginAdmin)  // Login Admin
-	e.GET("/admin/logout", authController.LogoutAdmin) // Logout Admin
+	e.POST("/admin/login", authController.LoginAdmin)          // Login Admin
+	e.POST("/admin/refresh", authController.RefreshAdminToken) // Memperbarui access token dengan refresh token
+}
+
+// Routes sesi login Admin (membutuhkan access token)
+func AdminSessionRoutes(e *echo.Group, authController *controller_auth.AdminAuthController) {
+	e.GET("/logout", authController.LogoutAdmin)         // Logout Admin
+	e.POST("/logout", authController.LogoutAdmin)        // Logout Admin sekaligus mencabut refresh token
+	e.POST("/logout-all", authController.LogoutAllAdmin) // Keluar dari semua perangkat
 }
 
 func AdminManagementRoutes(e *echo.Group, adminManagement *controller_management.AdminManagementController, artikelController *controller_artikel.ArtikelController, consultationController *controller_konsultasi.ConsultationController, profil *controller_profil.AdminController, statsController *controller_statistik.StatsControllerImpl) {
@@ -31,13 +38,21 @@ func AdminManagementRoutes(e *echo.Group, adminManagement *controller_management
 	e.DELETE("/docters/:id", adminManagement.DeleteDoctor) // Hapus Dokter berdasarkan ID
 
 	// artikel
-	e.POST("/artikel", artikelController.CreateArtikel)                     // Tambah artikel
-	e.GET("/artikel", artikelController.GetAllArtikel)                      // Lihat semua artikel
-	e.GET("/artikel/:id", artikelController.GetArtikelByID)                 // Lihat detail artikel
-	e.PUT("/artikel/:id", artikelController.UpdateArtikel)                  // Update artikel
-	e.DELETE("/artikel/:id", artikelController.DeleteArtikel)               // Hapus artikel
-	e.POST("/artikel/upload-image", artikelController.UploadArtikelImage)   // Upload image untuk artikel
-	e.DELETE("/artikel/delete-image", artikelController.DeleteArtikelImage) // Hapus image artikel
+	e.POST("/artikel", artikelController.CreateArtikel)                                        // Tambah artikel
+	e.GET("/artikel", artikelController.GetAllArtikel)                                         // Lihat semua artikel, termasuk draft dan arsip
+	e.GET("/artikel/:id", artikelController.GetArtikelByID)                                    // Lihat detail artikel
+	e.PUT("/artikel/:id", artikelController.UpdateArtikel)                                     // Update artikel
+	e.DELETE("/artikel/:id", artikelController.DeleteArtikel)                                  // Hapus artikel
+	e.POST("/artikel/upload-image", artikelController.UploadArtikelImage)                      // Upload image untuk artikel
+	e.DELETE("/artikel/delete-image", artikelController.DeleteArtikelImage)                    // Hapus image artikel
+	e.GET("/artikel/:id/revisions", artikelController.GetArtikelRevisions)                     // Riwayat revisi artikel
+	e.GET("/artikel/:id/revisions/diff", artikelController.DiffArtikelRevisions)               // Bandingkan revisi ?from=&to=
+	e.GET("/artikel/:id/revisions/:number", artikelController.GetArtikelRevision)              // Detail revisi
+	e.POST("/artikel/:id/revisions/:number/restore", artikelController.RestoreArtikelRevision) // Pulihkan revisi sebagai versi terkini
+	e.GET("/artikel-categories", artikelController.GetCategories)
+	e.POST("/artikel-categories", artikelController.CreateCategory)
+	e.PUT("/artikel-categories/:id", artikelController.UpdateCategory)
+	e.DELETE("/artikel-categories/:id", artikelController.DeleteCategory) // Artikel di dalamnya menjadi tanpa kategori
 
 	// konsultasi
 	e.GET("/consultations", consultationController.GetAllStatusConsultations)
@@ -45,6 +60,7 @@ func AdminManagementRoutes(e *echo.Group, adminManagement *controller_management
 	e.GET("/consultations/pending", consultationController.GetPendingConsultations)
 	e.GET("/consultations/approve", consultationController.GetAproveConsultations)
 	e.PUT("/consultations/:id/approve", consultationController.ApprovePaymentAndConsultation)
+	e.GET("/consultations/:id/history", consultationController.GetAdminConsultationHistory)
 
 	// statistik
 	e.GET("/statistik", statsController.GetStats)
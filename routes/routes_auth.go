@@ -12,11 +12,20 @@ import (
 
 // Routes untuk User
 func UserAuthRoutes(e *echo.Echo, authController *controller_auth.AuthController) {
-	e.POST("/user/register", authController.RegisterUser) // Daftar User
-	e.POST("/user/login", authController.LoginUser)       // Login User
-	e.POST("/user/verify-otp", authController.VerifyOtp)  // Verifikasi OTP
-	e.POST("/user/resend-otp", authController.ResendOtp)  // Kirim ulang OTP
-	e.GET("/user/logout", authController.LogoutUser)      // Logout User
+	e.POST("/user/register", authController.RegisterUser)          // Daftar User
+	e.POST("/user/login", authController.LoginUser)                // Login User
+	e.POST("/user/verify-otp", authController.VerifyOtp)           // Verifikasi OTP
+	e.POST("/user/resend-otp", authController.ResendOtp)           // Kirim ulang OTP
+	e.POST("/user/refresh", authController.RefreshUserToken)       // Memperbarui access token dengan refresh token
+	e.POST("/user/forgot-password", authController.ForgotPassword) // Kirim OTP dan tautan reset password
+	e.POST("/user/reset-password", authController.ResetPassword)   // Ganti password dengan OTP atau tautan
+}
+
+// Routes sesi login User (membutuhkan access token)
+func UserSessionRoutes(e *echo.Group, authController *controller_auth.AuthController) {
+	e.GET("/logout", authController.LogoutUser)         // Logout User
+	e.POST("/logout", authController.LogoutUser)        // Logout User sekaligus mencabut refresh token
+	e.POST("/logout-all", authController.LogoutAllUser) // Keluar dari semua perangkat
 }
 
 func UserProfil(
@@ -36,7 +45,7 @@ func UserProfil(
 	e.GET("/doctors", fitur.GetDoctors)                // Mendapatkan daftar semua dokter
 	e.GET("/doctors/tag", fitur.GetDoctorsByTag)       // Mendapatkan dokter berdasarkan tag
 	e.GET("/doctors/status", fitur.GetDoctorsByStatus) // Mendapatkan dokter berdasarkan status
-	e.GET("/doctors/search", fitur.SearchDoctors)      // Mencari dokter berdasarkan query
+	e.GET("/doctors/search", fitur.SearchDoctors)      // Pencarian dokter gabungan (teks, tag, title, harga, ketersediaan)
 	e.GET("/doctors/:id", fitur.GetDoctorDetail)       // Mendapatkan detail dokter berdasarkan ID
 	e.GET("/tags", fitur.GetAllTags)                   // Mendapatkan semua tag (bidang keahlian)
 
@@ -44,12 +53,14 @@ func UserProfil(
 	e.GET("/titles", fitur.GetAllTitles)             // Mendapatkan semua title
 	e.GET("/doctors/title", fitur.GetDoctorsByTitle) // Mendapatkan dokter berdasarkan title
 
-	e.POST("/consultations", konsultasi.CreateConsultation)            // Membuat konsultasi
-	e.GET("/consultations", konsultasi.GetUserConsultations)           // Mendapatkan semua konsultasi user
-	e.GET("/consultations/:id", konsultasi.GetUserConsultationDetails) // Mendapatkan detail konsultasi user
+	e.POST("/consultations", konsultasi.CreateConsultation)                    // Membuat konsultasi
+	e.GET("/consultations", konsultasi.GetUserConsultations)                   // Mendapatkan semua konsultasi user
+	e.GET("/consultations/:id", konsultasi.GetUserConsultationDetails)         // Mendapatkan detail konsultasi user
+	e.GET("/consultations/:id/history", konsultasi.GetUserConsultationHistory) // Melihat riwayat status konsultasi
 
-	// Endpoint untuk artikel
-	e.GET("/artikel", artikelController.GetAllArtikel)      // Mendapatkan semua artikel
-	e.GET("/artikel/:id", artikelController.GetArtikelByID) // Mendapatkan detail artikel berdasarkan ID
-	e.GET("/artikel/search", artikelController.SearchArtikel)
+	// Endpoint untuk artikel, hanya yang sudah tayang
+	e.GET("/artikel", artikelController.GetPublishedArtikels)     // Mendapatkan artikel, filter ?tag= dan ?category=
+	e.GET("/artikel/:id", artikelController.GetPublishedArtikel)  // Mendapatkan detail artikel berdasarkan ID atau slug
+	e.GET("/artikel/search", artikelController.SearchArtikel)     // Mencari judul dan isi artikel
+	e.GET("/artikel-categories", artikelController.GetCategories) // Mendapatkan kategori artikel
 }
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	controller "calmind/controller/chat"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Routes chat konsultasi untuk User
+func UserConsultationChatRoutes(e *echo.Group, chatController *controller.ChatController) {
+	e.GET("/consultations/:id/chat/ws", chatController.UserChatWS)       // Koneksi WebSocket chat (token boleh lewat query ?token=)
+	e.GET("/consultations/:id/messages", chatController.GetUserMessages) // Riwayat chat (?before_id=&limit=)
+}
+
+// Routes chat konsultasi untuk Dokter
+func DoctorConsultationChatRoutes(e *echo.Group, chatController *controller.ChatController) {
+	e.GET("/consultations/:id/chat/ws", chatController.DoctorChatWS)       // Koneksi WebSocket chat (token boleh lewat query ?token=)
+	e.GET("/consultations/:id/messages", chatController.GetDoctorMessages) // Riwayat chat (?before_id=&limit=)
+}
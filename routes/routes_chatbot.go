@@ -8,4 +8,12 @@ import (
 
 func UserChatbotRoutes(e *echo.Group, chatbotController *controller.ChatbotController) {
 	e.POST("/chatbot", chatbotController.GetChatResponse)
+	e.POST("/chatbot/stream", chatbotController.StreamChatResponse) // Jawaban dikirim bertahap melalui server-sent events
+
+	// Sesi percakapan chatbot
+	e.GET("/chatbot/sessions", chatbotController.GetSessions)
+	e.POST("/chatbot/sessions", chatbotController.CreateSession)
+	e.PUT("/chatbot/sessions/:id", chatbotController.RenameSession)
+	e.DELETE("/chatbot/sessions/:id", chatbotController.DeleteSession)
+	e.GET("/chatbot/sessions/:id/messages", chatbotController.GetSessionMessages)
 }
@@ -9,4 +9,8 @@ import (
 
 func DoctorChatbotRoutes(e *echo.Group, chatbotController *controller.DoctorChatbotController) {
 	e.POST("/chatbot", chatbotController.GetDoctorRecommendation)
+	e.POST("/chatbot/stream", chatbotController.StreamDoctorRecommendation) // Jawaban dikirim bertahap melalui server-sent events
+
+	// Draf rekomendasi berdasarkan konsultasi, disimpan dokter melalui POST /consultations/:id/recommendation
+	e.POST("/consultations/:id/assistant", chatbotController.DraftRecommendation)
 }
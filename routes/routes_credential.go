@@ -0,0 +1,25 @@
+package routes
+
+import (
+	controller "calmind/controller/credential"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Routes kredensial profesi untuk Dokter
+func DoctorCredentialRoutes(e *echo.Group, credentialController *controller.CredentialController) {
+	e.GET("/credentials", credentialController.GetCredentials)              // Melihat status verifikasi dan dokumen
+	e.POST("/credentials", credentialController.UploadCredential)           // Mengunggah dokumen STR atau sertifikat
+	e.DELETE("/credentials/:id", credentialController.DeleteCredential)     // Menghapus dokumen sebelum diajukan
+	e.GET("/credentials/:id/file", credentialController.DownloadCredential) // Mengunduh dokumen milik sendiri
+	e.POST("/credentials/submit", credentialController.SubmitCredentials)   // Mengajukan dokumen untuk direview admin
+}
+
+// Routes review kredensial dokter untuk Admin
+func AdminCredentialRoutes(e *echo.Group, credentialController *controller.CredentialController) {
+	e.GET("/doctors/verification", credentialController.GetVerificationQueue)                                         // Antrian review kredensial
+	e.GET("/doctors/verification/:id", credentialController.GetVerificationDetail)                                    // Detail pengajuan dokter
+	e.GET("/doctors/verification/:id/documents/:document_id/file", credentialController.DownloadVerificationDocument) // Mengunduh dokumen pengajuan
+	e.PUT("/doctors/verification/:id/approve", credentialController.ApproveCredential)                                // Menyetujui kredensial
+	e.PUT("/doctors/verification/:id/reject", credentialController.RejectCredential)                                  // Menolak kredensial dengan alasan
+}
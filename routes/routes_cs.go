@@ -10,3 +10,20 @@ func UserCustServiceRoutes(e *echo.Echo, custServiceController *controller.CustS
 	e.POST("/customer-service", custServiceController.GetResponse)
 	e.GET("/customer-service", custServiceController.GetQuestion)
 }
+
+// Routes tiket bantuan untuk User
+func UserTicketRoutes(e *echo.Group, custServiceController *controller.CustServiceController) {
+	e.POST("/tickets", custServiceController.OpenTicket)              // Membuka tiket jika FAQ tidak membantu
+	e.GET("/tickets", custServiceController.GetUserTickets)           // Daftar tiket milik user
+	e.GET("/tickets/:id", custServiceController.GetUserTicket)        // Detail tiket beserta balasan
+	e.POST("/tickets/:id/replies", custServiceController.ReplyAsUser) // Membalas tiket
+	e.PUT("/tickets/:id/close", custServiceController.CloseTicket)    // Menutup tiket yang sudah selesai
+}
+
+// Routes inbox tiket bantuan untuk Admin
+func AdminTicketRoutes(e *echo.Group, custServiceController *controller.CustServiceController) {
+	e.GET("/tickets", custServiceController.GetTickets)               // Daftar tiket dengan filter status, prioritas, penugasan
+	e.GET("/tickets/:id", custServiceController.GetTicket)            // Detail tiket beserta balasan
+	e.PUT("/tickets/:id", custServiceController.UpdateTicket)         // Mengubah status, prioritas, atau penugasan
+	e.POST("/tickets/:id/answer", custServiceController.AnswerTicket) // Menjawab tiket dan mengirim email ke user
+}
@@ -12,11 +12,20 @@ import (
 
 // Routes untuk Doctor
 func DoctorAuthRoutes(e *echo.Echo, authController *controller_auth.DoctorAuthController) {
-	e.POST("/doctor/register", authController.RegisterDoctor) // Daftar Dokter
-	e.POST("/doctor/login", authController.LoginDoctor)       // Login Dokter
-	e.GET("/doctor/logout", authController.LogoutDoctor)      // Logout Dokter
-	e.POST("/doctor/verify-otp", authController.VerifyOtp)    // Verifikasi OTP
-	e.POST("/doctor/resend-otp", authController.ResendOtp)    // Kirim ulang OTP
+	e.POST("/doctor/register", authController.RegisterDoctor)        // Daftar Dokter
+	e.POST("/doctor/login", authController.LoginDoctor)              // Login Dokter
+	e.POST("/doctor/refresh", authController.RefreshDoctorToken)     // Memperbarui access token dengan refresh token
+	e.POST("/doctor/verify-otp", authController.VerifyOtp)           // Verifikasi OTP
+	e.POST("/doctor/resend-otp", authController.ResendOtp)           // Kirim ulang OTP
+	e.POST("/doctor/forgot-password", authController.ForgotPassword) // Kirim OTP dan tautan reset password
+	e.POST("/doctor/reset-password", authController.ResetPassword)   // Ganti password dengan OTP atau tautan
+}
+
+// Routes sesi login Dokter (membutuhkan access token)
+func DoctorSessionRoutes(e *echo.Group, authController *controller_auth.DoctorAuthController) {
+	e.GET("/logout", authController.LogoutDoctor)         // Logout Dokter
+	e.POST("/logout", authController.LogoutDoctor)        // Logout Dokter sekaligus mencabut refresh token
+	e.POST("/logout-all", authController.LogoutAllDoctor) // Keluar dari semua perangkat
 }
 
 func DoctorProfil(
@@ -38,8 +47,9 @@ func DoctorProfil(
 	e.GET("/titles", fiturController.GetAllTitles) // Mendapatkan semua title
 
 	// Konsultasi
-	e.GET("/consultations", consultationController.GetAllConsultationsForDoctor)          // Mendapatkan semua konsultasi pasien dokter
-	e.GET("/consultations/:id", consultationController.ViewConsultationDetails)           // Mendapatkan detail konsultasi tertentu
-	e.POST("/consultations/:id/recommendation", consultationController.AddRecommendation) // Menambahkan rekomendasi pada konsultasi
-	e.GET("/consultations/search", consultationController.SearchConsultationsByName)      // Melihat pasien sesuai nama dengan search
+	e.GET("/consultations", consultationController.GetAllConsultationsForDoctor)             // Mendapatkan semua konsultasi pasien dokter
+	e.GET("/consultations/:id", consultationController.ViewConsultationDetails)              // Mendapatkan detail konsultasi tertentu
+	e.POST("/consultations/:id/recommendation", consultationController.AddRecommendation)    // Menambahkan rekomendasi pada konsultasi
+	e.GET("/consultations/search", consultationController.SearchConsultationsByName)         // Melihat pasien sesuai nama dengan search
+	e.GET("/consultations/:id/history", consultationController.GetDoctorConsultationHistory) // Melihat riwayat status konsultasi
 }
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	controller "calmind/controller/faq"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Routes FAQ publik
+func FaqRoutes(e *echo.Echo, faqController *controller.FaqController) {
+	e.GET("/customer-service/faqs", faqController.GetCategories) // FAQ dikelompokkan per kategori
+}
+
+// Routes pengelolaan FAQ untuk Admin
+func AdminFaqRoutes(e *echo.Group, faqController *controller.FaqController) {
+	e.GET("/faq-categories", faqController.GetCategories)
+	e.POST("/faq-categories", faqController.CreateCategory)
+	e.PUT("/faq-categories/:id", faqController.UpdateCategory)
+	e.DELETE("/faq-categories/:id", faqController.DeleteCategory) // Hanya kategori yang sudah kosong
+	e.GET("/faqs", faqController.GetFaqs)
+	e.POST("/faqs", faqController.CreateFaq)
+	e.PUT("/faqs/:id", faqController.UpdateFaq)
+	e.DELETE("/faqs/:id", faqController.DeleteFaq)
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	controller "calmind/controller/jadwal"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Routes jadwal untuk Dokter
+func DoctorScheduleRoutes(e *echo.Group, jadwalController *controller.JadwalController) {
+	e.GET("/schedule", jadwalController.GetSchedule)                       // Melihat jadwal mingguan dan pengecualian
+	e.POST("/schedule", jadwalController.AddSchedule)                      // Menambahkan jadwal mingguan
+	e.PUT("/schedule/:id", jadwalController.UpdateSchedule)                // Memperbarui jadwal mingguan
+	e.DELETE("/schedule/:id", jadwalController.DeleteSchedule)             // Menghapus jadwal mingguan
+	e.POST("/schedule/exceptions", jadwalController.AddException)          // Menambahkan libur atau jam tambahan
+	e.DELETE("/schedule/exceptions/:id", jadwalController.DeleteException) // Menghapus pengecualian jadwal
+}
+
+// Routes jadwal untuk User
+func UserScheduleRoutes(e *echo.Group, jadwalController *controller.JadwalController) {
+	e.GET("/doctors/:id/slots", jadwalController.GetDoctorSlots) // Melihat slot dokter yang masih tersedia
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	controller "calmind/controller/refund"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Routes pembatalan dan refund untuk User
+func UserRefundRoutes(e *echo.Group, refundController *controller.RefundController) {
+	e.POST("/consultations/:id/cancel", refundController.CancelByUser) // Membatalkan konsultasi sebelum sesi dimulai
+	e.GET("/refunds", refundController.GetUserRefunds)                 // Melihat riwayat refund
+}
+
+// Routes pembatalan dan refund untuk Admin
+func AdminRefundRoutes(e *echo.Group, refundController *controller.RefundController) {
+	e.POST("/consultations/:id/cancel", refundController.CancelByAdmin) // Membatalkan konsultasi atas nama dokter (refund penuh)
+	e.GET("/refunds", refundController.GetAllRefunds)                   // Melihat semua refund
+	e.POST("/refunds/:id/retry", refundController.RetryRefund)          // Memproses ulang refund yang gagal
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	controller "calmind/controller/review"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Routes ulasan dokter untuk User
+func UserReviewRoutes(e *echo.Group, reviewController *controller.ReviewController) {
+	e.POST("/consultations/:id/review", reviewController.CreateReview) // Memberi ulasan untuk konsultasi yang sudah selesai
+	e.GET("/doctors/:id/reviews", reviewController.GetDoctorReviews)   // Daftar ulasan dokter
+}
+
+// Routes ulasan untuk Dokter
+func DoctorReviewRoutes(e *echo.Group, reviewController *controller.ReviewController) {
+	e.GET("/reviews", reviewController.GetMyReviews)          // Ulasan dari pasien
+	e.PUT("/reviews/:id/reply", reviewController.ReplyReview) // Membalas ulasan
+}
+
+// Routes moderasi ulasan untuk Admin
+func AdminReviewRoutes(e *echo.Group, reviewController *controller.ReviewController) {
+	e.GET("/reviews", reviewController.GetReviews)         // Semua ulasan
+	e.PUT("/reviews/:id", reviewController.ModerateReview) // Menyembunyikan atau menampilkan ulasan
+}
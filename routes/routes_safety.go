@@ -0,0 +1,14 @@
+package routes
+
+import (
+	controller "calmind/controller/safety"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Routes peringatan keamanan chatbot untuk Admin
+func AdminSafetyRoutes(e *echo.Group, safetyController *controller.SafetyAlertController) {
+	e.GET("/safety-alerts", safetyController.GetAlerts)       // Daftar peringatan risiko bunuh diri / menyakiti diri
+	e.GET("/safety-alerts/:id", safetyController.GetAlert)    // Detail peringatan beserta potongan percakapan
+	e.PUT("/safety-alerts/:id", safetyController.UpdateAlert) // Menandai peringatan ditangani atau selesai
+}
@@ -0,0 +1,65 @@
+package service
+
+import "sync"
+
+// Koneksi chat yang terdaftar di hub
+type ChatClient struct {
+	Role string
+	ID   int
+	Send chan []byte
+}
+
+// ChatHub menyimpan koneksi WebSocket aktif per konsultasi dan meneruskan pesan ke semua peserta.
+// Hub berada di memori sehingga hanya menjangkau koneksi pada instance yang sama.
+type ChatHub interface {
+	Join(consultationID int, client *ChatClient)
+	Leave(consultationID int, client *ChatClient)
+	Broadcast(consultationID int, payload []byte)
+}
+
+type chatHub struct {
+	mu    sync.RWMutex
+	rooms map[int]map[*ChatClient]struct{}
+}
+
+func NewChatHub() ChatHub {
+	return &chatHub{rooms: map[int]map[*ChatClient]struct{}{}}
+}
+
+func (h *chatHub) Join(consultationID int, client *ChatClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.rooms[consultationID] == nil {
+		h.rooms[consultationID] = map[*ChatClient]struct{}{}
+	}
+	h.rooms[consultationID][client] = struct{}{}
+}
+
+func (h *chatHub) Leave(consultationID int, client *ChatClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room := h.rooms[consultationID]
+	if _, ok := room[client]; !ok {
+		return
+	}
+	delete(room, client)
+	close(client.Send)
+	if len(room) == 0 {
+		delete(h.rooms, consultationID)
+	}
+}
+
+func (h *chatHub) Broadcast(consultationID int, payload []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.rooms[consultationID] {
+		select {
+		case client.Send <- payload:
+		default:
+			// Klien yang terlalu lambat dilewati agar tidak menahan peserta lain
+		}
+	}
+}
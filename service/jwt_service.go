@@ -2,6 +2,10 @@ package service
 
 import (
 	"calmind/config"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -16,8 +20,30 @@ type JwtCustomClaims struct {
 	jwt.RegisteredClaims
 }
 
+// Masa berlaku access token dan refresh token
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Claims untuk tautan reset password, hanya berlaku selama hash password belum berubah
+type PasswordResetClaims struct {
+	UserID      int    `json:"user_id"`
+	Email       string `json:"email"`
+	Role        string `json:"role"`
+	Fingerprint string `json:"fingerprint"`
+	jwt.RegisteredClaims
+}
+
+// Masa berlaku tautan dan kode OTP reset password
+const PasswordResetTTL = 15 * time.Minute
+
+var ErrInvalidResetToken = errors.New("tautan reset password tidak valid atau sudah kedaluwarsa")
+
 type JWTService interface {
 	GenerateJWT(email string, userID int, role string, isVerified bool) (string, error)
+	GeneratePasswordResetToken(email string, userID int, role string, passwordHash string) (string, error)
+	ParsePasswordResetToken(token string, passwordHash string) (*PasswordResetClaims, error)
 }
 
 type jwtService struct {
@@ -38,7 +64,9 @@ func (s *jwtService) GenerateJWT(email string, id int, role string, isVerified b
 		Role:       role,
 		IsVerified: isVerified,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(72 * time.Hour)),
+			ID:        RandomToken(16),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 	// Membuat token dengan claims
@@ -50,3 +78,65 @@ func (s *jwtService) GenerateJWT(email string, id int, role string, isVerified b
 	}
 	return t, nil
 }
+
+func (s *jwtService) GeneratePasswordResetToken(email string, id int, role string, passwordHash string) (string, error) {
+	if s.config.SecretKey == "" {
+		return "", errors.New("secret key is required")
+	}
+
+	claims := &PasswordResetClaims{
+		UserID:      id,
+		Email:       email,
+		Role:        role,
+		Fingerprint: passwordFingerprint(passwordHash),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(PasswordResetTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.passwordResetKey())
+}
+
+// Token ditolak jika password sudah diganti sejak token dibuat, sehingga tautan hanya bisa dipakai sekali
+func (s *jwtService) ParsePasswordResetToken(tokenString string, passwordHash string) (*PasswordResetClaims, error) {
+	claims := &PasswordResetClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.passwordResetKey(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidResetToken
+	}
+
+	expected := passwordFingerprint(passwordHash)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(claims.Fingerprint)) != 1 {
+		return nil, ErrInvalidResetToken
+	}
+	return claims, nil
+}
+
+// Kunci terpisah agar token reset tidak bisa dipakai sebagai token akses
+func (s *jwtService) passwordResetKey() []byte {
+	return []byte(s.config.SecretKey + ":password_reset")
+}
+
+func passwordFingerprint(passwordHash string) string {
+	hash := sha256.Sum256([]byte(passwordHash))
+	return hex.EncodeToString(hash[:16])
+}
+
+// RandomToken menghasilkan string hex acak sepanjang 2*size karakter (jti, refresh token)
+func RandomToken(size int) string {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		panic("crypto/rand tidak tersedia: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// HashRefreshToken menghasilkan hash yang disimpan di database sebagai pengganti refresh token asli
+func HashRefreshToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -39,7 +39,8 @@ func TestGenerateJWT_Success(t *testing.T) {
 	assert.Equal(t, userID, claims.UserID)
 	assert.Equal(t, role, claims.Role)
 	assert.Equal(t, isVerified, claims.IsVerified)
-	assert.WithinDuration(t, time.Now().Add(72*time.Hour), claims.ExpiresAt.Time, 1*time.Second)
+	assert.WithinDuration(t, time.Now().Add(AccessTokenTTL), claims.ExpiresAt.Time, 1*time.Second)
+	assert.NotEmpty(t, claims.ID, "jti dibutuhkan untuk mencabut token saat logout")
 }
 
 func TestGenerateJWT_EmptySecretKey(t *testing.T) {
@@ -83,10 +84,39 @@ func TestGenerateJWT_SignedStringError(t *testing.T) {
 	// Act
 	// Override `SignedString` untuk menghasilkan error
 	jwtSigningMethod := token.Method.(*jwt.SigningMethodHMAC)
+	originalHash := jwtSigningMethod.Hash
 	jwtSigningMethod.Hash = 0 // Force error by using invalid hash
+	// Signing method bersifat global, kembalikan agar tidak memengaruhi test lain
+	t.Cleanup(func() { jwtSigningMethod.Hash = originalHash })
 	tok, err := jwtService.GenerateJWT("user@example.com", 1, "user", true)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Empty(t, tok)
 }
+
+func TestPasswordResetToken(t *testing.T) {
+	cfg := &config.JWTConfig{SecretKey: "test-secret-key"}
+	jwtService := NewJWTService(cfg)
+
+	token, err := jwtService.GeneratePasswordResetToken("user@example.com", 1, "user", "old-hash")
+	assert.NoError(t, err)
+
+	claims, err := jwtService.ParsePasswordResetToken(token, "old-hash")
+	assert.NoError(t, err)
+	if !assert.NotNil(t, claims) {
+		return
+	}
+	assert.Equal(t, "user@example.com", claims.Email)
+	assert.Equal(t, "user", claims.Role)
+
+	// Setelah password diganti, tautan yang sama tidak bisa dipakai lagi
+	_, err = jwtService.ParsePasswordResetToken(token, "new-hash")
+	assert.ErrorIs(t, err, ErrInvalidResetToken)
+
+	// Token reset tidak valid sebagai token akses
+	_, err = jwt.ParseWithClaims(token, &JwtCustomClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(cfg.SecretKey), nil
+	})
+	assert.Error(t, err)
+}
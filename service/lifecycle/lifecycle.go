@@ -0,0 +1,80 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Status konsultasi
+const (
+	StatusPendingPayment = "pending_payment"
+	StatusPaid           = "paid"
+	StatusApproved       = "approved"
+	StatusInSession      = "in_session"
+	StatusCompleted      = "completed"
+	StatusCancelled      = "cancelled"
+	StatusRefunded       = "refunded"
+	StatusExpired        = "expired"
+
+	// Status lama sebelum state machine, diperlakukan sama dengan pending_payment
+	LegacyStatusPending = "pending"
+)
+
+// Peran pelaku perubahan status yang dicatat di riwayat
+const (
+	ActorUser   = "user"
+	ActorDoctor = "doctor"
+	ActorAdmin  = "admin"
+	ActorSystem = "system"
+)
+
+var ErrIllegalTransition = errors.New("perubahan status konsultasi tidak diizinkan")
+
+// Daftar perpindahan status yang diizinkan
+var transitions = map[string][]string{
+	StatusPendingPayment: {StatusPaid, StatusCancelled, StatusExpired},
+	StatusPaid:           {StatusApproved, StatusCancelled, StatusRefunded},
+	StatusApproved:       {StatusInSession, StatusCompleted, StatusCancelled, StatusRefunded, StatusExpired},
+	StatusInSession:      {StatusCompleted, StatusExpired},
+	StatusCancelled:      {StatusRefunded},
+}
+
+// Mengubah status lama ke status yang dikenal state machine
+func Normalize(status string) string {
+	if status == LegacyStatusPending || status == "" {
+		return StatusPendingPayment
+	}
+	return status
+}
+
+// Mengecek apakah status dikenal
+func IsValid(status string) bool {
+	switch Normalize(status) {
+	case StatusPendingPayment, StatusPaid, StatusApproved, StatusInSession,
+		StatusCompleted, StatusCancelled, StatusRefunded, StatusExpired:
+		return true
+	}
+	return false
+}
+
+// Mengecek apakah status tidak bisa berpindah lagi
+func IsTerminal(status string) bool {
+	return len(transitions[Normalize(status)]) == 0
+}
+
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[Normalize(from)] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Memvalidasi perpindahan status, mengembalikan ErrIllegalTransition jika tidak diizinkan
+func Transition(from, to string) error {
+	if !IsValid(to) || !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, Normalize(from), to)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransition_Allowed(t *testing.T) {
+	assert.NoError(t, Transition(StatusPendingPayment, StatusPaid))
+	assert.NoError(t, Transition(StatusPaid, StatusApproved))
+	assert.NoError(t, Transition(StatusApproved, StatusInSession))
+	assert.NoError(t, Transition(StatusInSession, StatusCompleted))
+	assert.NoError(t, Transition(StatusCancelled, StatusRefunded))
+}
+
+func TestTransition_Illegal(t *testing.T) {
+	err := Transition(StatusPendingPayment, StatusApproved)
+	assert.True(t, errors.Is(err, ErrIllegalTransition), "pending_payment tidak boleh langsung approved")
+
+	assert.Error(t, Transition(StatusCompleted, StatusInSession))
+	assert.Error(t, Transition(StatusExpired, StatusApproved))
+	assert.Error(t, Transition(StatusRefunded, StatusCancelled))
+	assert.Error(t, Transition(StatusPaid, "unknown"))
+}
+
+func TestNormalize_LegacyPending(t *testing.T) {
+	assert.Equal(t, StatusPendingPayment, Normalize(LegacyStatusPending))
+	assert.NoError(t, Transition(LegacyStatusPending, StatusPaid))
+	assert.True(t, IsTerminal(StatusCompleted))
+	assert.False(t, IsTerminal(StatusApproved))
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"calmind/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Penyedia untuk server yang mengikuti API chat completions OpenAI (vLLM, Ollama, LM Studio, dan sejenisnya)
+type OpenAICompatibleProvider struct {
+	Config     *config.LLMConfig
+	HTTPClient *http.Client
+}
+
+func NewOpenAICompatibleProvider(cfg *config.LLMConfig) (LLMProvider, error) {
+	if cfg.BaseURL == "" {
+		return nil, errors.New("LLM_BASE_URL is required for the openai provider")
+	}
+	if cfg.Model == "" {
+		return nil, errors.New("LLM_MODEL is required for the openai provider")
+	}
+	return &OpenAICompatibleProvider{
+		Config:     cfg,
+		HTTPClient: &http.Client{Timeout: 5 * time.Minute}, // Mencakup durasi stream, permintaan dibatasi juga oleh ctx
+	}, nil
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature float32         `json:"temperature"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+		Delta   openAIMessage `json:"delta"` // Diisi pada mode stream
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *OpenAICompatibleProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	resp, err := p.send(ctx, prompt, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid LLM response (%d): %v", resp.StatusCode, err)
+	}
+	if len(result.Choices) == 0 || result.Choices[0].Message.Content == "" {
+		return "", ErrEmptyLLMResponse
+	}
+	return result.Choices[0].Message.Content, nil
+}
+
+// Membaca jawaban dalam format server-sent events ("data: {...}" diakhiri "data: [DONE]")
+func (p *OpenAICompatibleProvider) Stream(ctx context.Context, prompt string, onChunk func(chunk string) error) (string, error) {
+	resp, err := p.send(ctx, prompt, true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var answer strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var event openAIResponse
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return "", fmt.Errorf("invalid LLM stream event: %v", err)
+		}
+		if len(event.Choices) == 0 || event.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		chunk := event.Choices[0].Delta.Content
+		answer.WriteString(chunk)
+		if err := onChunk(chunk); err != nil {
+			return "", err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	if answer.Len() == 0 {
+		return "", ErrEmptyLLMResponse
+	}
+	return answer.String(), nil
+}
+
+func (p *OpenAICompatibleProvider) send(ctx context.Context, prompt string, stream bool) (*http.Response, error) {
+	body, err := json.Marshal(openAIRequest{
+		Model:       p.Config.Model,
+		Messages:    []openAIMessage{{Role: "user", Content: prompt}},
+		Temperature: p.Config.Temperature,
+		MaxTokens:   p.Config.MaxTokens,
+		Stream:      stream,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimRight(p.Config.BaseURL, "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.Config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Config.APIKey)
+	}
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		var result openAIResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err == nil && result.Error != nil {
+			return nil, fmt.Errorf("LLM error (%d): %s", resp.StatusCode, result.Error.Message)
+		}
+		return nil, fmt.Errorf("LLM error (%d)", resp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"calmind/config"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// Model tidak mengembalikan teks (misalnya diblokir filter keamanan penyedia)
+var ErrEmptyLLMResponse = errors.New("model tidak mengembalikan jawaban")
+
+// Penyedia model bahasa yang dipakai chatbot user dan dokter
+type LLMProvider interface {
+	Generate(ctx context.Context, prompt string) (string, error)
+	// Mengirim potongan jawaban ke onChunk selama model menghasilkan teks, lalu mengembalikan jawaban lengkap.
+	// Membatalkan ctx (misalnya klien terputus) menghentikan permintaan ke model.
+	Stream(ctx context.Context, prompt string, onChunk func(chunk string) error) (string, error)
+}
+
+// Memilih penyedia berdasarkan LLM_PROVIDER
+func NewLLMProvider(cfg *config.LLMConfig) (LLMProvider, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", "gemini":
+		return NewGeminiProvider(cfg)
+	case "openai":
+		return NewOpenAICompatibleProvider(cfg)
+	case "fake":
+		return &FakeLLMProvider{}, nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", cfg.Provider)
+	}
+}
+
+type GeminiProvider struct {
+	Client *genai.Client
+	Config *config.LLMConfig
+}
+
+// Client dibuat sekali dan dipakai ulang untuk setiap permintaan
+func NewGeminiProvider(cfg *config.LLMConfig) (LLMProvider, error) {
+	client, err := genai.NewClient(context.Background(), option.WithAPIKey(cfg.APIKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %v", err)
+	}
+	return &GeminiProvider{Client: client, Config: cfg}, nil
+}
+
+func (p *GeminiProvider) model() *genai.GenerativeModel {
+	modelName := p.Config.Model
+	if modelName == "" {
+		modelName = "gemini-pro"
+	}
+
+	modelAI := p.Client.GenerativeModel(modelName)
+	modelAI.SetTemperature(p.Config.Temperature)
+	if p.Config.MaxTokens > 0 {
+		modelAI.SetMaxOutputTokens(int32(p.Config.MaxTokens))
+	}
+	return modelAI
+}
+
+func (p *GeminiProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	resp, err := p.model().GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return "", err
+	}
+
+	answer := geminiText(resp)
+	if answer == "" {
+		return "", ErrEmptyLLMResponse
+	}
+	return answer, nil
+}
+
+func (p *GeminiProvider) Stream(ctx context.Context, prompt string, onChunk func(chunk string) error) (string, error) {
+	iter := p.model().GenerateContentStream(ctx, genai.Text(prompt))
+
+	var answer strings.Builder
+	for {
+		resp, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		chunk := geminiText(resp)
+		if chunk == "" {
+			continue
+		}
+		answer.WriteString(chunk)
+		if err := onChunk(chunk); err != nil {
+			return "", err
+		}
+	}
+
+	if answer.Len() == 0 {
+		return "", ErrEmptyLLMResponse
+	}
+	return answer.String(), nil
+}
+
+func geminiText(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return ""
+	}
+
+	var text strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if t, ok := part.(genai.Text); ok {
+			text.WriteString(string(t))
+		}
+	}
+	return text.String()
+}
+
+// Penyedia palsu yang deterministik untuk pengujian dan pengembangan lokal tanpa API key
+type FakeLLMProvider struct {
+	Reply   string // Jawaban tetap, kosong berarti menggema potongan prompt
+	Err     error
+	Prompts []string // Prompt yang diterima, untuk diperiksa di test
+}
+
+func (p *FakeLLMProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	p.Prompts = append(p.Prompts, prompt)
+	if p.Err != nil {
+		return "", p.Err
+	}
+	if p.Reply != "" {
+		return p.Reply, nil
+	}
+
+	lines := strings.Split(strings.TrimSpace(prompt), "\n")
+	return "Jawaban untuk: " + lines[len(lines)-1], nil
+}
+
+// Mengirim jawaban per kata agar alur streaming bisa diuji
+func (p *FakeLLMProvider) Stream(ctx context.Context, prompt string, onChunk func(chunk string) error) (string, error) {
+	answer, err := p.Generate(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	words := strings.SplitAfter(answer, " ")
+	for _, word := range words {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if err := onChunk(word); err != nil {
+			return "", err
+		}
+	}
+	return answer, nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"calmind/config"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAICompatibleProvider_Generate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer local-key", r.Header.Get("Authorization"))
+
+		var body openAIRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "llama3", body.Model)
+		assert.Equal(t, 256, body.MaxTokens)
+		assert.Equal(t, "Halo", body.Messages[0].Content)
+
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"Halo juga"}}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewLLMProvider(&config.LLMConfig{
+		Provider:  "openai",
+		Model:     "llama3",
+		MaxTokens: 256,
+		BaseURL:   server.URL + "/v1/",
+		APIKey:    "local-key",
+	})
+	assert.NoError(t, err)
+
+	answer, err := provider.Generate(context.Background(), "Halo")
+	assert.NoError(t, err)
+	assert.Equal(t, "Halo juga", answer)
+}
+
+func TestOpenAICompatibleProvider_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"rate limited"}}`))
+	}))
+	defer server.Close()
+
+	provider, _ := NewOpenAICompatibleProvider(&config.LLMConfig{Model: "llama3", BaseURL: server.URL})
+	_, err := provider.Generate(context.Background(), "Halo")
+	assert.EqualError(t, err, "LLM error (429): rate limited")
+}
+
+func TestNewLLMProvider_Unknown(t *testing.T) {
+	_, err := NewLLMProvider(&config.LLMConfig{Provider: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestOpenAICompatibleProvider_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body openAIRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.True(t, body.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n"))
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"Halo\"}}]}\n\n"))
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\" juga\"}}]}\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	provider, _ := NewOpenAICompatibleProvider(&config.LLMConfig{Model: "llama3", BaseURL: server.URL})
+
+	var chunks []string
+	answer, err := provider.Stream(context.Background(), "Halo", func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Halo", " juga"}, chunks)
+	assert.Equal(t, "Halo juga", answer)
+}
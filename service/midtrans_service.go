@@ -0,0 +1,79 @@
+package service
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+)
+
+// Transaksi belum pernah dibuat di Midtrans (misalnya user belum membuka halaman pembayaran)
+var ErrMidtransTransactionNotFound = errors.New("transaksi tidak ditemukan di Midtrans")
+
+type MidtransService interface {
+	VerifySignature(orderID, statusCode, grossAmount, signatureKey string) bool
+	CancelTransaction(orderID string) error
+	RefundTransaction(orderID, refundKey string, amount int64, reason string) error
+}
+
+type MidtransServiceImpl struct {
+	ServerKey string
+}
+
+func NewMidtransService(serverKey string) MidtransService {
+	return &MidtransServiceImpl{ServerKey: serverKey}
+}
+
+// GenerateMidtransSignature menghitung SHA512(order_id+status_code+gross_amount+server_key) sesuai dokumentasi Midtrans
+func GenerateMidtransSignature(orderID, statusCode, grossAmount, serverKey string) string {
+	hash := sha512.Sum512([]byte(orderID + statusCode + grossAmount + serverKey))
+	return hex.EncodeToString(hash[:])
+}
+
+func (s *MidtransServiceImpl) VerifySignature(orderID, statusCode, grossAmount, signatureKey string) bool {
+	if s.ServerKey == "" || signatureKey == "" {
+		return false
+	}
+
+	expected := GenerateMidtransSignature(orderID, statusCode, grossAmount, s.ServerKey)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(signatureKey))) == 1
+}
+
+// Membatalkan transaksi yang belum dibayar
+func (s *MidtransServiceImpl) CancelTransaction(orderID string) error {
+	_, err := s.coreClient().CancelTransaction(orderID)
+	return midtransError(err)
+}
+
+// Mengembalikan dana transaksi yang sudah dibayar, refundKey membuat permintaan aman untuk diulang
+func (s *MidtransServiceImpl) RefundTransaction(orderID, refundKey string, amount int64, reason string) error {
+	_, err := s.coreClient().RefundTransaction(orderID, &coreapi.RefundReq{
+		RefundKey: refundKey,
+		Amount:    amount,
+		Reason:    reason,
+	})
+	return midtransError(err)
+}
+
+func (s *MidtransServiceImpl) coreClient() *coreapi.Client {
+	client := &coreapi.Client{}
+	client.New(s.ServerKey, midtrans.Sandbox)
+	return client
+}
+
+// Mengubah *midtrans.Error menjadi error biasa (nil pointer tidak boleh dikembalikan sebagai error)
+func midtransError(err *midtrans.Error) error {
+	if err == nil {
+		return nil
+	}
+	if err.GetStatusCode() == http.StatusNotFound {
+		return ErrMidtransTransactionNotFound
+	}
+	return fmt.Errorf("midtrans error (%d): %s", err.GetStatusCode(), err.GetMessage())
+}
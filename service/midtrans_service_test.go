@@ -0,0 +1,36 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySignature_Valid(t *testing.T) {
+	midtransService := NewMidtransService("server-key")
+	signature := GenerateMidtransSignature("consultation-1-jj", "200", "100000.00", "server-key")
+
+	assert.Len(t, signature, 128, "SHA512 hex harus terdiri dari 128 karakter")
+	assert.True(t, midtransService.VerifySignature("consultation-1-jj", "200", "100000.00", signature))
+	assert.True(t, midtransService.VerifySignature("consultation-1-jj", "200", "100000.00", strings.ToUpper(signature)))
+}
+
+func TestVerifySignature_Tampered(t *testing.T) {
+	midtransService := NewMidtransService("server-key")
+	signature := GenerateMidtransSignature("consultation-1-jj", "200", "100000.00", "server-key")
+
+	// Nominal diubah oleh pihak lain
+	assert.False(t, midtransService.VerifySignature("consultation-1-jj", "200", "1000.00", signature))
+	// Signature dibuat dengan server key yang berbeda
+	forged := GenerateMidtransSignature("consultation-1-jj", "200", "100000.00", "wrong-key")
+	assert.False(t, midtransService.VerifySignature("consultation-1-jj", "200", "100000.00", forged))
+	assert.False(t, midtransService.VerifySignature("consultation-1-jj", "200", "100000.00", ""))
+}
+
+func TestVerifySignature_EmptyServerKey(t *testing.T) {
+	midtransService := NewMidtransService("")
+	signature := GenerateMidtransSignature("consultation-1-jj", "200", "100000.00", "")
+
+	assert.False(t, midtransService.VerifySignature("consultation-1-jj", "200", "100000.00", signature))
+}
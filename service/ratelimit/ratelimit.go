@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Pembatas jumlah permintaan per kunci (misalnya IP) dalam jendela waktu tetap. Disimpan di memori,
+// jadi batasnya berlaku per instance server.
+type Limiter struct {
+	Limit  int
+	Window time.Duration
+	Now    func() time.Time
+
+	mu      sync.Mutex
+	windows map[string]*window
+	pruned  time.Time
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{Limit: limit, Window: window, Now: time.Now}
+}
+
+// Mencatat satu permintaan untuk key, false jika key sudah mencapai batas pada jendela saat ini
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.Now()
+	if l.windows == nil {
+		l.windows = map[string]*window{}
+	}
+	// Kunci yang jendelanya sudah lewat dibuang sesekali agar map tidak terus membesar
+	if now.Sub(l.pruned) >= l.Window {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= l.Window {
+				delete(l.windows, k)
+			}
+		}
+		l.pruned = now
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.Window {
+		l.windows[key] = &window{start: now, count: 1}
+		return true
+	}
+	if w.count >= l.Limit {
+		return false
+	}
+	w.count++
+	return true
+}
@@ -0,0 +1,25 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllow_PerKeyWindow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	limiter := New(2, time.Minute)
+	limiter.Now = func() time.Time { return now }
+
+	assert.True(t, limiter.Allow("10.0.0.1"))
+	assert.True(t, limiter.Allow("10.0.0.1"))
+	assert.False(t, limiter.Allow("10.0.0.1"))
+
+	// Kunci lain punya kuota sendiri
+	assert.True(t, limiter.Allow("10.0.0.2"))
+
+	// Jendela baru mengembalikan kuota
+	now = now.Add(time.Minute)
+	assert.True(t, limiter.Allow("10.0.0.1"))
+}
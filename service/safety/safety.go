@@ -0,0 +1,168 @@
+package safety
+
+import (
+	"calmind/service"
+	"context"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// Tingkat risiko menyakiti diri
+const (
+	RiskNone   = "none"
+	RiskMedium = "medium" // Tanda putus asa tanpa niat yang jelas
+	RiskHigh   = "high"   // Niat atau rencana bunuh diri / menyakiti diri
+)
+
+// Asal penilaian
+const (
+	SourceRule  = "rule"
+	SourceModel = "model"
+)
+
+// Jawaban krisis yang sudah ditinjau, dikirim tanpa melalui model
+const CrisisResponse = "Terima kasih sudah mau bercerita. Apa yang kamu rasakan sekarang terdengar sangat berat, dan kamu tidak harus menghadapinya sendirian. " +
+	"Jika kamu berpikir untuk menyakiti diri atau mengakhiri hidup, segera hubungi layanan kesehatan jiwa Kemenkes di 119 ekstensi 8, " +
+	"atau nomor darurat 112 jika kamu dalam bahaya saat ini. " +
+	"Kamu juga bisa menghubungi orang yang kamu percaya atau datang ke IGD rumah sakit terdekat. " +
+	"Psikolog dan psikiater di Calmind juga siap membantu melalui fitur konsultasi."
+
+// Catatan tambahan untuk pesan berisiko sedang
+const SupportNote = " Jika perasaan ini terasa terlalu berat, kamu bisa menghubungi layanan kesehatan jiwa di 119 ekstensi 8 atau berkonsultasi dengan psikolog di Calmind."
+
+type Assessment struct {
+	Level   string   `json:"level"`
+	Source  string   `json:"source"`
+	Matches []string `json:"matches"`
+}
+
+func (a Assessment) Flagged() bool {
+	return a.Level != RiskNone
+}
+
+type Classifier interface {
+	// Menilai pesan user dengan aturan, lalu model jika tersedia
+	ClassifyMessage(ctx context.Context, text string) Assessment
+	// Memeriksa jawaban model yang berisi cara menyakiti diri atau dorongan untuk melakukannya
+	CheckResponse(text string) Assessment
+}
+
+type rule struct {
+	Level   string
+	Pattern *regexp.Regexp
+}
+
+var messageRules = []rule{
+	// Niat bunuh diri
+	{RiskHigh, regexp.MustCompile(`\b(bunuh|membunuh)\s*diri\b`)},
+	{RiskHigh, regexp.MustCompile(`\bbundir\b`)},
+	{RiskHigh, regexp.MustCompile(`\b(ingin|ingn|mau|pengen|pingin|kepingin|rasanya)\s+(aku\s+|saya\s+)?mati+\b`)},
+	{RiskHigh, regexp.MustCompile(`\bmengakhiri\s+(hidup|nyawa)\b`)},
+	{RiskHigh, regexp.MustCompile(`\b(tidak|tak|gak|ga|nggak|enggak|ngga)\s+(mau|ingin|pengen|sanggup)\s+hidup\s+lagi\b`)},
+	{RiskHigh, regexp.MustCompile(`\b(gantung|menggantung)\s+diri\b`)},
+	{RiskHigh, regexp.MustCompile(`\b(minum|menelan)\s+(racun|obat\s+sebanyak|semua\s+obat)\b`)},
+	{RiskHigh, regexp.MustCompile(`\b(lompat|loncat)\s+dari\s+(gedung|jembatan|lantai)\b`)},
+	{RiskHigh, regexp.MustCompile(`\b(suicide|suicidal|kill\s+myself|end\s+my\s+life|want\s+to\s+die)\b`)},
+
+	// Menyakiti diri
+	{RiskHigh, regexp.MustCompile(`\b(melukai|menyakiti|nyakitin|lukai|sakiti)\s+diri\b`)},
+	{RiskHigh, regexp.MustCompile(`\b(menyayat|sayat|silet)\s+(tangan|lengan|nadi|kulit)\b`)},
+	{RiskHigh, regexp.MustCompile(`\b(self\s*harm|cutting\s+myself)\b`)},
+
+	// Tanda putus asa
+	{RiskMedium, regexp.MustCompile(`\b(putus\s+asa|tidak\s+ada\s+harapan|gak\s+ada\s+harapan)\b`)},
+	{RiskMedium, regexp.MustCompile(`\b(capek|lelah|cape)\s+(hidup|dengan\s+hidup)\b`)},
+	{RiskMedium, regexp.MustCompile(`\blebih\s+baik\s+(aku|saya)\s+(tidak\s+ada|menghilang|hilang)\b`)},
+	{RiskMedium, regexp.MustCompile(`\b(hidup|hidupku)\s+(tidak|gak|ga)\s+(ada\s+)?(artinya|berarti|gunanya)\b`)},
+	{RiskMedium, regexp.MustCompile(`\b(hopeless|worthless)\b`)},
+}
+
+// Jawaban empatik boleh menyebut "bunuh diri", yang ditolak adalah cara dan dorongan
+var responseRules = []rule{
+	{RiskHigh, regexp.MustCompile(`\bcara\s+(bunuh\s+diri|mengakhiri\s+hidup|melukai\s+diri|menyakiti\s+diri)\b`)},
+	{RiskHigh, regexp.MustCompile(`\b(dosis|takaran)\s+(yang\s+)?(mematikan|fatal|letal)\b`)},
+	{RiskHigh, regexp.MustCompile(`\b(sebaiknya|lebih\s+baik)\s+(kamu|anda)\s+(mati|bunuh\s+diri|mengakhiri\s+hidup)\b`)},
+	{RiskHigh, regexp.MustCompile(`\b(kamu|anda)\s+pantas\s+mati\b`)},
+	{RiskHigh, regexp.MustCompile(`\b(lethal\s+dose|how\s+to\s+kill\s+yourself)\b`)},
+}
+
+var nonLetter = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Huruf kecil, tanda baca dibuang, spasi dirapikan
+func normalize(text string) string {
+	return strings.TrimSpace(nonLetter.ReplaceAllString(strings.ToLower(text), " "))
+}
+
+type ClassifierImpl struct {
+	LLM service.LLMProvider // Opsional, nil berarti hanya memakai aturan
+}
+
+func NewClassifier(llm service.LLMProvider) Classifier {
+	return &ClassifierImpl{LLM: llm}
+}
+
+func (c *ClassifierImpl) CheckResponse(text string) Assessment {
+	return applyRules(responseRules, text)
+}
+
+func applyRules(rules []rule, text string) Assessment {
+	normalized := normalize(text)
+	assessment := Assessment{Level: RiskNone, Source: SourceRule}
+
+	for _, r := range rules {
+		match := r.Pattern.FindString(normalized)
+		if match == "" {
+			continue
+		}
+		assessment.Matches = append(assessment.Matches, match)
+		if r.Level == RiskHigh {
+			assessment.Level = RiskHigh
+		} else if assessment.Level == RiskNone {
+			assessment.Level = RiskMedium
+		}
+	}
+	return assessment
+}
+
+func (c *ClassifierImpl) ClassifyMessage(ctx context.Context, text string) Assessment {
+	assessment := applyRules(messageRules, text)
+	if assessment.Level == RiskHigh || c.LLM == nil {
+		return assessment
+	}
+
+	// Model hanya bisa menaikkan tingkat risiko, tidak menurunkan hasil aturan
+	level, err := c.modelCheck(ctx, text)
+	if err != nil {
+		log.Printf("Gagal memeriksa risiko pesan dengan model: %v", err)
+		return assessment
+	}
+	if level == RiskHigh || (level == RiskMedium && assessment.Level == RiskNone) {
+		assessment.Level = level
+		assessment.Source = SourceModel
+	}
+	return assessment
+}
+
+func (c *ClassifierImpl) modelCheck(ctx context.Context, text string) (string, error) {
+	prompt := "Anda adalah sistem penyaring keamanan untuk aplikasi kesehatan mental. " +
+		"Nilai apakah pesan berikut menunjukkan risiko bunuh diri atau menyakiti diri. " +
+		"Jawab hanya dengan satu kata: TINGGI jika ada niat, rencana, atau keinginan mati; " +
+		"SEDANG jika ada tanda putus asa tanpa niat yang jelas; AMAN jika tidak ada risiko.\n\n" +
+		"Pesan: " + text
+
+	answer, err := c.LLM.Generate(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	answer = strings.ToUpper(answer)
+	switch {
+	case strings.Contains(answer, "TINGGI"):
+		return RiskHigh, nil
+	case strings.Contains(answer, "SEDANG"):
+		return RiskMedium, nil
+	default:
+		return RiskNone, nil
+	}
+}
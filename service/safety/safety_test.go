@@ -0,0 +1,65 @@
+package safety
+
+import (
+	"calmind/service"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyMessage_Rules(t *testing.T) {
+	classifier := NewClassifier(nil)
+	ctx := context.Background()
+
+	high := []string{
+		"Aku ingin bunuh diri",
+		"rasanya pengen matiii aja",
+		"Saya nggak mau hidup lagi...",
+		"kemarin aku menyayat tangan lagi",
+		"I want to die",
+	}
+	for _, message := range high {
+		assert.Equal(t, RiskHigh, classifier.ClassifyMessage(ctx, message).Level, message)
+	}
+
+	assessment := classifier.ClassifyMessage(ctx, "Aku capek hidup, rasanya putus asa")
+	assert.Equal(t, RiskMedium, assessment.Level)
+	assert.Len(t, assessment.Matches, 2)
+
+	safe := []string{
+		"Aku susah tidur karena deadline kerja",
+		"Baterai HP saya mati terus",
+		"Bagaimana cara mengatasi cemas sebelum presentasi?",
+	}
+	for _, message := range safe {
+		assert.False(t, classifier.ClassifyMessage(ctx, message).Flagged(), message)
+	}
+}
+
+func TestClassifyMessage_ModelCheck(t *testing.T) {
+	ctx := context.Background()
+
+	// Model dapat menaikkan risiko pesan yang lolos dari aturan
+	classifier := NewClassifier(&service.FakeLLMProvider{Reply: "TINGGI"})
+	assessment := classifier.ClassifyMessage(ctx, "Aku sudah menulis surat perpisahan untuk keluargaku")
+	assert.Equal(t, RiskHigh, assessment.Level)
+	assert.Equal(t, SourceModel, assessment.Source)
+
+	// Model tidak bisa menurunkan hasil aturan
+	classifier = NewClassifier(&service.FakeLLMProvider{Reply: "AMAN"})
+	assert.Equal(t, RiskMedium, classifier.ClassifyMessage(ctx, "Aku merasa putus asa").Level)
+
+	// Model gagal, hasil aturan tetap dipakai
+	classifier = NewClassifier(&service.FakeLLMProvider{Err: errors.New("timeout")})
+	assert.Equal(t, RiskNone, classifier.ClassifyMessage(ctx, "Halo").Level)
+}
+
+func TestCheckResponse(t *testing.T) {
+	classifier := NewClassifier(nil)
+
+	// Jawaban krisis sendiri tidak boleh tertandai
+	assert.Equal(t, RiskNone, classifier.CheckResponse(CrisisResponse).Level)
+	assert.Equal(t, RiskHigh, classifier.CheckResponse("Ini cara bunuh diri yang ...").Level)
+}
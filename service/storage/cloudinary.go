@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"bytes"
+	"calmind/config"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Penyimpanan gambar di Cloudinary. Tanpa API secret upload memakai upload preset unsigned seperti sebelumnya;
+// dengan API secret upload ditandatangani sehingga public_id bisa ditentukan dan ditimpa.
+type CloudinaryStorage struct {
+	Config *config.StorageConfig
+	Client *http.Client
+	Now    func() time.Time
+}
+
+func NewCloudinaryStorage(cfg *config.StorageConfig) Storage {
+	return &CloudinaryStorage{Config: cfg, Client: &http.Client{Timeout: 30 * time.Second}, Now: time.Now}
+}
+
+type cloudinaryResponse struct {
+	SecureURL string `json:"secure_url"`
+	PublicID  string `json:"public_id"`
+	Result    string `json:"result"`
+	Error     struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (s *CloudinaryStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) (*Object, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{}
+	if s.Config.CloudinaryUploadPreset != "" {
+		params["upload_preset"] = s.Config.CloudinaryUploadPreset
+	}
+	if s.Config.CloudinaryAPISecret != "" {
+		params["public_id"] = strings.TrimSuffix(key, path.Ext(key))
+		params["overwrite"] = "true"
+		s.sign(params)
+	}
+
+	payload := &bytes.Buffer{}
+	writer := multipart.NewWriter(payload)
+	for name, value := range params {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, err
+		}
+	}
+	part, err := writer.CreateFormFile("file", path.Base(key))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.do(ctx, "upload", writer.FormDataContentType(), payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUploadFailed, err)
+	}
+	if result.SecureURL == "" || result.PublicID == "" {
+		return nil, fmt.Errorf("%w: respons Cloudinary tidak berisi secure_url", ErrUploadFailed)
+	}
+	return &Object{Key: result.PublicID, URL: result.SecureURL}, nil
+}
+
+// Menghapus gambar berdasarkan public_id, membutuhkan API key dan secret
+func (s *CloudinaryStorage) Delete(ctx context.Context, key string) error {
+	if key == "" {
+		return ErrInvalidKey
+	}
+	if s.Config.CloudinaryAPIKey == "" || s.Config.CloudinaryAPISecret == "" {
+		return fmt.Errorf("%w: CLOUDINARY_API_KEY dan CLOUDINARY_API_SECRET belum diatur", ErrDeleteFailed)
+	}
+
+	params := map[string]string{"public_id": key}
+	s.sign(params)
+	form := url.Values{}
+	for name, value := range params {
+		form.Set(name, value)
+	}
+
+	result, err := s.do(ctx, "destroy", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDeleteFailed, err)
+	}
+	if result.Result != "ok" && result.Result != "not found" {
+		return fmt.Errorf("%w: %s", ErrDeleteFailed, result.Result)
+	}
+	return nil
+}
+
+// Contoh: https://res.cloudinary.com/<cloud>/image/upload/v1700000000/folder/avatar.jpg -> folder/avatar
+var cloudinaryPathPattern = regexp.MustCompile(`/upload/(?:v\d+/)?(.+)$`)
+
+func (s *CloudinaryStorage) KeyFromURL(fileURL string) string {
+	parsed, err := url.Parse(fileURL)
+	if err != nil || !strings.HasSuffix(parsed.Host, "cloudinary.com") || !strings.Contains(parsed.Path, "/"+s.Config.CloudinaryCloudName+"/") {
+		return ""
+	}
+	match := cloudinaryPathPattern.FindStringSubmatch(parsed.Path)
+	if match == nil {
+		return ""
+	}
+	return strings.TrimSuffix(match[1], path.Ext(match[1]))
+}
+
+func (s *CloudinaryStorage) do(ctx context.Context, action, contentType string, body io.Reader) (*cloudinaryResponse, error) {
+	endpoint := fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/image/%s", s.Config.CloudinaryCloudName, action)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result cloudinaryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid Cloudinary response (status %d): %v", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Cloudinary status %d: %s", resp.StatusCode, result.Error.Message)
+	}
+	return &result, nil
+}
+
+// Tanda tangan Cloudinary: SHA-1 dari parameter terurut "a=1&b=2" ditambah API secret
+func (s *CloudinaryStorage) sign(params map[string]string) {
+	params["timestamp"] = strconv.FormatInt(s.Now().Unix(), 10)
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+params[name])
+	}
+	sum := sha1.Sum([]byte(strings.Join(pairs, "&") + s.Config.CloudinaryAPISecret))
+
+	params["signature"] = hex.EncodeToString(sum[:])
+	params["api_key"] = s.Config.CloudinaryAPIKey
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File disimpan di disk dan disajikan oleh route static /uploads, cocok untuk pengembangan dan pengujian offline.
+// Sebagai PrivateStorage direktorinya tidak disajikan route mana pun.
+type LocalStorage struct {
+	Dir       string
+	PublicURL string
+}
+
+func NewLocalStorage(dir, publicURL string) Storage {
+	return &LocalStorage{Dir: dir, PublicURL: strings.TrimRight(publicURL, "/")}
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) (*Object, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	target := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUploadFailed, err)
+	}
+
+	// Ditulis ke file sementara lalu di-rename agar file lama tidak pernah terbaca setengah jadi
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUploadFailed, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("%w: %v", ErrUploadFailed, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUploadFailed, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUploadFailed, err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUploadFailed, err)
+	}
+
+	return &Object{Key: key, URL: s.PublicURL + "/" + key}, nil
+}
+
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filepath.Join(s.Dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// File yang sudah tidak ada dianggap berhasil dihapus
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.Dir, filepath.FromSlash(key))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %v", ErrDeleteFailed, err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) KeyFromURL(fileURL string) string {
+	key, ok := strings.CutPrefix(fileURL, s.PublicURL+"/")
+	if !ok {
+		return ""
+	}
+	if key, err := cleanKey(key); err == nil {
+		return key
+	}
+	return ""
+}
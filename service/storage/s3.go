@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"bytes"
+	"calmind/config"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Penyimpanan di AWS S3 atau layanan kompatibel S3, permintaan ditandatangani dengan AWS Signature V4
+type S3Storage struct {
+	Config *config.StorageConfig
+	Client *http.Client
+	Now    func() time.Time
+}
+
+func NewS3Storage(cfg *config.StorageConfig) Storage {
+	return &S3Storage{Config: cfg, Client: &http.Client{Timeout: 30 * time.Second}, Now: time.Now}
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, contentType string) (*Object, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Isi file dibaca seluruhnya karena hash payload ikut ditandatangani
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUploadFailed, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentTypeOf(key, contentType))
+	if err := s.send(req, payload, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUploadFailed, err)
+	}
+
+	return &Object{Key: key, URL: s.publicURL(key)}, nil
+}
+
+// Isi objek untuk dibaca pemanggil, yang wajib menutupnya
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(nil)
+	signV4(req, hex.EncodeToString(sum[:]), s.Config.S3AccessKey, s.Config.S3SecretKey, s.Config.S3Region, s.Now())
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("S3 status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	if err := s.send(req, nil, http.StatusNoContent, http.StatusOK, http.StatusNotFound); err != nil {
+		return fmt.Errorf("%w: %v", ErrDeleteFailed, err)
+	}
+	return nil
+}
+
+func (s *S3Storage) KeyFromURL(fileURL string) string {
+	key, ok := strings.CutPrefix(fileURL, s.publicURL(""))
+	if !ok {
+		return ""
+	}
+	if unescaped, err := url.PathUnescape(key); err == nil {
+		key = unescaped
+	}
+	if key, err := cleanKey(key); err == nil {
+		return key
+	}
+	return ""
+}
+
+func (s *S3Storage) send(req *http.Request, payload []byte, expected ...int) error {
+	sum := sha256.Sum256(payload)
+	signV4(req, hex.EncodeToString(sum[:]), s.Config.S3AccessKey, s.Config.S3SecretKey, s.Config.S3Region, s.Now())
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("S3 status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+}
+
+func (s *S3Storage) endpoint() *url.URL {
+	endpoint := s.Config.S3Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + s.Config.S3Region + ".amazonaws.com"
+	}
+	parsed, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil {
+		return &url.URL{Scheme: "https", Host: endpoint}
+	}
+	return parsed
+}
+
+// Path-style: endpoint/bucket/key, virtual-hosted: bucket.endpoint/key
+func (s *S3Storage) objectURL(key string) string {
+	endpoint := s.endpoint()
+	if s.Config.S3PathStyle {
+		return endpoint.Scheme + "://" + endpoint.Host + endpoint.Path + "/" + s.Config.S3Bucket + "/" + encodeKey(key)
+	}
+	return endpoint.Scheme + "://" + s.Config.S3Bucket + "." + endpoint.Host + endpoint.Path + "/" + encodeKey(key)
+}
+
+func (s *S3Storage) publicURL(key string) string {
+	if s.Config.S3PublicURL != "" {
+		return strings.TrimRight(s.Config.S3PublicURL, "/") + "/" + encodeKey(key)
+	}
+	return s.objectURL(key)
+}
+
+// Encode tiap segmen key sesuai RFC 3986 seperti yang diharapkan S3, "/" tetap dipertahankan
+func encodeKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// Menandatangani permintaan S3 dengan AWS Signature V4. Header yang ditandatangani: host, content-type,
+// range, dan seluruh x-amz-*.
+func signV4(req *http.Request, payloadHash, accessKey, secretKey, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalURI := req.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func canonicalQuery(values url.Values) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := []string{}
+	for _, name := range names {
+		sorted := append([]string(nil), values[name]...)
+		sort.Strings(sorted)
+		for _, value := range sorted {
+			pairs = append(pairs, encodeKey(name)+"="+strings.ReplaceAll(encodeKey(value), "/", "%2F"))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"calmind/config"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidKey   = errors.New("nama file tidak valid")
+	ErrUploadFailed = errors.New("gagal mengunggah file")
+	ErrDeleteFailed = errors.New("gagal menghapus file")
+	ErrNotFound     = errors.New("file tidak ditemukan")
+)
+
+// File yang sudah tersimpan. Key dipakai untuk menghapus, URL disimpan di database dan dikirim ke klien.
+type Object struct {
+	Key string
+	URL string
+}
+
+// Penyimpanan file yang dipakai controller dan usecase, implementasinya dipilih lewat STORAGE_DRIVER
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+	// Key dari URL yang dihasilkan penyimpanan ini, kosong jika URL bukan milik penyimpanan ini
+	KeyFromURL(fileURL string) string
+}
+
+// Penyimpanan dokumen sensitif (misalnya kredensial dokter) tanpa URL publik. Isinya hanya dibaca lewat Open
+// oleh endpoint yang sudah memeriksa hak akses.
+type PrivateStorage interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) (*Object, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Bucket S3 terpisah jika S3_PRIVATE_BUCKET diatur, selain itu direktori lokal yang tidak disajikan route static.
+// Di luar driver local direktori tersebut wajib diatur eksplisit agar dokumen tidak tersimpan di dalam container.
+func NewPrivate(cfg *config.StorageConfig) (PrivateStorage, error) {
+	if strings.ToLower(cfg.Driver) == "s3" && cfg.S3PrivateBucket != "" {
+		private := *cfg
+		private.S3Bucket = cfg.S3PrivateBucket
+		private.S3PublicURL = ""
+		return &S3Storage{Config: &private, Client: &http.Client{Timeout: 30 * time.Second}, Now: time.Now}, nil
+	}
+	if cfg.LocalPrivateDir == "" {
+		return nil, fmt.Errorf("S3_PRIVATE_BUCKET (s3 driver) or a persistent STORAGE_PRIVATE_DIR is required for private storage with %s driver", cfg.Driver)
+	}
+	return &LocalStorage{Dir: cfg.LocalPrivateDir}, nil
+}
+
+// Memilih penyimpanan berdasarkan STORAGE_DRIVER
+func New(cfg *config.StorageConfig) (Storage, error) {
+	switch strings.ToLower(cfg.Driver) {
+	case "local":
+		return NewLocalStorage(cfg.LocalDir, cfg.LocalPublicURL), nil
+	case "cloudinary":
+		if cfg.CloudinaryCloudName == "" {
+			return nil, errors.New("CLOUDINARY_CLOUD_NAME is required for cloudinary storage")
+		}
+		return NewCloudinaryStorage(cfg), nil
+	case "s3":
+		if cfg.S3Bucket == "" || cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+			return nil, errors.New("S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY are required for s3 storage")
+		}
+		return NewS3Storage(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// Menolak key kosong, absolut, atau yang keluar dari direktori penyimpanan
+func cleanKey(key string) (string, error) {
+	key = strings.TrimSpace(strings.ReplaceAll(key, "\\", "/"))
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	return path.Clean(key), nil
+}
+
+func contentTypeOf(key, contentType string) string {
+	if contentType != "" {
+		return contentType
+	}
+	if byExt := mime.TypeByExtension(path.Ext(key)); byExt != "" {
+		return byExt
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,117 @@
+package textdiff
+
+import "strings"
+
+// Jenis perubahan satu baris
+const (
+	Equal  = "equal"
+	Insert = "insert"
+	Delete = "delete"
+)
+
+// Batas sel tabel LCS; di atas ini bagian tengah yang berbeda dianggap diganti seluruhnya
+const maxCells = 4_000_000
+
+type Line struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type Diff struct {
+	Lines   []Line `json:"lines"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+}
+
+// Perbedaan per baris dari oldText ke newText berdasarkan longest common subsequence
+func Lines(oldText, newText string) Diff {
+	a, b := splitLines(oldText), splitLines(newText)
+
+	// Awalan dan akhiran yang sama tidak perlu masuk tabel LCS
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var diff Diff
+	for _, line := range a[:prefix] {
+		diff.Lines = append(diff.Lines, Line{Op: Equal, Text: line})
+	}
+	for _, line := range middle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		switch line.Op {
+		case Insert:
+			diff.Added++
+		case Delete:
+			diff.Removed++
+		}
+		diff.Lines = append(diff.Lines, line)
+	}
+	for _, line := range a[len(a)-suffix:] {
+		diff.Lines = append(diff.Lines, Line{Op: Equal, Text: line})
+	}
+	return diff
+}
+
+func middle(a, b []string) []Line {
+	lines := make([]Line, 0, len(a)+len(b))
+	if len(a)*len(b) > maxCells {
+		for _, line := range a {
+			lines = append(lines, Line{Op: Delete, Text: line})
+		}
+		for _, line := range b {
+			lines = append(lines, Line{Op: Insert, Text: line})
+		}
+		return lines
+	}
+
+	// lcs[i][j] = panjang LCS dari a[i:] dan b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Line{Op: Equal, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Op: Delete, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: Insert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, Line{Op: Delete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, Line{Op: Insert, Text: b[j]})
+	}
+	return lines
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
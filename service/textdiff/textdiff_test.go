@@ -0,0 +1,38 @@
+package textdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines_MinimalEdit(t *testing.T) {
+	diff := Lines("a\nb\nc\nd", "a\nc\nd\ne")
+
+	assert.Equal(t, []Line{
+		{Op: Equal, Text: "a"},
+		{Op: Delete, Text: "b"},
+		{Op: Equal, Text: "c"},
+		{Op: Equal, Text: "d"},
+		{Op: Insert, Text: "e"},
+	}, diff.Lines)
+	assert.Equal(t, 1, diff.Added)
+	assert.Equal(t, 1, diff.Removed)
+}
+
+func TestLines_Replacement(t *testing.T) {
+	diff := Lines("judul\nisi lama\npenutup", "judul\nisi baru\npenutup")
+
+	assert.Equal(t, []Line{
+		{Op: Equal, Text: "judul"},
+		{Op: Delete, Text: "isi lama"},
+		{Op: Insert, Text: "isi baru"},
+		{Op: Equal, Text: "penutup"},
+	}, diff.Lines)
+}
+
+func TestLines_EmptySides(t *testing.T) {
+	assert.Empty(t, Lines("", "").Lines)
+	assert.Equal(t, 2, Lines("", "x\ny").Added)
+	assert.Equal(t, 2, Lines("x\ny", "").Removed)
+}
@@ -0,0 +1,141 @@
+package textsearch
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Penanda kata yang cocok dengan kueri pada teks hasil pencarian
+const (
+	markOpen  = "<mark>"
+	markClose = "</mark>"
+)
+
+type wordSpan struct {
+	start, end int // Indeks rune
+	match      bool
+}
+
+// Kata dasar unik dari kueri, urut sesuai kemunculan
+func Terms(query string) []string {
+	seen := map[string]bool{}
+	var terms []string
+	for _, token := range Tokenize(query) {
+		if !seen[token] {
+			seen[token] = true
+			terms = append(terms, token)
+		}
+	}
+	return terms
+}
+
+// Bentuk teks yang disimpan untuk indeks FULLTEXT: kata dasar dipisah spasi
+func Normalize(text string) string {
+	return strings.Join(Tokenize(text), " ")
+}
+
+// Seluruh teks di-escape untuk HTML dengan kata yang cocok dibungkus <mark>
+func Highlight(text string, terms []string) string {
+	runes := []rune(text)
+	return render(runes, wordSpans(runes, terms), 0, len(runes))
+}
+
+// Potongan teks sepanjang kira-kira width karakter di sekitar bagian yang paling banyak memuat kata kueri,
+// dengan kata yang cocok dibungkus <mark>. Tanpa kecocokan, potongan diambil dari awal teks.
+func Snippet(text string, terms []string, width int) string {
+	runes := []rune(text)
+	spans := wordSpans(runes, terms)
+	if len(runes) <= width {
+		return render(runes, spans, 0, len(runes))
+	}
+
+	// Jendela yang diawali sebuah kecocokan dan memuat kecocokan terbanyak
+	start, best := 0, 0
+	var matches []int
+	for i, span := range spans {
+		if span.match {
+			matches = append(matches, i)
+		}
+	}
+	for i, j := 0, 0; i < len(matches); i++ {
+		for j < len(matches) && spans[matches[j]].start < spans[matches[i]].start+width {
+			j++
+		}
+		if j-i > best {
+			best = j - i
+			start = spans[matches[i]].start
+		}
+	}
+
+	// Sedikit konteks sebelum kecocokan pertama, dimulai di awal kata
+	if start -= width / 5; start < 0 {
+		start = 0
+	}
+	for _, span := range spans {
+		if span.end > start {
+			start = span.start
+			break
+		}
+	}
+	end := start + width
+	if end >= len(runes) {
+		end = len(runes)
+	} else {
+		for i := len(spans) - 1; i >= 0; i-- {
+			if spans[i].end <= end {
+				end = spans[i].end
+				break
+			}
+		}
+	}
+
+	var builder strings.Builder
+	if start > 0 {
+		builder.WriteString("…")
+	}
+	builder.WriteString(render(runes, spans, start, end))
+	if end < len(runes) {
+		builder.WriteString("…")
+	}
+	return builder.String()
+}
+
+func wordSpans(runes []rune, terms []string) []wordSpan {
+	wanted := map[string]bool{}
+	for _, term := range terms {
+		wanted[term] = true
+	}
+
+	var spans []wordSpan
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) && !unicode.IsNumber(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsNumber(runes[i])) {
+			i++
+		}
+		word := strings.ToLower(string(runes[start:i]))
+		spans = append(spans, wordSpan{start: start, end: i, match: !stopwords[word] && wanted[Stem(word)]})
+	}
+	return spans
+}
+
+func render(runes []rune, spans []wordSpan, start, end int) string {
+	var builder strings.Builder
+	position := start
+	for _, span := range spans {
+		if !span.match || span.start < start || span.end > end {
+			continue
+		}
+		builder.WriteString(html.EscapeString(string(runes[position:span.start])))
+		builder.WriteString(markOpen)
+		builder.WriteString(html.EscapeString(string(runes[span.start:span.end])))
+		builder.WriteString(markClose)
+		position = span.end
+	}
+	builder.WriteString(html.EscapeString(string(runes[position:end])))
+	return builder.String()
+}
@@ -0,0 +1,187 @@
+package textsearch
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Parameter BM25
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Kata umum bahasa Indonesia yang tidak membantu pencocokan
+var stopwords = map[string]bool{
+	"yang": true, "dan": true, "di": true, "ke": true, "dari": true, "untuk": true, "dengan": true,
+	"atau": true, "ini": true, "itu": true, "apa": true, "apakah": true, "bagaimana": true,
+	"gimana": true, "gmn": true, "saya": true, "aku": true, "anda": true, "kamu": true, "bisa": true,
+	"ada": true, "jika": true, "kalau": true, "kalo": true, "harus": true, "mau": true, "ingin": true,
+	"tolong": true, "mohon": true, "min": true, "kak": true, "halo": true, "hai": true, "pada": true,
+	"dalam": true, "sudah": true, "akan": true, "juga": true, "saja": true, "aja": true, "nya": true,
+	"kah": true, "dong": true, "pakai": true, "pake": true, "ya": true, "sih": true, "nih": true, "deh": true, "kok": true, "the": true, "a": true,
+}
+
+// Awalan dan akhiran yang dilepas, urut dari yang terpanjang
+var (
+	particleSuffixes   = []string{"lah", "kah", "tah", "pun"}
+	possessiveSuffixes = []string{"nya", "ku", "mu"}
+	derivationSuffixes = []string{"kan", "an", "i"}
+	prefixes           = []string{"meng", "meny", "peng", "peny", "mem", "men", "pem", "pen", "ber", "per", "ter", "me", "pe", "di", "ke", "se"}
+)
+
+// Panjang minimal kata dasar setelah imbuhan dilepas
+const minStemLength = 4
+
+// Memecah teks menjadi kata dasar huruf kecil tanpa tanda baca dan kata umum
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if stopwords[word] {
+			continue
+		}
+		if stem := Stem(word); stem != "" && !stopwords[stem] {
+			tokens = append(tokens, stem)
+		}
+	}
+	return tokens
+}
+
+// Stemmer ringan bahasa Indonesia: melepas partikel, kata ganti milik, akhiran, lalu awalan.
+// Tanpa kamus, jadi hasilnya tidak selalu kata baku, tetapi konsisten untuk teks dan kueri.
+func Stem(word string) string {
+	word = trimSuffix(word, particleSuffixes)
+	word = trimSuffix(word, possessiveSuffixes)
+	word = trimSuffix(word, derivationSuffixes)
+	return trimPrefix(word, prefixes)
+}
+
+func trimSuffix(word string, suffixes []string) string {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= minStemLength {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+func trimPrefix(word string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(word, prefix) && len(word)-len(prefix) >= minStemLength {
+			return strings.TrimPrefix(word, prefix)
+		}
+	}
+	return word
+}
+
+// Bagian dokumen dengan bobot, misalnya pertanyaan lebih penting daripada jawaban
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+type Document struct {
+	ID     int
+	Fields []Field
+}
+
+type Result struct {
+	ID       int     `json:"id"`
+	Score    float64 `json:"score"`    // Skor BM25, hanya untuk mengurutkan
+	Coverage float64 `json:"coverage"` // Porsi kata kueri (berbobot IDF) yang ditemukan, 0 sampai 1
+}
+
+type indexedDocument struct {
+	id     int
+	terms  map[string]float64 // Frekuensi kata berbobot
+	length float64
+}
+
+// Indeks BM25 di memori untuk kumpulan dokumen kecil (FAQ, daftar dokter, dan sejenisnya)
+type Index struct {
+	docs      []indexedDocument
+	docFreq   map[string]int
+	avgLength float64
+}
+
+func NewIndex(documents []Document) *Index {
+	index := &Index{docFreq: map[string]int{}}
+
+	var totalLength float64
+	for _, document := range documents {
+		doc := indexedDocument{id: document.ID, terms: map[string]float64{}}
+		for _, field := range document.Fields {
+			for _, token := range Tokenize(field.Text) {
+				doc.terms[token] += field.Weight
+				doc.length += field.Weight
+			}
+		}
+		for term := range doc.terms {
+			index.docFreq[term]++
+		}
+		totalLength += doc.length
+		index.docs = append(index.docs, doc)
+	}
+
+	if len(index.docs) > 0 {
+		index.avgLength = totalLength / float64(len(index.docs))
+	}
+	return index
+}
+
+// Kata yang tidak ada di indeks dihitung seperti kata langka (df = 1) agar satu kata asing tidak menenggelamkan cakupan kueri
+func (i *Index) idf(term string) float64 {
+	n := float64(len(i.docs))
+	df := float64(i.docFreq[term])
+	if df == 0 {
+		df = 1
+	}
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+// Dokumen yang memuat minimal satu kata kueri, skor tertinggi di depan. limit <= 0 berarti semua.
+func (i *Index) Search(query string, limit int) []Result {
+	seen := map[string]bool{}
+	var terms []string
+	var totalIDF float64
+	for _, token := range Tokenize(query) {
+		if seen[token] {
+			continue
+		}
+		seen[token] = true
+		terms = append(terms, token)
+		totalIDF += i.idf(token)
+	}
+	if len(terms) == 0 || len(i.docs) == 0 {
+		return nil
+	}
+
+	var results []Result
+	for _, doc := range i.docs {
+		var score, matchedIDF float64
+		for _, term := range terms {
+			tf := doc.terms[term]
+			if tf == 0 {
+				continue
+			}
+			idf := i.idf(term)
+			matchedIDF += idf
+			score += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*doc.length/i.avgLength))
+		}
+		if score > 0 {
+			results = append(results, Result{ID: doc.id, Score: score, Coverage: matchedIDF / totalIDF})
+		}
+	}
+
+	sort.SliceStable(results, func(a, c int) bool { return results[a].Score > results[c].Score })
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,52 @@
+package textsearch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"cara", "bayar", "konsultas", "gopay"}, Tokenize("Bagaimana cara pembayaran konsultasi dengan GoPay?"))
+	assert.Equal(t, []string{"daftar", "akun"}, Tokenize("Saya mau mendaftar akun"))
+	assert.Equal(t, Stem("membayar"), Stem("pembayaran"))
+	assert.Equal(t, "laku", Stem("dilakukan"))
+	assert.Empty(t, Tokenize("apa itu?"))
+}
+
+func TestSearch(t *testing.T) {
+	index := NewIndex([]Document{
+		{ID: 1, Fields: []Field{{Text: "Metode pembayaran?", Weight: 3}, {Text: "Kartu kredit, e-wallet, transfer bank", Weight: 1}}},
+		{ID: 2, Fields: []Field{{Text: "Bagaimana cara mendaftar akun?", Weight: 3}, {Text: "Klik tombol daftar pada halaman awal", Weight: 1}}},
+		{ID: 3, Fields: []Field{{Text: "Bagaimana jika lupa kata sandi?", Weight: 3}, {Text: "lupa password, reset", Weight: 2}}},
+	})
+
+	results := index.Search("saya lupa password akun", 0)
+	assert.NotEmpty(t, results)
+	assert.Equal(t, 3, results[0].ID)
+	assert.Less(t, results[0].Coverage, 1.0) // "akun" hanya ada di dokumen 2
+
+	results = index.Search("bisa bayar pakai transfer bank?", 1)
+	assert.Len(t, results, 1)
+	assert.Equal(t, 1, results[0].ID)
+	assert.Equal(t, 1.0, results[0].Coverage)
+
+	assert.Empty(t, index.Search("jadwal dokter", 0))
+}
+
+func TestHighlightAndSnippet(t *testing.T) {
+	terms := Terms("mengatasi kecemasan")
+	assert.Equal(t, []string{"atas", "cemas"}, terms)
+
+	assert.Equal(t, "Cara <mark>Mengatasi</mark> Rasa <mark>Cemas</mark> &amp; Panik", Highlight("Cara Mengatasi Rasa Cemas & Panik", terms))
+
+	text := strings.Repeat("kalimat pembuka yang panjang sekali. ", 10) + "Rasa cemas bisa diatasi dengan napas dalam. " + strings.Repeat("penutup. ", 10)
+	snippet := Snippet(text, terms, 80)
+	assert.True(t, strings.HasPrefix(snippet, "…"))
+	assert.True(t, strings.HasSuffix(snippet, "…"))
+	assert.Contains(t, snippet, "<mark>cemas</mark>")
+	assert.Contains(t, snippet, "<mark>diatasi</mark>")
+
+	assert.Equal(t, "teks pendek", Snippet("teks pendek", terms, 80))
+}
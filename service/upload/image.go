@@ -0,0 +1,268 @@
+package upload
+
+import (
+	"bytes"
+	"calmind/service/storage"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Dekoder WebP untuk image.Decode
+)
+
+// Batas jumlah piksel sebelum decode agar gambar kecil yang sangat besar dimensinya (decompression bomb) ditolak
+const maxPixels = 40_000_000
+
+const jpegQuality = 85
+
+var (
+	ErrUnsupportedType = errors.New("format file tidak didukung")
+	ErrFileTooLarge    = errors.New("ukuran file melebihi batas")
+	ErrImageTooLarge   = errors.New("dimensi gambar terlalu besar")
+	ErrCorruptImage    = errors.New("file gambar rusak atau tidak dapat dibaca")
+)
+
+// Aturan unggah untuk satu jenis gambar
+type Kind struct {
+	Name        string
+	Limits      map[string]int64 // Content type hasil deteksi isi file -> ukuran maksimal (byte)
+	MaxWidth    int
+	MaxHeight   int
+	Square      bool // Dipotong di tengah menjadi persegi sebelum diperkecil
+	ThumbWidth  int
+	ThumbHeight int
+}
+
+const mb = 1024 * 1024
+
+var (
+	// Avatar persegi 512px dengan thumbnail 128px
+	Avatar = Kind{
+		Name:        "avatar",
+		Limits:      map[string]int64{"image/jpeg": 5 * mb, "image/png": 5 * mb, "image/webp": 5 * mb},
+		MaxWidth:    512,
+		MaxHeight:   512,
+		Square:      true,
+		ThumbWidth:  128,
+		ThumbHeight: 128,
+	}
+
+	// Gambar artikel maksimal 1600x1200 dengan thumbnail 16:9 untuk daftar artikel
+	ArticleImage = Kind{
+		Name:        "article",
+		Limits:      map[string]int64{"image/jpeg": 8 * mb, "image/png": 8 * mb, "image/webp": 8 * mb},
+		MaxWidth:    1600,
+		MaxHeight:   1200,
+		ThumbWidth:  480,
+		ThumbHeight: 270,
+	}
+)
+
+// Gambar dan thumbnail yang sudah diproses dan disimpan
+type Result struct {
+	Image     storage.Object
+	Thumbnail storage.Object
+	Width     int
+	Height    int
+}
+
+// Validasi, pembersihan metadata, resize, dan pembuatan thumbnail sebelum file disimpan
+type ImagePipeline interface {
+	// baseKey tanpa ekstensi, ekstensi ditentukan dari format hasil encode
+	Process(ctx context.Context, kind Kind, baseKey string, file io.Reader) (*Result, error)
+	// Menghapus gambar beserta thumbnail-nya, URL dari penyimpanan lain diabaikan
+	Delete(ctx context.Context, fileURL string) error
+}
+
+type imagePipeline struct {
+	Storage storage.Storage
+}
+
+func NewImagePipeline(store storage.Storage) ImagePipeline {
+	return &imagePipeline{Storage: store}
+}
+
+func (p *imagePipeline) Process(ctx context.Context, kind Kind, baseKey string, file io.Reader) (*Result, error) {
+	data, err := readLimited(file, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorruptImage
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorruptImage
+	}
+
+	// Metadata EXIF tidak ikut ter-encode ulang, jadi orientasi kamera diterapkan lebih dulu
+	img := toRGBA(decoded)
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	var main *image.RGBA
+	if kind.Square {
+		main = cover(img, kind.MaxWidth, kind.MaxHeight)
+	} else {
+		main = fit(img, kind.MaxWidth, kind.MaxHeight)
+	}
+	thumb := cover(img, kind.ThumbWidth, kind.ThumbHeight)
+
+	// PNG transparan tetap PNG, selain itu JPEG agar ukuran file kecil
+	ext, encode := ".jpg", encodeJPEG
+	if format == "png" && !isOpaque(img) {
+		ext, encode = ".png", encodePNG
+	}
+
+	mainData, err := encode(main)
+	if err != nil {
+		return nil, err
+	}
+	thumbData, err := encode(thumb)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := p.Storage.Put(ctx, baseKey+ext, bytes.NewReader(mainData), contentTypes[ext])
+	if err != nil {
+		return nil, err
+	}
+	storedThumb, err := p.Storage.Put(ctx, ThumbnailKey(baseKey+ext), bytes.NewReader(thumbData), contentTypes[ext])
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := main.Bounds()
+	return &Result{Image: *stored, Thumbnail: *storedThumb, Width: bounds.Dx(), Height: bounds.Dy()}, nil
+}
+
+func (p *imagePipeline) Delete(ctx context.Context, fileURL string) error {
+	key := p.Storage.KeyFromURL(fileURL)
+	if key == "" {
+		return nil
+	}
+	if err := p.Storage.Delete(ctx, key); err != nil {
+		return err
+	}
+	return p.Storage.Delete(ctx, ThumbnailKey(key))
+}
+
+// Key thumbnail berada di samping gambar aslinya: avatars/user_1.jpg -> avatars/user_1_thumb.jpg
+func ThumbnailKey(key string) string {
+	ext := path.Ext(key)
+	return strings.TrimSuffix(key, ext) + "_thumb" + ext
+}
+
+var contentTypes = map[string]string{".jpg": "image/jpeg", ".png": "image/png"}
+
+// Membaca file sampai batas terbesar jenis tersebut, lalu memeriksa tipe isi sebenarnya dan batas ukurannya
+func readLimited(file io.Reader, kind Kind) ([]byte, error) {
+	var maxLimit int64
+	for _, limit := range kind.Limits {
+		if limit > maxLimit {
+			maxLimit = limit
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxLimit+1))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file: %v", err)
+	}
+
+	contentType := http.DetectContentType(data)
+	limit, ok := kind.Limits[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: maksimal %d MB untuk %s", ErrFileTooLarge, limit/mb, contentType)
+	}
+	return data, nil
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
+	return dst
+}
+
+// Memperkecil agar muat di dalam width x height dengan rasio tetap, gambar kecil tidak diperbesar
+func fit(src *image.RGBA, width, height int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w <= width && h <= height {
+		return src
+	}
+	scale := min(float64(width)/float64(w), float64(height)/float64(h))
+	return scaleTo(src, src.Bounds(), max(1, int(float64(w)*scale+0.5)), max(1, int(float64(h)*scale+0.5)))
+}
+
+// Memotong bagian tengah sesuai rasio width:height lalu memperkecil ke ukuran tersebut (tanpa memperbesar)
+func cover(src *image.RGBA, width, height int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	crop := image.Rect(0, 0, w, h)
+	if w*height > h*width {
+		cropW := h * width / height
+		crop = image.Rect((w-cropW)/2, 0, (w-cropW)/2+cropW, h)
+	} else {
+		cropH := w * height / width
+		crop = image.Rect(0, (h-cropH)/2, w, (h-cropH)/2+cropH)
+	}
+
+	if crop.Dx() < width {
+		width, height = crop.Dx(), crop.Dy()
+	}
+	return scaleTo(src, crop, max(1, width), max(1, height))
+}
+
+func scaleTo(src *image.RGBA, from image.Rectangle, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, from, draw.Src, nil)
+	return dst
+}
+
+func isOpaque(img *image.RGBA) bool {
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0xff {
+			return false
+		}
+	}
+	return true
+}
+
+func encodeJPEG(img *image.RGBA) ([]byte, error) {
+	// Piksel transparan diberi latar putih karena JPEG tidak punya kanal alfa
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodePNG(img *image.RGBA) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
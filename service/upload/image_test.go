@@ -0,0 +1,166 @@
+package upload
+
+import (
+	"bytes"
+	"calmind/service/storage"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPipeline(t *testing.T) (ImagePipeline, string) {
+	dir := t.TempDir()
+	return NewImagePipeline(storage.NewLocalStorage(dir, "/uploads")), dir
+}
+
+func solidImage(width, height int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// JPEG dengan segmen APP1 Exif berisi tag Orientation dan Make kamera
+func jpegWithExif(t *testing.T, img image.Image, orientation uint16) []byte {
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, img, nil))
+
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)      // Jumlah entri IFD0
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0112) // Orientation
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)      // SHORT
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)      // Count
+	tiff = binary.LittleEndian.AppendUint32(tiff, uint32(orientation))
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0) // Tidak ada IFD berikutnya
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	segment = append(segment, []byte("SecretCamera GPS 1.2345")...)
+
+	app1 := []byte{0xff, 0xe1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	data := encoded.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+func decodeFile(t *testing.T, path string) (image.Image, []byte) {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	img, _, err := image.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	return img, data
+}
+
+func TestProcess_AvatarStripsExifAndResizes(t *testing.T) {
+	pipeline, dir := newTestPipeline(t)
+	source := jpegWithExif(t, solidImage(1200, 800, color.RGBA{200, 10, 10, 255}), 1)
+	assert.Equal(t, 1, jpegOrientation(source))
+	assert.Contains(t, string(source), "SecretCamera")
+
+	result, err := pipeline.Process(context.Background(), Avatar, "avatars/user_1", bytes.NewReader(source))
+	require.NoError(t, err)
+	assert.Equal(t, "/uploads/avatars/user_1.jpg", result.Image.URL)
+	assert.Equal(t, "/uploads/avatars/user_1_thumb.jpg", result.Thumbnail.URL)
+	assert.Equal(t, 512, result.Width)
+	assert.Equal(t, 512, result.Height)
+
+	img, data := decodeFile(t, filepath.Join(dir, "avatars", "user_1.jpg"))
+	assert.Equal(t, image.Rect(0, 0, 512, 512), img.Bounds())
+	assert.NotContains(t, string(data), "Exif")
+	assert.NotContains(t, string(data), "SecretCamera")
+
+	thumb, _ := decodeFile(t, filepath.Join(dir, "avatars", "user_1_thumb.jpg"))
+	assert.Equal(t, image.Rect(0, 0, 128, 128), thumb.Bounds())
+
+	require.NoError(t, pipeline.Delete(context.Background(), result.Image.URL))
+	_, err = os.Stat(filepath.Join(dir, "avatars", "user_1_thumb.jpg"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestProcess_AppliesExifOrientation(t *testing.T) {
+	pipeline, _ := newTestPipeline(t)
+	// Foto potret dari kamera disimpan mendatar dengan Orientation 6 (putar 90°)
+	source := jpegWithExif(t, solidImage(400, 200, color.Gray{128}), 6)
+	assert.Equal(t, 6, jpegOrientation(source))
+
+	result, err := pipeline.Process(context.Background(), ArticleImage, "artikel/foto", bytes.NewReader(source))
+	require.NoError(t, err)
+	assert.Equal(t, 200, result.Width)
+	assert.Equal(t, 400, result.Height)
+}
+
+func TestProcess_ArticleFitsWithoutUpscaling(t *testing.T) {
+	pipeline, dir := newTestPipeline(t)
+
+	var large bytes.Buffer
+	require.NoError(t, jpeg.Encode(&large, solidImage(3200, 1800, color.White), nil))
+	result, err := pipeline.Process(context.Background(), ArticleImage, "artikel/besar", &large)
+	require.NoError(t, err)
+	assert.Equal(t, 1600, result.Width)
+	assert.Equal(t, 900, result.Height)
+	thumb, _ := decodeFile(t, filepath.Join(dir, "artikel", "besar_thumb.jpg"))
+	assert.Equal(t, image.Rect(0, 0, 480, 270), thumb.Bounds())
+
+	var small bytes.Buffer
+	require.NoError(t, jpeg.Encode(&small, solidImage(300, 200, color.White), nil))
+	result, err = pipeline.Process(context.Background(), ArticleImage, "artikel/kecil", &small)
+	require.NoError(t, err)
+	assert.Equal(t, 300, result.Width)
+	assert.Equal(t, 200, result.Height)
+}
+
+func TestProcess_KeepsTransparentPNG(t *testing.T) {
+	pipeline, _ := newTestPipeline(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, solidImage(64, 64, color.NRGBA{0, 0, 255, 100})))
+	result, err := pipeline.Process(context.Background(), Avatar, "avatars/logo", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "/uploads/avatars/logo.png", result.Image.URL)
+	assert.Equal(t, 64, result.Width, "gambar kecil tidak diperbesar")
+}
+
+func TestProcess_Rejects(t *testing.T) {
+	pipeline, _ := newTestPipeline(t)
+
+	// Ekstensi tidak dipakai, tipe dideteksi dari isi file
+	_, err := pipeline.Process(context.Background(), Avatar, "avatars/x", strings.NewReader("<html><script>alert(1)</script></html>"))
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = pipeline.Process(context.Background(), Avatar, "avatars/x", strings.NewReader("GIF89a......"))
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	small := Avatar
+	small.Limits = map[string]int64{"image/jpeg": 1024}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, noisyImage(200, 200), nil))
+	_, err = pipeline.Process(context.Background(), small, "avatars/x", &buf)
+	assert.ErrorIs(t, err, ErrFileTooLarge)
+
+	// Header PNG valid tetapi isinya terpotong
+	var broken bytes.Buffer
+	require.NoError(t, png.Encode(&broken, solidImage(50, 50, color.White)))
+	_, err = pipeline.Process(context.Background(), Avatar, "avatars/x", bytes.NewReader(broken.Bytes()[:60]))
+	assert.ErrorIs(t, err, ErrCorruptImage)
+}
+
+func noisyImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i * 7919 % 251)
+	}
+	return img
+}
@@ -3,7 +3,15 @@ package usecase
 import (
 	"calmind/model"
 	repository "calmind/repository/admin_management"
+	"errors"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAccountNotFound         = errors.New("akun tidak ditemukan")
+	ErrAccountHasConsultations = errors.New("akun masih memiliki riwayat konsultasi sehingga tidak bisa dihapus")
 )
 
 // DTO Struct untuk User
@@ -36,6 +44,7 @@ type DoctorDTO struct {
 	JenisKelamin       string                    `json:"jenis_kelamin"`
 	IsVerified         bool                      `json:"is_verified"`
 	IsActive           bool                      `json:"is_active"`
+	CredentialStatus   string                    `json:"credential_status"`
 	Title              model.Title               `json:"title"`
 	Tags               []model.Tags              `json:"tags"`
 	CreatedAt          time.Time                 `json:"created_at"`
@@ -61,8 +70,8 @@ type RecommendationSummaryDTO struct {
 }
 
 type AdminManagementUsecase interface {
-	GetAllUsers() ([]UserDTO, error)
-	GetAllDoctors() ([]DoctorDTO, error)
+	GetAllUsers(opts model.QueryOptions) ([]UserDTO, model.PageInfo, error)
+	GetAllDoctors(opts model.QueryOptions) ([]DoctorDTO, model.PageInfo, error)
 	DeleteUser(id int) (*model.User, error)
 	DeleteDoctor(id int) (*model.Doctor, error)
 }
@@ -76,10 +85,10 @@ func NewAdminManagementUsecase(repo repository.AdminManagementRepo) AdminManagem
 }
 
 // Mendapatkan semua pengguna dengan konsultasi terakhir dan rekomendasi terakhir mereka
-func (au *AdminManagementUsecaseImpl) GetAllUsers() ([]UserDTO, error) {
-	users, err := au.Repo.FindAllUsersWithLastConsultation()
+func (au *AdminManagementUsecaseImpl) GetAllUsers(opts model.QueryOptions) ([]UserDTO, model.PageInfo, error) {
+	users, page, err := au.Repo.FindAllUsersWithLastConsultation(opts)
 	if err != nil {
-		return nil, err
+		return nil, model.PageInfo{}, err
 	}
 
 	var result []UserDTO
@@ -112,14 +121,14 @@ func (au *AdminManagementUsecaseImpl) GetAllUsers() ([]UserDTO, error) {
 		})
 	}
 
-	return result, nil
+	return result, page, nil
 }
 
 // Mendapatkan semua dokter dengan konsultasi terakhir dan rekomendasi terakhir mereka
-func (au *AdminManagementUsecaseImpl) GetAllDoctors() ([]DoctorDTO, error) {
-	doctors, err := au.Repo.FindAllDoctorsWithLastConsultation()
+func (au *AdminManagementUsecaseImpl) GetAllDoctors(opts model.QueryOptions) ([]DoctorDTO, model.PageInfo, error) {
+	doctors, page, err := au.Repo.FindAllDoctorsWithLastConsultation(opts)
 	if err != nil {
-		return nil, err
+		return nil, model.PageInfo{}, err
 	}
 
 	var result []DoctorDTO
@@ -159,6 +168,7 @@ func (au *AdminManagementUsecaseImpl) GetAllDoctors() ([]DoctorDTO, error) {
 			JenisKelamin:       doctor.JenisKelamin,
 			IsVerified:         doctor.IsVerified,
 			IsActive:           doctor.IsActive,
+			CredentialStatus:   doctor.CredentialStatus,
 			Title:              doctor.Title,
 			Tags:               doctor.Tags,
 			CreatedAt:          doctor.CreatedAt,
@@ -168,15 +178,34 @@ func (au *AdminManagementUsecaseImpl) GetAllDoctors() ([]DoctorDTO, error) {
 		})
 	}
 
-	return result, nil
+	return result, page, nil
 }
 
-// Menghapus pengguna berdasarkan ID
+// Menghapus pengguna berdasarkan ID. Pengguna yang pernah memesan konsultasi tidak bisa dihapus
+// karena riwayat konsultasi dan pembayarannya harus tetap ada.
 func (au *AdminManagementUsecaseImpl) DeleteUser(id int) (*model.User, error) {
-	return au.Repo.DeleteUser(id)
+	user, err := au.Repo.DeleteUser(id)
+	if err != nil {
+		return nil, deleteError(err)
+	}
+	return user, nil
 }
 
-// Menghapus dokter berdasarkan ID
+// Menghapus dokter berdasarkan ID, dengan batasan yang sama seperti DeleteUser
 func (au *AdminManagementUsecaseImpl) DeleteDoctor(id int) (*model.Doctor, error) {
-	return au.Repo.DeleteDoctor(id)
+	doctor, err := au.Repo.DeleteDoctor(id)
+	if err != nil {
+		return nil, deleteError(err)
+	}
+	return doctor, nil
+}
+
+func deleteError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrAccountNotFound
+	case errors.Is(err, repository.ErrHasConsultations):
+		return ErrAccountHasConsultations
+	}
+	return err
 }
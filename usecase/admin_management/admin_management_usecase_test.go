@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"calmind/model"
+	repository "calmind/repository/admin_management"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// In-memory implementation of the admin management repository for testing.
+// Akun yang ada di Booked tidak bisa dihapus, meniru foreign key ON DELETE RESTRICT.
+type InMemoryAdminManagementRepo struct {
+	Users   map[int]*model.User
+	Doctors map[int]*model.Doctor
+	Booked  map[int]bool
+}
+
+func (repo *InMemoryAdminManagementRepo) FindAllUsers() ([]*model.User, error) {
+	return nil, nil
+}
+
+func (repo *InMemoryAdminManagementRepo) FindAllDoctors() ([]*model.Doctor, error) {
+	return nil, nil
+}
+
+func (repo *InMemoryAdminManagementRepo) DeleteUser(id int) (*model.User, error) {
+	user, ok := repo.Users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if repo.Booked[id] {
+		return nil, repository.ErrHasConsultations
+	}
+	delete(repo.Users, id)
+	return user, nil
+}
+
+func (repo *InMemoryAdminManagementRepo) DeleteDoctor(id int) (*model.Doctor, error) {
+	doctor, ok := repo.Doctors[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if repo.Booked[id] {
+		return nil, repository.ErrHasConsultations
+	}
+	delete(repo.Doctors, id)
+	return doctor, nil
+}
+
+func (repo *InMemoryAdminManagementRepo) FindAllUsersWithLastConsultation(opts model.QueryOptions) ([]*model.User, model.PageInfo, error) {
+	return nil, model.PageInfo{}, nil
+}
+
+func (repo *InMemoryAdminManagementRepo) FindAllDoctorsWithLastConsultation(opts model.QueryOptions) ([]*model.Doctor, model.PageInfo, error) {
+	return nil, model.PageInfo{}, nil
+}
+
+func TestDeleteUser(t *testing.T) {
+	repo := &InMemoryAdminManagementRepo{
+		Users:  map[int]*model.User{1: {ID: 1}, 2: {ID: 2}},
+		Booked: map[int]bool{2: true},
+	}
+	u := NewAdminManagementUsecase(repo)
+
+	user, err := u.DeleteUser(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, user.ID)
+
+	_, err = u.DeleteUser(2)
+	assert.ErrorIs(t, err, ErrAccountHasConsultations)
+	assert.Contains(t, repo.Users, 2)
+
+	_, err = u.DeleteUser(3)
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+func TestDeleteDoctor(t *testing.T) {
+	repo := &InMemoryAdminManagementRepo{
+		Doctors: map[int]*model.Doctor{1: {ID: 1}, 2: {ID: 2}},
+		Booked:  map[int]bool{2: true},
+	}
+	u := NewAdminManagementUsecase(repo)
+
+	doctor, err := u.DeleteDoctor(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, doctor.ID)
+
+	_, err = u.DeleteDoctor(2)
+	assert.ErrorIs(t, err, ErrAccountHasConsultations)
+	assert.Contains(t, repo.Doctors, 2)
+
+	_, err = u.DeleteDoctor(3)
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+}
@@ -1,64 +1,602 @@
 package usecase
 
 import (
+	"calmind/helper"
 	"calmind/model"
 	repository "calmind/repository/artikel"
+	"calmind/service/textdiff"
+	"calmind/service/textsearch"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Panjang ringkasan otomatis yang diambil dari isi artikel
+const summaryLength = 160
+
+// Panjang potongan isi pada hasil pencarian
+const snippetLength = 200
+
+// Batas percobaan akhiran -2, -3, ... saat slug sudah dipakai
+const maxSlugAttempts = 100
+
+var (
+	ErrArtikelNotFound         = errors.New("artikel tidak ditemukan")
+	ErrArtikelForbidden        = errors.New("artikel milik admin lain")
+	ErrInvalidArtikel          = errors.New("judul dan isi artikel wajib diisi")
+	ErrInvalidArtikelStatus    = errors.New("status artikel harus draft, published, atau archived")
+	ErrInvalidSlug             = errors.New("slug hanya boleh berisi huruf kecil, angka, dan tanda -")
+	ErrSlugTaken               = errors.New("slug sudah dipakai artikel lain")
+	ErrArtikelCategoryNotFound = errors.New("kategori artikel tidak ditemukan")
+	ErrInvalidCategoryName     = errors.New("nama kategori wajib diisi, maksimal 100 karakter")
+	ErrCategoryExists          = errors.New("kategori artikel dengan nama tersebut sudah ada")
+	ErrTagNotFound             = errors.New("tag tidak ditemukan")
+	ErrRevisionNotFound        = errors.New("revisi artikel tidak ditemukan")
+	ErrInvalidArtikelSearch    = errors.New("kata kunci wajib diisi dan rentang tanggal harus valid")
 )
 
+// Isian artikel dari editor. Pada update, Slug/Status/PublishedAt/Gambar kosong berarti tetap,
+// CategoryID nil berarti tetap dan 0 berarti dikosongkan, Tags nil berarti tetap.
+// GambarThumbnail hanya dipakai bersama Gambar baru; gambar dihapus lewat ClearArtikelImage.
+type ArtikelInput struct {
+	Judul           string     `json:"judul"`
+	Slug            string     `json:"slug"`
+	Ringkasan       string     `json:"ringkasan"`
+	Isi             string     `json:"isi"`
+	Gambar          string     `json:"gambar"`
+	GambarThumbnail string     `json:"gambar_thumbnail"`
+	Status          string     `json:"status"`
+	PublishedAt     *time.Time `json:"published_at"`
+	CategoryID      *int       `json:"category_id"`
+	Tags            []string   `json:"tags"`
+}
+
+// Field artikel selain isi yang berbeda antara dua revisi
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type RevisionDiff struct {
+	ArtikelID int           `json:"artikel_id"`
+	From      int           `json:"from"`
+	To        int           `json:"to"`
+	Changes   []FieldChange `json:"changes"`
+	Isi       textdiff.Diff `json:"isi"`
+}
+
+// Filter tambahan pencarian artikel, nilai kosong berarti tidak difilter
+type ArtikelSearchFilter struct {
+	CategorySlug string
+	Tag          string
+	From         *time.Time // Tayang sejak (inklusif)
+	To           *time.Time // Tayang sebelum (eksklusif)
+}
+
+// Artikel hasil pencarian dengan judul dan potongan isi yang kata kuncinya dibungkus <mark>
+type ArtikelSearchResult struct {
+	Artikel        model.Artikel
+	JudulHighlight string
+	Snippet        string
+}
+
 type ArtikelUsecase interface {
-	CreateArtikel(adminID int, artikel *model.Artikel) error
-	GetAllArtikel() ([]model.Artikel, error)
+	// Pengelolaan oleh admin, semua status
+	CreateArtikel(adminID int, input ArtikelInput) (*model.Artikel, error)
+	GetAllArtikel(opts model.QueryOptions) ([]model.Artikel, model.PageInfo, error)
 	GetArtikelByID(id int) (*model.Artikel, error)
-	UpdateArtikel(artikel *model.Artikel) error
+	UpdateArtikel(adminID int, id int, input ArtikelInput) (*model.Artikel, error)
 	DeleteArtikel(adminID int, id int) error
-	SearchArtikel(query string) ([]model.Artikel, error)
+	ClearArtikelImage(adminID int, id int) (*model.Artikel, error)
+
+	// Riwayat revisi, setiap penyimpanan menghasilkan satu revisi
+	GetRevisions(id int, opts model.QueryOptions) ([]model.ArtikelRevision, model.PageInfo, error)
+	GetRevision(id int, number int) (*model.ArtikelRevision, error)
+	DiffRevisions(id int, from int, to int) (*RevisionDiff, error)
+	RestoreRevision(adminID int, id int, number int) (*model.Artikel, error)
+
+	// Untuk pembaca, hanya artikel yang sudah tayang
+	GetPublishedArtikels(tag, categorySlug string, opts model.QueryOptions) ([]model.Artikel, model.PageInfo, error)
+	GetPublishedArtikel(idOrSlug string) (*model.Artikel, error)
+	SearchArtikel(query string, filter ArtikelSearchFilter, opts model.QueryOptions) ([]ArtikelSearchResult, model.PageInfo, error)
+
+	GetCategories() ([]model.ArtikelCategory, error)
+	CreateCategory(name string) (*model.ArtikelCategory, error)
+	UpdateCategory(id int, name string) (*model.ArtikelCategory, error)
+	DeleteCategory(id int) error
 }
 
 type artikelUsecase struct {
 	repo repository.ArtikelRepository
+	now  func() time.Time
 }
 
 func NewArtikelUsecase(repo repository.ArtikelRepository) ArtikelUsecase {
-	return &artikelUsecase{repo: repo}
+	return &artikelUsecase{repo: repo, now: time.Now}
 }
 
-func (u *artikelUsecase) CreateArtikel(adminID int, artikel *model.Artikel) error {
-	artikel.AdminID = adminID
-	return u.repo.Create(artikel)
+func (u *artikelUsecase) CreateArtikel(adminID int, input ArtikelInput) (*model.Artikel, error) {
+	artikel := &model.Artikel{AdminID: adminID, Status: model.ArtikelStatusDraft}
+	if err := u.apply(artikel, input); err != nil {
+		return nil, err
+	}
+	if err := u.repo.Create(artikel, revisionOf(artikel, adminID, "Artikel dibuat")); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan artikel: %w", err)
+	}
+	return u.repo.GetByID(artikel.ID)
 }
 
-func (u *artikelUsecase) GetAllArtikel() ([]model.Artikel, error) {
-	return u.repo.GetAll()
+func (u *artikelUsecase) GetAllArtikel(opts model.QueryOptions) ([]model.Artikel, model.PageInfo, error) {
+	return u.repo.GetAll(repository.ArtikelFilter{}, opts)
 }
 
 func (u *artikelUsecase) GetArtikelByID(id int) (*model.Artikel, error) {
-	return u.repo.GetByID(id)
+	artikel, err := u.repo.GetByID(id)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return artikel, nil
 }
 
-func (u *artikelUsecase) UpdateArtikel(artikel *model.Artikel) error {
-	existingArtikel, err := u.repo.GetByID(artikel.ID)
+// Admin mana pun boleh menyunting, pemilik artikel (admin_id) tetap dan penyunting dicatat di revisi
+func (u *artikelUsecase) UpdateArtikel(adminID int, id int, input ArtikelInput) (*model.Artikel, error) {
+	artikel, err := u.GetArtikelByID(id)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	if err := u.apply(artikel, input); err != nil {
+		return nil, err
+	}
+	return u.save(artikel, adminID, "")
+}
 
-	// Pastikan admin_id tidak diubah
-	artikel.AdminID = existingArtikel.AdminID
-
-	return u.repo.Update(artikel)
+func (u *artikelUsecase) save(artikel *model.Artikel, adminID int, note string) (*model.Artikel, error) {
+	artikel.UpdatedAt = u.now()
+	if err := u.repo.Update(artikel, revisionOf(artikel, adminID, note)); err != nil {
+		return nil, fmt.Errorf("gagal memperbarui artikel: %w", err)
+	}
+	return u.repo.GetByID(artikel.ID)
 }
 
 func (u *artikelUsecase) DeleteArtikel(adminID int, id int) error {
-	existingArtikel, err := u.repo.GetByID(id)
+	existingArtikel, err := u.GetArtikelByID(id)
 	if err != nil {
 		return err
 	}
 	if existingArtikel.AdminID != adminID {
-		return errors.New("unauthorized to delete this artikel")
+		return ErrArtikelForbidden
 	}
 	return u.repo.Delete(id)
 }
 
-func (u *artikelUsecase) SearchArtikel(query string) ([]model.Artikel, error) {
-	return u.repo.SearchArtikel(query)
+// Mengosongkan gambar artikel milik admin setelah berkasnya dihapus dari penyimpanan
+func (u *artikelUsecase) ClearArtikelImage(adminID int, id int) (*model.Artikel, error) {
+	artikel, err := u.GetArtikelByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if artikel.AdminID != adminID {
+		return nil, ErrArtikelForbidden
+	}
+	artikel.Gambar = ""
+	artikel.GambarThumbnail = ""
+	return u.save(artikel, adminID, "Gambar dihapus")
+}
+
+func (u *artikelUsecase) GetRevisions(id int, opts model.QueryOptions) ([]model.ArtikelRevision, model.PageInfo, error) {
+	if _, err := u.GetArtikelByID(id); err != nil {
+		return nil, model.PageInfo{}, err
+	}
+	return u.repo.GetRevisions(id, opts)
+}
+
+func (u *artikelUsecase) GetRevision(id int, number int) (*model.ArtikelRevision, error) {
+	revision, err := u.repo.GetRevision(id, number)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return revision, nil
+}
+
+// Perbandingan revisi from ke to: isi per baris, field lain sebagai nilai lama dan baru
+func (u *artikelUsecase) DiffRevisions(id int, from int, to int) (*RevisionDiff, error) {
+	older, err := u.GetRevision(id, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := u.GetRevision(id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &RevisionDiff{ArtikelID: id, From: from, To: to, Changes: []FieldChange{}, Isi: textdiff.Lines(older.Isi, newer.Isi)}
+	oldFields, newFields := revisionFields(older), revisionFields(newer)
+	for i := range oldFields {
+		if oldFields[i].New != newFields[i].New {
+			diff.Changes = append(diff.Changes, FieldChange{Field: oldFields[i].Field, Old: oldFields[i].New, New: newFields[i].New})
+		}
+	}
+	return diff, nil
+}
+
+// Mengembalikan konten artikel ke revisi tertentu sebagai revisi baru. Status dan jadwal tayang tidak ikut
+// dikembalikan; slug, kategori, dan tag yang sudah tidak tersedia dilewati, begitu pula gambar yang kosong.
+func (u *artikelUsecase) RestoreRevision(adminID int, id int, number int) (*model.Artikel, error) {
+	artikel, err := u.GetArtikelByID(id)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := u.GetRevision(id, number)
+	if err != nil {
+		return nil, err
+	}
+
+	input := ArtikelInput{
+		Judul:           revision.Judul,
+		Ringkasan:       revision.Ringkasan,
+		Isi:             revision.Isi,
+		Gambar:          revision.Gambar,
+		GambarThumbnail: revision.GambarThumbnail,
+		CategoryID:      new(int),
+		Tags:            []string{},
+	}
+	if revision.Slug != "" {
+		taken, err := u.repo.SlugExists(revision.Slug, id)
+		if err != nil {
+			return nil, fmt.Errorf("gagal memeriksa slug: %w", err)
+		}
+		if !taken {
+			input.Slug = revision.Slug
+		}
+	}
+	if revision.CategoryID != nil {
+		if _, err := u.repo.GetCategoryByID(*revision.CategoryID); err == nil {
+			input.CategoryID = revision.CategoryID
+		}
+	}
+	tags, err := u.repo.FindTagsByName(revision.TagNames)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil tag: %w", err)
+	}
+	for _, tag := range tags {
+		input.Tags = append(input.Tags, tag.Name)
+	}
+
+	if err := u.apply(artikel, input); err != nil {
+		return nil, err
+	}
+	return u.save(artikel, adminID, fmt.Sprintf("Dipulihkan dari revisi %d", number))
+}
+
+func (u *artikelUsecase) GetPublishedArtikels(tag, categorySlug string, opts model.QueryOptions) ([]model.Artikel, model.PageInfo, error) {
+	now := u.now()
+	filter := repository.ArtikelFilter{PublishedBy: &now, Tag: strings.TrimSpace(tag), CategorySlug: strings.TrimSpace(categorySlug)}
+	// Pembaca tidak bisa memilih status lain selain published
+	delete(opts.Filters, "status")
+	return u.repo.GetAll(filter, opts)
+}
+
+// Artikel dicari lewat ID bila parameternya angka, selain itu lewat slug
+func (u *artikelUsecase) GetPublishedArtikel(idOrSlug string) (*model.Artikel, error) {
+	var artikel *model.Artikel
+	var err error
+	if id, convErr := strconv.Atoi(idOrSlug); convErr == nil {
+		artikel, err = u.repo.GetByID(id)
+	} else {
+		artikel, err = u.repo.GetBySlug(idOrSlug)
+	}
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if !IsPublished(artikel, u.now()) {
+		return nil, ErrArtikelNotFound
+	}
+	return artikel, nil
+}
+
+// Pencarian judul dan isi artikel yang sudah tayang, diurutkan berdasarkan relevansi. Kueri yang
+// seluruhnya kata umum tidak bisa dicari lewat FULLTEXT sehingga dicocokkan apa adanya dengan judul.
+func (u *artikelUsecase) SearchArtikel(query string, filter ArtikelSearchFilter, opts model.QueryOptions) ([]ArtikelSearchResult, model.PageInfo, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || (filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To)) {
+		return nil, model.PageInfo{}, ErrInvalidArtikelSearch
+	}
+
+	now := u.now()
+	terms := textsearch.Terms(query)
+	repoFilter := repository.ArtikelFilter{
+		PublishedBy:   &now,
+		PublishedFrom: filter.From,
+		PublishedTo:   filter.To,
+		Terms:         terms,
+		Tag:           strings.TrimSpace(filter.Tag),
+		CategorySlug:  strings.TrimSpace(filter.CategorySlug),
+	}
+	delete(opts.Filters, "status")
+
+	var artikels []model.Artikel
+	var page model.PageInfo
+	var err error
+	if len(terms) > 0 {
+		artikels, page, err = u.repo.Search(repoFilter, opts)
+	} else {
+		repoFilter.Keyword = query
+		if strings.TrimPrefix(opts.Sort, "-") == "relevance" {
+			opts.Sort = ""
+		}
+		artikels, page, err = u.repo.GetAll(repoFilter, opts)
+	}
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+
+	results := make([]ArtikelSearchResult, 0, len(artikels))
+	for _, artikel := range artikels {
+		results = append(results, ArtikelSearchResult{
+			Artikel:        artikel,
+			JudulHighlight: textsearch.Highlight(artikel.Judul, terms),
+			Snippet:        textsearch.Snippet(helper.PlainText(artikel.Isi), terms, snippetLength),
+		})
+	}
+	return results, page, nil
+}
+
+func (u *artikelUsecase) GetCategories() ([]model.ArtikelCategory, error) {
+	categories, err := u.repo.GetCategories()
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil kategori artikel: %w", err)
+	}
+	return categories, nil
+}
+
+func (u *artikelUsecase) CreateCategory(name string) (*model.ArtikelCategory, error) {
+	category := &model.ArtikelCategory{}
+	if err := u.nameCategory(category, name); err != nil {
+		return nil, err
+	}
+	if err := u.repo.CreateCategory(category); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan kategori artikel: %w", err)
+	}
+	return category, nil
+}
+
+func (u *artikelUsecase) UpdateCategory(id int, name string) (*model.ArtikelCategory, error) {
+	category, err := u.repo.GetCategoryByID(id)
+	if err != nil {
+		return nil, ErrArtikelCategoryNotFound
+	}
+	if err := u.nameCategory(category, name); err != nil {
+		return nil, err
+	}
+	if err := u.repo.UpdateCategory(category); err != nil {
+		return nil, fmt.Errorf("gagal memperbarui kategori artikel: %w", err)
+	}
+	return category, nil
+}
+
+// Artikel di dalamnya tetap ada, hanya kategorinya dikosongkan
+func (u *artikelUsecase) DeleteCategory(id int) error {
+	if _, err := u.repo.GetCategoryByID(id); err != nil {
+		return ErrArtikelCategoryNotFound
+	}
+	if err := u.repo.DeleteCategory(id); err != nil {
+		return fmt.Errorf("gagal menghapus kategori artikel: %w", err)
+	}
+	return nil
+}
+
+// Artikel tampil untuk pembaca bila published dan waktu tayangnya sudah lewat
+func IsPublished(artikel *model.Artikel, now time.Time) bool {
+	return artikel.Status == model.ArtikelStatusPublished && (artikel.PublishedAt == nil || !artikel.PublishedAt.After(now))
+}
+
+// Menerapkan isian editor ke artikel lalu menghitung ulang slug, ringkasan, dan waktu baca
+func (u *artikelUsecase) apply(artikel *model.Artikel, input ArtikelInput) error {
+	input.Judul = strings.TrimSpace(input.Judul)
+	if input.Judul == "" || strings.TrimSpace(helper.PlainText(input.Isi)) == "" {
+		return ErrInvalidArtikel
+	}
+	artikel.Judul = input.Judul
+	artikel.Isi = input.Isi
+	// Thumbnail milik gambar lama tidak dipakai lagi saat gambarnya diganti
+	if input.Gambar != "" && input.Gambar != artikel.Gambar {
+		artikel.Gambar = input.Gambar
+		artikel.GambarThumbnail = input.GambarThumbnail
+	}
+	artikel.ReadingMinutes = helper.ReadingMinutes(input.Isi)
+	artikel.SearchJudul = textsearch.Normalize(input.Judul)
+	artikel.SearchIsi = textsearch.Normalize(helper.PlainText(input.Isi))
+
+	artikel.Ringkasan = strings.TrimSpace(input.Ringkasan)
+	if artikel.Ringkasan == "" {
+		artikel.Ringkasan = helper.Summarize(input.Isi, summaryLength)
+	}
+
+	if input.Status != "" {
+		switch input.Status {
+		case model.ArtikelStatusDraft, model.ArtikelStatusPublished, model.ArtikelStatusArchived:
+			artikel.Status = input.Status
+		default:
+			return ErrInvalidArtikelStatus
+		}
+	}
+	if input.PublishedAt != nil {
+		publishedAt := *input.PublishedAt
+		artikel.PublishedAt = &publishedAt
+	}
+	// Terbit tanpa jadwal berarti tayang sekarang
+	if artikel.Status == model.ArtikelStatusPublished && artikel.PublishedAt == nil {
+		now := u.now()
+		artikel.PublishedAt = &now
+	}
+
+	if input.CategoryID != nil {
+		if *input.CategoryID == 0 {
+			artikel.CategoryID = nil
+			artikel.Category = nil
+		} else {
+			category, err := u.repo.GetCategoryByID(*input.CategoryID)
+			if err != nil {
+				return ErrArtikelCategoryNotFound
+			}
+			artikel.CategoryID = &category.ID
+			artikel.Category = category
+		}
+	}
+
+	if input.Tags != nil {
+		tags, err := u.resolveTags(input.Tags)
+		if err != nil {
+			return err
+		}
+		artikel.Tags = tags
+	}
+
+	return u.assignSlug(artikel, strings.TrimSpace(input.Slug))
+}
+
+// Slug eksplisit harus valid dan belum dipakai; tanpa slug, artikel baru memakai slug judul
+// dengan akhiran -2, -3, ... bila bentrok, sedangkan artikel lama mempertahankan slugnya
+func (u *artikelUsecase) assignSlug(artikel *model.Artikel, requested string) error {
+	if requested != "" {
+		if helper.Slugify(requested) != requested {
+			return ErrInvalidSlug
+		}
+		taken, err := u.repo.SlugExists(requested, artikel.ID)
+		if err != nil {
+			return fmt.Errorf("gagal memeriksa slug: %w", err)
+		}
+		if taken {
+			return ErrSlugTaken
+		}
+		artikel.Slug = requested
+		return nil
+	}
+	if artikel.Slug != "" {
+		return nil
+	}
+
+	base := helper.Slugify(artikel.Judul)
+	if base == "" {
+		base = "artikel"
+	}
+	for attempt := 1; attempt <= maxSlugAttempts; attempt++ {
+		candidate := base
+		if attempt > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, attempt)
+		}
+		taken, err := u.repo.SlugExists(candidate, artikel.ID)
+		if err != nil {
+			return fmt.Errorf("gagal memeriksa slug: %w", err)
+		}
+		if !taken {
+			artikel.Slug = candidate
+			return nil
+		}
+	}
+	artikel.Slug = fmt.Sprintf("%s-%d", base, u.now().UnixNano())
+	return nil
+}
+
+// Nama tag dicocokkan tanpa duplikat; satu saja yang tidak dikenal membatalkan penyimpanan
+func (u *artikelUsecase) resolveTags(names []string) ([]model.Tags, error) {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, name)
+	}
+
+	tags, err := u.repo.FindTagsByName(unique)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil tag: %w", err)
+	}
+	if len(tags) != len(unique) {
+		return nil, ErrTagNotFound
+	}
+	return tags, nil
+}
+
+func (u *artikelUsecase) nameCategory(category *model.ArtikelCategory, name string) error {
+	name = strings.TrimSpace(name)
+	slug := helper.Slugify(name)
+	if name == "" || slug == "" || len([]rune(name)) > 100 {
+		return ErrInvalidCategoryName
+	}
+
+	categories, err := u.repo.GetCategories()
+	if err != nil {
+		return fmt.Errorf("gagal mengambil kategori artikel: %w", err)
+	}
+	for _, existing := range categories {
+		if existing.ID != category.ID && existing.Slug == slug {
+			return ErrCategoryExists
+		}
+	}
+	category.Name = name
+	category.Slug = slug
+	return nil
+}
+
+// Salinan keadaan artikel untuk dicatat sebagai revisi
+func revisionOf(artikel *model.Artikel, adminID int, note string) *model.ArtikelRevision {
+	tagNames := make([]string, 0, len(artikel.Tags))
+	for _, tag := range artikel.Tags {
+		tagNames = append(tagNames, tag.Name)
+	}
+	return &model.ArtikelRevision{
+		AdminID:         adminID,
+		Note:            note,
+		Judul:           artikel.Judul,
+		Slug:            artikel.Slug,
+		Ringkasan:       artikel.Ringkasan,
+		Isi:             artikel.Isi,
+		Gambar:          artikel.Gambar,
+		GambarThumbnail: artikel.GambarThumbnail,
+		Status:          artikel.Status,
+		PublishedAt:     artikel.PublishedAt,
+		CategoryID:      artikel.CategoryID,
+		TagNames:        tagNames,
+	}
+}
+
+// Field revisi yang dibandingkan, nilainya disimpan di New
+func revisionFields(revision *model.ArtikelRevision) []FieldChange {
+	publishedAt, categoryID := "", ""
+	if revision.PublishedAt != nil {
+		publishedAt = revision.PublishedAt.Format("2006-01-02 15:04:05")
+	}
+	if revision.CategoryID != nil {
+		categoryID = strconv.Itoa(*revision.CategoryID)
+	}
+	return []FieldChange{
+		{Field: "judul", New: revision.Judul},
+		{Field: "slug", New: revision.Slug},
+		{Field: "ringkasan", New: revision.Ringkasan},
+		{Field: "gambar", New: revision.Gambar},
+		{Field: "status", New: revision.Status},
+		{Field: "published_at", New: publishedAt},
+		{Field: "category_id", New: categoryID},
+		{Field: "tags", New: strings.Join(revision.TagNames, ", ")},
+	}
+}
+
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrArtikelNotFound
+	}
+	return err
 }
@@ -0,0 +1,383 @@
+package usecase
+
+import (
+	"calmind/model"
+	repository "calmind/repository/artikel"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// In-memory implementation of the artikel repository for testing.
+type InMemoryArtikelRepo struct {
+	Artikels   []model.Artikel
+	Categories []model.ArtikelCategory
+	Tags       []model.Tags
+	Revisions  []model.ArtikelRevision
+}
+
+func (repo *InMemoryArtikelRepo) Create(artikel *model.Artikel, revision *model.ArtikelRevision) error {
+	artikel.ID = len(repo.Artikels) + 1
+	repo.Artikels = append(repo.Artikels, *artikel)
+	return repo.addRevision(artikel.ID, revision)
+}
+
+func (repo *InMemoryArtikelRepo) addRevision(artikelID int, revision *model.ArtikelRevision) error {
+	revision.ArtikelID = artikelID
+	revision.Number = 1
+	for _, existing := range repo.Revisions {
+		if existing.ArtikelID == artikelID && existing.Number >= revision.Number {
+			revision.Number = existing.Number + 1
+		}
+	}
+	revision.ID = len(repo.Revisions) + 1
+	repo.Revisions = append(repo.Revisions, *revision)
+	return nil
+}
+
+func (repo *InMemoryArtikelRepo) GetAll(filter repository.ArtikelFilter, opts model.QueryOptions) ([]model.Artikel, model.PageInfo, error) {
+	var result []model.Artikel
+	for _, artikel := range repo.Artikels {
+		if filter.PublishedBy != nil && !IsPublished(&artikel, *filter.PublishedBy) {
+			continue
+		}
+		result = append(result, artikel)
+	}
+	return result, model.PageInfo{Total: int64(len(result))}, nil
+}
+
+func (repo *InMemoryArtikelRepo) Search(filter repository.ArtikelFilter, opts model.QueryOptions) ([]model.Artikel, model.PageInfo, error) {
+	var result []model.Artikel
+	for _, artikel := range repo.Artikels {
+		if filter.PublishedBy != nil && !IsPublished(&artikel, *filter.PublishedBy) {
+			continue
+		}
+		if filter.PublishedFrom != nil && artikel.PublishedAt.Before(*filter.PublishedFrom) {
+			continue
+		}
+		words := strings.Fields(artikel.SearchJudul + " " + artikel.SearchIsi)
+		for _, term := range filter.Terms {
+			if containsWord(words, term) {
+				result = append(result, artikel)
+				break
+			}
+		}
+	}
+	return result, model.PageInfo{Total: int64(len(result))}, nil
+}
+
+func containsWord(words []string, term string) bool {
+	for _, word := range words {
+		if word == term {
+			return true
+		}
+	}
+	return false
+}
+
+func (repo *InMemoryArtikelRepo) GetByID(id int) (*model.Artikel, error) {
+	for _, artikel := range repo.Artikels {
+		if artikel.ID == id {
+			return &artikel, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (repo *InMemoryArtikelRepo) GetBySlug(slug string) (*model.Artikel, error) {
+	for _, artikel := range repo.Artikels {
+		if artikel.Slug == slug {
+			return &artikel, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (repo *InMemoryArtikelRepo) SlugExists(slug string, excludeID int) (bool, error) {
+	for _, artikel := range repo.Artikels {
+		if artikel.Slug == slug && artikel.ID != excludeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (repo *InMemoryArtikelRepo) Update(artikel *model.Artikel, revision *model.ArtikelRevision) error {
+	for i := range repo.Artikels {
+		if repo.Artikels[i].ID == artikel.ID {
+			repo.Artikels[i] = *artikel
+		}
+	}
+	return repo.addRevision(artikel.ID, revision)
+}
+
+func (repo *InMemoryArtikelRepo) GetRevisions(artikelID int, opts model.QueryOptions) ([]model.ArtikelRevision, model.PageInfo, error) {
+	var revisions []model.ArtikelRevision
+	for _, revision := range repo.Revisions {
+		if revision.ArtikelID == artikelID {
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions, model.PageInfo{Total: int64(len(revisions))}, nil
+}
+
+func (repo *InMemoryArtikelRepo) GetRevision(artikelID int, number int) (*model.ArtikelRevision, error) {
+	for _, revision := range repo.Revisions {
+		if revision.ArtikelID == artikelID && revision.Number == number {
+			return &revision, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (repo *InMemoryArtikelRepo) Delete(id int) error {
+	return nil
+}
+
+func (repo *InMemoryArtikelRepo) FindTagsByName(names []string) ([]model.Tags, error) {
+	var tags []model.Tags
+	for _, tag := range repo.Tags {
+		for _, name := range names {
+			if tag.Name == name {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags, nil
+}
+
+func (repo *InMemoryArtikelRepo) GetCategories() ([]model.ArtikelCategory, error) {
+	return repo.Categories, nil
+}
+
+func (repo *InMemoryArtikelRepo) GetCategoryByID(id int) (*model.ArtikelCategory, error) {
+	for _, category := range repo.Categories {
+		if category.ID == id {
+			return &category, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (repo *InMemoryArtikelRepo) CreateCategory(category *model.ArtikelCategory) error {
+	category.ID = len(repo.Categories) + 1
+	repo.Categories = append(repo.Categories, *category)
+	return nil
+}
+
+func (repo *InMemoryArtikelRepo) UpdateCategory(category *model.ArtikelCategory) error {
+	return nil
+}
+
+func (repo *InMemoryArtikelRepo) DeleteCategory(id int) error {
+	return nil
+}
+
+var testNow = time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+func newTestUsecase(repo *InMemoryArtikelRepo) *artikelUsecase {
+	return &artikelUsecase{repo: repo, now: func() time.Time { return testNow }}
+}
+
+func TestCreateArtikel_DraftWithSlugSummaryAndReadingTime(t *testing.T) {
+	repo := &InMemoryArtikelRepo{}
+	u := newTestUsecase(repo)
+
+	isi := "<p>" + strings.Repeat("tenang ", 450) + "</p>"
+	artikel, err := u.CreateArtikel(1, ArtikelInput{Judul: "Mengelola Stres di Tempat Kerja!", Isi: isi})
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.ArtikelStatusDraft, artikel.Status)
+	assert.Nil(t, artikel.PublishedAt)
+	assert.Equal(t, "mengelola-stres-di-tempat-kerja", artikel.Slug)
+	assert.Equal(t, 3, artikel.ReadingMinutes)
+	assert.NotContains(t, artikel.Ringkasan, "<p>")
+	assert.LessOrEqual(t, len([]rune(artikel.Ringkasan)), summaryLength+1)
+}
+
+func TestCreateArtikel_DuplicateTitleGetsSuffixedSlug(t *testing.T) {
+	repo := &InMemoryArtikelRepo{}
+	u := newTestUsecase(repo)
+
+	first, _ := u.CreateArtikel(1, ArtikelInput{Judul: "Tidur Cukup", Isi: "isi"})
+	second, err := u.CreateArtikel(1, ArtikelInput{Judul: "Tidur cukup", Isi: "isi"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "tidur-cukup", first.Slug)
+	assert.Equal(t, "tidur-cukup-2", second.Slug)
+
+	_, err = u.CreateArtikel(1, ArtikelInput{Judul: "Lain", Isi: "isi", Slug: "tidur-cukup"})
+	assert.ErrorIs(t, err, ErrSlugTaken)
+}
+
+func TestCreateArtikel_Validation(t *testing.T) {
+	repo := &InMemoryArtikelRepo{Tags: []model.Tags{{ID: 1, Name: "Stres"}}}
+	u := newTestUsecase(repo)
+
+	_, err := u.CreateArtikel(1, ArtikelInput{Judul: "Judul", Isi: "<p> </p>"})
+	assert.ErrorIs(t, err, ErrInvalidArtikel)
+
+	_, err = u.CreateArtikel(1, ArtikelInput{Judul: "Judul", Isi: "isi", Status: "live"})
+	assert.ErrorIs(t, err, ErrInvalidArtikelStatus)
+
+	categoryID := 9
+	_, err = u.CreateArtikel(1, ArtikelInput{Judul: "Judul", Isi: "isi", CategoryID: &categoryID})
+	assert.ErrorIs(t, err, ErrArtikelCategoryNotFound)
+
+	_, err = u.CreateArtikel(1, ArtikelInput{Judul: "Judul", Isi: "isi", Tags: []string{"Stres", "Cemas"}})
+	assert.ErrorIs(t, err, ErrTagNotFound)
+
+	_, err = u.CreateArtikel(1, ArtikelInput{Judul: "Judul", Isi: "isi", Slug: "Bukan Slug"})
+	assert.ErrorIs(t, err, ErrInvalidSlug)
+}
+
+func TestPublishedVisibility(t *testing.T) {
+	repo := &InMemoryArtikelRepo{}
+	u := newTestUsecase(repo)
+
+	draft, _ := u.CreateArtikel(1, ArtikelInput{Judul: "Draft", Isi: "isi"})
+	published, _ := u.CreateArtikel(1, ArtikelInput{Judul: "Terbit", Isi: "isi", Status: model.ArtikelStatusPublished})
+	later := testNow.Add(24 * time.Hour)
+	scheduled, _ := u.CreateArtikel(1, ArtikelInput{Judul: "Terjadwal", Isi: "isi", Status: model.ArtikelStatusPublished, PublishedAt: &later})
+
+	// Terbit tanpa jadwal langsung tayang
+	assert.Equal(t, testNow, *published.PublishedAt)
+
+	artikels, _, err := u.GetPublishedArtikels("", "", model.QueryOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, artikels, 1)
+	assert.Equal(t, published.ID, artikels[0].ID)
+
+	_, err = u.GetPublishedArtikel(draft.Slug)
+	assert.ErrorIs(t, err, ErrArtikelNotFound)
+	_, err = u.GetPublishedArtikel("3")
+	assert.ErrorIs(t, err, ErrArtikelNotFound)
+
+	found, err := u.GetPublishedArtikel(published.Slug)
+	assert.NoError(t, err)
+	assert.Equal(t, published.ID, found.ID)
+
+	// Setelah jadwal lewat artikel terjadwal ikut tampil
+	u.now = func() time.Time { return later }
+	found, err = u.GetPublishedArtikel(scheduled.Slug)
+	assert.NoError(t, err)
+	assert.Equal(t, scheduled.ID, found.ID)
+}
+
+func TestUpdateArtikel_KeepsSlugAndTagsWhenOmitted(t *testing.T) {
+	repo := &InMemoryArtikelRepo{Tags: []model.Tags{{ID: 1, Name: "Stres"}}}
+	u := newTestUsecase(repo)
+
+	artikel, _ := u.CreateArtikel(1, ArtikelInput{Judul: "Judul Awal", Isi: "isi", Tags: []string{"Stres"}})
+
+	updated, err := u.UpdateArtikel(1, artikel.ID, ArtikelInput{Judul: "Judul Baru", Isi: "isi baru"})
+	assert.NoError(t, err)
+	assert.Equal(t, "judul-awal", updated.Slug)
+	assert.Len(t, updated.Tags, 1)
+	assert.Equal(t, model.ArtikelStatusDraft, updated.Status)
+
+	updated, err = u.UpdateArtikel(1, artikel.ID, ArtikelInput{Judul: "Judul Baru", Isi: "isi baru", Tags: []string{}, Status: model.ArtikelStatusArchived})
+	assert.NoError(t, err)
+	assert.Empty(t, updated.Tags)
+	assert.Equal(t, model.ArtikelStatusArchived, updated.Status)
+}
+
+func TestUpdateArtikel_ImageKeptUnlessReplaced(t *testing.T) {
+	repo := &InMemoryArtikelRepo{}
+	u := newTestUsecase(repo)
+
+	artikel, _ := u.CreateArtikel(1, ArtikelInput{Judul: "Judul", Isi: "isi", Gambar: "/uploads/a.jpg", GambarThumbnail: "/uploads/a_thumb.jpg"})
+
+	// Gambar kosong berarti tetap
+	updated, err := u.UpdateArtikel(1, artikel.ID, ArtikelInput{Judul: "Judul", Isi: "isi baru"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/uploads/a.jpg", updated.Gambar)
+	assert.Equal(t, "/uploads/a_thumb.jpg", updated.GambarThumbnail)
+
+	// Gambar yang sama tidak mengganti thumbnail
+	updated, _ = u.UpdateArtikel(1, artikel.ID, ArtikelInput{Judul: "Judul", Isi: "isi", Gambar: "/uploads/a.jpg"})
+	assert.Equal(t, "/uploads/a_thumb.jpg", updated.GambarThumbnail)
+
+	// Gambar baru tanpa thumbnail tidak mewarisi thumbnail gambar lama
+	updated, _ = u.UpdateArtikel(1, artikel.ID, ArtikelInput{Judul: "Judul", Isi: "isi", Gambar: "https://cdn.example.com/b.jpg"})
+	assert.Equal(t, "https://cdn.example.com/b.jpg", updated.Gambar)
+	assert.Empty(t, updated.GambarThumbnail)
+}
+
+func TestCreateCategory_RejectsDuplicateSlug(t *testing.T) {
+	repo := &InMemoryArtikelRepo{}
+	u := newTestUsecase(repo)
+
+	category, err := u.CreateCategory("Kesehatan Mental")
+	assert.NoError(t, err)
+	assert.Equal(t, "kesehatan-mental", category.Slug)
+
+	_, err = u.CreateCategory("kesehatan  mental")
+	assert.ErrorIs(t, err, ErrCategoryExists)
+}
+
+func TestRevisions_RecordDiffAndRestore(t *testing.T) {
+	repo := &InMemoryArtikelRepo{Tags: []model.Tags{{ID: 1, Name: "Stres"}}}
+	u := newTestUsecase(repo)
+
+	artikel, _ := u.CreateArtikel(1, ArtikelInput{Judul: "Tidur", Isi: "pembuka\nisi lama\npenutup", Tags: []string{"Stres"}})
+	_, err := u.UpdateArtikel(2, artikel.ID, ArtikelInput{Judul: "Tidur Nyenyak", Isi: "pembuka\nisi baru\npenutup", Tags: []string{}})
+	assert.NoError(t, err)
+
+	revisions, _, err := u.GetRevisions(artikel.ID, model.QueryOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+	assert.Equal(t, 1, revisions[0].AdminID)
+	assert.Equal(t, 2, revisions[1].AdminID)
+
+	diff, err := u.DiffRevisions(artikel.ID, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, diff.Isi.Added)
+	assert.Equal(t, 1, diff.Isi.Removed)
+	assert.Contains(t, diff.Changes, FieldChange{Field: "judul", Old: "Tidur", New: "Tidur Nyenyak"})
+	assert.Contains(t, diff.Changes, FieldChange{Field: "tags", Old: "Stres", New: ""})
+
+	restored, err := u.RestoreRevision(3, artikel.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Tidur", restored.Judul)
+	assert.Equal(t, "pembuka\nisi lama\npenutup", restored.Isi)
+	assert.Len(t, restored.Tags, 1)
+
+	// Pemulihan tercatat sebagai revisi baru, revisi lama tetap ada
+	revisions, _, _ = u.GetRevisions(artikel.ID, model.QueryOptions{})
+	assert.Len(t, revisions, 3)
+	assert.Equal(t, 3, revisions[2].AdminID)
+	assert.Equal(t, "Dipulihkan dari revisi 1", revisions[2].Note)
+
+	_, err = u.DiffRevisions(artikel.ID, 1, 9)
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+}
+
+func TestSearchArtikel_StemmedTermsAndSnippet(t *testing.T) {
+	repo := &InMemoryArtikelRepo{}
+	u := newTestUsecase(repo)
+
+	published := model.ArtikelStatusPublished
+	_, _ = u.CreateArtikel(1, ArtikelInput{Judul: "Mengatasi Kecemasan", Isi: "<p>Rasa cemas bisa diredakan dengan latihan napas.</p>", Status: published})
+	_, _ = u.CreateArtikel(1, ArtikelInput{Judul: "Tidur Cukup", Isi: "<p>Tidur delapan jam sehari.</p>", Status: published})
+	_, _ = u.CreateArtikel(1, ArtikelInput{Judul: "Draft Cemas", Isi: "cemas", Status: model.ArtikelStatusDraft})
+
+	results, _, err := u.SearchArtikel("bagaimana mengatasi cemas", ArtikelSearchFilter{}, model.QueryOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "<mark>Mengatasi</mark> <mark>Kecemasan</mark>", results[0].JudulHighlight)
+	assert.Equal(t, "Rasa <mark>cemas</mark> bisa diredakan dengan latihan napas.", results[0].Snippet)
+
+	later := testNow.Add(time.Hour)
+	results, _, err = u.SearchArtikel("cemas", ArtikelSearchFilter{From: &later}, model.QueryOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	_, _, err = u.SearchArtikel("cemas", ArtikelSearchFilter{From: &later, To: &testNow}, model.QueryOptions{})
+	assert.ErrorIs(t, err, ErrInvalidArtikelSearch)
+	_, _, err = u.SearchArtikel("  ", ArtikelSearchFilter{}, model.QueryOptions{})
+	assert.ErrorIs(t, err, ErrInvalidArtikelSearch)
+}
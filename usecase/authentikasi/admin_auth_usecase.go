@@ -9,37 +9,33 @@ import (
 )
 
 type AdminAuthUsecase interface {
-	LoginAdmin(email, password string) (string, error)
+	LoginAdmin(email, password string) (*TokenPair, error)
 }
 
 type AdminAuthUsecaseImpl struct {
 	AdminRepo  repository.AdminAuthRepository
 	JWTService service.JWTService
+	Sessions   SessionUsecase
 }
 
-func NewAdminAuthUsecase(repo repository.AdminAuthRepository, jwt service.JWTService) AdminAuthUsecase {
-	return &AdminAuthUsecaseImpl{AdminRepo: repo, JWTService: jwt}
+func NewAdminAuthUsecase(repo repository.AdminAuthRepository, jwt service.JWTService, sessions SessionUsecase) AdminAuthUsecase {
+	return &AdminAuthUsecaseImpl{AdminRepo: repo, JWTService: jwt, Sessions: sessions}
 }
 
-func (u *AdminAuthUsecaseImpl) LoginAdmin(email, password string) (string, error) {
+func (u *AdminAuthUsecaseImpl) LoginAdmin(email, password string) (*TokenPair, error) {
 	if email == "" {
-		return "", errors.New("email is required")
+		return nil, errors.New("email is required")
 	}
 
 	user, err := u.AdminRepo.GetByEmail(email)
 	if err != nil || user == nil {
-		return "", errors.New("invalid credentials")
+		return nil, errors.New("invalid credentials")
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		return nil, errors.New("invalid credentials")
 	}
 
-	token, err := u.JWTService.GenerateJWT(user.Email, user.ID, user.Role, true)
-	if err != nil {
-		return "", err
-	}
-
-	return token, nil
+	return u.Sessions.IssueTokens(user.Email, user.ID, user.Role, true)
 }
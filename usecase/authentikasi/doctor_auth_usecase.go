@@ -14,9 +14,11 @@ import (
 
 type DoctorUsecase interface {
 	Register(*model.Doctor) error
-	Login(email string, password string) (string, error)
+	Login(email string, password string) (*TokenPair, error)
 	VerifyOtp(email string, code string) error
 	ResendOtp(email string) error
+	ForgotPassword(email string) error
+	ResetPassword(email, code, token, newPassword string) error
 }
 
 type doctorUsecase struct {
@@ -24,14 +26,16 @@ type doctorUsecase struct {
 	JWTService service.JWTService
 	OtpRepo    repository.OtpRepository
 	OtpService service.OtpService
+	Sessions   SessionUsecase
 }
 
-func NewDoctorAuthUsecase(repo repository.DoctorRepository, jwtService service.JWTService, otpRepo repository.OtpRepository, otpService service.OtpService) DoctorUsecase {
+func NewDoctorAuthUsecase(repo repository.DoctorRepository, jwtService service.JWTService, otpRepo repository.OtpRepository, otpService service.OtpService, sessions SessionUsecase) DoctorUsecase {
 	return &doctorUsecase{
 		DoctorRepo: repo,
 		JWTService: jwtService,
 		OtpRepo:    otpRepo,
 		OtpService: otpService,
+		Sessions:   sessions,
 	}
 }
 
@@ -90,30 +94,24 @@ func (u *doctorUsecase) Register(doctor *model.Doctor) error {
 	return nil
 }
 
-func (u *doctorUsecase) Login(email, password string) (string, error) {
+func (u *doctorUsecase) Login(email, password string) (*TokenPair, error) {
 	doctor, err := u.DoctorRepo.GetByEmail(email)
 	if err != nil || doctor == nil {
 		log.Printf("Login gagal: Email atau password salah untuk %s", email)
-		return "", errors.New("Email atau password salah. Mohon periksa kembali informasi Anda.")
+		return nil, errors.New("Email atau password salah. Mohon periksa kembali informasi Anda.")
 	}
 
 	if !doctor.IsVerified {
-		return "", errors.New("Akun Anda belum terverifikasi. Silakan verifikasi akun dengan kode OTP terlebih dahulu.")
+		return nil, errors.New("Akun Anda belum terverifikasi. Silakan verifikasi akun dengan kode OTP terlebih dahulu.")
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(doctor.Password), []byte(password))
 	if err != nil {
 		log.Printf("Login gagal: Password tidak cocok untuk %s", email)
-		return "", errors.New("Email atau password salah. Mohon periksa kembali informasi Anda.")
+		return nil, errors.New("Email atau password salah. Mohon periksa kembali informasi Anda.")
 	}
 
-	token, err := u.JWTService.GenerateJWT(doctor.Email, doctor.ID, doctor.Role, doctor.IsVerified)
-	if err != nil {
-		log.Printf("Gagal membuat token akses untuk %s: %s", email, err.Error())
-		return "", errors.New("Gagal membuat token akses. Terjadi masalah dengan sistem kami. Coba lagi nanti.")
-	}
-
-	return token, nil
+	return u.Sessions.IssueTokens(doctor.Email, doctor.ID, doctor.Role, doctor.IsVerified)
 }
 
 func (u *doctorUsecase) VerifyOtp(email string, code string) error {
@@ -174,3 +172,42 @@ func (u *doctorUsecase) ResendOtp(email string) error {
 
 	return nil
 }
+
+// Mengirim kode OTP dan tautan reset password, email yang tidak terdaftar tidak diberi tahu
+func (u *doctorUsecase) ForgotPassword(email string) error {
+	doctor, err := u.DoctorRepo.GetByEmail(email)
+	if err != nil || doctor == nil {
+		log.Printf("Permintaan reset password untuk email tidak terdaftar: %s", email)
+		return nil
+	}
+
+	return u.passwordReset().send(resetAccount{ID: doctor.ID, Email: doctor.Email, Role: "doctor", PasswordHash: doctor.Password})
+}
+
+// Mengganti password dengan kode OTP atau token tautan, lalu mencabut semua sesi yang aktif
+func (u *doctorUsecase) ResetPassword(email, code, token, newPassword string) error {
+	doctor, err := u.DoctorRepo.GetByEmail(email)
+	if err != nil || doctor == nil {
+		return errors.New("Kode OTP atau tautan reset password tidak valid.")
+	}
+
+	account := resetAccount{ID: doctor.ID, Email: doctor.Email, Role: "doctor", PasswordHash: doctor.Password}
+	reset := u.passwordReset()
+	hashPassword, err := reset.verify(account, code, token, newPassword)
+	if err != nil {
+		return err
+	}
+
+	err = u.DoctorRepo.UpdatePassword(doctor.ID, hashPassword, sessionsRevokedAt())
+	if err != nil {
+		log.Printf("Gagal memperbarui password dokter %s: %s", email, err.Error())
+		return errors.New("Gagal memperbarui password. Coba lagi nanti.")
+	}
+
+	reset.complete(account)
+	return nil
+}
+
+func (u *doctorUsecase) passwordReset() *passwordReset {
+	return &passwordReset{OtpRepo: u.OtpRepo, OtpService: u.OtpService, JWTService: u.JWTService}
+}
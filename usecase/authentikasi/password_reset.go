@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"calmind/helper"
+	"calmind/model"
+	repository "calmind/repository/authentikasi"
+	"calmind/service"
+	"errors"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Batas percobaan kode OTP reset password yang salah sebelum kode dianggap hangus
+const MaxPasswordResetAttempts = 5
+
+// Akun yang password-nya akan diatur ulang
+type resetAccount struct {
+	ID           int
+	Email        string
+	Role         string
+	PasswordHash string
+}
+
+// Kebutuhan bersama alur lupa password untuk user dan dokter
+type passwordReset struct {
+	OtpRepo    repository.OtpRepository
+	OtpService service.OtpService
+	JWTService service.JWTService
+}
+
+func passwordResetPurpose(role string) string {
+	return model.OtpPurposePasswordReset + ":" + role
+}
+
+// Mengirim kode OTP dan tautan reset ke email akun
+func (p *passwordReset) send(account resetAccount) error {
+	otpCode := p.OtpService.GenerateOtp()
+	expiry := time.Now().Add(service.PasswordResetTTL)
+
+	err := p.OtpRepo.SaveOtp(account.Email, otpCode, passwordResetPurpose(account.Role), expiry)
+	if err != nil {
+		log.Printf("Gagal menyimpan OTP reset password untuk %s: %s", account.Email, err.Error())
+		return errors.New("Gagal membuat kode OTP. Coba lagi nanti.")
+	}
+
+	resetLink := ""
+	token, err := p.JWTService.GeneratePasswordResetToken(account.Email, account.ID, account.Role, account.PasswordHash)
+	if err != nil {
+		log.Printf("Gagal membuat tautan reset password untuk %s: %s", account.Email, err.Error())
+	} else {
+		resetLink = passwordResetLink(account, token)
+	}
+
+	err = helper.SendPasswordResetEmail(account.Email, otpCode, resetLink)
+	if err != nil {
+		log.Printf("Gagal mengirim email reset password ke %s: %v", account.Email, err)
+		return errors.New("Gagal mengirim email reset password. Mohon periksa koneksi internet Anda dan coba lagi.")
+	}
+	return nil
+}
+
+// Tautan dibentuk dari PASSWORD_RESET_URL (halaman frontend), kosong jika variabel tidak diatur
+func passwordResetLink(account resetAccount, token string) string {
+	base := os.Getenv("PASSWORD_RESET_URL")
+	if base == "" {
+		return ""
+	}
+
+	link, err := url.Parse(base)
+	if err != nil {
+		log.Printf("PASSWORD_RESET_URL tidak valid: %s", err.Error())
+		return ""
+	}
+	query := link.Query()
+	query.Set("token", token)
+	query.Set("email", account.Email)
+	query.Set("role", account.Role)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// Memvalidasi kode OTP atau token tautan, lalu mengembalikan hash password baru
+func (p *passwordReset) verify(account resetAccount, code, token, newPassword string) (string, error) {
+	if !helper.IsValidPassword(newPassword) {
+		return "", errors.New("Password harus minimal 8 karakter dan mengandung huruf besar, huruf kecil, angka, dan simbol.")
+	}
+
+	purpose := passwordResetPurpose(account.Role)
+	if token != "" {
+		claims, err := p.JWTService.ParsePasswordResetToken(token, account.PasswordHash)
+		if err != nil || claims.UserID != account.ID || claims.Role != account.Role {
+			return "", errors.New("Tautan reset password tidak valid atau sudah kedaluwarsa.")
+		}
+	} else {
+		if code == "" {
+			return "", errors.New("Kode OTP atau tautan reset password wajib diisi.")
+		}
+
+		otp, err := p.OtpRepo.GetOtp(account.Email, purpose)
+		if err != nil {
+			log.Printf("Gagal mengambil OTP reset password untuk %s: %s", account.Email, err.Error())
+			return "", errors.New("Terjadi kesalahan saat mengambil data OTP. Coba lagi nanti.")
+		}
+		if otp == nil || otp.Attempts >= MaxPasswordResetAttempts {
+			return "", errors.New("Kode OTP tidak ditemukan. Mohon minta ulang kode reset password.")
+		}
+		if p.OtpService.IsOtpExpired(otp.ExpiresAt) {
+			return "", errors.New("Kode OTP sudah kedaluwarsa. Mohon minta ulang kode reset password.")
+		}
+		if otp.Code != strings.ToUpper(strings.TrimSpace(code)) {
+			if err := p.OtpRepo.IncrementOtpAttempts(otp.ID); err != nil {
+				log.Printf("Gagal mencatat percobaan OTP untuk %s: %s", account.Email, err.Error())
+			}
+			return "", errors.New("Kode OTP yang Anda masukkan tidak valid. Mohon coba lagi.")
+		}
+	}
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return "", errors.New("Gagal mengenkripsi password. Silakan coba lagi.")
+	}
+	return string(hashPassword), nil
+}
+
+// Menghapus kode OTP setelah password berhasil diganti
+func (p *passwordReset) complete(account resetAccount) {
+	if err := p.OtpRepo.DeleteOtp(account.Email, passwordResetPurpose(account.Role)); err != nil {
+		log.Printf("Gagal menghapus OTP reset password untuk %s: %s", account.Email, err.Error())
+	}
+}
+
+// Token dengan iat sebelum waktu ini ditolak oleh middleware, presisi detik mengikuti klaim JWT
+func sessionsRevokedAt() time.Time {
+	return time.Now().Truncate(time.Second)
+}
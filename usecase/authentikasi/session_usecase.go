@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"calmind/model"
+	repository "calmind/repository/authentikasi"
+	"calmind/service"
+	usecase_scheduler "calmind/usecase/scheduler"
+	"errors"
+	"log"
+	"time"
+)
+
+var ErrInvalidRefreshToken = errors.New("Refresh token tidak valid atau sudah kedaluwarsa. Silakan login kembali.")
+
+// Pasangan token yang dikembalikan saat login dan refresh
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // Masa berlaku access token (detik)
+}
+
+type SessionUsecase interface {
+	IssueTokens(email string, id int, role string, isVerified bool) (*TokenPair, error)
+	Refresh(role string, refreshToken string) (*TokenPair, error)
+	Logout(claims *service.JwtCustomClaims, refreshToken string) error
+	LogoutAll(claims *service.JwtCustomClaims) error
+	RegisterJobs()
+}
+
+type sessionUsecase struct {
+	Repo       repository.SessionRepository
+	JWTService service.JWTService
+	Scheduler  usecase_scheduler.SchedulerUsecase
+}
+
+func NewSessionUsecase(repo repository.SessionRepository, jwtService service.JWTService, scheduler usecase_scheduler.SchedulerUsecase) SessionUsecase {
+	return &sessionUsecase{Repo: repo, JWTService: jwtService, Scheduler: scheduler}
+}
+
+// Membuat sesi baru (family refresh token baru) setelah login berhasil
+func (u *sessionUsecase) IssueTokens(email string, id int, role string, isVerified bool) (*TokenPair, error) {
+	return u.issue(service.RandomToken(16), email, id, role, isVerified, nil)
+}
+
+// Menukar refresh token dengan pasangan token baru. Token lama langsung tidak berlaku (rotasi),
+// dan token yang dipakai ulang dianggap dicuri sehingga seluruh sesinya dicabut.
+func (u *sessionUsecase) Refresh(role string, refreshToken string) (*TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	current, err := u.Repo.GetRefreshTokenByHash(service.HashRefreshToken(refreshToken))
+	if err != nil {
+		log.Printf("Gagal mengambil refresh token: %v", err)
+		return nil, errors.New("Gagal memperbarui sesi. Coba lagi nanti.")
+	}
+	if current == nil || current.Role != role {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	now := time.Now()
+	if current.UsedAt != nil || current.RevokedAt != nil {
+		if current.RevokedAt == nil {
+			log.Printf("Refresh token %s %d dipakai ulang, mencabut sesi %s", current.Role, current.AccountID, current.FamilyID)
+		}
+		u.revokeFamily(current.FamilyID, now)
+		return nil, ErrInvalidRefreshToken
+	}
+	if now.After(current.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Sesi yang dibuat sebelum reset password atau "keluar dari semua perangkat" tidak boleh diperpanjang
+	revokedAt, err := u.Repo.GetSessionsRevokedAt(current.Role, current.AccountID)
+	if err != nil {
+		log.Printf("Gagal memeriksa sesi %s %d: %v", current.Role, current.AccountID, err)
+		return nil, errors.New("Gagal memperbarui sesi. Coba lagi nanti.")
+	}
+	if revokedAt != nil && current.CreatedAt.Before(*revokedAt) {
+		u.revokeFamily(current.FamilyID, now)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return u.issue(current.FamilyID, current.Email, current.AccountID, current.Role, current.IsVerified, current)
+}
+
+// Mencabut access token yang sedang dipakai dan sesi refresh token milik perangkat ini
+func (u *sessionUsecase) Logout(claims *service.JwtCustomClaims, refreshToken string) error {
+	if err := u.revokeAccessToken(claims); err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	current, err := u.Repo.GetRefreshTokenByHash(service.HashRefreshToken(refreshToken))
+	if err != nil {
+		log.Printf("Gagal mengambil refresh token: %v", err)
+		return errors.New("Gagal logout. Coba lagi nanti.")
+	}
+	if current == nil || current.Role != claims.Role || current.AccountID != claims.UserID {
+		return nil
+	}
+	if err := u.Repo.RevokeRefreshTokenFamily(current.FamilyID, time.Now()); err != nil {
+		log.Printf("Gagal mencabut refresh token %s: %v", current.FamilyID, err)
+		return errors.New("Gagal logout. Coba lagi nanti.")
+	}
+	return nil
+}
+
+// Keluar dari semua perangkat
+func (u *sessionUsecase) LogoutAll(claims *service.JwtCustomClaims) error {
+	if err := u.Repo.RevokeAllSessions(claims.Role, claims.UserID, sessionsRevokedAt()); err != nil {
+		log.Printf("Gagal mencabut semua sesi %s %d: %v", claims.Role, claims.UserID, err)
+		return errors.New("Gagal keluar dari semua perangkat. Coba lagi nanti.")
+	}
+	// Token yang diterbitkan pada detik yang sama belum tertolak oleh sessions_revoked_at
+	return u.revokeAccessToken(claims)
+}
+
+func (u *sessionUsecase) RegisterJobs() {
+	u.Scheduler.RegisterHandler(usecase_scheduler.JobAuthTokenCleanup, u.handleCleanupJob)
+	u.scheduleCleanup(time.Now())
+}
+
+func (u *sessionUsecase) handleCleanupJob(job *model.ScheduledJob) error {
+	if err := u.Repo.DeleteExpiredTokens(time.Now()); err != nil {
+		return err
+	}
+	u.scheduleCleanup(job.RunAt)
+	return nil
+}
+
+// Job berulang harian. Setiap putaran punya key per tanggal dan waktu jalan yang ditentukan dari tanggalnya,
+// jadi job yang sedang berjalan tidak perlu menjadwalkan ulang dirinya sendiri dan restart tidak menunda pembersihan.
+func (u *sessionUsecase) scheduleCleanup(after time.Time) {
+	runAt := NextTokenCleanup(after)
+	err := u.Scheduler.Schedule(usecase_scheduler.JobAuthTokenCleanup,
+		usecase_scheduler.JobDateKey(usecase_scheduler.JobAuthTokenCleanup, runAt), runAt, nil)
+	if err != nil {
+		log.Printf("Gagal menjadwalkan pembersihan token: %v", err)
+	}
+}
+
+// Pembersihan token berikutnya: tengah malam sesudah waktu yang diberikan
+func NextTokenCleanup(after time.Time) time.Time {
+	return time.Date(after.Year(), after.Month(), after.Day()+1, 0, 0, 0, 0, after.Location())
+}
+
+func (u *sessionUsecase) issue(familyID, email string, id int, role string, isVerified bool, previous *model.RefreshToken) (*TokenPair, error) {
+	accessToken, err := u.JWTService.GenerateJWT(email, id, role, isVerified)
+	if err != nil {
+		log.Printf("Gagal membuat token akses untuk %s: %s", email, err.Error())
+		return nil, errors.New("Gagal membuat token akses. Terjadi masalah dengan sistem kami. Coba lagi nanti.")
+	}
+
+	refreshToken := service.RandomToken(32)
+	now := time.Now()
+	next := &model.RefreshToken{
+		FamilyID:   familyID,
+		TokenHash:  service.HashRefreshToken(refreshToken),
+		Role:       role,
+		AccountID:  id,
+		Email:      email,
+		IsVerified: isVerified,
+		ExpiresAt:  now.Add(service.RefreshTokenTTL),
+	}
+
+	if previous == nil {
+		err = u.Repo.CreateRefreshToken(next)
+	} else {
+		err = u.Repo.RotateRefreshToken(previous, next, now)
+		if errors.Is(err, repository.ErrRefreshTokenReused) {
+			u.revokeFamily(familyID, now)
+			return nil, ErrInvalidRefreshToken
+		}
+	}
+	if err != nil {
+		log.Printf("Gagal menyimpan refresh token untuk %s: %v", email, err)
+		return nil, errors.New("Gagal membuat sesi login. Coba lagi nanti.")
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(service.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (u *sessionUsecase) revokeAccessToken(claims *service.JwtCustomClaims) error {
+	if claims.ID == "" {
+		return nil
+	}
+
+	expiresAt := time.Now().Add(service.AccessTokenTTL)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	err := u.Repo.RevokeAccessToken(&model.RevokedToken{
+		JTI:       claims.ID,
+		Role:      claims.Role,
+		AccountID: claims.UserID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		log.Printf("Gagal mencabut token %s %d: %v", claims.Role, claims.UserID, err)
+		return errors.New("Gagal logout. Coba lagi nanti.")
+	}
+	return nil
+}
+
+func (u *sessionUsecase) revokeFamily(familyID string, now time.Time) {
+	if err := u.Repo.RevokeRefreshTokenFamily(familyID, now); err != nil {
+		log.Printf("Gagal mencabut refresh token %s: %v", familyID, err)
+	}
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"calmind/config"
+	"calmind/model"
+	repository "calmind/repository/authentikasi"
+	"calmind/service"
+	usecase_scheduler "calmind/usecase/scheduler"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// In-memory implementation of the session repository for testing.
+type InMemorySessionRepo struct {
+	RefreshTokens []*model.RefreshToken
+	RevokedJTIs   map[string]bool
+	RevokedAt     *time.Time
+}
+
+func (repo *InMemorySessionRepo) GetSessionsRevokedAt(role string, id int) (*time.Time, error) {
+	return repo.RevokedAt, nil
+}
+
+func (repo *InMemorySessionRepo) IsAccessTokenRevoked(jti string) (bool, error) {
+	return repo.RevokedJTIs[jti], nil
+}
+
+func (repo *InMemorySessionRepo) RevokeAccessToken(token *model.RevokedToken) error {
+	if repo.RevokedJTIs == nil {
+		repo.RevokedJTIs = map[string]bool{}
+	}
+	repo.RevokedJTIs[token.JTI] = true
+	return nil
+}
+
+func (repo *InMemorySessionRepo) CreateRefreshToken(token *model.RefreshToken) error {
+	token.ID = len(repo.RefreshTokens) + 1
+	token.CreatedAt = time.Now()
+	repo.RefreshTokens = append(repo.RefreshTokens, token)
+	return nil
+}
+
+func (repo *InMemorySessionRepo) GetRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error) {
+	for _, token := range repo.RefreshTokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (repo *InMemorySessionRepo) RotateRefreshToken(current *model.RefreshToken, next *model.RefreshToken, now time.Time) error {
+	stored := repo.RefreshTokens[current.ID-1]
+	if stored.UsedAt != nil || stored.RevokedAt != nil {
+		return repository.ErrRefreshTokenReused
+	}
+	stored.UsedAt = &now
+	return repo.CreateRefreshToken(next)
+}
+
+func (repo *InMemorySessionRepo) RevokeRefreshTokenFamily(familyID string, now time.Time) error {
+	for _, token := range repo.RefreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (repo *InMemorySessionRepo) RevokeAllSessions(role string, id int, now time.Time) error {
+	repo.RevokedAt = &now
+	for _, token := range repo.RefreshTokens {
+		if token.Role == role && token.AccountID == id && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (repo *InMemorySessionRepo) DeleteExpiredTokens(before time.Time) error {
+	return nil
+}
+
+func newTestSessions() (SessionUsecase, *InMemorySessionRepo) {
+	repo := &InMemorySessionRepo{}
+	jwtService := service.NewJWTService(&config.JWTConfig{SecretKey: "test-secret-key"})
+	return NewSessionUsecase(repo, jwtService, nil), repo
+}
+
+func TestRefresh_RotatesToken(t *testing.T) {
+	sessions, _ := newTestSessions()
+
+	login, err := sessions.IssueTokens("user@example.com", 1, "user", true)
+	assert.NoError(t, err)
+
+	refreshed, err := sessions.Refresh("user", login.RefreshToken)
+	assert.NoError(t, err)
+	assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+	assert.NotEmpty(t, refreshed.AccessToken)
+
+	// Refresh token tidak berlaku untuk role lain
+	_, err = sessions.Refresh("doctor", refreshed.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestRefresh_ReuseRevokesFamily(t *testing.T) {
+	sessions, _ := newTestSessions()
+
+	login, _ := sessions.IssueTokens("user@example.com", 1, "user", true)
+	refreshed, err := sessions.Refresh("user", login.RefreshToken)
+	assert.NoError(t, err)
+
+	// Token lama dipakai ulang (misalnya dicuri), seluruh sesi dicabut termasuk token terbaru
+	_, err = sessions.Refresh("user", login.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	_, err = sessions.Refresh("user", refreshed.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestLogoutAll_RevokesEverySession(t *testing.T) {
+	sessions, repo := newTestSessions()
+
+	phone, _ := sessions.IssueTokens("user@example.com", 1, "user", true)
+	laptop, _ := sessions.IssueTokens("user@example.com", 1, "user", true)
+
+	claims := &service.JwtCustomClaims{UserID: 1, Role: "user"}
+	claims.ID = "current-jti"
+	assert.NoError(t, sessions.LogoutAll(claims))
+
+	assert.True(t, repo.RevokedJTIs["current-jti"])
+	_, err := sessions.Refresh("user", phone.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	_, err = sessions.Refresh("user", laptop.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+// Scheduler yang hanya mencatat job yang dijadwalkan
+type recordingScheduler struct {
+	usecase_scheduler.SchedulerUsecase
+	Keys  []string
+	RunAt []time.Time
+}
+
+func (s *recordingScheduler) RegisterHandler(jobType string, handler usecase_scheduler.JobHandler) {}
+
+func (s *recordingScheduler) Schedule(jobType, uniqueKey string, runAt time.Time, payload interface{}) error {
+	s.Keys = append(s.Keys, uniqueKey)
+	s.RunAt = append(s.RunAt, runAt)
+	return nil
+}
+
+func TestTokenCleanup_EachRunHasOwnKey(t *testing.T) {
+	scheduler := &recordingScheduler{}
+	sessions := &sessionUsecase{Repo: &InMemorySessionRepo{}, Scheduler: scheduler}
+
+	// Restart berkali-kali pada hari yang sama tidak menggeser jadwal
+	sessions.RegisterJobs()
+	sessions.RegisterJobs()
+	assert.Equal(t, scheduler.Keys[0], scheduler.Keys[1])
+	assert.Equal(t, scheduler.RunAt[0], scheduler.RunAt[1])
+
+	// Job yang sedang berjalan menjadwalkan putaran berikutnya dengan key baru
+	runAt := time.Date(2024, 12, 1, 0, 0, 0, 0, time.Local)
+	assert.NoError(t, sessions.handleCleanupJob(&model.ScheduledJob{RunAt: runAt}))
+	assert.Equal(t, "auth_token_cleanup:2024-12-02", scheduler.Keys[2])
+	assert.Equal(t, runAt.AddDate(0, 0, 1), scheduler.RunAt[2])
+}
@@ -14,9 +14,11 @@ import (
 
 type UserUsecase interface {
 	Register(*model.User) error
-	Login(email string, password string) (string, error)
+	Login(email string, password string) (*TokenPair, error)
 	VerifyOtp(email string, code string) error
 	ResendOtp(email string) error
+	ForgotPassword(email string) error
+	ResetPassword(email, code, token, newPassword string) error
 }
 
 type AuthUsecase struct {
@@ -24,10 +26,11 @@ type AuthUsecase struct {
 	JWTService service.JWTService
 	OtpRepo    repository.OtpRepository
 	OtpService service.OtpService
+	Sessions   SessionUsecase
 }
 
-func NewAuthUsecase(repo repository.UserRepository, jwtService service.JWTService, otpRepo repository.OtpRepository, otpService service.OtpService) UserUsecase {
-	return &AuthUsecase{UserRepo: repo, JWTService: jwtService, OtpRepo: otpRepo, OtpService: otpService}
+func NewAuthUsecase(repo repository.UserRepository, jwtService service.JWTService, otpRepo repository.OtpRepository, otpService service.OtpService, sessions SessionUsecase) UserUsecase {
+	return &AuthUsecase{UserRepo: repo, JWTService: jwtService, OtpRepo: otpRepo, OtpService: otpService, Sessions: sessions}
 }
 
 func (u *AuthUsecase) Register(user *model.User) error {
@@ -81,30 +84,24 @@ func (u *AuthUsecase) Register(user *model.User) error {
 	return nil
 }
 
-func (u *AuthUsecase) Login(email, password string) (string, error) {
+func (u *AuthUsecase) Login(email, password string) (*TokenPair, error) {
 	user, err := u.UserRepo.GetByUsername(email)
 	if err != nil || user == nil {
 		log.Printf("Login gagal: Email atau password salah untuk %s", email)
-		return "", errors.New("Email atau password salah. Mohon periksa kembali informasi Anda.")
+		return nil, errors.New("Email atau password salah. Mohon periksa kembali informasi Anda.")
 	}
 
 	if !user.IsVerified {
-		return "", errors.New("Akun Anda belum terverifikasi. Silakan verifikasi akun dengan kode OTP terlebih dahulu.")
+		return nil, errors.New("Akun Anda belum terverifikasi. Silakan verifikasi akun dengan kode OTP terlebih dahulu.")
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
 		log.Printf("Login gagal: Password tidak cocok untuk %s", email)
-		return "", errors.New("Email atau password salah. Mohon periksa kembali informasi Anda.")
+		return nil, errors.New("Email atau password salah. Mohon periksa kembali informasi Anda.")
 	}
 
-	token, err := u.JWTService.GenerateJWT(user.Email, user.ID, user.Role, user.IsVerified)
-	if err != nil {
-		log.Printf("Gagal membuat token akses untuk %s: %s", email, err.Error())
-		return "", errors.New("Gagal membuat token akses. Terjadi masalah dengan sistem kami. Coba lagi nanti.")
-	}
-
-	return token, nil
+	return u.Sessions.IssueTokens(user.Email, user.ID, user.Role, user.IsVerified)
 }
 
 func (a *AuthUsecase) VerifyOtp(email string, code string) error {
@@ -165,3 +162,42 @@ func (u *AuthUsecase) ResendOtp(email string) error {
 
 	return nil
 }
+
+// Mengirim kode OTP dan tautan reset password, email yang tidak terdaftar tidak diberi tahu
+func (u *AuthUsecase) ForgotPassword(email string) error {
+	user, err := u.UserRepo.GetByUsername(email)
+	if err != nil || user == nil {
+		log.Printf("Permintaan reset password untuk email tidak terdaftar: %s", email)
+		return nil
+	}
+
+	return u.passwordReset().send(resetAccount{ID: user.ID, Email: user.Email, Role: "user", PasswordHash: user.Password})
+}
+
+// Mengganti password dengan kode OTP atau token tautan, lalu mencabut semua sesi yang aktif
+func (u *AuthUsecase) ResetPassword(email, code, token, newPassword string) error {
+	user, err := u.UserRepo.GetByUsername(email)
+	if err != nil || user == nil {
+		return errors.New("Kode OTP atau tautan reset password tidak valid.")
+	}
+
+	account := resetAccount{ID: user.ID, Email: user.Email, Role: "user", PasswordHash: user.Password}
+	reset := u.passwordReset()
+	hashPassword, err := reset.verify(account, code, token, newPassword)
+	if err != nil {
+		return err
+	}
+
+	err = u.UserRepo.UpdatePassword(user.ID, hashPassword, sessionsRevokedAt())
+	if err != nil {
+		log.Printf("Gagal memperbarui password user %s: %s", email, err.Error())
+		return errors.New("Gagal memperbarui password. Coba lagi nanti.")
+	}
+
+	reset.complete(account)
+	return nil
+}
+
+func (u *AuthUsecase) passwordReset() *passwordReset {
+	return &passwordReset{OtpRepo: u.OtpRepo, OtpService: u.OtpService, JWTService: u.JWTService}
+}
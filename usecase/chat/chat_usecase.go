@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"calmind/model"
+	repository "calmind/repository/chat"
+	"calmind/service/lifecycle"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	maxMessageLength    = 2000
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 100
+)
+
+var (
+	ErrNotParticipant = errors.New("anda bukan peserta konsultasi ini")
+	ErrChatClosed     = errors.New("chat hanya dapat digunakan selama jadwal konsultasi yang telah disetujui")
+	ErrInvalidMessage = errors.New("pesan tidak boleh kosong dan maksimal 2000 karakter")
+)
+
+// Dipanggil saat pesan pertama dikirim agar konsultasi berpindah ke status in_session
+type SessionStarter interface {
+	StartSession(consultationID int, actorRole string, actorID int) error
+}
+
+type ChatUsecase interface {
+	Authorize(consultationID int, role string, actorID int) (*model.Consultation, error)
+	CanSend(consultation *model.Consultation) error
+	SendMessage(consultationID int, role string, actorID int, content string) (*model.ConsultationMessage, error)
+	GetMessages(consultationID int, role string, actorID, beforeID, limit int) ([]model.ConsultationMessage, error)
+}
+
+type ChatUsecaseImpl struct {
+	Repo           repository.ChatRepository
+	SessionStarter SessionStarter
+	Now            func() time.Time
+}
+
+func NewChatUsecase(repo repository.ChatRepository, sessionStarter SessionStarter) ChatUsecase {
+	return &ChatUsecaseImpl{Repo: repo, SessionStarter: sessionStarter, Now: time.Now}
+}
+
+// Memastikan pengguna adalah user atau dokter dari konsultasi tersebut
+func (u *ChatUsecaseImpl) Authorize(consultationID int, role string, actorID int) (*model.Consultation, error) {
+	consultation, err := u.Repo.GetConsultationByID(consultationID)
+	if err != nil {
+		return nil, fmt.Errorf("consultation not found: %w", err)
+	}
+
+	switch {
+	case role == lifecycle.ActorUser && consultation.UserID == actorID:
+	case role == lifecycle.ActorDoctor && consultation.DoctorID == actorID:
+	default:
+		return nil, ErrNotParticipant
+	}
+	return consultation, nil
+}
+
+// Pesan hanya boleh dikirim saat konsultasi aktif dan berada di dalam slot waktunya
+func (u *ChatUsecaseImpl) CanSend(consultation *model.Consultation) error {
+	if consultation.Status != lifecycle.StatusApproved && consultation.Status != lifecycle.StatusInSession {
+		return ErrChatClosed
+	}
+
+	now := u.Now()
+	endTime := consultation.StartTime.Add(time.Duration(consultation.Duration) * time.Minute)
+	if now.Before(consultation.StartTime) || !now.Before(endTime) {
+		return ErrChatClosed
+	}
+	return nil
+}
+
+func (u *ChatUsecaseImpl) SendMessage(consultationID int, role string, actorID int, content string) (*model.ConsultationMessage, error) {
+	content = strings.TrimSpace(content)
+	if content == "" || len([]rune(content)) > maxMessageLength {
+		return nil, ErrInvalidMessage
+	}
+
+	consultation, err := u.Authorize(consultationID, role, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.CanSend(consultation); err != nil {
+		return nil, err
+	}
+
+	message := &model.ConsultationMessage{
+		ConsultationID: consultationID,
+		SenderRole:     role,
+		SenderID:       actorID,
+		Content:        content,
+	}
+	if err := u.Repo.SaveMessage(message); err != nil {
+		return nil, fmt.Errorf("failed to save message: %v", err)
+	}
+
+	if consultation.Status == lifecycle.StatusApproved && u.SessionStarter != nil {
+		if err := u.SessionStarter.StartSession(consultationID, role, actorID); err != nil {
+			log.Printf("Failed to start session for consultation %d: %v", consultationID, err)
+		}
+	}
+
+	return message, nil
+}
+
+// Riwayat pesan dapat dibaca peserta kapan saja
+func (u *ChatUsecaseImpl) GetMessages(consultationID int, role string, actorID, beforeID, limit int) ([]model.ConsultationMessage, error) {
+	if _, err := u.Authorize(consultationID, role, actorID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+	return u.Repo.GetMessages(consultationID, beforeID, limit)
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"calmind/model"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// In-memory implementation of the chat repository for testing.
+type InMemoryChatRepo struct {
+	Consultation model.Consultation
+	Messages     []model.ConsultationMessage
+}
+
+func (repo *InMemoryChatRepo) GetConsultationByID(consultationID int) (*model.Consultation, error) {
+	if repo.Consultation.ID != consultationID {
+		return nil, errors.New("record not found")
+	}
+	consultation := repo.Consultation
+	return &consultation, nil
+}
+
+func (repo *InMemoryChatRepo) SaveMessage(message *model.ConsultationMessage) error {
+	message.ID = len(repo.Messages) + 1
+	repo.Messages = append(repo.Messages, *message)
+	return nil
+}
+
+func (repo *InMemoryChatRepo) GetMessages(consultationID, beforeID, limit int) ([]model.ConsultationMessage, error) {
+	return repo.Messages, nil
+}
+
+type recordingStarter struct {
+	Started []int
+}
+
+func (s *recordingStarter) StartSession(consultationID int, actorRole string, actorID int) error {
+	s.Started = append(s.Started, consultationID)
+	return nil
+}
+
+func newTestChat(status string, now time.Time) (*ChatUsecaseImpl, *InMemoryChatRepo, *recordingStarter) {
+	repo := &InMemoryChatRepo{Consultation: model.Consultation{
+		ID:        1,
+		UserID:    10,
+		DoctorID:  20,
+		Status:    status,
+		StartTime: time.Date(2024, 12, 1, 9, 0, 0, 0, time.UTC),
+		Duration:  60,
+	}}
+	starter := &recordingStarter{}
+	chat := NewChatUsecase(repo, starter).(*ChatUsecaseImpl)
+	chat.Now = func() time.Time { return now }
+	return chat, repo, starter
+}
+
+func TestSendMessage_InsideWindow(t *testing.T) {
+	chat, repo, starter := newTestChat("approved", time.Date(2024, 12, 1, 9, 30, 0, 0, time.UTC))
+
+	message, err := chat.SendMessage(1, "user", 10, "  Halo dokter  ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Halo dokter", message.Content)
+	assert.Len(t, repo.Messages, 1)
+	assert.Equal(t, []int{1}, starter.Started, "Pesan pertama memulai sesi konsultasi")
+}
+
+func TestSendMessage_Rejected(t *testing.T) {
+	// Di luar slot waktu
+	chat, _, _ := newTestChat("approved", time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC))
+	_, err := chat.SendMessage(1, "doctor", 20, "Halo")
+	assert.ErrorIs(t, err, ErrChatClosed)
+
+	// Konsultasi belum disetujui
+	chat, _, _ = newTestChat("paid", time.Date(2024, 12, 1, 9, 30, 0, 0, time.UTC))
+	_, err = chat.SendMessage(1, "user", 10, "Halo")
+	assert.ErrorIs(t, err, ErrChatClosed)
+
+	// Bukan peserta konsultasi
+	chat, _, _ = newTestChat("in_session", time.Date(2024, 12, 1, 9, 30, 0, 0, time.UTC))
+	_, err = chat.SendMessage(1, "doctor", 99, "Halo")
+	assert.ErrorIs(t, err, ErrNotParticipant)
+
+	// Pesan kosong
+	_, err = chat.SendMessage(1, "user", 10, "   ")
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+}
@@ -4,53 +4,392 @@ import (
 	"calmind/helper"
 	"calmind/model"
 	repository "calmind/repository/chatbot_ai"
+	"calmind/service"
+	"calmind/service/safety"
 	"context"
+	"errors"
+	"fmt"
+	"log"
 	"strings"
+	"unicode/utf8"
 )
 
+const (
+	// Jumlah pasangan pesan terakhir yang selalu dikirim utuh ke AI
+	ContextWindowSize = 10
+	// Ringkasan diperbarui setelah pesan di luar jendela konteks mencapai jumlah ini
+	SummaryBatchSize = 10
+
+	maxTitleLength      = 100
+	autoTitleLength     = 50
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 100
+
+	legacySessionTitle = "Percakapan sebelumnya"
+)
+
+var (
+	ErrSessionNotFound = errors.New("sesi percakapan tidak ditemukan")
+	ErrInvalidTitle    = errors.New("judul sesi wajib diisi dan maksimal 100 karakter")
+	ErrEmptyMessage    = errors.New("pesan tidak boleh kosong")
+)
+
+// Hasil satu giliran percakapan
+type ChatReply struct {
+	SessionID int    `json:"session_id"`
+	Message   string `json:"message"`
+	Response  string `json:"response"`
+	Flagged   bool   `json:"flagged"` // Pesan terdeteksi berisiko, aplikasi dapat menampilkan kontak bantuan
+}
+
 type ChatbotUsecase interface {
-	GenerateResponse(userID int, message string) (string, error)
+	GenerateResponse(userID, sessionID int, message string) (*ChatReply, error)
+	StreamResponse(ctx context.Context, userID, sessionID int, message string, onChunk func(chunk string) error) (*ChatReply, error)
+
+	CreateSession(userID int, title string) (*model.ChatSession, error)
+	ListSessions(userID int, opts model.QueryOptions) ([]model.ChatSession, model.PageInfo, error)
+	RenameSession(userID, sessionID int, title string) (*model.ChatSession, error)
+	DeleteSession(userID, sessionID int) error
+	GetSessionHistory(userID, sessionID, beforeID, limit int) ([]model.ChatLog, error)
 }
 
 type ChatbotUsecaseImpl struct {
 	ChatLogRepo repository.ChatLogRepository
+	LLM         service.LLMProvider
+	Safety      safety.Classifier
 }
 
-func NewChatbotUsecase(chatLogRepo repository.ChatLogRepository) ChatbotUsecase {
-	return &ChatbotUsecaseImpl{ChatLogRepo: chatLogRepo}
+func NewChatbotUsecase(chatLogRepo repository.ChatLogRepository, llm service.LLMProvider, classifier safety.Classifier) ChatbotUsecase {
+	return &ChatbotUsecaseImpl{ChatLogRepo: chatLogRepo, LLM: llm, Safety: classifier}
 }
 
-func (u *ChatbotUsecaseImpl) GenerateResponse(userID int, message string) (string, error) {
-	// Ambil log percakapan sebelumnya
-	logs, err := u.ChatLogRepo.GetLogsByUserID(userID)
-	if err != nil {
-		return "", err
+// Mengirim pesan ke AI. sessionID = 0 membuat sesi baru dengan judul dari pesan pertama.
+func (u *ChatbotUsecaseImpl) GenerateResponse(userID, sessionID int, message string) (*ChatReply, error) {
+	return u.respond(context.Background(), userID, sessionID, message, nil)
+}
+
+// Sama seperti GenerateResponse, tetapi jawaban dikirim ke onChunk per kalimat selama model menulis.
+// Log baru disimpan setelah stream selesai, stream yang dibatalkan tidak disimpan.
+func (u *ChatbotUsecaseImpl) StreamResponse(ctx context.Context, userID, sessionID int, message string, onChunk func(chunk string) error) (*ChatReply, error) {
+	return u.respond(ctx, userID, sessionID, message, onChunk)
+}
+
+func (u *ChatbotUsecaseImpl) respond(ctx context.Context, userID, sessionID int, message string, onChunk func(chunk string) error) (*ChatReply, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, ErrEmptyMessage
+	}
+
+	// Sesi baru belum disimpan sampai jawaban siap, lalu dibuat bersama log pertamanya
+	session := &model.ChatSession{UserID: userID, Title: titleFromMessage(message)}
+	if sessionID != 0 {
+		var err error
+		if session, err = u.getSession(userID, sessionID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Pesan berisiko tinggi tidak dikirim ke model, langsung dijawab dengan jawaban krisis
+	assessment := u.Safety.ClassifyMessage(ctx, message)
+	if assessment.Level == safety.RiskHigh {
+		if onChunk != nil {
+			if err := onChunk(safety.CrisisResponse); err != nil {
+				return nil, err
+			}
+		}
+		return u.saveFlagged(session, userID, message, assessment, "message")
 	}
 
-	// Gabungkan percakapan sebelumnya
-	var contextBuilder strings.Builder
-	for _, log := range logs {
-		contextBuilder.WriteString(log.Message + "\n")
-		contextBuilder.WriteString(log.Response + "\n")
+	// Ambil log yang belum diringkas, lalu ringkas bagian lama jika sudah melewati batas. Sesi baru belum punya log,
+	// dan session_id 0 justru menandai log lama tanpa sesi.
+	var recent []model.ChatLog
+	if session.ID != 0 {
+		logs, err := u.ChatLogRepo.GetLogsAfter(session.ID, session.SummarizedUntilID)
+		if err != nil {
+			return nil, err
+		}
+		var older []model.ChatLog
+		older, recent = splitContext(logs)
+		if len(older) > 0 {
+			if err := u.summarize(ctx, session, older); err != nil {
+				// Ringkasan gagal tidak menghentikan percakapan, log lama dikirim apa adanya
+				log.Printf("Gagal meringkas sesi chatbot %d: %v", session.ID, err)
+				recent = logs
+			}
+		}
 	}
-	contextBuilder.WriteString(message + "\n")
 
 	// Panggil AI untuk respons
-	ctx := context.Background()
-	response, err := helper.ResponseAI(ctx, contextBuilder.String())
-	if err != nil {
-		return "", err
+	prompt := buildChatPrompt(session.Summary, recent, message)
+	var response string
+	if onChunk == nil {
+		answer, err := u.LLM.Generate(ctx, prompt)
+		if err != nil {
+			return nil, err
+		}
+		response = helper.CleanAIResponse(answer)
+	} else {
+		// Jawaban ditahan per kalimat dan diperiksa sebelum dikirim. Begitu ada yang tidak aman,
+		// stream dihentikan dan klien menerima jawaban krisis sebagai pengganti.
+		guard := &responseGuard{safety: u.Safety, send: onChunk}
+		streamCtx, cancel := context.WithCancel(ctx)
+		answer, err := u.LLM.Stream(streamCtx, prompt, guard.write)
+		cancel()
+		if errors.Is(err, errUnsafeResponse) {
+			if err := onChunk(safety.CrisisResponse); err != nil {
+				return nil, err
+			}
+			return u.saveFlagged(session, userID, message, guard.check, "response")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := guard.flush(); err != nil {
+			return nil, err
+		}
+		response = strings.TrimSpace(answer)
+	}
+
+	// Jawaban model yang tidak aman diganti dengan jawaban krisis
+	if check := u.Safety.CheckResponse(response); check.Level == safety.RiskHigh {
+		return u.saveFlagged(session, userID, message, check, "response")
+	}
+	if assessment.Level == safety.RiskMedium {
+		response += safety.SupportNote
+		if onChunk != nil {
+			if err := onChunk(safety.SupportNote); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Simpan log percakapan baru
 	newLog := model.ChatLog{
-		UserID:   userID,
-		Message:  message,
-		Response: response,
+		UserID:    userID,
+		Message:   message,
+		Response:  response,
+		RiskLevel: assessment.Level,
+		Flagged:   assessment.Flagged(),
+	}
+	if err := u.ChatLogRepo.SaveLog(session, &newLog); err != nil {
+		return nil, err
+	}
+
+	return &ChatReply{SessionID: session.ID, Message: message, Response: response, Flagged: newLog.Flagged}, nil
+}
+
+// Menghentikan stream saat jawaban model terdeteksi tidak aman
+var errUnsafeResponse = errors.New("jawaban model tidak aman")
+
+// Menahan potongan stream sampai kalimatnya lengkap. Seluruh jawaban sejauh ini diperiksa setiap ada potongan baru,
+// jadi kalimat baru dikirim ke klien setelah lolos pemeriksaan.
+type responseGuard struct {
+	safety  safety.Classifier
+	send    func(chunk string) error
+	written strings.Builder // Seluruh jawaban yang sudah diterima dari model
+	pending strings.Builder // Bagian yang belum dikirim ke klien
+	check   safety.Assessment
+}
+
+func (g *responseGuard) write(chunk string) error {
+	g.written.WriteString(chunk)
+	g.pending.WriteString(chunk)
+	if g.check = g.safety.CheckResponse(g.written.String()); g.check.Level == safety.RiskHigh {
+		return errUnsafeResponse
+	}
+
+	pending := g.pending.String()
+	end := strings.LastIndexAny(pending, ".!?\n")
+	if end < 0 {
+		return nil
+	}
+	g.pending.Reset()
+	g.pending.WriteString(pending[end+1:])
+	return g.send(pending[:end+1])
+}
+
+// Mengirim sisa jawaban yang belum diakhiri tanda kalimat setelah stream selesai
+func (g *responseGuard) flush() error {
+	if g.pending.Len() == 0 {
+		return nil
+	}
+	rest := g.pending.String()
+	g.pending.Reset()
+	return g.send(rest)
+}
+
+// Menyimpan jawaban krisis dan membuat peringatan untuk ditindaklanjuti admin
+func (u *ChatbotUsecaseImpl) saveFlagged(session *model.ChatSession, userID int, message string, assessment safety.Assessment, flaggedOn string) (*ChatReply, error) {
+	chatLog := model.ChatLog{
+		UserID:    userID,
+		Message:   message,
+		Response:  safety.CrisisResponse,
+		RiskLevel: assessment.Level,
+		Flagged:   true,
+	}
+	alert := model.SafetyAlert{
+		UserID:    userID,
+		RiskLevel: assessment.Level,
+		Source:    assessment.Source,
+		FlaggedOn: flaggedOn,
+		Matches:   strings.Join(assessment.Matches, ", "),
+		Message:   message,
+		Status:    model.SafetyAlertStatusOpen,
+	}
+	if err := u.ChatLogRepo.SaveFlaggedLog(session, &chatLog, &alert); err != nil {
+		return nil, err
+	}
+
+	return &ChatReply{SessionID: session.ID, Message: message, Response: safety.CrisisResponse, Flagged: true}, nil
+}
+
+func (u *ChatbotUsecaseImpl) CreateSession(userID int, title string) (*model.ChatSession, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = "Percakapan baru"
+	}
+	if utf8.RuneCountInString(title) > maxTitleLength {
+		return nil, ErrInvalidTitle
 	}
-	if err := u.ChatLogRepo.SaveLog(&newLog); err != nil {
-		return "", err
+
+	session := &model.ChatSession{UserID: userID, Title: title}
+	if err := u.ChatLogRepo.CreateSession(session); err != nil {
+		return nil, fmt.Errorf("failed to create chat session: %v", err)
 	}
+	return session, nil
+}
 
-	return response, nil
+// Daftar sesi, log lama tanpa sesi dipindahkan ke satu sesi tersendiri
+func (u *ChatbotUsecaseImpl) ListSessions(userID int, opts model.QueryOptions) ([]model.ChatSession, model.PageInfo, error) {
+	orphans, err := u.ChatLogRepo.CountOrphanLogs(userID)
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+	if orphans > 0 {
+		session, err := u.CreateSession(userID, legacySessionTitle)
+		if err != nil {
+			return nil, model.PageInfo{}, err
+		}
+		if err := u.ChatLogRepo.AssignOrphanLogs(userID, session.ID); err != nil {
+			return nil, model.PageInfo{}, fmt.Errorf("failed to move legacy chat logs: %v", err)
+		}
+	}
+
+	return u.ChatLogRepo.GetSessionsByUserID(userID, opts)
+}
+
+func (u *ChatbotUsecaseImpl) RenameSession(userID, sessionID int, title string) (*model.ChatSession, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || utf8.RuneCountInString(title) > maxTitleLength {
+		return nil, ErrInvalidTitle
+	}
+
+	session, err := u.getSession(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.ChatLogRepo.UpdateSessionTitle(session.ID, title); err != nil {
+		return nil, fmt.Errorf("failed to rename chat session: %v", err)
+	}
+
+	session.Title = title
+	return session, nil
+}
+
+func (u *ChatbotUsecaseImpl) DeleteSession(userID, sessionID int) error {
+	session, err := u.getSession(userID, sessionID)
+	if err != nil {
+		return err
+	}
+	return u.ChatLogRepo.DeleteSession(session.ID)
+}
+
+func (u *ChatbotUsecaseImpl) GetSessionHistory(userID, sessionID, beforeID, limit int) ([]model.ChatLog, error) {
+	session, err := u.getSession(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+	return u.ChatLogRepo.GetSessionLogs(session.ID, beforeID, limit)
+}
+
+func (u *ChatbotUsecaseImpl) getSession(userID, sessionID int) (*model.ChatSession, error) {
+	session, err := u.ChatLogRepo.GetSessionByID(userID, sessionID)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// Memperbarui ringkasan berjalan dengan log yang keluar dari jendela konteks
+func (u *ChatbotUsecaseImpl) summarize(ctx context.Context, session *model.ChatSession, older []model.ChatLog) error {
+	var prompt strings.Builder
+	prompt.WriteString("Ringkas percakapan konseling berikut dalam maksimal 5 kalimat. ")
+	prompt.WriteString("Pertahankan keluhan utama, perasaan, dan saran penting yang sudah diberikan.\n\n")
+	if session.Summary != "" {
+		prompt.WriteString("Ringkasan sebelumnya:\n" + session.Summary + "\n\n")
+	}
+	prompt.WriteString("Percakapan lanjutan:\n")
+	writeTurns(&prompt, older)
+
+	summary, err := u.LLM.Generate(ctx, prompt.String())
+	if err != nil {
+		return err
+	}
+	summary = strings.TrimSpace(summary)
+
+	untilID := older[len(older)-1].ID
+	if err := u.ChatLogRepo.UpdateSessionSummary(session.ID, summary, untilID); err != nil {
+		return err
+	}
+	session.Summary = summary
+	session.SummarizedUntilID = untilID
+	return nil
+}
+
+// Memisahkan log yang perlu diringkas dari log terbaru yang dikirim utuh
+func splitContext(logs []model.ChatLog) (older, recent []model.ChatLog) {
+	if len(logs) < ContextWindowSize+SummaryBatchSize {
+		return nil, logs
+	}
+	cut := len(logs) - ContextWindowSize
+	return logs[:cut], logs[cut:]
+}
+
+// Menyusun prompt dari ringkasan, percakapan terakhir, dan pesan baru
+func buildChatPrompt(summary string, recent []model.ChatLog, message string) string {
+	var prompt strings.Builder
+	if summary != "" {
+		prompt.WriteString("Ringkasan percakapan sebelumnya:\n" + summary + "\n\n")
+	}
+	if len(recent) > 0 {
+		prompt.WriteString("Percakapan terakhir:\n")
+		writeTurns(&prompt, recent)
+		prompt.WriteString("\n")
+	}
+	prompt.WriteString("User: " + message + "\n")
+	return prompt.String()
+}
+
+func writeTurns(prompt *strings.Builder, logs []model.ChatLog) {
+	for _, chatLog := range logs {
+		prompt.WriteString("User: " + chatLog.Message + "\n")
+		prompt.WriteString("Asisten: " + chatLog.Response + "\n")
+	}
+}
+
+// Judul otomatis dari potongan pesan pertama
+func titleFromMessage(message string) string {
+	title := strings.Join(strings.Fields(message), " ")
+	if utf8.RuneCountInString(title) <= autoTitleLength {
+		return title
+	}
+	runes := []rune(title)
+	return strings.TrimSpace(string(runes[:autoTitleLength])) + "..."
 }
@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"calmind/model"
+	"calmind/service"
+	"calmind/service/safety"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// In-memory implementation of the chat log repository for testing.
+type InMemoryChatLogRepo struct {
+	Logs     []model.ChatLog
+	Sessions []*model.ChatSession
+	Alerts   []model.SafetyAlert
+}
+
+func (repo *InMemoryChatLogRepo) SaveLog(session *model.ChatSession, chatLog *model.ChatLog) error {
+	if session.ID == 0 {
+		repo.CreateSession(session)
+	}
+	chatLog.ID = len(repo.Logs) + 1
+	chatLog.SessionID = session.ID
+	repo.Logs = append(repo.Logs, *chatLog)
+	return nil
+}
+
+func (repo *InMemoryChatLogRepo) SaveFlaggedLog(session *model.ChatSession, chatLog *model.ChatLog, alert *model.SafetyAlert) error {
+	repo.SaveLog(session, chatLog)
+	alert.SessionID = session.ID
+	alert.ChatLogID = chatLog.ID
+	repo.Alerts = append(repo.Alerts, *alert)
+	return nil
+}
+
+func (repo *InMemoryChatLogRepo) GetLogsAfter(sessionID, afterID int) ([]model.ChatLog, error) {
+	var logs []model.ChatLog
+	for _, chatLog := range repo.Logs {
+		if chatLog.SessionID == sessionID && chatLog.ID > afterID {
+			logs = append(logs, chatLog)
+		}
+	}
+	return logs, nil
+}
+
+func (repo *InMemoryChatLogRepo) GetSessionLogs(sessionID, beforeID, limit int) ([]model.ChatLog, error) {
+	return repo.GetLogsAfter(sessionID, 0)
+}
+
+func (repo *InMemoryChatLogRepo) CreateSession(session *model.ChatSession) error {
+	session.ID = len(repo.Sessions) + 1
+	repo.Sessions = append(repo.Sessions, session)
+	return nil
+}
+
+func (repo *InMemoryChatLogRepo) GetSessionsByUserID(userID int, opts model.QueryOptions) ([]model.ChatSession, model.PageInfo, error) {
+	var sessions []model.ChatSession
+	for _, session := range repo.Sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, model.NewPageInfo(opts, int64(len(sessions))), nil
+}
+
+func (repo *InMemoryChatLogRepo) GetSessionByID(userID, sessionID int) (*model.ChatSession, error) {
+	for _, session := range repo.Sessions {
+		if session.ID == sessionID && session.UserID == userID {
+			copied := *session
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (repo *InMemoryChatLogRepo) UpdateSessionTitle(sessionID int, title string) error {
+	repo.Sessions[sessionID-1].Title = title
+	return nil
+}
+
+func (repo *InMemoryChatLogRepo) UpdateSessionSummary(sessionID int, summary string, summarizedUntilID int) error {
+	repo.Sessions[sessionID-1].Summary = summary
+	repo.Sessions[sessionID-1].SummarizedUntilID = summarizedUntilID
+	return nil
+}
+
+func (repo *InMemoryChatLogRepo) DeleteSession(sessionID int) error {
+	return nil
+}
+
+func (repo *InMemoryChatLogRepo) CountOrphanLogs(userID int) (int64, error) {
+	return 0, nil
+}
+
+func (repo *InMemoryChatLogRepo) AssignOrphanLogs(userID, sessionID int) error {
+	return nil
+}
+
+func makeLogs(n int) []model.ChatLog {
+	logs := make([]model.ChatLog, n)
+	for i := range logs {
+		logs[i] = model.ChatLog{ID: i + 1, Message: "pesan", Response: "jawaban"}
+	}
+	return logs
+}
+
+func TestSplitContext(t *testing.T) {
+	older, recent := splitContext(makeLogs(ContextWindowSize + SummaryBatchSize - 1))
+	assert.Empty(t, older)
+	assert.Len(t, recent, ContextWindowSize+SummaryBatchSize-1)
+
+	// Setelah mencapai batas, bagian lama diringkas dan hanya jendela terakhir dikirim utuh
+	older, recent = splitContext(makeLogs(ContextWindowSize + SummaryBatchSize))
+	assert.Len(t, older, SummaryBatchSize)
+	assert.Len(t, recent, ContextWindowSize)
+	assert.Equal(t, SummaryBatchSize+1, recent[0].ID)
+}
+
+func TestBuildChatPrompt(t *testing.T) {
+	recent := []model.ChatLog{{Message: "Saya sulit tidur", Response: "Sudah berapa lama?"}}
+
+	prompt := buildChatPrompt("User merasa cemas karena ujian.", recent, "Sekitar seminggu")
+	assert.True(t, strings.HasPrefix(prompt, "Ringkasan percakapan sebelumnya:\nUser merasa cemas karena ujian."))
+	assert.Contains(t, prompt, "Percakapan terakhir:\nUser: Saya sulit tidur\nAsisten: Sudah berapa lama?\n")
+	assert.True(t, strings.HasSuffix(prompt, "User: Sekitar seminggu\n"))
+
+	// Sesi baru tanpa riwayat hanya berisi pesan
+	assert.Equal(t, "User: Halo\n", buildChatPrompt("", nil, "Halo"))
+}
+
+func TestTitleFromMessage(t *testing.T) {
+	assert.Equal(t, "Saya merasa lelah", titleFromMessage("  Saya merasa\n lelah "))
+
+	title := titleFromMessage(strings.Repeat("kata ", 30))
+	assert.True(t, strings.HasSuffix(title, "..."))
+	assert.LessOrEqual(t, len([]rune(title)), autoTitleLength+3)
+}
+
+func TestGenerateResponse_NewSession(t *testing.T) {
+	repo := &InMemoryChatLogRepo{}
+	llm := &service.FakeLLMProvider{}
+	chatbot := NewChatbotUsecase(repo, llm, safety.NewClassifier(nil))
+
+	reply, err := chatbot.GenerateResponse(1, 0, "Saya sulit tidur")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reply.SessionID)
+	assert.Equal(t, "Jawaban untuk: User: Saya sulit tidur", reply.Response)
+	assert.Equal(t, "Saya sulit tidur", repo.Sessions[0].Title)
+	assert.Len(t, repo.Logs, 1)
+
+	// Sesi milik user lain tidak bisa dipakai
+	_, err = chatbot.GenerateResponse(2, reply.SessionID, "Halo")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestGenerateResponse_SummarizesOlderMessages(t *testing.T) {
+	repo := &InMemoryChatLogRepo{}
+	llm := &service.FakeLLMProvider{Reply: "Ringkasan singkat."}
+	chatbot := NewChatbotUsecase(repo, llm, safety.NewClassifier(nil))
+
+	session, _ := chatbot.CreateSession(1, "Tes")
+	for i := 0; i < ContextWindowSize+SummaryBatchSize; i++ {
+		repo.SaveLog(session, &model.ChatLog{UserID: 1, Message: "pesan", Response: "jawaban"})
+	}
+
+	_, err := chatbot.GenerateResponse(1, session.ID, "Pesan baru")
+	assert.NoError(t, err)
+
+	// Satu panggilan untuk ringkasan, satu untuk jawaban
+	assert.Len(t, llm.Prompts, 2)
+	assert.Equal(t, SummaryBatchSize, repo.Sessions[0].SummarizedUntilID)
+	assert.True(t, strings.HasPrefix(llm.Prompts[1], "Ringkasan percakapan sebelumnya:\nRingkasan singkat."))
+	assert.Equal(t, ContextWindowSize, strings.Count(llm.Prompts[1], "Asisten: "))
+}
+
+func TestGenerateResponse_ProviderError(t *testing.T) {
+	repo := &InMemoryChatLogRepo{}
+	chatbot := NewChatbotUsecase(repo, &service.FakeLLMProvider{Err: errors.New("quota exceeded")}, safety.NewClassifier(nil))
+
+	_, err := chatbot.GenerateResponse(1, 0, "Halo")
+	assert.Error(t, err)
+	assert.Empty(t, repo.Logs)
+	assert.Empty(t, repo.Sessions, "sesi baru tidak dibuat jika model gagal menjawab")
+}
+
+func TestGenerateResponse_CrisisMessage(t *testing.T) {
+	repo := &InMemoryChatLogRepo{}
+	llm := &service.FakeLLMProvider{}
+	chatbot := NewChatbotUsecase(repo, llm, safety.NewClassifier(nil))
+
+	reply, err := chatbot.GenerateResponse(1, 0, "Aku sudah tidak mau hidup lagi")
+	assert.NoError(t, err)
+	assert.True(t, reply.Flagged)
+	assert.Equal(t, safety.CrisisResponse, reply.Response)
+
+	// Pesan tidak diteruskan ke model, log ditandai dan admin mendapat peringatan
+	assert.Empty(t, llm.Prompts)
+	assert.True(t, repo.Logs[0].Flagged)
+	assert.Len(t, repo.Alerts, 1)
+	assert.Equal(t, repo.Logs[0].ID, repo.Alerts[0].ChatLogID)
+	assert.Equal(t, reply.SessionID, repo.Alerts[0].SessionID)
+	assert.Equal(t, "message", repo.Alerts[0].FlaggedOn)
+}
+
+func TestGenerateResponse_UnsafeModelResponse(t *testing.T) {
+	repo := &InMemoryChatLogRepo{}
+	llm := &service.FakeLLMProvider{Reply: "Berikut dosis yang mematikan untuk obat tersebut."}
+	chatbot := NewChatbotUsecase(repo, llm, safety.NewClassifier(nil))
+
+	reply, err := chatbot.GenerateResponse(1, 0, "Berapa banyak obat tidur yang aman?")
+	assert.NoError(t, err)
+	assert.Equal(t, safety.CrisisResponse, reply.Response)
+	assert.Len(t, repo.Alerts, 1)
+	assert.Equal(t, "response", repo.Alerts[0].FlaggedOn)
+}
+
+func TestStreamResponse_PersistsAfterCompletion(t *testing.T) {
+	repo := &InMemoryChatLogRepo{}
+	llm := &service.FakeLLMProvider{Reply: "Coba tarik napas perlahan selama beberapa menit."}
+	chatbot := NewChatbotUsecase(repo, llm, safety.NewClassifier(nil))
+
+	var streamed strings.Builder
+	reply, err := chatbot.StreamResponse(context.Background(), 1, 0, "Saya cemas", func(chunk string) error {
+		streamed.WriteString(chunk)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, llm.Reply, streamed.String())
+	assert.Equal(t, llm.Reply, reply.Response)
+	assert.Equal(t, llm.Reply, repo.Logs[0].Response)
+}
+
+func TestStreamResponse_UnsafeSentenceNotSent(t *testing.T) {
+	repo := &InMemoryChatLogRepo{}
+	llm := &service.FakeLLMProvider{Reply: "Aku mengerti perasaanmu. Berikut dosis yang mematikan untuk obat itu. Semoga membantu."}
+	chatbot := NewChatbotUsecase(repo, llm, safety.NewClassifier(nil))
+
+	var chunks []string
+	reply, err := chatbot.StreamResponse(context.Background(), 1, 0, "Berapa banyak obat tidur yang aman?", func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// Kalimat aman sebelumnya terkirim, kalimat tidak aman diganti jawaban krisis
+	assert.Equal(t, []string{"Aku mengerti perasaanmu.", safety.CrisisResponse}, chunks)
+	assert.NotContains(t, strings.Join(chunks, ""), "dosis")
+	assert.Equal(t, safety.CrisisResponse, reply.Response)
+	assert.True(t, reply.Flagged)
+	assert.Len(t, repo.Alerts, 1)
+	assert.Equal(t, "response", repo.Alerts[0].FlaggedOn)
+}
+
+func TestStreamResponse_ClientDisconnected(t *testing.T) {
+	repo := &InMemoryChatLogRepo{}
+	chatbot := NewChatbotUsecase(repo, &service.FakeLLMProvider{Reply: "Satu. Dua. Tiga. Empat."}, safety.NewClassifier(nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chunks := 0
+	_, err := chatbot.StreamResponse(ctx, 1, 0, "Halo", func(chunk string) error {
+		chunks++
+		cancel()
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, chunks)
+	assert.Empty(t, repo.Logs)
+	assert.Empty(t, repo.Sessions)
+}
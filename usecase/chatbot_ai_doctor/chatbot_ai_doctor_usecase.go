@@ -4,27 +4,43 @@ import (
 	"calmind/helper"
 	"calmind/model"
 	repository "calmind/repository/chatbot_ai_doctor"
+	"calmind/service"
 	"context"
-	"fmt"
+	"errors"
 	"strings"
 )
 
+// Pesan dokter yang bukan permintaan rekomendasi perawatan
+var ErrNotRecommendationRequest = errors.New("pesan tidak terkait dengan rekomendasi perawatan, hanya pertanyaan terkait rekomendasi perawatan yang bisa diproses")
+
 type DoctorChatbotUsecase interface {
 	GenerateDoctorRecommendation(doctorID int, message string) (string, error)
+	StreamDoctorRecommendation(ctx context.Context, doctorID int, message string, onChunk func(chunk string) error) (string, error)
+	DraftRecommendation(ctx context.Context, doctorID, consultationID int, question string) (*RecommendationDraft, error)
 }
 
 type DoctorChatbotUsecaseImpl struct {
 	ChatbotRepo repository.DoctorChatbotRepository
+	LLM         service.LLMProvider
 }
 
-func NewDoctorChatbotUsecase(chatbotRepo repository.DoctorChatbotRepository) DoctorChatbotUsecase {
-	return &DoctorChatbotUsecaseImpl{ChatbotRepo: chatbotRepo}
+func NewDoctorChatbotUsecase(chatbotRepo repository.DoctorChatbotRepository, llm service.LLMProvider) DoctorChatbotUsecase {
+	return &DoctorChatbotUsecaseImpl{ChatbotRepo: chatbotRepo, LLM: llm}
 }
 
 func (u *DoctorChatbotUsecaseImpl) GenerateDoctorRecommendation(doctorID int, message string) (string, error) {
+	return u.recommend(context.Background(), doctorID, message, nil)
+}
+
+// Potongan rekomendasi dikirim ke onChunk, log disimpan setelah stream selesai
+func (u *DoctorChatbotUsecaseImpl) StreamDoctorRecommendation(ctx context.Context, doctorID int, message string, onChunk func(chunk string) error) (string, error) {
+	return u.recommend(ctx, doctorID, message, onChunk)
+}
+
+func (u *DoctorChatbotUsecaseImpl) recommend(ctx context.Context, doctorID int, message string, onChunk func(chunk string) error) (string, error) {
 	// Validasi bahwa pesan harus berisi permintaan rekomendasi
 	if !strings.Contains(message, "rekomendasi") {
-		return "", fmt.Errorf("pesan tidak terkait dengan rekomendasi perawatan, hanya pertanyaan terkait rekomendasi perawatan yang bisa diproses")
+		return "", ErrNotRecommendationRequest
 	}
 
 	// Prompt kustom untuk dokter
@@ -46,10 +62,19 @@ func (u *DoctorChatbotUsecaseImpl) GenerateDoctorRecommendation(doctorID int, me
 	contextBuilder.WriteString(customPrompt + "\n")
 
 	// Panggil AI untuk respons
-	ctx := context.Background()
-	response, err := helper.ResponseAI(ctx, contextBuilder.String())
-	if err != nil {
-		return "", err
+	var response string
+	if onChunk == nil {
+		answer, err := u.LLM.Generate(ctx, contextBuilder.String())
+		if err != nil {
+			return "", err
+		}
+		response = helper.CleanAIResponse(answer)
+	} else {
+		answer, err := u.LLM.Stream(ctx, contextBuilder.String(), onChunk)
+		if err != nil {
+			return "", err
+		}
+		response = helper.CleanAIResponse(answer)
 	}
 
 	// Simpan log percakapan baru
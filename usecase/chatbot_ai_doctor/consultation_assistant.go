@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"calmind/model"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Jumlah draf sebelumnya yang ikut dikirim sebagai konteks
+const previousDraftLimit = 3
+
+var ErrConsultationNotFound = errors.New("konsultasi tidak ditemukan atau bukan milik dokter ini")
+
+// Draf rekomendasi yang bisa diedit dokter lalu disimpan melalui POST /doctor/consultations/:id/recommendation
+type RecommendationDraft struct {
+	ConsultationID int    `json:"consultation_id"`
+	Draft          string `json:"draft"`
+}
+
+// Membuat draf rekomendasi berdasarkan konsultasi tertentu milik dokter. question bersifat opsional.
+func (u *DoctorChatbotUsecaseImpl) DraftRecommendation(ctx context.Context, doctorID, consultationID int, question string) (*RecommendationDraft, error) {
+	consultation, err := u.ChatbotRepo.GetConsultationForDoctor(consultationID, doctorID)
+	if err != nil {
+		return nil, ErrConsultationNotFound
+	}
+
+	logs, err := u.ChatbotRepo.GetLogsByConsultation(doctorID, consultationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) > previousDraftLimit {
+		logs = logs[len(logs)-previousDraftLimit:]
+	}
+
+	question = redactPatient(strings.TrimSpace(question), consultation.User)
+	answer, err := u.LLM.Generate(ctx, buildConsultationPrompt(consultation, logs, question))
+	if err != nil {
+		return nil, err
+	}
+	draft := strings.TrimSpace(answer)
+
+	newLog := model.Chatbot{
+		UserID:         doctorID,
+		ConsultationID: consultationID,
+		Message:        question,
+		Response:       draft,
+	}
+	if err := u.ChatbotRepo.SaveLog(&newLog); err != nil {
+		return nil, err
+	}
+
+	return &RecommendationDraft{ConsultationID: consultationID, Draft: draft}, nil
+}
+
+// Menyusun prompt dari judul, keluhan, dan rekomendasi sebelumnya tanpa identitas pasien
+func buildConsultationPrompt(consultation *model.Consultation, previousDrafts []model.Chatbot, question string) string {
+	patient := consultation.User
+
+	var prompt strings.Builder
+	prompt.WriteString("Anda adalah asisten AI yang membantu psikolog atau psikiater menyusun rekomendasi perawatan untuk pasien. ")
+	prompt.WriteString("Tulis draf rekomendasi yang jelas, praktis, dan dapat diedit dokter sebelum dikirim ke pasien. ")
+	prompt.WriteString("Jangan membuat diagnosis pasti dan jangan menebak identitas pasien.\n\n")
+
+	prompt.WriteString("Judul konsultasi: " + redactPatient(consultation.Title, patient) + "\n")
+	prompt.WriteString("Keluhan pasien: " + redactPatient(consultation.Description, patient) + "\n")
+
+	if len(consultation.Rekomendasi) > 0 {
+		prompt.WriteString("\nRekomendasi yang sudah diberikan sebelumnya:\n")
+		for i, recommendation := range consultation.Rekomendasi {
+			prompt.WriteString(fmt.Sprintf("%d. %s\n", i+1, redactPatient(recommendation.Rekomendasi, patient)))
+		}
+	}
+
+	if len(previousDrafts) > 0 {
+		prompt.WriteString("\nDraf asisten sebelumnya:\n")
+		for _, draft := range previousDrafts {
+			if draft.Message != "" {
+				prompt.WriteString("Dokter: " + draft.Message + "\n")
+			}
+			prompt.WriteString("Asisten: " + draft.Response + "\n")
+		}
+	}
+
+	if question != "" {
+		prompt.WriteString("\nPermintaan dokter: " + question + "\n")
+	} else {
+		prompt.WriteString("\nBuat draf rekomendasi lanjutan untuk pasien ini.\n")
+	}
+	return prompt.String()
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`(\+62|62|0)[\s\-]?8[0-9][0-9\s\-]{6,12}[0-9]`)
+)
+
+// Mengganti identitas pasien (nama, email, nomor HP, alamat, tanggal lahir) dengan penanda
+func redactPatient(text string, patient model.User) string {
+	if text == "" {
+		return text
+	}
+
+	text = emailPattern.ReplaceAllString(text, "[EMAIL]")
+	text = phonePattern.ReplaceAllString(text, "[TELEPON]")
+
+	replacements := map[string]string{}
+	addIdentifier := func(value, marker string) {
+		value = strings.TrimSpace(value)
+		if utf8.RuneCountInString(value) >= 3 {
+			replacements[value] = marker
+		}
+	}
+	addIdentifier(patient.Alamat, "[ALAMAT]")
+	addIdentifier(patient.TglLahir, "[TANGGAL LAHIR]")
+	addIdentifier(patient.NoHp, "[TELEPON]")
+	addIdentifier(patient.Email, "[EMAIL]")
+	addIdentifier(patient.Username, "[PASIEN]")
+	for _, part := range strings.FieldsFunc(patient.Username, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '.' || r == '-'
+	}) {
+		addIdentifier(part, "[PASIEN]")
+	}
+
+	// Nilai terpanjang diganti lebih dulu agar nama lengkap tidak terpotong oleh nama depan
+	values := make([]string, 0, len(replacements))
+	for value := range replacements {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+
+	for _, value := range values {
+		text = replaceWholeWord(text, regexp.MustCompile(`(?i)`+regexp.QuoteMeta(value)), replacements[value])
+	}
+	return text
+}
+
+// Mengganti kecocokan yang tidak menempel pada huruf atau angka lain. Batas kata diperiksa manual karena \b tidak
+// berlaku untuk nilai yang diawali tanda baca (misalnya +62), dan pemisahnya tidak ikut dikonsumsi sehingga
+// nama yang berulang berdampingan ("Budi Budi", "Budi,Budi") tetap terganti semua.
+func replaceWholeWord(text string, pattern *regexp.Regexp, marker string) string {
+	var result strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringIndex(text, -1) {
+		start, end := match[0], match[1]
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if isWordRune(before) || isWordRune(after) {
+			continue
+		}
+		result.WriteString(text[last:start])
+		result.WriteString(marker)
+		last = end
+	}
+	result.WriteString(text[last:])
+	return result.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
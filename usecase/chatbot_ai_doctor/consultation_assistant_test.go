@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"calmind/helper"
+	"calmind/model"
+	"calmind/service"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// In-memory implementation of the doctor chatbot repository for testing.
+type InMemoryDoctorChatbotRepo struct {
+	Consultation model.Consultation
+	Logs         []model.Chatbot
+}
+
+func (repo *InMemoryDoctorChatbotRepo) GetLogsByDoctorID(doctorID int) ([]model.Chatbot, error) {
+	return nil, nil
+}
+
+func (repo *InMemoryDoctorChatbotRepo) SaveLog(log *model.Chatbot) error {
+	log.ID = len(repo.Logs) + 1
+	repo.Logs = append(repo.Logs, *log)
+	return nil
+}
+
+func (repo *InMemoryDoctorChatbotRepo) GetConsultationForDoctor(consultationID, doctorID int) (*model.Consultation, error) {
+	if repo.Consultation.ID != consultationID || repo.Consultation.DoctorID != doctorID {
+		return nil, errors.New("record not found")
+	}
+	consultation := repo.Consultation
+	return &consultation, nil
+}
+
+func (repo *InMemoryDoctorChatbotRepo) GetLogsByConsultation(doctorID, consultationID int) ([]model.Chatbot, error) {
+	return repo.Logs, nil
+}
+
+var testPatient = model.User{
+	Username: "Budi Santoso",
+	Email:    "budi.santoso@example.com",
+	NoHp:     "081234567890",
+	Alamat:   "Jl. Melati No. 5, Bandung",
+}
+
+func TestRedactPatient(t *testing.T) {
+	text := "Budi Santoso (budi.santoso@example.com, 0812-3456-7890) tinggal di Jl. Melati No. 5, Bandung. Pak budi sulit tidur."
+
+	redacted := redactPatient(text, testPatient)
+	assert.Equal(t, "[PASIEN] ([EMAIL], [TELEPON]) tinggal di [ALAMAT]. Pak [PASIEN] sulit tidur.", redacted)
+
+	// Kata yang hanya mengandung potongan nama tidak ikut diganti
+	assert.Equal(t, "Budidaya tanaman", redactPatient("Budidaya tanaman", testPatient))
+
+	// Nama yang berulang berdampingan tetap terganti semua
+	assert.Equal(t, "[PASIEN] [PASIEN],[PASIEN] sulit tidur", redactPatient("Budi budi,Budi sulit tidur", testPatient))
+}
+
+func TestDraftRecommendation(t *testing.T) {
+	repo := &InMemoryDoctorChatbotRepo{Consultation: model.Consultation{
+		ID:          7,
+		DoctorID:    3,
+		User:        testPatient,
+		Title:       "Konsultasi Budi",
+		Description: "Saya Budi, sering cemas saat bekerja. Hubungi saya di 081234567890.",
+		Rekomendasi: []model.Rekomendasi{{Rekomendasi: "Latihan pernapasan 4-7-8 setiap malam"}},
+	}}
+	llm := &service.FakeLLMProvider{Reply: "Lanjutkan latihan pernapasan dan catat pemicu kecemasan."}
+	chatbot := NewDoctorChatbotUsecase(repo, llm)
+
+	draft, err := chatbot.DraftRecommendation(context.Background(), 3, 7, "Buat versi singkat untuk Budi")
+	assert.NoError(t, err)
+	assert.Equal(t, llm.Reply, draft.Draft)
+
+	prompt := llm.Prompts[0]
+	assert.Contains(t, prompt, "Judul konsultasi: Konsultasi [PASIEN]")
+	assert.Contains(t, prompt, "Hubungi saya di [TELEPON]")
+	assert.Contains(t, prompt, "1. Latihan pernapasan 4-7-8 setiap malam")
+	assert.Contains(t, prompt, "Permintaan dokter: Buat versi singkat untuk [PASIEN]")
+	assert.NotContains(t, prompt, "Budi")
+	assert.Equal(t, 7, repo.Logs[0].ConsultationID)
+
+	// Dokter lain tidak bisa memakai konsultasi ini
+	_, err = chatbot.DraftRecommendation(context.Background(), 4, 7, "")
+	assert.ErrorIs(t, err, ErrConsultationNotFound)
+}
+
+// Mencatat context yang diterima model untuk memastikan context request diteruskan
+type contextRecordingLLM struct {
+	service.FakeLLMProvider
+	Ctx context.Context
+}
+
+func (p *contextRecordingLLM) Generate(ctx context.Context, prompt string) (string, error) {
+	p.Ctx = ctx
+	return p.FakeLLMProvider.Generate(ctx, prompt)
+}
+
+func TestDraftRecommendation_UsesRequestContext(t *testing.T) {
+	repo := &InMemoryDoctorChatbotRepo{Consultation: model.Consultation{ID: 7, DoctorID: 3, User: testPatient}}
+	llm := &contextRecordingLLM{}
+	chatbot := NewDoctorChatbotUsecase(repo, llm)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := chatbot.DraftRecommendation(ctx, 3, 7, "")
+	assert.NoError(t, err)
+	assert.Equal(t, ctx, llm.Ctx)
+}
+
+func TestStreamDoctorRecommendation(t *testing.T) {
+	repo := &InMemoryDoctorChatbotRepo{}
+	llm := &service.FakeLLMProvider{Reply: "**Terapi** perilaku kognitif.\n\nLatihan pernapasan."}
+	chatbot := NewDoctorChatbotUsecase(repo, llm)
+
+	// Log versi stream dibersihkan sama seperti versi biasa
+	var streamed strings.Builder
+	response, err := chatbot.StreamDoctorRecommendation(context.Background(), 3, "minta rekomendasi untuk insomnia", func(chunk string) error {
+		streamed.WriteString(chunk)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, llm.Reply, streamed.String())
+	assert.Equal(t, helper.CleanAIResponse(llm.Reply), response)
+	assert.Equal(t, response, repo.Logs[0].Response)
+
+	_, err = chatbot.StreamDoctorRecommendation(context.Background(), 3, "halo dok", func(string) error { return nil })
+	assert.ErrorIs(t, err, ErrNotRecommendationRequest)
+	assert.Len(t, repo.Logs, 1)
+}
@@ -0,0 +1,334 @@
+package usecase
+
+import (
+	"bytes"
+	"calmind/helper"
+	"calmind/model"
+	repository "calmind/repository/credential"
+	"calmind/service/storage"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Batas ukuran dokumen kredensial
+const maxCredentialFileSize = 5 * 1024 * 1024
+
+// Content type hasil deteksi isi file yang diterima -> ekstensi yang dipakai saat menyimpan
+var credentialFileTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+var (
+	ErrCredentialLocked      = errors.New("dokumen tidak bisa diubah selama pengajuan direview atau sudah disetujui")
+	ErrInvalidCredentialType = errors.New("jenis dokumen harus 'str' atau 'certificate'")
+	ErrSTRNumberRequired     = errors.New("nomor STR wajib diisi pada profil sebelum mengajukan verifikasi")
+	ErrSTRDocumentRequired   = errors.New("dokumen STR wajib diunggah sebelum mengajukan verifikasi")
+	ErrNotPendingReview      = errors.New("pengajuan kredensial tidak sedang menunggu review")
+	ErrRejectReasonRequired  = errors.New("alasan penolakan wajib diisi")
+	ErrCredentialNotFound    = errors.New("dokumen kredensial tidak ditemukan")
+	ErrUnsupportedFileType   = errors.New("format file tidak didukung, gunakan JPG, PNG, atau PDF")
+	ErrFileTooLarge          = errors.New("ukuran file melebihi 5 MB")
+)
+
+// Isi dokumen kredensial untuk diunduh. Dokumen lama yang diunggah sebelum penyimpanan privat hanya punya URL.
+type CredentialFile struct {
+	Name        string
+	ContentType string
+	Body        io.ReadCloser
+	URL         string
+}
+
+// Ringkasan pengajuan kredensial untuk dokter dan admin
+type CredentialDTO struct {
+	DoctorID         int                            `json:"doctor_id"`
+	Username         string                         `json:"username"`
+	Email            string                         `json:"email"`
+	NoHp             string                         `json:"no_hp"`
+	Title            string                         `json:"title"`
+	STRNumber        string                         `json:"str_number"`
+	CredentialStatus string                         `json:"credential_status"`
+	CredentialNote   string                         `json:"credential_note"`
+	ReviewedAt       *time.Time                     `json:"reviewed_at"`
+	UpdatedAt        time.Time                      `json:"updated_at"`
+	Documents        []model.DoctorCredential       `json:"documents"`
+	Reviews          []model.DoctorCredentialReview `json:"reviews,omitempty"`
+}
+
+type CredentialUsecase interface {
+	GetDoctorCredential(doctorID int) (*CredentialDTO, error)
+	AddCredential(doctorID int, credentialType, fileName string, file io.Reader) (*model.DoctorCredential, error)
+	DeleteCredential(doctorID, credentialID int) error
+	OpenCredential(doctorID, credentialID int) (*CredentialFile, error)
+	Submit(doctorID int) error
+
+	GetReviewQueue(status string, opts model.QueryOptions) ([]CredentialDTO, model.PageInfo, error)
+	Approve(adminID, doctorID int) error
+	Reject(adminID, doctorID int, reason string) error
+}
+
+type CredentialUsecaseImpl struct {
+	Repo    repository.CredentialRepository
+	Storage storage.PrivateStorage
+	Legacy  storage.Storage // Penyimpanan publik dokumen lama, hanya dipakai untuk menghapusnya
+}
+
+func NewCredentialUsecase(repo repository.CredentialRepository, store storage.PrivateStorage, legacy storage.Storage) CredentialUsecase {
+	return &CredentialUsecaseImpl{Repo: repo, Storage: store, Legacy: legacy}
+}
+
+// Dokumen hanya bisa diubah sebelum diajukan atau setelah ditolak
+func isEditable(status string) bool {
+	return status == "" || status == model.CredentialStatusUnsubmitted || status == model.CredentialStatusRejected
+}
+
+func (u *CredentialUsecaseImpl) GetDoctorCredential(doctorID int) (*CredentialDTO, error) {
+	doctor, err := u.Repo.GetDoctorByID(doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("doctor not found: %v", err)
+	}
+
+	reviews, err := u.Repo.GetReviews(doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch credential reviews: %v", err)
+	}
+
+	dto := toCredentialDTO(doctor)
+	dto.Reviews = reviews
+	return &dto, nil
+}
+
+func (u *CredentialUsecaseImpl) AddCredential(doctorID int, credentialType, fileName string, file io.Reader) (*model.DoctorCredential, error) {
+	if credentialType != model.CredentialTypeSTR && credentialType != model.CredentialTypeCertificate {
+		return nil, ErrInvalidCredentialType
+	}
+
+	doctor, err := u.Repo.GetDoctorByID(doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("doctor not found: %v", err)
+	}
+	if !isEditable(doctor.CredentialStatus) {
+		return nil, ErrCredentialLocked
+	}
+
+	// Jenis file ditentukan dari isinya, bukan dari nama file kiriman klien
+	data, err := io.ReadAll(io.LimitReader(file, maxCredentialFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credential: %v", err)
+	}
+	if len(data) > maxCredentialFileSize {
+		return nil, ErrFileTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := credentialFileTypes[contentType]
+	if !ok {
+		return nil, ErrUnsupportedFileType
+	}
+
+	uploadName := fmt.Sprintf("credentials/doctor_%d/%s_%d%s", doctorID, credentialType, time.Now().UnixNano(), ext)
+	object, err := u.Storage.Put(context.Background(), uploadName, bytes.NewReader(data), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload credential: %v", err)
+	}
+
+	credential := &model.DoctorCredential{
+		DoctorID: doctorID,
+		Type:     credentialType,
+		FileName: path.Base(strings.ReplaceAll(fileName, "\\", "/")),
+		PublicID: object.Key,
+	}
+	if err := u.Repo.CreateCredential(credential); err != nil {
+		return nil, fmt.Errorf("failed to save credential: %v", err)
+	}
+	return credential, nil
+}
+
+func (u *CredentialUsecaseImpl) DeleteCredential(doctorID, credentialID int) error {
+	doctor, err := u.Repo.GetDoctorByID(doctorID)
+	if err != nil {
+		return fmt.Errorf("doctor not found: %v", err)
+	}
+	if !isEditable(doctor.CredentialStatus) {
+		return ErrCredentialLocked
+	}
+
+	credential, err := u.Repo.GetCredentialByID(doctorID, credentialID)
+	if err != nil {
+		return ErrCredentialNotFound
+	}
+
+	if credential.PublicID != "" {
+		// Dokumen lama yang masih punya URL publik tersimpan di penyimpanan publik
+		remove := u.Storage.Delete
+		if credential.FileURL != "" && u.Legacy != nil {
+			remove = u.Legacy.Delete
+		}
+		if err := remove(context.Background(), credential.PublicID); err != nil {
+			log.Printf("Gagal menghapus dokumen kredensial %d: %v", credential.ID, err)
+		}
+	}
+	return u.Repo.DeleteCredential(credential)
+}
+
+// Isi dokumen kredensial milik dokter. Pemanggil memastikan peminta adalah dokter itu sendiri atau admin.
+func (u *CredentialUsecaseImpl) OpenCredential(doctorID, credentialID int) (*CredentialFile, error) {
+	credential, err := u.Repo.GetCredentialByID(doctorID, credentialID)
+	if err != nil {
+		return nil, ErrCredentialNotFound
+	}
+	if credential.FileURL != "" {
+		return &CredentialFile{Name: credential.FileName, URL: credential.FileURL}, nil
+	}
+
+	body, err := u.Storage.Open(context.Background(), credential.PublicID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrCredentialNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open credential: %v", err)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(credential.PublicID))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &CredentialFile{Name: credential.FileName, ContentType: contentType, Body: body}, nil
+}
+
+// Mengajukan dokumen untuk direview admin
+func (u *CredentialUsecaseImpl) Submit(doctorID int) error {
+	doctor, err := u.Repo.GetDoctorByID(doctorID)
+	if err != nil {
+		return fmt.Errorf("doctor not found: %v", err)
+	}
+	if !isEditable(doctor.CredentialStatus) {
+		return ErrCredentialLocked
+	}
+	if strings.TrimSpace(doctor.STRNumber) == "" {
+		return ErrSTRNumberRequired
+	}
+
+	hasSTR := false
+	for _, credential := range doctor.Credentials {
+		if credential.Type == model.CredentialTypeSTR {
+			hasSTR = true
+			break
+		}
+	}
+	if !hasSTR {
+		return ErrSTRDocumentRequired
+	}
+
+	err = u.Repo.UpdateCredentialStatus(doctorID, []string{"", model.CredentialStatusUnsubmitted, model.CredentialStatusRejected}, model.CredentialStatusPending)
+	if errors.Is(err, repository.ErrCredentialStatusConflict) {
+		return ErrCredentialLocked
+	}
+	return err
+}
+
+// Daftar pengajuan berdasarkan status, default yang menunggu review
+func (u *CredentialUsecaseImpl) GetReviewQueue(status string, opts model.QueryOptions) ([]CredentialDTO, model.PageInfo, error) {
+	if status == "" {
+		status = model.CredentialStatusPending
+	}
+
+	doctors, page, err := u.Repo.GetDoctorsByCredentialStatus(status, opts)
+	if err != nil {
+		return nil, model.PageInfo{}, fmt.Errorf("failed to fetch credential queue: %w", err)
+	}
+
+	queue := make([]CredentialDTO, 0, len(doctors))
+	for i := range doctors {
+		queue = append(queue, toCredentialDTO(&doctors[i]))
+	}
+	return queue, page, nil
+}
+
+func (u *CredentialUsecaseImpl) Approve(adminID, doctorID int) error {
+	return u.decide(adminID, doctorID, model.CredentialStatusApproved, "")
+}
+
+func (u *CredentialUsecaseImpl) Reject(adminID, doctorID int, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrRejectReasonRequired
+	}
+	return u.decide(adminID, doctorID, model.CredentialStatusRejected, reason)
+}
+
+func (u *CredentialUsecaseImpl) decide(adminID, doctorID int, decision, reason string) error {
+	doctor, err := u.Repo.GetDoctorByID(doctorID)
+	if err != nil {
+		return fmt.Errorf("doctor not found: %v", err)
+	}
+	if doctor.CredentialStatus != model.CredentialStatusPending {
+		return ErrNotPendingReview
+	}
+
+	review := &model.DoctorCredentialReview{
+		DoctorID: doctorID,
+		AdminID:  adminID,
+		Decision: decision,
+		Reason:   reason,
+	}
+	err = u.Repo.SaveReview(doctorID, review, decision, time.Now())
+	if errors.Is(err, repository.ErrCredentialStatusConflict) {
+		return ErrNotPendingReview
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save credential review: %v", err)
+	}
+
+	u.notifyDoctor(doctor, decision, reason)
+	return nil
+}
+
+func (u *CredentialUsecaseImpl) notifyDoctor(doctor *model.Doctor, decision, reason string) {
+	subject := "Verifikasi Kredensial Disetujui - Calmind"
+	heading := "Kredensial Anda Telah Disetujui"
+	message := fmt.Sprintf("Halo %s,\nDokumen STR dan sertifikat Anda telah diverifikasi. Profil Anda kini tampil di daftar dokter dan dapat dipesan oleh pengguna.", doctor.Username)
+	if decision == model.CredentialStatusRejected {
+		subject = "Verifikasi Kredensial Ditolak - Calmind"
+		heading = "Kredensial Anda Perlu Diperbaiki"
+		message = fmt.Sprintf("Halo %s,\nPengajuan kredensial Anda belum dapat disetujui dengan alasan berikut:\n%s\nSilakan perbarui dokumen Anda lalu ajukan kembali.", doctor.Username, reason)
+	}
+
+	if err := helper.SendNotificationEmail(doctor.Email, subject, heading, message); err != nil {
+		log.Printf("Gagal mengirim email verifikasi kredensial ke %s: %v", doctor.Email, err)
+	}
+}
+
+func toCredentialDTO(doctor *model.Doctor) CredentialDTO {
+	status := doctor.CredentialStatus
+	if status == "" {
+		status = model.CredentialStatusUnsubmitted
+	}
+
+	documents := doctor.Credentials
+	if documents == nil {
+		documents = []model.DoctorCredential{}
+	}
+
+	return CredentialDTO{
+		DoctorID:         doctor.ID,
+		Username:         doctor.Username,
+		Email:            doctor.Email,
+		NoHp:             doctor.NoHp,
+		Title:            doctor.Title.Name,
+		STRNumber:        doctor.STRNumber,
+		CredentialStatus: status,
+		CredentialNote:   doctor.CredentialNote,
+		ReviewedAt:       doctor.CredentialReviewedAt,
+		UpdatedAt:        doctor.UpdatedAt,
+		Documents:        documents,
+	}
+}
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"bytes"
+	"calmind/model"
+	repository "calmind/repository/credential"
+	"calmind/service/storage"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// In-memory implementation of the credential repository for testing.
+type InMemoryCredentialRepo struct {
+	Doctors     map[int]*model.Doctor
+	Credentials []model.DoctorCredential
+	Reviews     []model.DoctorCredentialReview
+}
+
+func (repo *InMemoryCredentialRepo) GetDoctorByID(doctorID int) (*model.Doctor, error) {
+	doctor, ok := repo.Doctors[doctorID]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	copied := *doctor
+	copied.Credentials, _ = repo.GetCredentials(doctorID)
+	return &copied, nil
+}
+
+func (repo *InMemoryCredentialRepo) GetCredentials(doctorID int) ([]model.DoctorCredential, error) {
+	var credentials []model.DoctorCredential
+	for _, credential := range repo.Credentials {
+		if credential.DoctorID == doctorID {
+			credentials = append(credentials, credential)
+		}
+	}
+	return credentials, nil
+}
+
+func (repo *InMemoryCredentialRepo) GetCredentialByID(doctorID, credentialID int) (*model.DoctorCredential, error) {
+	for _, credential := range repo.Credentials {
+		if credential.DoctorID == doctorID && credential.ID == credentialID {
+			return &credential, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (repo *InMemoryCredentialRepo) CreateCredential(credential *model.DoctorCredential) error {
+	credential.ID = len(repo.Credentials) + 1
+	repo.Credentials = append(repo.Credentials, *credential)
+	return nil
+}
+
+func (repo *InMemoryCredentialRepo) DeleteCredential(credential *model.DoctorCredential) error {
+	for i := range repo.Credentials {
+		if repo.Credentials[i].ID == credential.ID {
+			repo.Credentials = append(repo.Credentials[:i], repo.Credentials[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (repo *InMemoryCredentialRepo) UpdateCredentialStatus(doctorID int, fromStatuses []string, toStatus string) error {
+	doctor := repo.Doctors[doctorID]
+	for _, status := range fromStatuses {
+		if doctor.CredentialStatus == status {
+			doctor.CredentialStatus = toStatus
+			return nil
+		}
+	}
+	return repository.ErrCredentialStatusConflict
+}
+
+func (repo *InMemoryCredentialRepo) GetDoctorsByCredentialStatus(status string, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
+	return nil, model.PageInfo{}, nil
+}
+
+func (repo *InMemoryCredentialRepo) SaveReview(doctorID int, review *model.DoctorCredentialReview, toStatus string, reviewedAt time.Time) error {
+	doctor := repo.Doctors[doctorID]
+	if doctor.CredentialStatus != model.CredentialStatusPending {
+		return repository.ErrCredentialStatusConflict
+	}
+	doctor.CredentialStatus = toStatus
+	doctor.CredentialNote = review.Reason
+	doctor.CredentialReviewedAt = &reviewedAt
+	repo.Reviews = append(repo.Reviews, *review)
+	return nil
+}
+
+func (repo *InMemoryCredentialRepo) GetReviews(doctorID int) ([]model.DoctorCredentialReview, error) {
+	return repo.Reviews, nil
+}
+
+// In-memory private storage for testing.
+type InMemoryPrivateStorage struct {
+	Files map[string][]byte
+	Types map[string]string
+}
+
+func (s *InMemoryPrivateStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) (*storage.Object, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	s.Files[key] = data
+	s.Types[key] = contentType
+	return &storage.Object{Key: key}, nil
+}
+
+func (s *InMemoryPrivateStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.Files[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *InMemoryPrivateStorage) Delete(ctx context.Context, key string) error {
+	delete(s.Files, key)
+	return nil
+}
+
+var pdfContent = []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n")
+
+func newTestUsecase(doctor *model.Doctor) (*CredentialUsecaseImpl, *InMemoryCredentialRepo, *InMemoryPrivateStorage) {
+	repo := &InMemoryCredentialRepo{Doctors: map[int]*model.Doctor{doctor.ID: doctor}}
+	store := &InMemoryPrivateStorage{Files: map[string][]byte{}, Types: map[string]string{}}
+	return &CredentialUsecaseImpl{Repo: repo, Storage: store}, repo, store
+}
+
+func TestAddCredential_SniffsContent(t *testing.T) {
+	u, repo, store := newTestUsecase(&model.Doctor{ID: 1})
+
+	// Nama file kiriman klien tidak menentukan jenis file
+	credential, err := u.AddCredential(1, model.CredentialTypeSTR, "../../str.html", bytes.NewReader(pdfContent))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(credential.PublicID, "credentials/doctor_1/str_"))
+	assert.True(t, strings.HasSuffix(credential.PublicID, ".pdf"))
+	assert.Equal(t, "str.html", credential.FileName)
+	assert.Empty(t, credential.FileURL)
+	assert.Equal(t, "application/pdf", store.Types[credential.PublicID])
+
+	_, err = u.AddCredential(1, model.CredentialTypeSTR, "str.pdf", strings.NewReader("<html><script>alert(1)</script></html>"))
+	assert.ErrorIs(t, err, ErrUnsupportedFileType)
+
+	tooLarge := append(append([]byte{}, pdfContent...), make([]byte, maxCredentialFileSize)...)
+	_, err = u.AddCredential(1, model.CredentialTypeSTR, "str.pdf", bytes.NewReader(tooLarge))
+	assert.ErrorIs(t, err, ErrFileTooLarge)
+	assert.Len(t, repo.Credentials, 1)
+
+	file, err := u.OpenCredential(1, credential.ID)
+	assert.NoError(t, err)
+	defer file.Body.Close()
+	content, _ := io.ReadAll(file.Body)
+	assert.Equal(t, pdfContent, content)
+	assert.Equal(t, "application/pdf", file.ContentType)
+
+	// Dokumen dokter lain tidak bisa dibuka
+	_, err = u.OpenCredential(2, credential.ID)
+	assert.ErrorIs(t, err, ErrCredentialNotFound)
+}
+
+func TestSubmitAndApprove(t *testing.T) {
+	u, repo, _ := newTestUsecase(&model.Doctor{ID: 1, STRNumber: "STR-123"})
+
+	assert.ErrorIs(t, u.Submit(1), ErrSTRDocumentRequired)
+
+	_, err := u.AddCredential(1, model.CredentialTypeSTR, "str.pdf", bytes.NewReader(pdfContent))
+	assert.NoError(t, err)
+	assert.NoError(t, u.Submit(1))
+	assert.Equal(t, model.CredentialStatusPending, repo.Doctors[1].CredentialStatus)
+
+	// Dokumen terkunci selama direview
+	_, err = u.AddCredential(1, model.CredentialTypeCertificate, "sertifikat.pdf", bytes.NewReader(pdfContent))
+	assert.ErrorIs(t, err, ErrCredentialLocked)
+	assert.ErrorIs(t, u.Submit(1), ErrCredentialLocked)
+
+	assert.NoError(t, u.Approve(9, 1))
+	assert.Equal(t, model.CredentialStatusApproved, repo.Doctors[1].CredentialStatus)
+	assert.NotNil(t, repo.Doctors[1].CredentialReviewedAt)
+	assert.Equal(t, 9, repo.Reviews[0].AdminID)
+
+	assert.ErrorIs(t, u.Approve(9, 1), ErrNotPendingReview)
+}
+
+func TestSubmitAndReject(t *testing.T) {
+	u, repo, _ := newTestUsecase(&model.Doctor{ID: 1})
+
+	_, err := u.AddCredential(1, model.CredentialTypeSTR, "str.pdf", bytes.NewReader(pdfContent))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, u.Submit(1), ErrSTRNumberRequired)
+
+	repo.Doctors[1].STRNumber = "STR-123"
+	assert.NoError(t, u.Submit(1))
+
+	assert.ErrorIs(t, u.Reject(9, 1, "  "), ErrRejectReasonRequired)
+	assert.NoError(t, u.Reject(9, 1, "Dokumen STR tidak terbaca"))
+	assert.Equal(t, model.CredentialStatusRejected, repo.Doctors[1].CredentialStatus)
+	assert.Equal(t, "Dokumen STR tidak terbaca", repo.Doctors[1].CredentialNote)
+
+	// Setelah ditolak dokumen bisa diperbaiki dan diajukan ulang
+	_, err = u.AddCredential(1, model.CredentialTypeCertificate, "sertifikat.pdf", bytes.NewReader(pdfContent))
+	assert.NoError(t, err)
+	assert.NoError(t, u.Submit(1))
+	assert.Equal(t, model.CredentialStatusPending, repo.Doctors[1].CredentialStatus)
+}
@@ -1,28 +1,82 @@
 package usecase
 
 import (
+	"calmind/helper"
 	"calmind/model"
 	repository "calmind/repository/customer_service"
+	usecase_scheduler "calmind/usecase/scheduler"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+// Panjang maksimal subjek tiket (rune)
+const maxSubjectLength = 150
+
+var (
+	ErrTicketNotFound     = errors.New("tiket tidak ditemukan")
+	ErrEmptyTicketMessage = errors.New("pesan tiket tidak boleh kosong")
+	ErrSubjectTooLong     = errors.New("subjek tiket maksimal 150 karakter")
+	ErrTicketClosed       = errors.New("tiket sudah ditutup")
+	ErrInvalidTicketState = errors.New("status tiket harus 'open', 'in_progress', 'answered', atau 'closed'")
+	ErrInvalidPriority    = errors.New("prioritas tiket harus 'low', 'normal', 'high', atau 'urgent'")
+	ErrAdminNotFound      = errors.New("admin tidak ditemukan")
+)
+
+// Tiket beserta data pemilik untuk tampilan admin
+type TicketDTO struct {
+	model.CustService
+	User model.UserDTO `json:"user"`
+}
+
+// Perubahan tiket oleh admin, nilai kosong/nil berarti tidak diubah. AssignedAdminID 0 melepas penugasan.
+type TicketUpdate struct {
+	Status          string `json:"status"`
+	Priority        string `json:"priority"`
+	AssignedAdminID *int   `json:"assigned_admin_id"`
+}
+
 type CustServiceUsecase interface {
 	SaveCustService(userID int, message string) error
 	AnswerMessage(id int, answer string) error
+
+	// Tiket milik user
+	OpenTicket(userID int, subject, message string) (*model.CustService, error)
+	GetUserTickets(userID int, opts model.QueryOptions) ([]model.CustService, model.PageInfo, error)
+	GetUserTicket(userID, id int) (*model.CustService, error)
+	ReplyAsUser(userID, id int, message string) (*model.CustServiceReply, error)
+	CloseTicket(userID, id int) error
+
+	// Inbox admin
+	GetTickets(filter repository.TicketFilter, opts model.QueryOptions) ([]TicketDTO, model.PageInfo, error)
+	GetTicket(id int) (*TicketDTO, error)
+	AnswerTicket(adminID, id int, answer string) (*model.CustServiceReply, error)
+	UpdateTicket(id int, update TicketUpdate) (*TicketDTO, error)
+
+	// Job terjadwal
+	RegisterJobs()
 }
 
 type CustServiceUsecaseImpl struct {
 	CustServiceRepo repository.CustServiceRepository
+	Scheduler       usecase_scheduler.SchedulerUsecase
 }
 
-func NewCustServiceUsecase(repo repository.CustServiceRepository) CustServiceUsecase {
-	return &CustServiceUsecaseImpl{CustServiceRepo: repo}
+func NewCustServiceUsecase(repo repository.CustServiceRepository, scheduler usecase_scheduler.SchedulerUsecase) CustServiceUsecase {
+	return &CustServiceUsecaseImpl{CustServiceRepo: repo, Scheduler: scheduler}
 }
 
 func (u *CustServiceUsecaseImpl) SaveCustService(userID int, message string) error {
 	custService := &model.CustService{
 		UserID:     userID,
+		Subject:    subjectFromMessage(message),
 		Message:    message,
 		IsAnswered: false,
+		Status:     model.TicketStatusOpen,
+		Priority:   model.TicketPriorityNormal,
 	}
 	return u.CustServiceRepo.SaveCustService(custService)
 }
@@ -30,3 +84,293 @@ func (u *CustServiceUsecaseImpl) SaveCustService(userID int, message string) err
 func (u *CustServiceUsecaseImpl) AnswerMessage(id int, answer string) error {
 	return u.CustServiceRepo.AnswerMessage(id, answer)
 }
+
+// Membuka tiket baru, subjek kosong diambil dari awal pesan
+func (u *CustServiceUsecaseImpl) OpenTicket(userID int, subject, message string) (*model.CustService, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, ErrEmptyTicketMessage
+	}
+	subject = strings.TrimSpace(subject)
+	if utf8.RuneCountInString(subject) > maxSubjectLength {
+		return nil, ErrSubjectTooLong
+	}
+	if subject == "" {
+		subject = subjectFromMessage(message)
+	}
+
+	ticket := &model.CustService{
+		UserID:   userID,
+		Subject:  subject,
+		Message:  message,
+		Status:   model.TicketStatusOpen,
+		Priority: model.TicketPriorityNormal,
+	}
+	if err := u.CustServiceRepo.SaveCustService(ticket); err != nil {
+		return nil, fmt.Errorf("failed to open ticket: %v", err)
+	}
+	return ticket, nil
+}
+
+func (u *CustServiceUsecaseImpl) GetUserTickets(userID int, opts model.QueryOptions) ([]model.CustService, model.PageInfo, error) {
+	tickets, page, err := u.CustServiceRepo.GetTicketsByUserID(userID, opts)
+	if err != nil {
+		return nil, model.PageInfo{}, fmt.Errorf("failed to fetch tickets: %w", err)
+	}
+	return tickets, page, nil
+}
+
+// Tiket milik user lain dianggap tidak ada
+func (u *CustServiceUsecaseImpl) GetUserTicket(userID, id int) (*model.CustService, error) {
+	ticket, err := u.CustServiceRepo.GetTicketByID(id)
+	if err != nil || ticket.UserID != userID {
+		return nil, ErrTicketNotFound
+	}
+	return ticket, nil
+}
+
+// Balasan user mengembalikan tiket ke antrean admin
+func (u *CustServiceUsecaseImpl) ReplyAsUser(userID, id int, message string) (*model.CustServiceReply, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, ErrEmptyTicketMessage
+	}
+
+	ticket, err := u.GetUserTicket(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == model.TicketStatusClosed {
+		return nil, ErrTicketClosed
+	}
+
+	status := model.TicketStatusOpen
+	if ticket.AssignedAdminID != nil {
+		status = model.TicketStatusInProgress
+	}
+
+	reply := &model.CustServiceReply{
+		TicketID:   ticket.ID,
+		AuthorRole: model.TicketAuthorUser,
+		AuthorID:   userID,
+		Message:    message,
+	}
+	if err := u.CustServiceRepo.SaveReply(reply, status); err != nil {
+		return nil, fmt.Errorf("failed to save reply: %v", err)
+	}
+	return reply, nil
+}
+
+func (u *CustServiceUsecaseImpl) CloseTicket(userID, id int) error {
+	ticket, err := u.GetUserTicket(userID, id)
+	if err != nil {
+		return err
+	}
+	if ticket.Status == model.TicketStatusClosed {
+		return ErrTicketClosed
+	}
+
+	ticket.Status = model.TicketStatusClosed
+	if err := u.CustServiceRepo.UpdateTicket(ticket); err != nil {
+		return fmt.Errorf("failed to close ticket: %v", err)
+	}
+	return nil
+}
+
+func (u *CustServiceUsecaseImpl) GetTickets(filter repository.TicketFilter, opts model.QueryOptions) ([]TicketDTO, model.PageInfo, error) {
+	if filter.Status != "" && !validTicketStatus(filter.Status) {
+		return nil, model.PageInfo{}, ErrInvalidTicketState
+	}
+	if filter.Priority != "" && !validTicketPriority(filter.Priority) {
+		return nil, model.PageInfo{}, ErrInvalidPriority
+	}
+
+	tickets, page, err := u.CustServiceRepo.GetTickets(filter, opts)
+	if err != nil {
+		return nil, model.PageInfo{}, fmt.Errorf("failed to fetch tickets: %w", err)
+	}
+
+	result := make([]TicketDTO, 0, len(tickets))
+	for _, ticket := range tickets {
+		result = append(result, toTicketDTO(ticket))
+	}
+	return result, page, nil
+}
+
+func (u *CustServiceUsecaseImpl) GetTicket(id int) (*TicketDTO, error) {
+	ticket, err := u.CustServiceRepo.GetTicketByID(id)
+	if err != nil {
+		return nil, ErrTicketNotFound
+	}
+
+	dto := toTicketDTO(*ticket)
+	return &dto, nil
+}
+
+// Menjawab tiket, tiket yang belum ditugaskan otomatis menjadi milik admin yang menjawab. User diberi tahu lewat email.
+func (u *CustServiceUsecaseImpl) AnswerTicket(adminID, id int, answer string) (*model.CustServiceReply, error) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return nil, ErrEmptyTicketMessage
+	}
+
+	ticket, err := u.CustServiceRepo.GetTicketByID(id)
+	if err != nil {
+		return nil, ErrTicketNotFound
+	}
+	if ticket.Status == model.TicketStatusClosed {
+		return nil, ErrTicketClosed
+	}
+
+	if ticket.AssignedAdminID == nil {
+		ticket.AssignedAdminID = &adminID
+		if err := u.CustServiceRepo.UpdateTicket(ticket); err != nil {
+			return nil, fmt.Errorf("failed to assign ticket: %v", err)
+		}
+	}
+
+	reply := &model.CustServiceReply{
+		TicketID:   ticket.ID,
+		AuthorRole: model.TicketAuthorAdmin,
+		AuthorID:   adminID,
+		Message:    answer,
+	}
+	if err := u.CustServiceRepo.SaveReply(reply, model.TicketStatusAnswered); err != nil {
+		return nil, fmt.Errorf("failed to save answer: %v", err)
+	}
+
+	u.scheduleAnswerEmail(reply)
+	return reply, nil
+}
+
+func (u *CustServiceUsecaseImpl) UpdateTicket(id int, update TicketUpdate) (*TicketDTO, error) {
+	if update.Status != "" && !validTicketStatus(update.Status) {
+		return nil, ErrInvalidTicketState
+	}
+	if update.Priority != "" && !validTicketPriority(update.Priority) {
+		return nil, ErrInvalidPriority
+	}
+
+	ticket, err := u.CustServiceRepo.GetTicketByID(id)
+	if err != nil {
+		return nil, ErrTicketNotFound
+	}
+
+	if update.Status != "" {
+		ticket.Status = update.Status
+	}
+	if update.Priority != "" {
+		ticket.Priority = update.Priority
+	}
+	if update.AssignedAdminID != nil {
+		if *update.AssignedAdminID == 0 {
+			ticket.AssignedAdminID = nil
+		} else {
+			exists, err := u.CustServiceRepo.AdminExists(*update.AssignedAdminID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check admin: %v", err)
+			}
+			if !exists {
+				return nil, ErrAdminNotFound
+			}
+			adminID := *update.AssignedAdminID
+			ticket.AssignedAdminID = &adminID
+			if ticket.Status == model.TicketStatusOpen {
+				ticket.Status = model.TicketStatusInProgress
+			}
+		}
+	}
+
+	if err := u.CustServiceRepo.UpdateTicket(ticket); err != nil {
+		return nil, fmt.Errorf("failed to update ticket: %v", err)
+	}
+
+	dto := toTicketDTO(*ticket)
+	return &dto, nil
+}
+
+// -- job terjadwal --
+
+type answerEmailPayload struct {
+	TicketID int `json:"ticket_id"`
+	ReplyID  int `json:"reply_id"`
+}
+
+// Mendaftarkan handler email jawaban tiket ke scheduler
+func (u *CustServiceUsecaseImpl) RegisterJobs() {
+	u.Scheduler.RegisterHandler(usecase_scheduler.JobTicketAnswerEmail, u.handleAnswerEmailJob)
+}
+
+// Email dikirim worker scheduler agar admin tidak menunggu SMTP dan pengiriman yang gagal dicoba ulang
+func (u *CustServiceUsecaseImpl) scheduleAnswerEmail(reply *model.CustServiceReply) {
+	err := u.Scheduler.Schedule(usecase_scheduler.JobTicketAnswerEmail,
+		usecase_scheduler.JobKey(usecase_scheduler.JobTicketAnswerEmail, reply.ID), time.Now(),
+		answerEmailPayload{TicketID: reply.TicketID, ReplyID: reply.ID})
+	if err != nil {
+		log.Printf("Gagal menjadwalkan email jawaban tiket %d: %v", reply.TicketID, err)
+	}
+}
+
+func (u *CustServiceUsecaseImpl) handleAnswerEmailJob(job *model.ScheduledJob) error {
+	var payload answerEmailPayload
+	if err := usecase_scheduler.DecodePayload(job, &payload); err != nil {
+		return err
+	}
+	ticket, err := u.CustServiceRepo.GetTicketByID(payload.TicketID)
+	if err != nil {
+		return err
+	}
+	if ticket.User.Email == "" {
+		return nil
+	}
+
+	for _, reply := range ticket.Replies {
+		if reply.ID != payload.ReplyID {
+			continue
+		}
+		message := fmt.Sprintf("Halo %s,\nTiket bantuan Anda \"%s\" telah dijawab oleh Admin:\n\n%s\n\nSilakan balas tiket melalui aplikasi jika Anda masih membutuhkan bantuan.",
+			ticket.User.Username, ticket.Subject, reply.Message)
+		return helper.SendNotificationEmail(ticket.User.Email, "Tiket Bantuan Dijawab - Calmind", "Tiket Anda Telah Dijawab", message)
+	}
+	return nil
+}
+
+// Subjek default dari 50 karakter pertama pesan
+func subjectFromMessage(message string) string {
+	message = strings.Join(strings.Fields(message), " ")
+	if utf8.RuneCountInString(message) <= 50 {
+		return message
+	}
+	return string([]rune(message)[:50]) + "..."
+}
+
+func validTicketStatus(status string) bool {
+	switch status {
+	case model.TicketStatusOpen, model.TicketStatusInProgress, model.TicketStatusAnswered, model.TicketStatusClosed:
+		return true
+	}
+	return false
+}
+
+func validTicketPriority(priority string) bool {
+	switch priority {
+	case model.TicketPriorityLow, model.TicketPriorityNormal, model.TicketPriorityHigh, model.TicketPriorityUrgent:
+		return true
+	}
+	return false
+}
+
+func toTicketDTO(ticket model.CustService) TicketDTO {
+	return TicketDTO{
+		CustService: ticket,
+		User: model.UserDTO{
+			Avatar:       ticket.User.Avatar,
+			Username:     ticket.User.Username,
+			Email:        ticket.User.Email,
+			JenisKelamin: ticket.User.JenisKelamin,
+			NoHp:         ticket.User.NoHp,
+			TglLahir:     ticket.User.TglLahir,
+			Pekerjaan:    ticket.User.Pekerjaan,
+		},
+	}
+}
@@ -2,15 +2,24 @@ package usecase
 
 import (
 	"calmind/model"
+	repository "calmind/repository/customer_service"
+	usecase_scheduler "calmind/usecase/scheduler"
+	"errors"
 	"testing"
+	"time"
 )
 
 // Define a simple in-memory implementation of the repository for testing.
 type InMemoryCustServiceRepo struct {
-	Data []model.CustService
+	Data    []model.CustService
+	Replies []model.CustServiceReply
+	Admins  []int
 }
 
 func (repo *InMemoryCustServiceRepo) SaveCustService(custService *model.CustService) error {
+	if custService.ID == 0 {
+		custService.ID = len(repo.Data) + 1
+	}
 	repo.Data = append(repo.Data, *custService)
 	return nil
 }
@@ -26,11 +35,91 @@ func (repo *InMemoryCustServiceRepo) AnswerMessage(id int, answer string) error
 	return nil
 }
 
+func (repo *InMemoryCustServiceRepo) GetTicketByID(id int) (*model.CustService, error) {
+	for _, v := range repo.Data {
+		if v.ID == id {
+			ticket := v
+			for _, reply := range repo.Replies {
+				if reply.TicketID == id {
+					ticket.Replies = append(ticket.Replies, reply)
+				}
+			}
+			return &ticket, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (repo *InMemoryCustServiceRepo) GetTicketsByUserID(userID int, opts model.QueryOptions) ([]model.CustService, model.PageInfo, error) {
+	var tickets []model.CustService
+	for _, v := range repo.Data {
+		if v.UserID == userID {
+			tickets = append(tickets, v)
+		}
+	}
+	return tickets, model.NewPageInfo(opts, int64(len(tickets))), nil
+}
+
+func (repo *InMemoryCustServiceRepo) GetTickets(filter repository.TicketFilter, opts model.QueryOptions) ([]model.CustService, model.PageInfo, error) {
+	var tickets []model.CustService
+	for _, v := range repo.Data {
+		if filter.Status != "" && v.Status != filter.Status {
+			continue
+		}
+		if filter.Priority != "" && v.Priority != filter.Priority {
+			continue
+		}
+		if filter.AssignedAdminID > 0 && (v.AssignedAdminID == nil || *v.AssignedAdminID != filter.AssignedAdminID) {
+			continue
+		}
+		if filter.Unassigned && v.AssignedAdminID != nil {
+			continue
+		}
+		tickets = append(tickets, v)
+	}
+	return tickets, model.NewPageInfo(opts, int64(len(tickets))), nil
+}
+
+func (repo *InMemoryCustServiceRepo) UpdateTicket(ticket *model.CustService) error {
+	for i, v := range repo.Data {
+		if v.ID == ticket.ID {
+			repo.Data[i].Status = ticket.Status
+			repo.Data[i].Priority = ticket.Priority
+			repo.Data[i].AssignedAdminID = ticket.AssignedAdminID
+		}
+	}
+	return nil
+}
+
+func (repo *InMemoryCustServiceRepo) SaveReply(reply *model.CustServiceReply, status string) error {
+	reply.ID = len(repo.Replies) + 1
+	repo.Replies = append(repo.Replies, *reply)
+	for i, v := range repo.Data {
+		if v.ID == reply.TicketID {
+			repo.Data[i].Status = status
+			if reply.AuthorRole == model.TicketAuthorAdmin {
+				repo.Data[i].Answer = reply.Message
+				repo.Data[i].IsAnswered = true
+			}
+		}
+	}
+	return nil
+}
+
+func (repo *InMemoryCustServiceRepo) AdminExists(adminID int) (bool, error) {
+	for _, id := range repo.Admins {
+		if id == adminID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // Unit tests for SaveCustService and AnswerMessage methods.
 func TestSaveCustService(t *testing.T) {
 	// Arrange
 	repo := &InMemoryCustServiceRepo{}
-	usecase := NewCustServiceUsecase(repo)
+	usecase := NewCustServiceUsecase(repo, &recordingScheduler{Jobs: map[string]string{}})
 
 	userID := 1
 	message := "Test message"
@@ -63,7 +152,7 @@ func TestAnswerMessage(t *testing.T) {
 			{ID: 1, Message: "Test message", IsAnswered: false},
 		},
 	}
-	usecase := NewCustServiceUsecase(repo)
+	usecase := NewCustServiceUsecase(repo, &recordingScheduler{Jobs: map[string]string{}})
 
 	id := 1
 	answer := "Test answer"
@@ -90,7 +179,7 @@ func TestAnswerMessageWithInvalidID(t *testing.T) {
 			{ID: 1, Message: "Test message", IsAnswered: false},
 		},
 	}
-	usecase := NewCustServiceUsecase(repo)
+	usecase := NewCustServiceUsecase(repo, &recordingScheduler{Jobs: map[string]string{}})
 
 	invalidID := 2
 	answer := "Test answer"
@@ -109,3 +198,123 @@ func TestAnswerMessageWithInvalidID(t *testing.T) {
 		t.Errorf("AnswerMessage() failed, expected Answer to remain empty, got %s", repo.Data[0].Answer)
 	}
 }
+
+func TestOpenTicket(t *testing.T) {
+	repo := &InMemoryCustServiceRepo{}
+	usecase := NewCustServiceUsecase(repo, &recordingScheduler{Jobs: map[string]string{}})
+
+	ticket, err := usecase.OpenTicket(1, "", "  Saya tidak bisa membayar konsultasi menggunakan GoPay sejak kemarin sore  ")
+	if err != nil {
+		t.Fatalf("OpenTicket() failed, expected no error, got %v", err)
+	}
+	if ticket.Status != model.TicketStatusOpen || ticket.Priority != model.TicketPriorityNormal {
+		t.Errorf("OpenTicket() failed, expected open/normal ticket, got %s/%s", ticket.Status, ticket.Priority)
+	}
+	if ticket.Subject != "Saya tidak bisa membayar konsultasi menggunakan Go..." {
+		t.Errorf("OpenTicket() failed, unexpected default subject %q", ticket.Subject)
+	}
+
+	if _, err := usecase.OpenTicket(1, "Pembayaran", "   "); !errors.Is(err, ErrEmptyTicketMessage) {
+		t.Errorf("OpenTicket() failed, expected ErrEmptyTicketMessage, got %v", err)
+	}
+}
+
+func TestGetUserTicketOfAnotherUser(t *testing.T) {
+	repo := &InMemoryCustServiceRepo{
+		Data: []model.CustService{{ID: 1, UserID: 1, Message: "Test message", Status: model.TicketStatusOpen}},
+	}
+	usecase := NewCustServiceUsecase(repo, &recordingScheduler{Jobs: map[string]string{}})
+
+	if _, err := usecase.GetUserTicket(2, 1); !errors.Is(err, ErrTicketNotFound) {
+		t.Errorf("GetUserTicket() failed, expected ErrTicketNotFound, got %v", err)
+	}
+	if _, err := usecase.ReplyAsUser(2, 1, "Halo"); !errors.Is(err, ErrTicketNotFound) {
+		t.Errorf("ReplyAsUser() failed, expected ErrTicketNotFound, got %v", err)
+	}
+}
+
+// Scheduler palsu yang hanya mencatat job yang dijadwalkan
+type recordingScheduler struct {
+	usecase_scheduler.SchedulerUsecase
+	Jobs map[string]string // Key -> jenis job
+}
+
+func (s *recordingScheduler) Schedule(jobType, uniqueKey string, runAt time.Time, payload interface{}) error {
+	s.Jobs[uniqueKey] = jobType
+	return nil
+}
+
+func TestAnswerTicketAssignsAdminAndThreadsReplies(t *testing.T) {
+	repo := &InMemoryCustServiceRepo{
+		Data: []model.CustService{{ID: 1, UserID: 1, Message: "Test message", Status: model.TicketStatusOpen}},
+	}
+	scheduler := &recordingScheduler{Jobs: map[string]string{}}
+	usecase := NewCustServiceUsecase(repo, scheduler)
+
+	reply, err := usecase.AnswerTicket(7, 1, "Silakan coba lagi")
+	if err != nil {
+		t.Fatalf("AnswerTicket() failed, expected no error, got %v", err)
+	}
+	// Email jawaban tidak dikirim di request admin, tetapi diserahkan ke worker scheduler
+	key := usecase_scheduler.JobKey(usecase_scheduler.JobTicketAnswerEmail, reply.ID)
+	if scheduler.Jobs[key] != usecase_scheduler.JobTicketAnswerEmail {
+		t.Errorf("AnswerTicket() failed, expected answer email job %s to be scheduled, got %v", key, scheduler.Jobs)
+	}
+	if repo.Data[0].AssignedAdminID == nil || *repo.Data[0].AssignedAdminID != 7 {
+		t.Errorf("AnswerTicket() failed, expected ticket assigned to admin 7")
+	}
+	if repo.Data[0].Status != model.TicketStatusAnswered || repo.Data[0].Answer != "Silakan coba lagi" {
+		t.Errorf("AnswerTicket() failed, expected answered ticket, got status %s answer %q", repo.Data[0].Status, repo.Data[0].Answer)
+	}
+
+	// Balasan user mengembalikan tiket ke admin yang menangani
+	if _, err := usecase.ReplyAsUser(1, 1, "Masih gagal"); err != nil {
+		t.Fatalf("ReplyAsUser() failed, expected no error, got %v", err)
+	}
+	if repo.Data[0].Status != model.TicketStatusInProgress {
+		t.Errorf("ReplyAsUser() failed, expected status in_progress, got %s", repo.Data[0].Status)
+	}
+
+	ticket, _ := usecase.GetUserTicket(1, 1)
+	if len(ticket.Replies) != 2 || ticket.Replies[0].AuthorRole != model.TicketAuthorAdmin || ticket.Replies[1].AuthorRole != model.TicketAuthorUser {
+		t.Errorf("GetUserTicket() failed, expected admin then user reply, got %+v", ticket.Replies)
+	}
+
+	if err := usecase.CloseTicket(1, 1); err != nil {
+		t.Fatalf("CloseTicket() failed, expected no error, got %v", err)
+	}
+	if _, err := usecase.AnswerTicket(7, 1, "Tambahan"); !errors.Is(err, ErrTicketClosed) {
+		t.Errorf("AnswerTicket() failed, expected ErrTicketClosed, got %v", err)
+	}
+}
+
+func TestUpdateTicket(t *testing.T) {
+	repo := &InMemoryCustServiceRepo{
+		Data:   []model.CustService{{ID: 1, UserID: 1, Message: "Test message", Status: model.TicketStatusOpen, Priority: model.TicketPriorityNormal}},
+		Admins: []int{3},
+	}
+	usecase := NewCustServiceUsecase(repo, &recordingScheduler{Jobs: map[string]string{}})
+
+	if _, err := usecase.UpdateTicket(1, TicketUpdate{Priority: "critical"}); !errors.Is(err, ErrInvalidPriority) {
+		t.Errorf("UpdateTicket() failed, expected ErrInvalidPriority, got %v", err)
+	}
+
+	unknownAdmin := 9
+	if _, err := usecase.UpdateTicket(1, TicketUpdate{AssignedAdminID: &unknownAdmin}); !errors.Is(err, ErrAdminNotFound) {
+		t.Errorf("UpdateTicket() failed, expected ErrAdminNotFound, got %v", err)
+	}
+
+	adminID := 3
+	ticket, err := usecase.UpdateTicket(1, TicketUpdate{Priority: model.TicketPriorityUrgent, AssignedAdminID: &adminID})
+	if err != nil {
+		t.Fatalf("UpdateTicket() failed, expected no error, got %v", err)
+	}
+	if ticket.Priority != model.TicketPriorityUrgent || ticket.Status != model.TicketStatusInProgress {
+		t.Errorf("UpdateTicket() failed, expected urgent/in_progress, got %s/%s", ticket.Priority, ticket.Status)
+	}
+
+	tickets, _, _ := usecase.GetTickets(repository.TicketFilter{Unassigned: true}, model.QueryOptions{})
+	if len(tickets) != 0 {
+		t.Errorf("GetTickets() failed, expected no unassigned tickets, got %d", len(tickets))
+	}
+}
@@ -0,0 +1,333 @@
+package usecase
+
+import (
+	"calmind/helper"
+	"calmind/model"
+	repository "calmind/repository/faq"
+	"calmind/service"
+	"calmind/service/ratelimit"
+	"calmind/service/textsearch"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Porsi minimal kata pertanyaan yang harus ditemukan di FAQ agar jawabannya dipakai
+const MinMatchCoverage = 0.6
+
+// Bobot bagian FAQ saat pencarian
+const (
+	questionWeight = 3
+	keywordWeight  = 2
+	answerWeight   = 1
+)
+
+// Panjang maksimal pertanyaan customer service (rune)
+const maxQuestionLength = 500
+
+// Endpoint customer service terbuka tanpa login, jadi pertanyaan yang diteruskan ke AI dibatasi per klien (IP)
+const (
+	aiRequestsPerClient = 10
+	aiRateWindow        = time.Hour
+)
+
+// Asal jawaban customer service
+const (
+	SourceFaq  = "faq"
+	SourceAI   = "ai"
+	SourceNone = "none"
+)
+
+const fallbackResponse = "Jawaban atas pertanyaan anda tidak tersedia. Silahkan masuk lalu buat tiket bantuan agar Admin dapat membantu Anda."
+
+var (
+	ErrEmptyQuestion       = errors.New("pertanyaan tidak boleh kosong")
+	ErrQuestionTooLong     = errors.New("pertanyaan maksimal 500 karakter")
+	ErrFaqNotFound         = errors.New("FAQ tidak ditemukan")
+	ErrFaqCategoryNotFound = errors.New("kategori FAQ tidak ditemukan")
+	ErrCategoryNotEmpty    = errors.New("kategori FAQ masih memiliki pertanyaan")
+	ErrInvalidCategoryName = errors.New("nama kategori wajib diisi, maksimal 100 karakter")
+	ErrInvalidFaq          = errors.New("pertanyaan dan jawaban FAQ wajib diisi")
+)
+
+type FaqSuggestion struct {
+	ID       int    `json:"id"`
+	Question string `json:"question"`
+}
+
+// Jawaban untuk POST /customer-service. CanOpenTicket menandakan klien sebaiknya menawarkan pembuatan tiket.
+type FaqAnswer struct {
+	Message       string          `json:"message"`
+	Response      string          `json:"response"`
+	Source        string          `json:"source"`
+	FaqID         int             `json:"faq_id,omitempty"`
+	Suggestions   []FaqSuggestion `json:"suggestions,omitempty"`
+	CanOpenTicket bool            `json:"can_open_ticket"`
+}
+
+type FaqInput struct {
+	CategoryID int    `json:"category_id"`
+	Question   string `json:"question"`
+	Answer     string `json:"answer"`
+	Keywords   string `json:"keywords"`
+	Position   int    `json:"position"`
+}
+
+type FaqUsecase interface {
+	Answer(ctx context.Context, clientKey string, message string) (*FaqAnswer, error)
+	GetQuestions() (map[string]string, error)
+	GetCategories() ([]model.FaqCategory, error)
+
+	// Pengelolaan oleh admin
+	CreateCategory(name string, position int) (*model.FaqCategory, error)
+	UpdateCategory(id int, name string, position int) (*model.FaqCategory, error)
+	DeleteCategory(id int) error
+	GetFaqs() ([]model.Faq, error)
+	CreateFaq(input FaqInput) (*model.Faq, error)
+	UpdateFaq(id int, input FaqInput) (*model.Faq, error)
+	DeleteFaq(id int) error
+}
+
+type FaqUsecaseImpl struct {
+	Repo      repository.FaqRepository
+	LLM       service.LLMProvider // Opsional, nil berarti pertanyaan tanpa jawaban langsung diarahkan ke tiket
+	AILimiter *ratelimit.Limiter  // Batas pertanyaan ke AI per klien, nil berarti tanpa batas
+}
+
+func NewFaqUsecase(repo repository.FaqRepository, llm service.LLMProvider) FaqUsecase {
+	return &FaqUsecaseImpl{Repo: repo, LLM: llm, AILimiter: ratelimit.New(aiRequestsPerClient, aiRateWindow)}
+}
+
+// Mencari jawaban di FAQ. Nomor FAQ (format lama "1"-"7") dijawab langsung, teks bebas dicocokkan dengan skor kata kunci.
+// Jika tidak ada yang cukup cocok, pertanyaan diteruskan ke AI dengan FAQ sebagai konteks, lalu ke tiket.
+// clientKey (IP pengirim) membatasi pertanyaan ke AI; di atas batas, jawaban langsung mengarahkan ke tiket.
+func (u *FaqUsecaseImpl) Answer(ctx context.Context, clientKey string, message string) (*FaqAnswer, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, ErrEmptyQuestion
+	}
+	if utf8.RuneCountInString(message) > maxQuestionLength {
+		return nil, ErrQuestionTooLong
+	}
+
+	faqs, err := u.Repo.GetFaqs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch FAQ: %v", err)
+	}
+
+	if id, err := strconv.Atoi(message); err == nil {
+		for _, faq := range faqs {
+			if faq.ID == id {
+				return &FaqAnswer{Message: message, Response: faq.Answer, Source: SourceFaq, FaqID: faq.ID}, nil
+			}
+		}
+	}
+
+	byID := make(map[int]model.Faq, len(faqs))
+	documents := make([]textsearch.Document, 0, len(faqs))
+	for _, faq := range faqs {
+		byID[faq.ID] = faq
+		documents = append(documents, textsearch.Document{ID: faq.ID, Fields: []textsearch.Field{
+			{Text: faq.Question, Weight: questionWeight},
+			{Text: faq.Keywords, Weight: keywordWeight},
+			{Text: faq.Answer, Weight: answerWeight},
+		}})
+	}
+
+	results := textsearch.NewIndex(documents).Search(message, 3)
+	if len(results) > 0 && results[0].Coverage >= MinMatchCoverage {
+		best := byID[results[0].ID]
+		answer := &FaqAnswer{Message: message, Response: best.Answer, Source: SourceFaq, FaqID: best.ID}
+		for _, result := range results[1:] {
+			answer.Suggestions = append(answer.Suggestions, FaqSuggestion{ID: result.ID, Question: byID[result.ID].Question})
+		}
+		return answer, nil
+	}
+
+	var suggestions []FaqSuggestion
+	for _, result := range results {
+		suggestions = append(suggestions, FaqSuggestion{ID: result.ID, Question: byID[result.ID].Question})
+	}
+
+	if u.LLM != nil && (u.AILimiter == nil || u.AILimiter.Allow(clientKey)) {
+		response, err := u.LLM.Generate(ctx, buildFaqPrompt(faqs, message))
+		if err == nil {
+			return &FaqAnswer{
+				Message:       message,
+				Response:      helper.CleanAIResponse(response),
+				Source:        SourceAI,
+				Suggestions:   suggestions,
+				CanOpenTicket: true,
+			}, nil
+		}
+		log.Printf("Gagal meneruskan pertanyaan customer service ke AI: %v", err)
+	}
+
+	return &FaqAnswer{
+		Message:       message,
+		Response:      fallbackResponse,
+		Source:        SourceNone,
+		Suggestions:   suggestions,
+		CanOpenTicket: true,
+	}, nil
+}
+
+// Daftar pertanyaan dengan nomor FAQ sebagai kunci, format yang dipakai GET /customer-service sejak awal
+func (u *FaqUsecaseImpl) GetQuestions() (map[string]string, error) {
+	faqs, err := u.Repo.GetFaqs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch FAQ: %v", err)
+	}
+
+	questions := make(map[string]string, len(faqs))
+	for _, faq := range faqs {
+		questions[strconv.Itoa(faq.ID)] = faq.Question
+	}
+	return questions, nil
+}
+
+func (u *FaqUsecaseImpl) GetCategories() ([]model.FaqCategory, error) {
+	categories, err := u.Repo.GetCategories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch FAQ categories: %v", err)
+	}
+	return categories, nil
+}
+
+func (u *FaqUsecaseImpl) CreateCategory(name string, position int) (*model.FaqCategory, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > 100 {
+		return nil, ErrInvalidCategoryName
+	}
+
+	category := &model.FaqCategory{Name: name, Position: position}
+	if err := u.Repo.CreateCategory(category); err != nil {
+		return nil, fmt.Errorf("failed to create FAQ category: %v", err)
+	}
+	return category, nil
+}
+
+func (u *FaqUsecaseImpl) UpdateCategory(id int, name string, position int) (*model.FaqCategory, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > 100 {
+		return nil, ErrInvalidCategoryName
+	}
+
+	category, err := u.Repo.GetCategoryByID(id)
+	if err != nil {
+		return nil, ErrFaqCategoryNotFound
+	}
+
+	category.Name = name
+	category.Position = position
+	if err := u.Repo.UpdateCategory(category); err != nil {
+		return nil, fmt.Errorf("failed to update FAQ category: %v", err)
+	}
+	return category, nil
+}
+
+// Kategori hanya bisa dihapus jika sudah kosong agar FAQ tidak kehilangan kategorinya
+func (u *FaqUsecaseImpl) DeleteCategory(id int) error {
+	if _, err := u.Repo.GetCategoryByID(id); err != nil {
+		return ErrFaqCategoryNotFound
+	}
+
+	count, err := u.Repo.CountFaqsInCategory(id)
+	if err != nil {
+		return fmt.Errorf("failed to count FAQ: %v", err)
+	}
+	if count > 0 {
+		return ErrCategoryNotEmpty
+	}
+
+	if err := u.Repo.DeleteCategory(id); err != nil {
+		return fmt.Errorf("failed to delete FAQ category: %v", err)
+	}
+	return nil
+}
+
+func (u *FaqUsecaseImpl) GetFaqs() ([]model.Faq, error) {
+	faqs, err := u.Repo.GetFaqs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch FAQ: %v", err)
+	}
+	return faqs, nil
+}
+
+func (u *FaqUsecaseImpl) CreateFaq(input FaqInput) (*model.Faq, error) {
+	faq := &model.Faq{}
+	if err := u.applyInput(faq, input); err != nil {
+		return nil, err
+	}
+
+	if err := u.Repo.CreateFaq(faq); err != nil {
+		return nil, fmt.Errorf("failed to create FAQ: %v", err)
+	}
+	return faq, nil
+}
+
+func (u *FaqUsecaseImpl) UpdateFaq(id int, input FaqInput) (*model.Faq, error) {
+	faq, err := u.Repo.GetFaqByID(id)
+	if err != nil {
+		return nil, ErrFaqNotFound
+	}
+	if err := u.applyInput(faq, input); err != nil {
+		return nil, err
+	}
+
+	if err := u.Repo.UpdateFaq(faq); err != nil {
+		return nil, fmt.Errorf("failed to update FAQ: %v", err)
+	}
+	return faq, nil
+}
+
+func (u *FaqUsecaseImpl) DeleteFaq(id int) error {
+	if _, err := u.Repo.GetFaqByID(id); err != nil {
+		return ErrFaqNotFound
+	}
+
+	if err := u.Repo.DeleteFaq(id); err != nil {
+		return fmt.Errorf("failed to delete FAQ: %v", err)
+	}
+	return nil
+}
+
+func (u *FaqUsecaseImpl) applyInput(faq *model.Faq, input FaqInput) error {
+	question := strings.TrimSpace(input.Question)
+	answer := strings.TrimSpace(input.Answer)
+	if question == "" || answer == "" {
+		return ErrInvalidFaq
+	}
+	if _, err := u.Repo.GetCategoryByID(input.CategoryID); err != nil {
+		return ErrFaqCategoryNotFound
+	}
+
+	faq.CategoryID = input.CategoryID
+	faq.Question = question
+	faq.Answer = answer
+	faq.Keywords = strings.TrimSpace(input.Keywords)
+	faq.Position = input.Position
+	return nil
+}
+
+// Prompt AI dibatasi pada isi FAQ agar tidak mengarang kebijakan yang tidak ada
+func buildFaqPrompt(faqs []model.Faq, question string) string {
+	var prompt strings.Builder
+	prompt.WriteString("Anda adalah customer service aplikasi Calmind, layanan konsultasi kesehatan mental dengan psikolog dan psikiater. ")
+	prompt.WriteString("Jawab pertanyaan pengguna secara singkat dan ramah hanya berdasarkan informasi FAQ berikut. ")
+	prompt.WriteString("Jika informasinya tidak ada di FAQ, katakan bahwa Anda belum bisa menjawab dan sarankan pengguna membuat tiket bantuan agar Admin dapat membantu.\n\n")
+
+	prompt.WriteString("FAQ:\n")
+	for _, faq := range faqs {
+		prompt.WriteString("T: " + faq.Question + "\n")
+		prompt.WriteString("J: " + faq.Answer + "\n")
+	}
+
+	prompt.WriteString("\nPertanyaan pengguna: " + question + "\n")
+	return prompt.String()
+}
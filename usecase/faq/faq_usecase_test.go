@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"calmind/model"
+	"calmind/service"
+	"calmind/service/ratelimit"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// In-memory implementation of the FAQ repository for testing.
+type InMemoryFaqRepo struct {
+	Categories []model.FaqCategory
+	Faqs       []model.Faq
+}
+
+func (repo *InMemoryFaqRepo) GetCategories() ([]model.FaqCategory, error) {
+	return repo.Categories, nil
+}
+
+func (repo *InMemoryFaqRepo) GetCategoryByID(id int) (*model.FaqCategory, error) {
+	for _, category := range repo.Categories {
+		if category.ID == id {
+			return &category, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (repo *InMemoryFaqRepo) CreateCategory(category *model.FaqCategory) error {
+	category.ID = len(repo.Categories) + 1
+	repo.Categories = append(repo.Categories, *category)
+	return nil
+}
+
+func (repo *InMemoryFaqRepo) UpdateCategory(category *model.FaqCategory) error {
+	return nil
+}
+
+func (repo *InMemoryFaqRepo) DeleteCategory(id int) error {
+	return nil
+}
+
+func (repo *InMemoryFaqRepo) CountFaqsInCategory(categoryID int) (int64, error) {
+	var count int64
+	for _, faq := range repo.Faqs {
+		if faq.CategoryID == categoryID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (repo *InMemoryFaqRepo) GetFaqs() ([]model.Faq, error) {
+	return repo.Faqs, nil
+}
+
+func (repo *InMemoryFaqRepo) GetFaqByID(id int) (*model.Faq, error) {
+	for _, faq := range repo.Faqs {
+		if faq.ID == id {
+			return &faq, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (repo *InMemoryFaqRepo) CreateFaq(faq *model.Faq) error {
+	faq.ID = len(repo.Faqs) + 1
+	repo.Faqs = append(repo.Faqs, *faq)
+	return nil
+}
+
+func (repo *InMemoryFaqRepo) UpdateFaq(faq *model.Faq) error {
+	return nil
+}
+
+func (repo *InMemoryFaqRepo) DeleteFaq(id int) error {
+	return nil
+}
+
+func newSeededFaqRepo() *InMemoryFaqRepo {
+	return &InMemoryFaqRepo{
+		Categories: []model.FaqCategory{{ID: 1, Name: "Pembayaran"}, {ID: 2, Name: "Akun"}},
+		Faqs: []model.Faq{
+			{ID: 1, CategoryID: 1, Question: "Metode pembayaran?", Keywords: "cara bayar, gopay, ovo, e-wallet, transfer bank, kartu kredit, debit", Answer: "Kartu kredit/debit, E-wallet, Transfer bank."},
+			{ID: 5, CategoryID: 2, Question: "Bagaimana cara mendaftar akun?", Keywords: "daftar, register, registrasi, buat akun, sign up", Answer: "Klik tombol 'Daftar' pada halaman awal."},
+			{ID: 7, CategoryID: 2, Question: "Bagaimana jika lupa kata sandi?", Keywords: "lupa password, reset password, ganti kata sandi", Answer: "Klik tombol 'Lupa Kata Sandi' pada halaman masuk."},
+		},
+	}
+}
+
+func TestAnswer_LegacyNumberAndFreeText(t *testing.T) {
+	usecase := NewFaqUsecase(newSeededFaqRepo(), nil)
+	ctx := context.Background()
+
+	answer, err := usecase.Answer(ctx, "10.0.0.1", "5")
+	assert.NoError(t, err)
+	assert.Equal(t, SourceFaq, answer.Source)
+	assert.Equal(t, 5, answer.FaqID)
+
+	answer, err = usecase.Answer(ctx, "10.0.0.1", "Min, saya lupa password nih")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, answer.FaqID)
+	assert.False(t, answer.CanOpenTicket)
+
+	answer, err = usecase.Answer(ctx, "10.0.0.1", "bisa bayar pakai GoPay?")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, answer.FaqID)
+
+	_, err = usecase.Answer(ctx, "10.0.0.1", "   ")
+	assert.ErrorIs(t, err, ErrEmptyQuestion)
+}
+
+func TestAnswer_FallbackToAIThenTicket(t *testing.T) {
+	llm := &service.FakeLLMProvider{Reply: "Maaf, saya belum bisa menjawab. Silakan buat tiket bantuan."}
+	usecase := NewFaqUsecase(newSeededFaqRepo(), llm)
+
+	answer, err := usecase.Answer(context.Background(), "10.0.0.1", "Apakah dokter bisa membuat surat keterangan sakit?")
+	assert.NoError(t, err)
+	assert.Equal(t, SourceAI, answer.Source)
+	assert.True(t, answer.CanOpenTicket)
+	assert.Len(t, llm.Prompts, 1)
+	assert.Contains(t, llm.Prompts[0], "Metode pembayaran?")
+
+	llm.Err = errors.New("quota exceeded")
+	answer, err = usecase.Answer(context.Background(), "10.0.0.1", "Apakah dokter bisa membuat surat keterangan sakit?")
+	assert.NoError(t, err)
+	assert.Equal(t, SourceNone, answer.Source)
+	assert.True(t, answer.CanOpenTicket)
+}
+
+func TestAnswer_AIRateLimitedPerClient(t *testing.T) {
+	llm := &service.FakeLLMProvider{Reply: "Silakan buat tiket bantuan."}
+	usecase := NewFaqUsecase(newSeededFaqRepo(), llm).(*FaqUsecaseImpl)
+	usecase.AILimiter = ratelimit.New(1, time.Hour)
+	question := "Apakah dokter bisa membuat surat keterangan sakit?"
+
+	answer, _ := usecase.Answer(context.Background(), "10.0.0.1", question)
+	assert.Equal(t, SourceAI, answer.Source)
+
+	// Di atas batas, jawaban statis tanpa memanggil AI
+	answer, err := usecase.Answer(context.Background(), "10.0.0.1", question)
+	assert.NoError(t, err)
+	assert.Equal(t, SourceNone, answer.Source)
+	assert.True(t, answer.CanOpenTicket)
+	assert.Len(t, llm.Prompts, 1)
+
+	answer, _ = usecase.Answer(context.Background(), "10.0.0.2", question)
+	assert.Equal(t, SourceAI, answer.Source)
+
+	_, err = usecase.Answer(context.Background(), "10.0.0.3", strings.Repeat("a", maxQuestionLength+1))
+	assert.ErrorIs(t, err, ErrQuestionTooLong)
+}
+
+func TestDeleteCategory_NotEmpty(t *testing.T) {
+	usecase := NewFaqUsecase(newSeededFaqRepo(), nil)
+
+	assert.ErrorIs(t, usecase.DeleteCategory(2), ErrCategoryNotEmpty)
+	assert.ErrorIs(t, usecase.DeleteCategory(9), ErrFaqCategoryNotFound)
+
+	_, err := usecase.CreateFaq(FaqInput{CategoryID: 9, Question: "Q?", Answer: "A"})
+	assert.ErrorIs(t, err, ErrFaqCategoryNotFound)
+}
@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"calmind/model"
+	repository "calmind/repository/jadwal"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	dateLayout      = "2006-01-02"
+	clockLayout     = "15:04"
+	maxSlotRangeDay = 31
+	minSlotDuration = 15
+	maxSlotDuration = 240
+)
+
+var (
+	ErrInvalidSchedule = errors.New("jadwal tidak valid")
+	ErrSlotUnavailable = errors.New("slot jadwal tidak tersedia")
+)
+
+type JadwalUsecase interface {
+	GetDoctorSchedule(doctorID int) ([]model.DoctorSchedule, []model.DoctorScheduleException, error)
+	AddSchedule(doctorID int, schedule *model.DoctorSchedule) error
+	UpdateSchedule(doctorID, id int, schedule *model.DoctorSchedule) (*model.DoctorSchedule, error)
+	DeleteSchedule(doctorID, id int) error
+	AddException(doctorID int, exception *model.DoctorScheduleException) error
+	DeleteException(doctorID, id int) error
+	GetAvailableSlots(doctorID int, from, to time.Time) ([]model.TimeSlot, error)
+	FindSlot(doctorID int, start time.Time) (*model.TimeSlot, error)
+}
+
+type JadwalUsecaseImpl struct {
+	Repo repository.JadwalRepository
+	Now  func() time.Time
+}
+
+func NewJadwalUsecase(repo repository.JadwalRepository) JadwalUsecase {
+	return &JadwalUsecaseImpl{Repo: repo, Now: time.Now}
+}
+
+// Mendapatkan jadwal mingguan dan pengecualian yang akan datang milik dokter
+func (u *JadwalUsecaseImpl) GetDoctorSchedule(doctorID int) ([]model.DoctorSchedule, []model.DoctorScheduleException, error) {
+	schedules, err := u.Repo.GetSchedulesByDoctor(doctorID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch schedules: %v", err)
+	}
+
+	today := u.Now().Format(dateLayout)
+	exceptions, err := u.Repo.GetExceptionsByDoctor(doctorID, today, "9999-12-31")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch schedule exceptions: %v", err)
+	}
+
+	return schedules, exceptions, nil
+}
+
+// Menambahkan jadwal mingguan baru
+func (u *JadwalUsecaseImpl) AddSchedule(doctorID int, schedule *model.DoctorSchedule) error {
+	schedule.ID = 0
+	schedule.DoctorID = doctorID
+	if err := u.validateSchedule(schedule); err != nil {
+		return err
+	}
+	return u.Repo.CreateSchedule(schedule)
+}
+
+// Memperbarui jadwal mingguan milik dokter
+func (u *JadwalUsecaseImpl) UpdateSchedule(doctorID, id int, schedule *model.DoctorSchedule) (*model.DoctorSchedule, error) {
+	existing, err := u.Repo.GetScheduleByID(doctorID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.DayOfWeek = schedule.DayOfWeek
+	existing.StartTime = schedule.StartTime
+	existing.EndTime = schedule.EndTime
+	if schedule.SlotDuration > 0 {
+		existing.SlotDuration = schedule.SlotDuration
+	}
+
+	if err := u.validateSchedule(existing); err != nil {
+		return nil, err
+	}
+	if err := u.Repo.UpdateSchedule(existing); err != nil {
+		return nil, fmt.Errorf("failed to update schedule: %v", err)
+	}
+	return existing, nil
+}
+
+func (u *JadwalUsecaseImpl) DeleteSchedule(doctorID, id int) error {
+	return u.Repo.DeleteSchedule(doctorID, id)
+}
+
+// Menambahkan pengecualian jadwal (libur, blokir jam, atau jam tambahan)
+func (u *JadwalUsecaseImpl) AddException(doctorID int, exception *model.DoctorScheduleException) error {
+	exception.ID = 0
+	exception.DoctorID = doctorID
+
+	if _, err := time.ParseInLocation(dateLayout, exception.Date, time.Local); err != nil {
+		return fmt.Errorf("%w: format tanggal harus YYYY-MM-DD", ErrInvalidSchedule)
+	}
+
+	if exception.StartTime == "" && exception.EndTime == "" {
+		if exception.IsAvailable {
+			return fmt.Errorf("%w: jam tambahan wajib memiliki start_time dan end_time", ErrInvalidSchedule)
+		}
+		return u.Repo.CreateException(exception)
+	}
+
+	if exception.SlotDuration == 0 {
+		exception.SlotDuration = 60
+	}
+	if err := validateWindow(exception.StartTime, exception.EndTime, exception.SlotDuration); err != nil {
+		return err
+	}
+	return u.Repo.CreateException(exception)
+}
+
+func (u *JadwalUsecaseImpl) DeleteException(doctorID, id int) error {
+	return u.Repo.DeleteException(doctorID, id)
+}
+
+// Menghitung slot yang masih bisa dipesan dalam rentang tanggal (inklusif)
+func (u *JadwalUsecaseImpl) GetAvailableSlots(doctorID int, from, to time.Time) ([]model.TimeSlot, error) {
+	from = startOfDay(from)
+	to = startOfDay(to)
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: tanggal akhir sebelum tanggal awal", ErrInvalidSchedule)
+	}
+	if to.Sub(from) > maxSlotRangeDay*24*time.Hour {
+		return nil, fmt.Errorf("%w: rentang tanggal maksimal %d hari", ErrInvalidSchedule, maxSlotRangeDay)
+	}
+
+	schedules, err := u.Repo.GetSchedulesByDoctor(doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schedules: %v", err)
+	}
+
+	exceptions, err := u.Repo.GetExceptionsByDoctor(doctorID, from.Format(dateLayout), to.Format(dateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schedule exceptions: %v", err)
+	}
+
+	booked, err := u.Repo.GetBookedConsultations(doctorID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch booked consultations: %v", err)
+	}
+
+	now := u.Now()
+	slots := []model.TimeSlot{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		for _, slot := range buildDaySlots(day, schedules, exceptions) {
+			if slot.Start.Before(now) || overlapsBooked(slot, booked) {
+				continue
+			}
+			slots = append(slots, slot)
+		}
+	}
+
+	return slots, nil
+}
+
+// Mencari slot terbuka yang dimulai tepat pada waktu yang dipilih user
+func (u *JadwalUsecaseImpl) FindSlot(doctorID int, start time.Time) (*model.TimeSlot, error) {
+	start = start.In(time.Local)
+	slots, err := u.GetAvailableSlots(doctorID, start, start)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, slot := range slots {
+		if slot.Start.Equal(start) {
+			return &slot, nil
+		}
+	}
+	return nil, ErrSlotUnavailable
+}
+
+// Validasi jadwal mingguan dan pastikan tidak bertabrakan dengan jadwal lain di hari yang sama
+func (u *JadwalUsecaseImpl) validateSchedule(schedule *model.DoctorSchedule) error {
+	if schedule.DayOfWeek < 0 || schedule.DayOfWeek > 6 {
+		return fmt.Errorf("%w: day_of_week harus bernilai 0 (Minggu) sampai 6 (Sabtu)", ErrInvalidSchedule)
+	}
+	if schedule.SlotDuration == 0 {
+		schedule.SlotDuration = 60
+	}
+	if err := validateWindow(schedule.StartTime, schedule.EndTime, schedule.SlotDuration); err != nil {
+		return err
+	}
+
+	existing, err := u.Repo.GetSchedulesByDoctor(schedule.DoctorID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch schedules: %v", err)
+	}
+
+	start, _ := parseClock(schedule.StartTime)
+	end, _ := parseClock(schedule.EndTime)
+	for _, other := range existing {
+		if other.ID == schedule.ID || other.DayOfWeek != schedule.DayOfWeek {
+			continue
+		}
+		otherStart, _ := parseClock(other.StartTime)
+		otherEnd, _ := parseClock(other.EndTime)
+		if start < otherEnd && end > otherStart {
+			return fmt.Errorf("%w: jadwal bertabrakan dengan jadwal %s-%s", ErrInvalidSchedule, other.StartTime, other.EndTime)
+		}
+	}
+	return nil
+}
+
+func validateWindow(startTime, endTime string, slotDuration int) error {
+	start, err := parseClock(startTime)
+	if err != nil {
+		return fmt.Errorf("%w: start_time harus berformat HH:MM", ErrInvalidSchedule)
+	}
+	end, err := parseClock(endTime)
+	if err != nil {
+		return fmt.Errorf("%w: end_time harus berformat HH:MM", ErrInvalidSchedule)
+	}
+	if end <= start {
+		return fmt.Errorf("%w: end_time harus setelah start_time", ErrInvalidSchedule)
+	}
+	if slotDuration < minSlotDuration || slotDuration > maxSlotDuration {
+		return fmt.Errorf("%w: slot_duration harus antara %d dan %d menit", ErrInvalidSchedule, minSlotDuration, maxSlotDuration)
+	}
+	if end-start < slotDuration {
+		return fmt.Errorf("%w: rentang waktu lebih pendek dari slot_duration", ErrInvalidSchedule)
+	}
+	return nil
+}
+
+type timeWindow struct {
+	start time.Time
+	end   time.Time
+	step  int
+}
+
+// Menyusun slot untuk satu hari dari jadwal mingguan dan pengecualian tanggal tersebut
+func buildDaySlots(day time.Time, schedules []model.DoctorSchedule, exceptions []model.DoctorScheduleException) []model.TimeSlot {
+	var windows, blocked []timeWindow
+	date := day.Format(dateLayout)
+
+	for _, schedule := range schedules {
+		if schedule.DayOfWeek != int(day.Weekday()) {
+			continue
+		}
+		if window, ok := newWindow(day, schedule.StartTime, schedule.EndTime, schedule.SlotDuration); ok {
+			windows = append(windows, window)
+		}
+	}
+
+	for _, exception := range exceptions {
+		if exception.Date != date {
+			continue
+		}
+		if exception.StartTime == "" {
+			if !exception.IsAvailable {
+				return nil // Libur sepanjang hari
+			}
+			continue
+		}
+		window, ok := newWindow(day, exception.StartTime, exception.EndTime, exception.SlotDuration)
+		if !ok {
+			continue
+		}
+		if exception.IsAvailable {
+			windows = append(windows, window)
+		} else {
+			blocked = append(blocked, window)
+		}
+	}
+
+	seen := map[int64]bool{}
+	var slots []model.TimeSlot
+	for _, window := range windows {
+		step := time.Duration(window.step) * time.Minute
+		for start := window.start; !start.Add(step).After(window.end); start = start.Add(step) {
+			end := start.Add(step)
+			if seen[start.Unix()] || overlapsWindow(start, end, blocked) {
+				continue
+			}
+			seen[start.Unix()] = true
+			slots = append(slots, model.TimeSlot{Start: start, End: end, Duration: window.step})
+		}
+	}
+
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Start.Before(slots[j].Start) })
+	return slots
+}
+
+func newWindow(day time.Time, startTime, endTime string, step int) (timeWindow, bool) {
+	start, err := parseClock(startTime)
+	if err != nil {
+		return timeWindow{}, false
+	}
+	end, err := parseClock(endTime)
+	if err != nil || end <= start {
+		return timeWindow{}, false
+	}
+	if step <= 0 {
+		step = 60
+	}
+	return timeWindow{
+		start: day.Add(time.Duration(start) * time.Minute),
+		end:   day.Add(time.Duration(end) * time.Minute),
+		step:  step,
+	}, true
+}
+
+func overlapsWindow(start, end time.Time, windows []timeWindow) bool {
+	for _, window := range windows {
+		if start.Before(window.end) && end.After(window.start) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlapsBooked(slot model.TimeSlot, booked []model.Consultation) bool {
+	for _, consultation := range booked {
+		bookedEnd := consultation.StartTime.Add(time.Duration(consultation.Duration) * time.Minute)
+		if slot.Start.Before(bookedEnd) && slot.End.After(consultation.StartTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mengubah string HH:MM menjadi jumlah menit sejak tengah malam
+func parseClock(value string) (int, error) {
+	t, err := time.Parse(clockLayout, value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
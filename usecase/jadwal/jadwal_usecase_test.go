@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"calmind/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// In-memory implementation of the jadwal repository for testing.
+type InMemoryJadwalRepo struct {
+	Schedules     []model.DoctorSchedule
+	Exceptions    []model.DoctorScheduleException
+	Consultations []model.Consultation
+}
+
+func (repo *InMemoryJadwalRepo) GetSchedulesByDoctor(doctorID int) ([]model.DoctorSchedule, error) {
+	var schedules []model.DoctorSchedule
+	for _, schedule := range repo.Schedules {
+		if schedule.DoctorID == doctorID {
+			schedules = append(schedules, schedule)
+		}
+	}
+	return schedules, nil
+}
+
+func (repo *InMemoryJadwalRepo) GetScheduleByID(doctorID, id int) (*model.DoctorSchedule, error) {
+	for _, schedule := range repo.Schedules {
+		if schedule.DoctorID == doctorID && schedule.ID == id {
+			return &schedule, nil
+		}
+	}
+	return nil, ErrInvalidSchedule
+}
+
+func (repo *InMemoryJadwalRepo) CreateSchedule(schedule *model.DoctorSchedule) error {
+	schedule.ID = len(repo.Schedules) + 1
+	repo.Schedules = append(repo.Schedules, *schedule)
+	return nil
+}
+
+func (repo *InMemoryJadwalRepo) UpdateSchedule(schedule *model.DoctorSchedule) error {
+	return nil
+}
+
+func (repo *InMemoryJadwalRepo) DeleteSchedule(doctorID, id int) error {
+	return nil
+}
+
+func (repo *InMemoryJadwalRepo) GetExceptionsByDoctor(doctorID int, fromDate, toDate string) ([]model.DoctorScheduleException, error) {
+	var exceptions []model.DoctorScheduleException
+	for _, exception := range repo.Exceptions {
+		if exception.DoctorID == doctorID && exception.Date >= fromDate && exception.Date <= toDate {
+			exceptions = append(exceptions, exception)
+		}
+	}
+	return exceptions, nil
+}
+
+func (repo *InMemoryJadwalRepo) CreateException(exception *model.DoctorScheduleException) error {
+	exception.ID = len(repo.Exceptions) + 1
+	repo.Exceptions = append(repo.Exceptions, *exception)
+	return nil
+}
+
+func (repo *InMemoryJadwalRepo) DeleteException(doctorID, id int) error {
+	return nil
+}
+
+func (repo *InMemoryJadwalRepo) GetBookedConsultations(doctorID int, from, to time.Time) ([]model.Consultation, error) {
+	var booked []model.Consultation
+	for _, consultation := range repo.Consultations {
+		if consultation.DoctorID == doctorID && consultation.StartTime.Before(to) && !consultation.StartTime.Before(from) {
+			booked = append(booked, consultation)
+		}
+	}
+	return booked, nil
+}
+
+// Senin, 7 Januari 2030
+var monday = time.Date(2030, 1, 7, 0, 0, 0, 0, time.Local)
+
+func at(day time.Time, hour, minute int) time.Time {
+	return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+func newTestUsecase(repo *InMemoryJadwalRepo, now time.Time) *JadwalUsecaseImpl {
+	return &JadwalUsecaseImpl{Repo: repo, Now: func() time.Time { return now }}
+}
+
+func slotStarts(slots []model.TimeSlot) []string {
+	starts := []string{}
+	for _, slot := range slots {
+		starts = append(starts, slot.Start.Format("2006-01-02 15:04"))
+	}
+	return starts
+}
+
+func TestGetAvailableSlots_WeeklySchedule(t *testing.T) {
+	repo := &InMemoryJadwalRepo{Schedules: []model.DoctorSchedule{
+		{ID: 1, DoctorID: 1, DayOfWeek: 1, StartTime: "09:00", EndTime: "11:00", SlotDuration: 60},
+		{ID: 2, DoctorID: 1, DayOfWeek: 2, StartTime: "13:00", EndTime: "14:30", SlotDuration: 30},
+	}}
+	u := newTestUsecase(repo, monday.AddDate(0, 0, -1))
+
+	slots, err := u.GetAvailableSlots(1, monday, monday.AddDate(0, 0, 1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"2030-01-07 09:00", "2030-01-07 10:00",
+		"2030-01-08 13:00", "2030-01-08 13:30", "2030-01-08 14:00",
+	}, slotStarts(slots))
+}
+
+func TestGetAvailableSlots_ExceptionOverrides(t *testing.T) {
+	repo := &InMemoryJadwalRepo{
+		Schedules: []model.DoctorSchedule{
+			{ID: 1, DoctorID: 1, DayOfWeek: 1, StartTime: "09:00", EndTime: "12:00", SlotDuration: 60},
+			{ID: 2, DoctorID: 1, DayOfWeek: 2, StartTime: "09:00", EndTime: "11:00", SlotDuration: 60},
+		},
+		Exceptions: []model.DoctorScheduleException{
+			// Senin: jam 10-11 diblokir, ditambah jam tambahan malam
+			{DoctorID: 1, Date: "2030-01-07", IsAvailable: false, StartTime: "10:00", EndTime: "11:00"},
+			{DoctorID: 1, Date: "2030-01-07", IsAvailable: true, StartTime: "19:00", EndTime: "20:00", SlotDuration: 60},
+			// Selasa: libur sepanjang hari
+			{DoctorID: 1, Date: "2030-01-08", IsAvailable: false},
+		},
+	}
+	u := newTestUsecase(repo, monday.AddDate(0, 0, -1))
+
+	slots, err := u.GetAvailableSlots(1, monday, monday.AddDate(0, 0, 1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2030-01-07 09:00", "2030-01-07 11:00", "2030-01-07 19:00"}, slotStarts(slots))
+}
+
+func TestGetAvailableSlots_SkipsBookedAndPastSlots(t *testing.T) {
+	repo := &InMemoryJadwalRepo{
+		Schedules: []model.DoctorSchedule{
+			{ID: 1, DoctorID: 1, DayOfWeek: 1, StartTime: "08:00", EndTime: "13:00", SlotDuration: 60},
+		},
+		Consultations: []model.Consultation{
+			// Konsultasi 90 menit mulai 10:30 menutup slot 10:00, 11:00, dan tidak menyentuh 12:00
+			{DoctorID: 1, StartTime: at(monday, 10, 30), Duration: 90},
+			{DoctorID: 2, StartTime: at(monday, 12, 0), Duration: 60},
+		},
+	}
+	// Sekarang 08:30, slot 08:00 sudah lewat
+	u := newTestUsecase(repo, at(monday, 8, 30))
+
+	slots, err := u.GetAvailableSlots(1, monday, monday)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2030-01-07 09:00", "2030-01-07 12:00"}, slotStarts(slots))
+}
+
+func TestGetAvailableSlots_InvalidRange(t *testing.T) {
+	u := newTestUsecase(&InMemoryJadwalRepo{}, monday)
+
+	_, err := u.GetAvailableSlots(1, monday, monday.AddDate(0, 0, -1))
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+
+	_, err = u.GetAvailableSlots(1, monday, monday.AddDate(0, 0, maxSlotRangeDay+1))
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+}
+
+func TestFindSlot(t *testing.T) {
+	repo := &InMemoryJadwalRepo{
+		Schedules: []model.DoctorSchedule{
+			{ID: 1, DoctorID: 1, DayOfWeek: 1, StartTime: "09:00", EndTime: "12:00", SlotDuration: 60},
+		},
+		Consultations: []model.Consultation{{DoctorID: 1, StartTime: at(monday, 10, 0), Duration: 60}},
+	}
+	u := newTestUsecase(repo, at(monday, 9, 30))
+
+	slot, err := u.FindSlot(1, at(monday, 11, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, at(monday, 12, 0), slot.End)
+
+	// Sudah dipesan, sudah lewat, dan tidak sejajar dengan awal slot
+	for _, start := range []time.Time{at(monday, 10, 0), at(monday, 9, 0), at(monday, 11, 15)} {
+		_, err = u.FindSlot(1, start)
+		assert.ErrorIs(t, err, ErrSlotUnavailable, start.Format("15:04"))
+	}
+}
+
+func TestAddSchedule_RejectsOverlap(t *testing.T) {
+	repo := &InMemoryJadwalRepo{Schedules: []model.DoctorSchedule{
+		{ID: 1, DoctorID: 1, DayOfWeek: 1, StartTime: "09:00", EndTime: "12:00", SlotDuration: 60},
+	}}
+	u := newTestUsecase(repo, monday)
+
+	err := u.AddSchedule(1, &model.DoctorSchedule{DayOfWeek: 1, StartTime: "11:00", EndTime: "13:00"})
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+
+	err = u.AddSchedule(1, &model.DoctorSchedule{DayOfWeek: 1, StartTime: "12:00", EndTime: "13:00"})
+	assert.NoError(t, err)
+}
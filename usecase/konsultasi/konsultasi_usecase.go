@@ -1,12 +1,19 @@
 package usecase
 
 import (
+	"calmind/helper"
 	"calmind/model"
 	repository "calmind/repository/konsultasi"
+	"calmind/service"
+	"calmind/service/lifecycle"
+	usecase_jadwal "calmind/usecase/jadwal"
+	usecase_scheduler "calmind/usecase/scheduler"
 	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/midtrans/midtrans-go"
@@ -15,54 +22,90 @@ import (
 )
 
 type ConsultationUsecase interface {
-	CreateConsultation(userID, doctorID int, title, description, email string) (string, *model.Consultation, error)
-	SearchConsultationsByName(doctorID int, searchName string) ([]model.Consultation, error)
+	CreateConsultation(userID, doctorID int, title, description, email string, startTime time.Time) (string, *model.Consultation, error)
+	SearchConsultationsByName(doctorID int, searchName string, opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error)
 	GetConsultationByID(consultationID int) (*model.Consultation, error)
 	ViewConsultationDetails(doctorID, consultationID int) (*model.Consultation, error)
 	AddRecommendation(doctorID, consultationID int, recommendation string) error
-	GetUserConsultations(userID int) ([]model.Consultation, error)
-	GetPendingConsultations() ([]model.Consultation, error)
+	GetUserConsultations(userID int, opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error)
+	GetPendingConsultations(opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error)
 	CreateMidtransPayment(consultationID int, amount float64, email string) (string, error)
 	VerifyPayment(consultationID int) (string, error)
 	MarkExpiredConsultations() error
-	ApprovePaymentAndConsultation(consultationID int, paymentStatus string) error
-	GetApprovedConsultations() ([]model.Consultation, error)
-	GetAllStatusConsultations() ([]model.Consultation, error)
-	UpdatePaymentStatus(orderID, transactionStatus string) error
-	GetAllConsultationsForDoctor(doctorID int) ([]model.Consultation, error)
+	ApprovePaymentAndConsultation(adminID, consultationID int, paymentStatus string) error
+	GetStatusHistory(consultationID int) ([]model.ConsultationStatusHistory, error)
+	StartSession(consultationID int, actorRole string, actorID int) error
+	TransitionStatus(consultation *model.Consultation, actorRole string, actorID int, note string, steps ...string) error
+	GetApprovedConsultations(opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error)
+	GetAllStatusConsultations(opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error)
+	HandleMidtransNotification(notification model.MidtransNotification, rawPayload string) error
+	GetAllConsultationsForDoctor(doctorID int, opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error)
 }
 
+var ErrSlotUnavailable = usecase_jadwal.ErrSlotUnavailable
+
+var (
+	ErrInvalidSignature      = errors.New("signature notifikasi tidak valid")
+	ErrAmountMismatch        = errors.New("gross_amount tidak sesuai dengan nominal pemesanan")
+	ErrDuplicateNotification = errors.New("notifikasi sudah pernah diproses")
+	ErrOrderNotFound         = errors.New("order_id tidak ditemukan")
+	ErrDoctorNotBookable     = errors.New("dokter belum terverifikasi dan belum bisa dipesan")
+	ErrEmptyRecommendation   = errors.New("rekomendasi tidak boleh kosong")
+	ErrConsultationNotFound  = errors.New("konsultasi tidak ditemukan")
+)
+
+const (
+	PaymentTimeout   = 60 * time.Minute // Batas waktu pembayaran sebelum slot dilepas
+	ReminderLeadTime = 30 * time.Minute // Pengingat dikirim sebelum konsultasi dimulai
+)
+
 type ConsultationUsecaseImpl struct {
-	Repo *repository.ConsultationRepositoryImpl
+	Repo            repository.ConsultationRepository
+	JadwalUsecase   usecase_jadwal.JadwalUsecase
+	MidtransService service.MidtransService
+	Scheduler       usecase_scheduler.SchedulerUsecase
 }
 
-func NewConsultationUsecaseImpl(repo *repository.ConsultationRepositoryImpl) *ConsultationUsecaseImpl {
-	return &ConsultationUsecaseImpl{Repo: repo}
+func NewConsultationUsecaseImpl(repo repository.ConsultationRepository, jadwalUsecase usecase_jadwal.JadwalUsecase, midtransService service.MidtransService, scheduler usecase_scheduler.SchedulerUsecase) *ConsultationUsecaseImpl {
+	return &ConsultationUsecaseImpl{Repo: repo, JadwalUsecase: jadwalUsecase, MidtransService: midtransService, Scheduler: scheduler}
 }
 
 func (uc *ConsultationUsecaseImpl) MarkExpiredConsultations() error {
-	consultations, err := uc.Repo.GetActiveConsultations() // Ambil semua konsultasi dengan status approved
+	consultations, err := uc.Repo.GetActiveConsultations() // Ambil semua konsultasi dengan status approved atau in_session
 	if err != nil {
 		return fmt.Errorf("failed to fetch active consultations: %v", err)
 	}
 
 	now := time.Now()
-	for _, consultation := range consultations {
+	for i := range consultations {
+		consultation := &consultations[i]
 		endTime := consultation.StartTime.Add(time.Duration(consultation.Duration) * time.Minute)
 		if now.After(endTime) {
-			consultation.Status = "expired" // Tandai konsultasi sebagai expired
-			err := uc.Repo.UpdateConsultation(&consultation)
-			if err != nil {
-				log.Printf("Failed to update consultation %d to expired: %v", consultation.ID, err)
-			} else {
-				log.Printf("Consultation %d marked as expired", consultation.ID)
+			if err := uc.finishConsultation(consultation); err != nil {
+				log.Printf("Failed to close consultation %d: %v", consultation.ID, err)
 			}
 		}
 	}
 	return nil
 }
 
-func (uc *ConsultationUsecaseImpl) ApprovePaymentAndConsultation(consultationID int, paymentStatus string) error {
+// Menutup konsultasi yang slotnya sudah lewat: sesi yang berjalan selesai, yang belum dimulai kedaluwarsa
+func (uc *ConsultationUsecaseImpl) finishConsultation(consultation *model.Consultation) error {
+	target := lifecycle.StatusExpired
+	note := "Slot konsultasi telah berakhir"
+	if consultation.Status == lifecycle.StatusInSession {
+		target = lifecycle.StatusCompleted
+		note = "Sesi konsultasi selesai"
+	}
+
+	if err := uc.transition(consultation, lifecycle.ActorSystem, 0, note, target); err != nil {
+		return err
+	}
+	log.Printf("Consultation %d marked as %s", consultation.ID, target)
+	return nil
+}
+
+func (uc *ConsultationUsecaseImpl) ApprovePaymentAndConsultation(adminID, consultationID int, paymentStatus string) error {
 	// Validasi pembayaran
 	if paymentStatus != "paid" {
 		return errors.New("invalid payment status, only 'paid' is allowed")
@@ -74,36 +117,229 @@ func (uc *ConsultationUsecaseImpl) ApprovePaymentAndConsultation(consultationID
 		return fmt.Errorf("consultation not found: %w", err)
 	}
 
-	// Perbarui status konsultasi dan pembayaran
-	consultation.Status = "approved"
-	consultation.IsApproved = true
-	consultation.StartTime = time.Now()
-	// Simpan perubahan ke database
-	err = uc.Repo.UpdateConsultation(consultation)
-	if err != nil {
+	// Konsultasi yang belum tercatat lunas dikonfirmasi pembayarannya oleh admin terlebih dahulu
+	// StartTime tetap mengikuti slot yang dipilih user saat membuat konsultasi
+	steps := []string{lifecycle.StatusApproved}
+	if lifecycle.Normalize(consultation.Status) == lifecycle.StatusPendingPayment {
+		steps = []string{lifecycle.StatusPaid, lifecycle.StatusApproved}
+	}
+
+	if err := uc.transition(consultation, lifecycle.ActorAdmin, adminID, "Disetujui admin", steps...); err != nil {
 		return fmt.Errorf("failed to update consultation: %w", err)
 	}
 
-	go uc.StartTimerForConsultation(consultation)
+	uc.scheduleSessionJobs(consultation)
 	return nil
 }
 
-func (uc *ConsultationUsecaseImpl) StartTimerForConsultation(consultation *model.Consultation) {
-	duration := time.Duration(consultation.Duration) * time.Minute
-	time.Sleep(duration) // Tunggu hingga durasi selesai
+// -- job terjadwal --
+
+type consultationJobPayload struct {
+	ConsultationID int `json:"consultation_id"`
+}
+
+// Mendaftarkan handler job konsultasi ke scheduler
+func (uc *ConsultationUsecaseImpl) RegisterJobs() {
+	uc.Scheduler.RegisterHandler(usecase_scheduler.JobConsultationExpire, uc.handleExpireJob)
+	uc.Scheduler.RegisterHandler(usecase_scheduler.JobConsultationReminder, uc.handleReminderJob)
+	uc.Scheduler.RegisterHandler(usecase_scheduler.JobConsultationDoctorReminder, uc.handleDoctorReminderJob)
+	uc.Scheduler.RegisterHandler(usecase_scheduler.JobPaymentTimeout, uc.handlePaymentTimeoutJob)
+}
 
-	// Setelah timer selesai, perbarui status konsultasi menjadi expired
-	consultation.Status = "expired"
-	err := uc.Repo.UpdateConsultation(consultation)
+// Menjadwalkan ulang job yang mungkin terlewat saat server mati atau dibuat sebelum scheduler ada
+func (uc *ConsultationUsecaseImpl) CatchUpScheduledJobs() error {
+	if err := uc.MarkExpiredConsultations(); err != nil {
+		return err
+	}
+
+	consultations, err := uc.Repo.GetConsultationsByStatus([]string{lifecycle.StatusPendingPayment, lifecycle.StatusApproved, lifecycle.StatusInSession})
+	if err != nil {
+		return fmt.Errorf("failed to fetch consultations: %v", err)
+	}
+
+	for i := range consultations {
+		consultation := &consultations[i]
+		if consultation.Status == lifecycle.StatusPendingPayment {
+			uc.schedulePaymentTimeout(consultation)
+			continue
+		}
+		uc.scheduleSessionJobs(consultation)
+	}
+	return nil
+}
+
+func (uc *ConsultationUsecaseImpl) schedulePaymentTimeout(consultation *model.Consultation) {
+	err := uc.Scheduler.Schedule(usecase_scheduler.JobPaymentTimeout,
+		usecase_scheduler.JobKey(usecase_scheduler.JobPaymentTimeout, consultation.ID),
+		consultation.CreatedAt.Add(PaymentTimeout),
+		consultationJobPayload{ConsultationID: consultation.ID})
 	if err != nil {
-		log.Printf("Failed to update consultation %d to expired: %v", consultation.ID, err)
-	} else {
-		log.Printf("Consultation %d has expired", consultation.ID)
+		log.Printf("Failed to schedule payment timeout for consultation %d: %v", consultation.ID, err)
 	}
 }
 
+// Menjadwalkan pengingat dan penutupan sesi untuk konsultasi yang sudah disetujui
+func (uc *ConsultationUsecaseImpl) scheduleSessionJobs(consultation *model.Consultation) {
+	payload := consultationJobPayload{ConsultationID: consultation.ID}
+	endTime := consultation.StartTime.Add(time.Duration(consultation.Duration) * time.Minute)
+
+	err := uc.Scheduler.Schedule(usecase_scheduler.JobConsultationExpire,
+		usecase_scheduler.JobKey(usecase_scheduler.JobConsultationExpire, consultation.ID), endTime, payload)
+	if err != nil {
+		log.Printf("Failed to schedule expiry for consultation %d: %v", consultation.ID, err)
+	}
+
+	reminderAt := consultation.StartTime.Add(-ReminderLeadTime)
+	if reminderAt.After(time.Now()) {
+		for _, jobType := range []string{usecase_scheduler.JobConsultationReminder, usecase_scheduler.JobConsultationDoctorReminder} {
+			err := uc.Scheduler.Schedule(jobType, usecase_scheduler.JobKey(jobType, consultation.ID), reminderAt, payload)
+			if err != nil {
+				log.Printf("Failed to schedule %s for consultation %d: %v", jobType, consultation.ID, err)
+			}
+		}
+	}
+}
+
+func (uc *ConsultationUsecaseImpl) loadJobConsultation(job *model.ScheduledJob) (*model.Consultation, error) {
+	var payload consultationJobPayload
+	if err := usecase_scheduler.DecodePayload(job, &payload); err != nil {
+		return nil, err
+	}
+	return uc.Repo.GetConsultationByID(payload.ConsultationID)
+}
+
+func (uc *ConsultationUsecaseImpl) handleExpireJob(job *model.ScheduledJob) error {
+	consultation, err := uc.loadJobConsultation(job)
+	if err != nil {
+		return err
+	}
+
+	// Status bisa sudah berubah (dibatalkan, selesai) sejak job dijadwalkan
+	if consultation.Status != lifecycle.StatusApproved && consultation.Status != lifecycle.StatusInSession {
+		return nil
+	}
+	return uc.finishConsultation(consultation)
+}
+
+func (uc *ConsultationUsecaseImpl) handlePaymentTimeoutJob(job *model.ScheduledJob) error {
+	consultation, err := uc.loadJobConsultation(job)
+	if err != nil {
+		return err
+	}
+
+	if lifecycle.Normalize(consultation.Status) != lifecycle.StatusPendingPayment {
+		return nil
+	}
+	return uc.transition(consultation, lifecycle.ActorSystem, 0, "Batas waktu pembayaran habis", lifecycle.StatusExpired)
+}
+
+// Pengingat user dan dokter adalah job terpisah, jadi retry karena salah satu email gagal
+// tidak mengirim ulang email ke penerima lainnya
+func (uc *ConsultationUsecaseImpl) handleReminderJob(job *model.ScheduledJob) error {
+	return uc.sendReminder(job, func(consultation *model.Consultation) (string, string) {
+		return consultation.User.Email, "Dokter: " + consultation.Doctor.Username
+	})
+}
+
+func (uc *ConsultationUsecaseImpl) handleDoctorReminderJob(job *model.ScheduledJob) error {
+	return uc.sendReminder(job, func(consultation *model.Consultation) (string, string) {
+		return consultation.Doctor.Email, "Pasien: " + consultation.User.Username
+	})
+}
+
+// recipient mengembalikan email penerima dan baris keterangan pihak lawan konsultasi
+func (uc *ConsultationUsecaseImpl) sendReminder(job *model.ScheduledJob, recipient func(consultation *model.Consultation) (string, string)) error {
+	consultation, err := uc.loadJobConsultation(job)
+	if err != nil {
+		return err
+	}
+
+	if consultation.Status != lifecycle.StatusApproved {
+		return nil
+	}
+
+	startTime := consultation.StartTime.Format("02 Jan 2006 15:04")
+	message := fmt.Sprintf("Konsultasi \"%s\" akan dimulai pada %s (durasi %d menit).",
+		consultation.Title, startTime, consultation.Duration)
+
+	email, counterpart := recipient(consultation)
+	return helper.SendNotificationEmail(email, "Pengingat Konsultasi - Calmind", "Pengingat Konsultasi", message+"\n"+counterpart)
+}
+
+// Memindahkan status konsultasi dari usecase lain (misalnya pembatalan dan refund)
+func (uc *ConsultationUsecaseImpl) TransitionStatus(consultation *model.Consultation, actorRole string, actorID int, note string, steps ...string) error {
+	return uc.transition(consultation, actorRole, actorID, note, steps...)
+}
+
+// Memindahkan status konsultasi melalui satu atau beberapa langkah dan mencatat riwayatnya
+func (uc *ConsultationUsecaseImpl) transition(consultation *model.Consultation, actorRole string, actorID int, note string, steps ...string) error {
+	fromStatus := consultation.Status
+	histories, err := uc.applySteps(consultation, actorRole, actorID, note, steps...)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.Repo.SaveStatusTransition(consultation, fromStatus, histories); err != nil {
+		consultation.Status = fromStatus
+		return err
+	}
+	return nil
+}
+
+// Memvalidasi setiap langkah terhadap state machine dan menyiapkan catatan riwayat
+func (uc *ConsultationUsecaseImpl) applySteps(consultation *model.Consultation, actorRole string, actorID int, note string, steps ...string) ([]model.ConsultationStatusHistory, error) {
+	current := consultation.Status
+	var histories []model.ConsultationStatusHistory
+	for _, next := range steps {
+		if err := lifecycle.Transition(current, next); err != nil {
+			return nil, err
+		}
+		histories = append(histories, model.ConsultationStatusHistory{
+			FromStatus: lifecycle.Normalize(current),
+			ToStatus:   next,
+			ActorRole:  actorRole,
+			ActorID:    actorID,
+			Note:       note,
+		})
+		current = next
+	}
+
+	consultation.Status = current
+	switch current {
+	case lifecycle.StatusPaid:
+		consultation.PaymentStatus = "paid"
+	case lifecycle.StatusApproved:
+		consultation.PaymentStatus = "paid"
+		consultation.IsApproved = true
+	}
+	return histories, nil
+}
+
+// Menandai konsultasi approved sebagai in_session saat chat dimulai
+func (uc *ConsultationUsecaseImpl) StartSession(consultationID int, actorRole string, actorID int) error {
+	consultation, err := uc.Repo.GetConsultationByID(consultationID)
+	if err != nil {
+		return fmt.Errorf("consultation not found: %w", err)
+	}
+	if consultation.Status != lifecycle.StatusApproved {
+		return nil
+	}
+
+	err = uc.transition(consultation, actorRole, actorID, "Sesi chat dimulai", lifecycle.StatusInSession)
+	if errors.Is(err, repository.ErrStatusConflict) {
+		// Peserta lain sudah memulai sesi lebih dulu
+		return nil
+	}
+	return err
+}
+
+// Mendapatkan riwayat perubahan status konsultasi
+func (uc *ConsultationUsecaseImpl) GetStatusHistory(consultationID int) ([]model.ConsultationStatusHistory, error) {
+	return uc.Repo.GetStatusHistory(consultationID)
+}
+
 // Membuat konsultasi baru
-func (uc *ConsultationUsecaseImpl) CreateConsultation(userID, doctorID int, title, description, email string) (string, *model.Consultation, error) {
+func (uc *ConsultationUsecaseImpl) CreateConsultation(userID, doctorID int, title, description, email string, startTime time.Time) (string, *model.Consultation, error) {
 	// Validasi user dan dokter
 	err := uc.Repo.ValidateUserAndDoctor(userID, doctorID)
 	if err != nil {
@@ -115,23 +351,38 @@ func (uc *ConsultationUsecaseImpl) CreateConsultation(userID, doctorID int, titl
 		return "", nil, fmt.Errorf("doctor not found: %w", err)
 	}
 
+	if doctor.CredentialStatus != model.CredentialStatusApproved {
+		return "", nil, ErrDoctorNotBookable
+	}
+
 	if doctor.Price <= 0 {
 		return "", nil, errors.New("doctor price is invalid")
 	}
 
+	// Pastikan waktu yang dipilih adalah slot terbuka pada jadwal dokter
+	slot, err := uc.JadwalUsecase.FindSlot(doctorID, startTime)
+	if err != nil {
+		return "", nil, err
+	}
+
 	// Buat konsultasi tanpa order_id
 	consultation := &model.Consultation{
 		UserID:      userID,
 		DoctorID:    doctorID,
 		Title:       title,
 		Description: description,
-		Status:      "pending",
-		StartTime:   time.Now(),
+		Status:      lifecycle.StatusPendingPayment,
+		StartTime:   slot.Start,
+		Duration:    slot.Duration,
+		Amount:      doctor.Price,
 	}
 
 	// Simpan ke database untuk mendapatkan consultationID
 	consultationID, err := uc.Repo.CreateConsultation(consultation)
 	if err != nil {
+		if errors.Is(err, repository.ErrSlotAlreadyBooked) {
+			return "", nil, ErrSlotUnavailable
+		}
 		return "", nil, fmt.Errorf("failed to create consultation: %w", err)
 	}
 
@@ -145,11 +396,14 @@ func (uc *ConsultationUsecaseImpl) CreateConsultation(userID, doctorID int, titl
 	}
 
 	// Buat URL pembayaran menggunakan Midtrans
-	paymentURL, err := uc.CreateMidtransPayment(consultation.OrderID, doctor.Price, email)
+	paymentURL, err := uc.CreateMidtransPayment(consultation.OrderID, consultation.Amount, email)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to create midtrans payment: %w", err)
 	}
 
+	// Slot dilepas kembali jika pembayaran tidak selesai dalam batas waktu
+	uc.schedulePaymentTimeout(consultation)
+
 	return paymentURL, consultation, nil
 }
 
@@ -159,31 +413,23 @@ func (uc *ConsultationUsecaseImpl) GetConsultationByID(consultationID int) (*mod
 }
 
 // Mendapatkan konsultasi berdasarkan nama user
-func (uc *ConsultationUsecaseImpl) SearchConsultationsByName(doctorID int, searchName string) ([]model.Consultation, error) {
+func (uc *ConsultationUsecaseImpl) SearchConsultationsByName(doctorID int, searchName string, opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error) {
 	// Validasi input searchName kosong
 	if searchName == "" {
-		return nil, errors.New("search name cannot be empty")
+		return nil, model.PageInfo{}, errors.New("search name cannot be empty")
 	}
 
-	var consultations []model.Consultation
-	err := uc.Repo.FindConsultationsByDoctorAndName(doctorID, searchName, &consultations)
+	consultations, page, err := uc.Repo.FindConsultationsByDoctorAndName(doctorID, searchName, opts)
 	if err != nil {
 		log.Printf("Error in SearchConsultationsByName: %v", err)
-		return nil, err
+		return nil, model.PageInfo{}, err
 	}
-	return consultations, nil
+	return consultations, page, nil
 }
 
 // Mendapatkan daftar konsultasi untuk dokter
-func (uc *ConsultationUsecaseImpl) GetAllConsultationsForDoctor(doctorID int) ([]model.Consultation, error) {
-	consultations, err := uc.Repo.GetAllConsultationsForDoctor(doctorID)
-	if err != nil {
-		return nil, err
-	}
-	if len(consultations) == 0 {
-		fmt.Printf("No consultations found for doctor ID %d\n", doctorID) // Debugging tambahan
-	}
-	return consultations, nil
+func (uc *ConsultationUsecaseImpl) GetAllConsultationsForDoctor(doctorID int, opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error) {
+	return uc.Repo.GetAllConsultationsForDoctor(doctorID, opts)
 }
 
 // Mendapatkan detail konsultasi tertentu untuk dokter
@@ -193,6 +439,16 @@ func (uc *ConsultationUsecaseImpl) ViewConsultationDetails(doctorID, consultatio
 
 // Menambahkan rekomendasi untuk konsultasi
 func (uc *ConsultationUsecaseImpl) AddRecommendation(doctorID, consultationID int, recommendation string) error {
+	recommendation = strings.TrimSpace(recommendation)
+	if recommendation == "" {
+		return ErrEmptyRecommendation
+	}
+
+	// Hanya dokter pemilik konsultasi yang boleh menambahkan rekomendasi
+	if _, err := uc.Repo.GetConsultationDetails(consultationID, doctorID); err != nil {
+		return ErrConsultationNotFound
+	}
+
 	recommendationObj := &model.Rekomendasi{
 		ConsultationID: consultationID,
 		DoctorID:       doctorID,
@@ -202,57 +458,139 @@ func (uc *ConsultationUsecaseImpl) AddRecommendation(doctorID, consultationID in
 }
 
 // Mendapatkan daftar konsultasi untuk user tertentu
-func (uc *ConsultationUsecaseImpl) GetUserConsultations(userID int) ([]model.Consultation, error) {
-	return uc.Repo.GetConsultationsWithDoctors(userID)
+func (uc *ConsultationUsecaseImpl) GetUserConsultations(userID int, opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error) {
+	return uc.Repo.GetConsultationsWithDoctors(userID, opts)
 }
 
 // Mendapatkan daftar konsultasi yang menunggu persetujuan admin
-func (uc *ConsultationUsecaseImpl) GetPendingConsultations() ([]model.Consultation, error) {
-	return uc.Repo.GetPendingConsultations()
+func (uc *ConsultationUsecaseImpl) GetPendingConsultations(opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error) {
+	return uc.Repo.GetPendingConsultations(opts)
 }
-func (uc *ConsultationUsecaseImpl) GetApprovedConsultations() ([]model.Consultation, error) {
-	return uc.Repo.GetApprovedConsultations()
+func (uc *ConsultationUsecaseImpl) GetApprovedConsultations(opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error) {
+	return uc.Repo.GetApprovedConsultations(opts)
 }
-func (uc *ConsultationUsecaseImpl) GetAllStatusConsultations() ([]model.Consultation, error) {
-	return uc.Repo.GetAllStatusConsultations()
+func (uc *ConsultationUsecaseImpl) GetAllStatusConsultations(opts model.QueryOptions) ([]model.Consultation, model.PageInfo, error) {
+	return uc.Repo.GetAllStatusConsultations(opts)
 }
-func (uc *ConsultationUsecaseImpl) UpdatePaymentStatus(orderID, transactionStatus string) error {
-	// Ambil data konsultasi berdasarkan orderID
-	consultation, err := uc.Repo.GetConsultationByOrderID(orderID)
+
+// Memproses notifikasi pembayaran dari Midtrans setelah signature dan nominal diverifikasi
+func (uc *ConsultationUsecaseImpl) HandleMidtransNotification(notification model.MidtransNotification, rawPayload string) error {
+	event := &model.PaymentEvent{
+		TransactionID:     notification.TransactionID,
+		OrderID:           notification.OrderID,
+		TransactionStatus: notification.TransactionStatus,
+		StatusCode:        notification.StatusCode,
+		GrossAmount:       notification.GrossAmount,
+		FraudStatus:       notification.FraudStatus,
+		PaymentType:       notification.PaymentType,
+		RawPayload:        rawPayload,
+	}
+
+	if !uc.MidtransService.VerifySignature(notification.OrderID, notification.StatusCode, notification.GrossAmount, notification.SignatureKey) {
+		uc.logRejectedPaymentEvent(event, "rejected_signature", "signature_key tidak cocok")
+		return ErrInvalidSignature
+	}
+
+	if notification.TransactionID == "" || notification.TransactionStatus == "" {
+		uc.logRejectedPaymentEvent(event, "rejected_payload", "transaction_id atau transaction_status kosong")
+		return errors.New("invalid notification data")
+	}
+
+	consultation, err := uc.Repo.GetConsultationWithDoctorByOrderID(notification.OrderID)
 	if err != nil {
-		log.Printf("Consultation not found for order_id=%s: %v", orderID, err)
-		return fmt.Errorf("consultation not found: %w", err)
+		uc.logRejectedPaymentEvent(event, "rejected_order", "order_id tidak ditemukan")
+		return ErrOrderNotFound
 	}
 
-	// Validasi status lama agar tidak mengubah status paid menjadi pending atau failed
-	if consultation.PaymentStatus == "paid" {
-		log.Printf("Payment already completed for order_id=%s", orderID)
-		return nil
+	// Nominal dari Midtrans berbentuk "100000.00", pembayaran dibuat dengan int64(consultation.Amount)
+	// sehingga perubahan harga dokter setelah pemesanan tidak memengaruhi verifikasi
+	grossAmount, err := strconv.ParseFloat(notification.GrossAmount, 64)
+	if err != nil || int64(grossAmount) != int64(consultation.Amount) {
+		uc.logRejectedPaymentEvent(event, "rejected_amount",
+			fmt.Sprintf("gross_amount %s, nominal pemesanan %.2f", notification.GrossAmount, consultation.Amount))
+		return ErrAmountMismatch
 	}
 
-	// Update payment status sesuai status transaksi
-	switch transactionStatus {
-	case "settlement":
-		consultation.PaymentStatus = "paid"
-	case "pending":
-		consultation.PaymentStatus = "pending"
-	case "cancel", "deny", "expire":
-		consultation.PaymentStatus = "failed"
-	default:
-		log.Printf("Unknown transaction status: %s", transactionStatus)
-		return fmt.Errorf("unknown transaction status: %s", transactionStatus)
+	paymentStatus, known := mapTransactionStatus(notification.TransactionStatus, notification.FraudStatus)
+	if !known {
+		log.Printf("Unknown transaction status: %s", notification.TransactionStatus)
 	}
 
-	// Simpan perubahan ke database
-	if err := uc.Repo.UpdateConsultation(consultation); err != nil {
-		log.Printf("Failed to update consultation for order_id=%s: %v", orderID, err)
-		return fmt.Errorf("failed to update consultation: %w", err)
+	dedupKey := notification.TransactionID + ":" + notification.TransactionStatus
+	event.DedupKey = &dedupKey
+	event.Outcome = "processed"
+
+	fromStatus := consultation.Status
+	var histories []model.ConsultationStatusHistory
+	if consultation.PaymentStatus == "paid" && paymentStatus != "paid" {
+		event.Note = "pembayaran sudah lunas, status tidak diubah"
+	} else if paymentStatus != "" {
+		consultation.PaymentStatus = paymentStatus
+
+		// Pembayaran menggerakkan konsultasi yang masih menunggu pembayaran
+		target := ""
+		switch {
+		case paymentStatus == "paid":
+			target = lifecycle.StatusPaid
+		case paymentStatus == "failed" && notification.TransactionStatus == "expire":
+			target = lifecycle.StatusExpired
+		case paymentStatus == "failed":
+			target = lifecycle.StatusCancelled
+		}
+		if target != "" && lifecycle.CanTransition(consultation.Status, target) {
+			histories, err = uc.applySteps(consultation, lifecycle.ActorSystem, 0, "Notifikasi Midtrans: "+notification.TransactionStatus, target)
+			if err != nil {
+				return err
+			}
+		} else if target != "" {
+			event.Note = fmt.Sprintf("status konsultasi %s tidak diubah", consultation.Status)
+		}
+	}
+
+	duplicate, err := uc.Repo.ApplyPaymentEvent(event, consultation, fromStatus, histories)
+	if err != nil {
+		return fmt.Errorf("failed to apply payment event: %w", err)
+	}
+	if duplicate {
+		log.Printf("Duplicate notification for order_id=%s transaction_id=%s status=%s", notification.OrderID, notification.TransactionID, notification.TransactionStatus)
+		return ErrDuplicateNotification
 	}
 
-	log.Printf("Payment status updated successfully for order_id=%s", orderID)
+	log.Printf("Payment status updated successfully for order_id=%s", notification.OrderID)
 	return nil
 }
 
+func (uc *ConsultationUsecaseImpl) logRejectedPaymentEvent(event *model.PaymentEvent, outcome, note string) {
+	event.Outcome = outcome
+	event.Note = note
+	if err := uc.Repo.LogPaymentEvent(event); err != nil {
+		log.Printf("Failed to log payment event for order_id=%s: %v", event.OrderID, err)
+	}
+}
+
+// Memetakan transaction_status Midtrans ke payment_status konsultasi
+func mapTransactionStatus(transactionStatus, fraudStatus string) (string, bool) {
+	switch transactionStatus {
+	case "settlement":
+		return "paid", true
+	case "capture":
+		// Transaksi kartu kredit hanya dianggap lunas jika lolos fraud detection
+		if fraudStatus == "" || fraudStatus == "accept" {
+			return "paid", true
+		}
+		return "pending", true
+	case "pending":
+		return "pending", true
+	case "cancel", "deny", "expire", "failure":
+		return "failed", true
+	case "refund", "partial_refund":
+		// Status refund dicatat oleh usecase refund saat permintaan dibuat
+		return "", true
+	default:
+		return "", false
+	}
+}
+
 // Membuat pembayaran menggunakan Midtrans
 func (uc *ConsultationUsecaseImpl) CreateMidtransPayment(orderID string, amount float64, email string) (string, error) {
 	client := snap.Client{}
@@ -266,6 +604,10 @@ func (uc *ConsultationUsecaseImpl) CreateMidtransPayment(orderID string, amount
 		CustomerDetail: &midtrans.CustomerDetails{
 			Email: email,
 		},
+		Expiry: &snap.ExpiryDetails{
+			Unit:     "minute",
+			Duration: int64(PaymentTimeout / time.Minute),
+		},
 	}
 
 	snapTokenResp, err := client.CreateTransaction(snapReq)
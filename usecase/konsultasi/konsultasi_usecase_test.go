@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"calmind/model"
+	repository "calmind/repository/konsultasi"
+	usecase_jadwal "calmind/usecase/jadwal"
+	usecase_scheduler "calmind/usecase/scheduler"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// In-memory doctor lookup for booking tests, other repository methods are not used.
+type InMemoryConsultationRepo struct {
+	repository.ConsultationRepository
+	Doctors map[int]model.Doctor
+}
+
+func (repo *InMemoryConsultationRepo) ValidateUserAndDoctor(userID, doctorID int) error {
+	return nil
+}
+
+func (repo *InMemoryConsultationRepo) GetDoctorByID(doctorID int) (*model.Doctor, error) {
+	doctor, ok := repo.Doctors[doctorID]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &doctor, nil
+}
+
+// Jadwal tanpa slot terbuka, cukup untuk memastikan pemesanan lolos pemeriksaan dokter
+type noSlotJadwal struct {
+	usecase_jadwal.JadwalUsecase
+}
+
+func (noSlotJadwal) FindSlot(doctorID int, start time.Time) (*model.TimeSlot, error) {
+	return nil, usecase_jadwal.ErrSlotUnavailable
+}
+
+func TestCreateConsultation_OnlyApprovedDoctorsBookable(t *testing.T) {
+	repo := &InMemoryConsultationRepo{Doctors: map[int]model.Doctor{}}
+	statuses := []string{"", model.CredentialStatusUnsubmitted, model.CredentialStatusPending, model.CredentialStatusRejected, model.CredentialStatusApproved}
+	for i, status := range statuses {
+		repo.Doctors[i+1] = model.Doctor{ID: i + 1, Price: 100000, CredentialStatus: status}
+	}
+	uc := &ConsultationUsecaseImpl{Repo: repo, JadwalUsecase: noSlotJadwal{}}
+
+	for i, status := range statuses {
+		_, _, err := uc.CreateConsultation(1, i+1, "Konsultasi", "", "user@example.com", time.Now().Add(time.Hour))
+		if status == model.CredentialStatusApproved {
+			assert.ErrorIs(t, err, usecase_jadwal.ErrSlotUnavailable, status)
+		} else {
+			assert.ErrorIs(t, err, ErrDoctorNotBookable, status)
+		}
+	}
+}
+
+type recordingScheduler struct {
+	usecase_scheduler.SchedulerUsecase
+	Jobs map[string]string // Key -> jenis job
+}
+
+func (s *recordingScheduler) Schedule(jobType, uniqueKey string, runAt time.Time, payload interface{}) error {
+	s.Jobs[uniqueKey] = jobType
+	return nil
+}
+
+func TestScheduleSessionJobs_SeparateReminders(t *testing.T) {
+	scheduler := &recordingScheduler{Jobs: map[string]string{}}
+	uc := &ConsultationUsecaseImpl{Scheduler: scheduler}
+
+	// Pengingat user dan dokter tidak berbagi job, jadi retry salah satunya tidak mengirim ulang yang lain
+	uc.scheduleSessionJobs(&model.Consultation{ID: 7, StartTime: time.Now().Add(2 * time.Hour), Duration: 60})
+	assert.Equal(t, map[string]string{
+		"consultation_expire:7":          usecase_scheduler.JobConsultationExpire,
+		"consultation_reminder:7":        usecase_scheduler.JobConsultationReminder,
+		"consultation_doctor_reminder:7": usecase_scheduler.JobConsultationDoctorReminder,
+	}, scheduler.Jobs)
+}
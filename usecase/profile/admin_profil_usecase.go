@@ -1,12 +1,11 @@
 package usecase
 
 import (
-	"calmind/helper"
 	"calmind/model"
 	repository "calmind/repository/profile"
+	"calmind/service/upload"
+	"context"
 	"errors"
-	"path/filepath"
-	"strings"
 )
 
 type AdminProfileUseCase interface {
@@ -17,10 +16,11 @@ type AdminProfileUseCase interface {
 
 type AdminProfileUseCaseImpl struct {
 	AdminProfileRepo repository.AdminProfileRepository
+	Images           upload.ImagePipeline
 }
 
-func NewAdminProfileUseCase(repo repository.AdminProfileRepository) AdminProfileUseCase {
-	return &AdminProfileUseCaseImpl{AdminProfileRepo: repo}
+func NewAdminProfileUseCase(repo repository.AdminProfileRepository, images upload.ImagePipeline) AdminProfileUseCase {
+	return &AdminProfileUseCaseImpl{AdminProfileRepo: repo, Images: images}
 }
 
 func (u *AdminProfileUseCaseImpl) GetAdminProfile(adminID int) (*model.Admin, error) {
@@ -44,15 +44,9 @@ func (u *AdminProfileUseCaseImpl) DeleteAdminAvatar(adminID int) error {
 		return errors.New("admin not found")
 	}
 
-	if admin.Avatar != "" {
-		// Ekstrak public ID dari Cloudinary URL
-		parts := strings.Split(admin.Avatar, "/")
-		publicID := strings.TrimSuffix(parts[len(parts)-1], filepath.Ext(admin.Avatar))
-
-		// Hapus file dari Cloudinary
-		if err := helper.DeleteFileFromCloudinary(publicID); err != nil {
-			return errors.New("failed to delete avatar from Cloudinary")
-		}
+	// Gambar beserta thumbnail dihapus, URL dari penyimpanan lain cukup dikosongkan di database
+	if err := u.Images.Delete(context.Background(), admin.Avatar); err != nil {
+		return errors.New("failed to delete avatar file")
 	}
 
 	// Clear kolom avatar di database
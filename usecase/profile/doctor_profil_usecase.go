@@ -50,11 +50,28 @@ func (u *doctorProfileUseCaseImpl) UpdateDoctorProfile(doctorID int, doctor *mod
 		}
 	}
 
+	// Nomor STR yang berubah setelah disetujui harus direview ulang oleh admin
+	needsReview := false
+	if doctor.STRNumber != "" {
+		existing, err := u.DoctorProfileRepo.GetByID(doctorID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch doctor profile: %v", err)
+		}
+		needsReview = doctor.STRNumber != existing.STRNumber && existing.CredentialStatus == model.CredentialStatusApproved
+	}
+
 	// Memperbarui profil dokter lainnya
 	updatedDoctor, err := u.DoctorProfileRepo.UpdateByID(doctorID, doctor)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update doctor profile: %v", err)
 	}
+
+	if needsReview {
+		if err := u.DoctorProfileRepo.RequestCredentialReview(doctorID); err != nil {
+			return nil, fmt.Errorf("failed to reset credential status: %v", err)
+		}
+		updatedDoctor.CredentialStatus = model.CredentialStatusPending
+	}
 	return updatedDoctor, nil
 }
 
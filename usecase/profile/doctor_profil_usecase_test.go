@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"calmind/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// In-memory implementation of the doctor profile repository for testing.
+type InMemoryDoctorProfilRepo struct {
+	Doctor model.Doctor
+}
+
+func (repo *InMemoryDoctorProfilRepo) GetByID(id int) (*model.Doctor, error) {
+	doctor := repo.Doctor
+	return &doctor, nil
+}
+
+func (repo *InMemoryDoctorProfilRepo) UpdateByID(id int, doctor *model.Doctor) (*model.Doctor, error) {
+	if doctor.STRNumber != "" {
+		repo.Doctor.STRNumber = doctor.STRNumber
+	}
+	if doctor.About != "" {
+		repo.Doctor.About = doctor.About
+	}
+	return repo.GetByID(id)
+}
+
+func (repo *InMemoryDoctorProfilRepo) UpdateDoctorActiveStatus(id int, isActive bool) error {
+	return nil
+}
+
+func (repo *InMemoryDoctorProfilRepo) GetTagByID(id int) (*model.Tags, error) {
+	return nil, nil
+}
+
+func (repo *InMemoryDoctorProfilRepo) UpdateTagsByName(doctorID int, tagNames []string) error {
+	return nil
+}
+
+func (repo *InMemoryDoctorProfilRepo) GetDoctorTitleByID(doctorID int) (*model.Title, error) {
+	return nil, nil
+}
+
+func (repo *InMemoryDoctorProfilRepo) UpdateDoctorTitleByName(doctorID int, titleName string) error {
+	return nil
+}
+
+func (repo *InMemoryDoctorProfilRepo) RequestCredentialReview(doctorID int) error {
+	if repo.Doctor.CredentialStatus == model.CredentialStatusApproved {
+		repo.Doctor.CredentialStatus = model.CredentialStatusPending
+	}
+	return nil
+}
+
+func TestUpdateDoctorProfile_STRChangeResetsApproval(t *testing.T) {
+	repo := &InMemoryDoctorProfilRepo{Doctor: model.Doctor{ID: 1, STRNumber: "STR-1", CredentialStatus: model.CredentialStatusApproved}}
+	u := NewDoctorProfileUseCase(repo)
+
+	// Nomor STR sama dan perubahan field lain tidak mengubah status
+	_, err := u.UpdateDoctorProfile(1, &model.Doctor{STRNumber: "STR-1", About: "Psikolog klinis"})
+	assert.NoError(t, err)
+	assert.Equal(t, model.CredentialStatusApproved, repo.Doctor.CredentialStatus)
+
+	doctor, err := u.UpdateDoctorProfile(1, &model.Doctor{STRNumber: "STR-2"})
+	assert.NoError(t, err)
+	assert.Equal(t, model.CredentialStatusPending, doctor.CredentialStatus)
+	assert.Equal(t, model.CredentialStatusPending, repo.Doctor.CredentialStatus)
+	assert.Equal(t, "STR-2", repo.Doctor.STRNumber)
+}
+
+func TestUpdateDoctorProfile_STRChangeBeforeApproval(t *testing.T) {
+	repo := &InMemoryDoctorProfilRepo{Doctor: model.Doctor{ID: 1, STRNumber: "STR-1", CredentialStatus: model.CredentialStatusRejected}}
+	u := NewDoctorProfileUseCase(repo)
+
+	_, err := u.UpdateDoctorProfile(1, &model.Doctor{STRNumber: "STR-2"})
+	assert.NoError(t, err)
+	assert.Equal(t, model.CredentialStatusRejected, repo.Doctor.CredentialStatus)
+}
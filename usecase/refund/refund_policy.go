@@ -0,0 +1,36 @@
+package usecase
+
+import "time"
+
+// Kebijakan pengembalian dana berdasarkan jarak waktu pembatalan ke jadwal sesi:
+//   - minimal 24 jam sebelum sesi: 100%
+//   - minimal 2 jam sebelum sesi: 50%
+//   - kurang dari 2 jam: tidak ada pengembalian
+//
+// Pembatalan oleh admin (atas nama dokter) selalu dikembalikan penuh.
+const (
+	FullRefundBefore    = 24 * time.Hour
+	PartialRefundBefore = 2 * time.Hour
+	PartialRefundRate   = 50
+)
+
+func RefundPercentage(startTime, cancelledAt time.Time, byAdmin bool) int {
+	if byAdmin {
+		return 100
+	}
+
+	remaining := startTime.Sub(cancelledAt)
+	switch {
+	case remaining >= FullRefundBefore:
+		return 100
+	case remaining >= PartialRefundBefore:
+		return PartialRefundRate
+	default:
+		return 0
+	}
+}
+
+// Menghitung nominal refund dalam rupiah penuh (Midtrans tidak menerima pecahan)
+func RefundAmount(paidAmount float64, percentage int) int64 {
+	return int64(paidAmount) * int64(percentage) / 100
+}
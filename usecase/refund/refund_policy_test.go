@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefundPercentage(t *testing.T) {
+	start := time.Date(2024, 12, 10, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 100, RefundPercentage(start, start.Add(-48*time.Hour), false))
+	assert.Equal(t, 100, RefundPercentage(start, start.Add(-24*time.Hour), false), "Tepat 24 jam masih dikembalikan penuh")
+	assert.Equal(t, 50, RefundPercentage(start, start.Add(-23*time.Hour), false))
+	assert.Equal(t, 50, RefundPercentage(start, start.Add(-2*time.Hour), false))
+	assert.Equal(t, 0, RefundPercentage(start, start.Add(-90*time.Minute), false))
+	assert.Equal(t, 0, RefundPercentage(start, start.Add(time.Hour), false))
+}
+
+func TestRefundPercentage_Admin(t *testing.T) {
+	start := time.Date(2024, 12, 10, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 100, RefundPercentage(start, start.Add(-10*time.Minute), true))
+}
+
+func TestRefundAmount(t *testing.T) {
+	assert.Equal(t, int64(150000), RefundAmount(150000, 100))
+	assert.Equal(t, int64(75000), RefundAmount(150000, 50))
+	assert.Equal(t, int64(37500), RefundAmount(75001, 50))
+	assert.Equal(t, int64(0), RefundAmount(150000, 0))
+}
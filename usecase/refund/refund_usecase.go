@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"calmind/model"
+	repository "calmind/repository/refund"
+	"calmind/service"
+	"calmind/service/lifecycle"
+	usecase_scheduler "calmind/usecase/scheduler"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotOwner           = errors.New("anda tidak memiliki akses ke konsultasi ini")
+	ErrCannotCancel       = errors.New("konsultasi tidak dapat dibatalkan pada status ini")
+	ErrSessionStarted     = errors.New("konsultasi yang sudah dimulai tidak dapat dibatalkan")
+	ErrRefundNotRetryable = errors.New("hanya refund yang gagal yang dapat diproses ulang")
+)
+
+// Dipenuhi oleh ConsultationUsecaseImpl agar perubahan status tetap melewati state machine
+type ConsultationTransitioner interface {
+	TransitionStatus(consultation *model.Consultation, actorRole string, actorID int, note string, steps ...string) error
+}
+
+type RefundUsecase interface {
+	CancelByUser(userID, consultationID int, reason string) (*model.Refund, error)
+	CancelByAdmin(adminID, consultationID int, reason string) (*model.Refund, error)
+	RetryRefund(adminID, refundID int) (*model.Refund, error)
+	GetUserRefunds(userID int, opts model.QueryOptions) ([]model.Refund, model.PageInfo, error)
+	GetAllRefunds(opts model.QueryOptions) ([]model.Refund, model.PageInfo, error)
+}
+
+type RefundUsecaseImpl struct {
+	Repo            repository.RefundRepository
+	Consultations   ConsultationTransitioner
+	MidtransService service.MidtransService
+	Scheduler       usecase_scheduler.SchedulerUsecase
+	Now             func() time.Time
+}
+
+func NewRefundUsecase(repo repository.RefundRepository, consultations ConsultationTransitioner, midtransService service.MidtransService, scheduler usecase_scheduler.SchedulerUsecase) RefundUsecase {
+	return &RefundUsecaseImpl{
+		Repo:            repo,
+		Consultations:   consultations,
+		MidtransService: midtransService,
+		Scheduler:       scheduler,
+		Now:             time.Now,
+	}
+}
+
+// Pembatalan oleh user, hanya sebelum sesi dimulai
+func (u *RefundUsecaseImpl) CancelByUser(userID, consultationID int, reason string) (*model.Refund, error) {
+	consultation, err := u.Repo.GetConsultationByID(consultationID)
+	if err != nil {
+		return nil, fmt.Errorf("consultation not found: %w", err)
+	}
+	if consultation.UserID != userID {
+		return nil, ErrNotOwner
+	}
+	if !u.Now().Before(consultation.StartTime) {
+		return nil, ErrSessionStarted
+	}
+
+	return u.cancel(consultation, lifecycle.ActorUser, userID, reason)
+}
+
+// Pembatalan oleh admin atas nama dokter, dana selalu dikembalikan penuh
+func (u *RefundUsecaseImpl) CancelByAdmin(adminID, consultationID int, reason string) (*model.Refund, error) {
+	consultation, err := u.Repo.GetConsultationByID(consultationID)
+	if err != nil {
+		return nil, fmt.Errorf("consultation not found: %w", err)
+	}
+
+	return u.cancel(consultation, lifecycle.ActorAdmin, adminID, reason)
+}
+
+func (u *RefundUsecaseImpl) cancel(consultation *model.Consultation, actorRole string, actorID int, reason string) (*model.Refund, error) {
+	status := lifecycle.Normalize(consultation.Status)
+	if !lifecycle.CanTransition(status, lifecycle.StatusCancelled) {
+		return nil, ErrCannotCancel
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = "Dibatalkan oleh " + actorRole
+	}
+
+	// Hanya satu catatan refund per konsultasi, refund_key dipakai ulang agar aman diproses ulang
+	if _, err := u.Repo.GetRefundByConsultation(consultation.ID); err == nil {
+		return nil, ErrCannotCancel
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	now := u.Now()
+	refund := &model.Refund{
+		ConsultationID: consultation.ID,
+		OrderID:        consultation.OrderID,
+		RefundKey:      fmt.Sprintf("refund-%d", consultation.ID),
+		Reason:         reason,
+		RequestedBy:    actorRole,
+		RequestedByID:  actorID,
+		Status:         model.RefundStatusPending,
+	}
+
+	paid := status != lifecycle.StatusPendingPayment
+	if paid {
+		refund.Method = "refund"
+		// Nominal yang benar-benar ditagihkan saat pemesanan, bukan harga dokter saat ini
+		refund.PaidAmount = consultation.Amount
+		refund.Percentage = RefundPercentage(consultation.StartTime, now, actorRole == lifecycle.ActorAdmin)
+		refund.Amount = float64(RefundAmount(refund.PaidAmount, refund.Percentage))
+	} else {
+		refund.Method = "cancel"
+	}
+
+	// Status dibatalkan lebih dulu agar slot dokter langsung dilepas
+	if err := u.Consultations.TransitionStatus(consultation, actorRole, actorID, reason, lifecycle.StatusCancelled); err != nil {
+		return nil, err
+	}
+	u.cancelJobs(consultation.ID)
+
+	if err := u.Repo.CreateRefund(refund); err != nil {
+		return nil, fmt.Errorf("failed to create refund record: %v", err)
+	}
+
+	if !paid {
+		u.processCancel(refund)
+	} else {
+		u.processRefund(refund, consultation)
+	}
+	return refund, nil
+}
+
+// Membatalkan transaksi Midtrans yang belum dibayar
+func (u *RefundUsecaseImpl) processCancel(refund *model.Refund) {
+	err := u.MidtransService.CancelTransaction(refund.OrderID)
+	switch {
+	case err == nil, errors.Is(err, service.ErrMidtransTransactionNotFound):
+		refund.Status = model.RefundStatusSkipped
+	default:
+		log.Printf("Failed to cancel midtrans transaction %s: %v", refund.OrderID, err)
+		refund.Status = model.RefundStatusFailed
+		refund.ErrorMessage = err.Error()
+	}
+
+	if err := u.Repo.UpdateRefund(refund); err != nil {
+		log.Printf("Failed to update refund %d: %v", refund.ID, err)
+	}
+}
+
+// Mengirim permintaan refund ke Midtrans lalu menandai konsultasi sebagai refunded
+func (u *RefundUsecaseImpl) processRefund(refund *model.Refund, consultation *model.Consultation) {
+	if refund.Amount <= 0 {
+		refund.Status = model.RefundStatusSkipped
+	} else if err := u.MidtransService.RefundTransaction(refund.OrderID, refund.RefundKey, int64(refund.Amount), refund.Reason); err != nil {
+		log.Printf("Failed to refund midtrans transaction %s: %v", refund.OrderID, err)
+		refund.Status = model.RefundStatusFailed
+		refund.ErrorMessage = err.Error()
+	} else {
+		refund.Status = model.RefundStatusSucceeded
+		refund.ErrorMessage = ""
+
+		consultation.PaymentStatus = "refunded"
+		note := fmt.Sprintf("Refund %d%% (Rp%.0f)", refund.Percentage, refund.Amount)
+		if err := u.Consultations.TransitionStatus(consultation, lifecycle.ActorSystem, 0, note, lifecycle.StatusRefunded); err != nil {
+			log.Printf("Failed to mark consultation %d as refunded: %v", consultation.ID, err)
+		}
+	}
+
+	if err := u.Repo.UpdateRefund(refund); err != nil {
+		log.Printf("Failed to update refund %d: %v", refund.ID, err)
+	}
+}
+
+// Memproses ulang refund yang gagal di Midtrans
+func (u *RefundUsecaseImpl) RetryRefund(adminID, refundID int) (*model.Refund, error) {
+	refund, err := u.Repo.GetRefundByID(refundID)
+	if err != nil {
+		return nil, fmt.Errorf("refund not found: %w", err)
+	}
+	if refund.Status != model.RefundStatusFailed {
+		return nil, ErrRefundNotRetryable
+	}
+
+	log.Printf("Admin %d retrying refund %d", adminID, refund.ID)
+	if refund.Method == "cancel" {
+		u.processCancel(refund)
+		return refund, nil
+	}
+
+	consultation, err := u.Repo.GetConsultationByID(refund.ConsultationID)
+	if err != nil {
+		return nil, fmt.Errorf("consultation not found: %w", err)
+	}
+	u.processRefund(refund, consultation)
+	return refund, nil
+}
+
+func (u *RefundUsecaseImpl) cancelJobs(consultationID int) {
+	jobTypes := []string{
+		usecase_scheduler.JobPaymentTimeout,
+		usecase_scheduler.JobConsultationReminder,
+		usecase_scheduler.JobConsultationDoctorReminder,
+		usecase_scheduler.JobConsultationExpire,
+	}
+	for _, jobType := range jobTypes {
+		if err := u.Scheduler.Cancel(usecase_scheduler.JobKey(jobType, consultationID)); err != nil {
+			log.Printf("Failed to cancel job %s for consultation %d: %v", jobType, consultationID, err)
+		}
+	}
+}
+
+func (u *RefundUsecaseImpl) GetUserRefunds(userID int, opts model.QueryOptions) ([]model.Refund, model.PageInfo, error) {
+	return u.Repo.GetRefundsByUser(userID, opts)
+}
+
+func (u *RefundUsecaseImpl) GetAllRefunds(opts model.QueryOptions) ([]model.Refund, model.PageInfo, error) {
+	return u.Repo.GetAllRefunds(opts)
+}
@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"calmind/model"
+	repository "calmind/repository/review"
+	"calmind/service/lifecycle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// Panjang maksimal komentar dan balasan ulasan (rune)
+const maxReviewTextLength = 1000
+
+var (
+	ErrReviewNotFound       = errors.New("ulasan tidak ditemukan")
+	ErrConsultationNotFound = errors.New("konsultasi tidak ditemukan")
+	ErrNotReviewable        = errors.New("ulasan hanya dapat diberikan setelah konsultasi selesai")
+	ErrAlreadyReviewed      = errors.New("konsultasi ini sudah diulas")
+	ErrInvalidRating        = errors.New("rating harus antara 1 sampai 5")
+	ErrReviewTextTooLong    = errors.New("komentar atau balasan maksimal 1000 karakter")
+	ErrEmptyReply           = errors.New("balasan tidak boleh kosong")
+	ErrInvalidReviewStatus  = errors.New("status ulasan harus 'visible' atau 'hidden'")
+)
+
+// Ulasan dengan nama pemberi ulasan, data kontak user tidak ikut ditampilkan
+type ReviewDTO struct {
+	model.DoctorReview
+	Reviewer string `json:"reviewer"`
+}
+
+type ReviewUsecase interface {
+	CreateReview(userID, consultationID, rating int, comment string) (*ReviewDTO, error)
+	GetDoctorReviews(doctorID int, opts model.QueryOptions) ([]ReviewDTO, model.PageInfo, error)
+
+	// Dokter
+	GetReviewsForDoctor(doctorID int, opts model.QueryOptions) ([]ReviewDTO, model.PageInfo, error)
+	ReplyReview(doctorID, reviewID int, reply string) (*ReviewDTO, error)
+
+	// Admin
+	GetReviews(opts model.QueryOptions) ([]ReviewDTO, model.PageInfo, error)
+	ModerateReview(adminID, reviewID int, status, note string) (*ReviewDTO, error)
+}
+
+type ReviewUsecaseImpl struct {
+	Repo repository.ReviewRepository
+	Now  func() time.Time
+}
+
+func NewReviewUsecase(repo repository.ReviewRepository) ReviewUsecase {
+	return &ReviewUsecaseImpl{Repo: repo, Now: time.Now}
+}
+
+// Satu ulasan per konsultasi, hanya oleh user pemilik konsultasi yang sudah selesai atau kedaluwarsa setelah dibayar
+func (u *ReviewUsecaseImpl) CreateReview(userID, consultationID, rating int, comment string) (*ReviewDTO, error) {
+	if rating < 1 || rating > 5 {
+		return nil, ErrInvalidRating
+	}
+	comment = strings.TrimSpace(comment)
+	if utf8.RuneCountInString(comment) > maxReviewTextLength {
+		return nil, ErrReviewTextTooLong
+	}
+
+	consultation, err := u.Repo.GetConsultationByID(consultationID)
+	if err != nil || consultation.UserID != userID {
+		return nil, ErrConsultationNotFound
+	}
+	if !reviewable(consultation) {
+		return nil, ErrNotReviewable
+	}
+
+	if _, err := u.Repo.GetReviewByConsultation(consultationID); err == nil {
+		return nil, ErrAlreadyReviewed
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check review: %v", err)
+	}
+
+	review := &model.DoctorReview{
+		ConsultationID: consultationID,
+		UserID:         userID,
+		DoctorID:       consultation.DoctorID,
+		Rating:         rating,
+		Comment:        comment,
+		Status:         model.ReviewStatusVisible,
+	}
+	if err := u.Repo.CreateReview(review); errors.Is(err, repository.ErrDuplicateReview) {
+		return nil, ErrAlreadyReviewed
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to save review: %v", err)
+	}
+
+	return u.getReview(review.ID)
+}
+
+// Konsultasi expired juga mencakup pemesanan yang tidak pernah dibayar (batas waktu pembayaran habis),
+// jadi hanya yang sudah dibayar atau disetujui yang boleh memengaruhi rating dokter
+func reviewable(consultation *model.Consultation) bool {
+	switch consultation.Status {
+	case lifecycle.StatusCompleted:
+		return true
+	case lifecycle.StatusExpired:
+		return consultation.PaymentStatus == "paid" || consultation.IsApproved
+	}
+	return false
+}
+
+// Ulasan dokter yang tampil untuk user
+func (u *ReviewUsecaseImpl) GetDoctorReviews(doctorID int, opts model.QueryOptions) ([]ReviewDTO, model.PageInfo, error) {
+	delete(opts.Filters, "status")
+	reviews, page, err := u.Repo.GetReviewsByDoctor(doctorID, true, opts)
+	if err != nil {
+		return nil, model.PageInfo{}, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+
+	// Catatan moderasi hanya untuk admin
+	for i := range reviews {
+		reviews[i].ModeratedBy = nil
+		reviews[i].ModerationNote = ""
+	}
+	return toReviewDTOs(reviews), page, nil
+}
+
+// Semua ulasan untuk dokter tersebut, termasuk yang disembunyikan admin
+func (u *ReviewUsecaseImpl) GetReviewsForDoctor(doctorID int, opts model.QueryOptions) ([]ReviewDTO, model.PageInfo, error) {
+	reviews, page, err := u.Repo.GetReviewsByDoctor(doctorID, false, opts)
+	if err != nil {
+		return nil, model.PageInfo{}, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+	return toReviewDTOs(reviews), page, nil
+}
+
+// Dokter membalas atau memperbarui balasan ulasan miliknya
+func (u *ReviewUsecaseImpl) ReplyReview(doctorID, reviewID int, reply string) (*ReviewDTO, error) {
+	reply = strings.TrimSpace(reply)
+	if reply == "" {
+		return nil, ErrEmptyReply
+	}
+	if utf8.RuneCountInString(reply) > maxReviewTextLength {
+		return nil, ErrReviewTextTooLong
+	}
+
+	review, err := u.Repo.GetReviewByID(reviewID)
+	if err != nil || review.DoctorID != doctorID {
+		return nil, ErrReviewNotFound
+	}
+
+	now := u.Now()
+	review.Reply = reply
+	review.RepliedAt = &now
+	if err := u.Repo.UpdateReview(review); err != nil {
+		return nil, fmt.Errorf("failed to save reply: %v", err)
+	}
+
+	dto := toReviewDTO(*review)
+	return &dto, nil
+}
+
+func (u *ReviewUsecaseImpl) GetReviews(opts model.QueryOptions) ([]ReviewDTO, model.PageInfo, error) {
+	if status := opts.Filters["status"]; status != "" && !validReviewStatus(status) {
+		return nil, model.PageInfo{}, ErrInvalidReviewStatus
+	}
+
+	reviews, page, err := u.Repo.GetReviews(opts)
+	if err != nil {
+		return nil, model.PageInfo{}, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+	return toReviewDTOs(reviews), page, nil
+}
+
+// Admin menyembunyikan atau menampilkan kembali ulasan, rating dokter ikut dihitung ulang
+func (u *ReviewUsecaseImpl) ModerateReview(adminID, reviewID int, status, note string) (*ReviewDTO, error) {
+	if !validReviewStatus(status) {
+		return nil, ErrInvalidReviewStatus
+	}
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > maxReviewTextLength {
+		return nil, ErrReviewTextTooLong
+	}
+
+	review, err := u.Repo.GetReviewByID(reviewID)
+	if err != nil {
+		return nil, ErrReviewNotFound
+	}
+
+	review.Status = status
+	review.ModeratedBy = &adminID
+	review.ModerationNote = note
+	if err := u.Repo.UpdateReview(review); err != nil {
+		return nil, fmt.Errorf("failed to moderate review: %v", err)
+	}
+
+	dto := toReviewDTO(*review)
+	return &dto, nil
+}
+
+func (u *ReviewUsecaseImpl) getReview(id int) (*ReviewDTO, error) {
+	review, err := u.Repo.GetReviewByID(id)
+	if err != nil {
+		return nil, ErrReviewNotFound
+	}
+	dto := toReviewDTO(*review)
+	return &dto, nil
+}
+
+func validReviewStatus(status string) bool {
+	return status == model.ReviewStatusVisible || status == model.ReviewStatusHidden
+}
+
+func toReviewDTO(review model.DoctorReview) ReviewDTO {
+	return ReviewDTO{DoctorReview: review, Reviewer: review.User.Username}
+}
+
+func toReviewDTOs(reviews []model.DoctorReview) []ReviewDTO {
+	result := make([]ReviewDTO, 0, len(reviews))
+	for _, review := range reviews {
+		result = append(result, toReviewDTO(review))
+	}
+	return result
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"calmind/model"
+	repository "calmind/repository/review"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// In-memory implementation of the review repository for testing.
+type InMemoryReviewRepo struct {
+	Consultations []model.Consultation
+	Reviews       []model.DoctorReview
+}
+
+func (repo *InMemoryReviewRepo) GetConsultationByID(consultationID int) (*model.Consultation, error) {
+	for _, consultation := range repo.Consultations {
+		if consultation.ID == consultationID {
+			return &consultation, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (repo *InMemoryReviewRepo) CreateReview(review *model.DoctorReview) error {
+	for _, existing := range repo.Reviews {
+		if existing.ConsultationID == review.ConsultationID {
+			return repository.ErrDuplicateReview
+		}
+	}
+	review.ID = len(repo.Reviews) + 1
+	repo.Reviews = append(repo.Reviews, *review)
+	return nil
+}
+
+func (repo *InMemoryReviewRepo) GetReviewByID(id int) (*model.DoctorReview, error) {
+	for _, review := range repo.Reviews {
+		if review.ID == id {
+			return &review, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (repo *InMemoryReviewRepo) GetReviewByConsultation(consultationID int) (*model.DoctorReview, error) {
+	for _, review := range repo.Reviews {
+		if review.ConsultationID == consultationID {
+			return &review, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (repo *InMemoryReviewRepo) GetReviews(opts model.QueryOptions) ([]model.DoctorReview, model.PageInfo, error) {
+	return repo.Reviews, model.NewPageInfo(opts, int64(len(repo.Reviews))), nil
+}
+
+func (repo *InMemoryReviewRepo) GetReviewsByDoctor(doctorID int, visibleOnly bool, opts model.QueryOptions) ([]model.DoctorReview, model.PageInfo, error) {
+	var reviews []model.DoctorReview
+	for _, review := range repo.Reviews {
+		if review.DoctorID != doctorID || (visibleOnly && review.Status != model.ReviewStatusVisible) {
+			continue
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, model.NewPageInfo(opts, int64(len(reviews))), nil
+}
+
+func (repo *InMemoryReviewRepo) UpdateReview(review *model.DoctorReview) error {
+	for i := range repo.Reviews {
+		if repo.Reviews[i].ID == review.ID {
+			repo.Reviews[i] = *review
+		}
+	}
+	return nil
+}
+
+func newReviewRepo() *InMemoryReviewRepo {
+	return &InMemoryReviewRepo{Consultations: []model.Consultation{
+		{ID: 1, UserID: 10, DoctorID: 20, Status: "completed"},
+		{ID: 2, UserID: 10, DoctorID: 20, Status: "approved"},
+		{ID: 3, UserID: 11, DoctorID: 20, Status: "expired", PaymentStatus: "paid", IsApproved: true},
+		{ID: 4, UserID: 10, DoctorID: 20, Status: "expired"},
+		{ID: 5, UserID: 10, DoctorID: 20, Status: "expired", PaymentStatus: "failed"},
+	}}
+}
+
+func TestCreateReview(t *testing.T) {
+	repo := newReviewRepo()
+	usecase := NewReviewUsecase(repo)
+
+	review, err := usecase.CreateReview(10, 1, 5, "  Sangat membantu  ")
+	assert.NoError(t, err)
+	assert.Equal(t, 20, review.DoctorID)
+	assert.Equal(t, "Sangat membantu", review.Comment)
+	assert.Equal(t, model.ReviewStatusVisible, review.Status)
+
+	_, err = usecase.CreateReview(10, 1, 4, "")
+	assert.ErrorIs(t, err, ErrAlreadyReviewed)
+}
+
+// Pemeriksaan ulasan lama terlewat karena dua request datang bersamaan, index unik yang menolak
+type racingReviewRepo struct {
+	*InMemoryReviewRepo
+}
+
+func (repo racingReviewRepo) GetReviewByConsultation(consultationID int) (*model.DoctorReview, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestCreateReview_ConcurrentDuplicate(t *testing.T) {
+	usecase := NewReviewUsecase(racingReviewRepo{newReviewRepo()})
+
+	_, err := usecase.CreateReview(10, 1, 5, "")
+	assert.NoError(t, err)
+	_, err = usecase.CreateReview(10, 1, 4, "")
+	assert.ErrorIs(t, err, ErrAlreadyReviewed)
+}
+
+func TestCreateReview_Rejected(t *testing.T) {
+	usecase := NewReviewUsecase(newReviewRepo())
+
+	_, err := usecase.CreateReview(10, 1, 0, "")
+	assert.ErrorIs(t, err, ErrInvalidRating)
+
+	_, err = usecase.CreateReview(10, 1, 6, "")
+	assert.ErrorIs(t, err, ErrInvalidRating)
+
+	_, err = usecase.CreateReview(10, 2, 5, "")
+	assert.ErrorIs(t, err, ErrNotReviewable)
+
+	// Pemesanan yang kedaluwarsa karena tidak dibayar tidak boleh diulas
+	_, err = usecase.CreateReview(10, 4, 1, "")
+	assert.ErrorIs(t, err, ErrNotReviewable)
+
+	_, err = usecase.CreateReview(10, 5, 1, "")
+	assert.ErrorIs(t, err, ErrNotReviewable)
+
+	// Konsultasi milik user lain dianggap tidak ada
+	_, err = usecase.CreateReview(10, 3, 5, "")
+	assert.ErrorIs(t, err, ErrConsultationNotFound)
+
+	_, err = usecase.CreateReview(10, 99, 5, "")
+	assert.ErrorIs(t, err, ErrConsultationNotFound)
+}
+
+func TestReplyReview(t *testing.T) {
+	repo := newReviewRepo()
+	usecase := NewReviewUsecase(repo)
+	review, _ := usecase.CreateReview(11, 3, 3, "Cukup")
+
+	_, err := usecase.ReplyReview(21, review.ID, "Terima kasih")
+	assert.ErrorIs(t, err, ErrReviewNotFound)
+
+	_, err = usecase.ReplyReview(20, review.ID, "   ")
+	assert.ErrorIs(t, err, ErrEmptyReply)
+
+	replied, err := usecase.ReplyReview(20, review.ID, "Terima kasih atas masukannya")
+	assert.NoError(t, err)
+	assert.Equal(t, "Terima kasih atas masukannya", replied.Reply)
+	assert.NotNil(t, replied.RepliedAt)
+}
+
+func TestModerateReview(t *testing.T) {
+	repo := newReviewRepo()
+	usecase := NewReviewUsecase(repo)
+	review, _ := usecase.CreateReview(10, 1, 1, "Kata-kata kasar")
+
+	_, err := usecase.ModerateReview(1, review.ID, "deleted", "")
+	assert.ErrorIs(t, err, ErrInvalidReviewStatus)
+
+	hidden, err := usecase.ModerateReview(1, review.ID, model.ReviewStatusHidden, "Melanggar pedoman")
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReviewStatusHidden, hidden.Status)
+
+	// Ulasan tersembunyi tidak tampil untuk user tetapi tetap terlihat oleh dokter
+	public, page, err := usecase.GetDoctorReviews(20, model.QueryOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, public)
+	assert.Equal(t, int64(0), page.Total)
+
+	own, _, err := usecase.GetReviewsForDoctor(20, model.QueryOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, own, 1)
+
+	_, err = usecase.ModerateReview(1, review.ID, model.ReviewStatusVisible, "Ditinjau ulang")
+	assert.NoError(t, err)
+	public, _, _ = usecase.GetDoctorReviews(20, model.QueryOptions{})
+	assert.Len(t, public, 1)
+	assert.Empty(t, public[0].ModerationNote)
+	assert.Nil(t, public[0].ModeratedBy)
+}
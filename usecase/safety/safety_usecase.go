@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"calmind/model"
+	repository "calmind/repository/safety"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Jumlah log percakapan yang ditampilkan bersama peringatan
+const conversationContextSize = 10
+
+var (
+	ErrAlertNotFound      = errors.New("peringatan tidak ditemukan")
+	ErrInvalidAlertStatus = errors.New("status peringatan harus 'acknowledged' atau 'resolved'")
+	ErrAlertResolved      = errors.New("peringatan sudah diselesaikan")
+)
+
+type SafetyAlertDTO struct {
+	model.SafetyAlert
+	User         model.UserDTO   `json:"user"`
+	Conversation []model.ChatLog `json:"conversation,omitempty"`
+}
+
+type SafetyAlertUsecase interface {
+	GetAlerts(opts model.QueryOptions) ([]SafetyAlertDTO, model.PageInfo, error)
+	GetAlert(id int) (*SafetyAlertDTO, error)
+	UpdateStatus(adminID, id int, status, note string) (*SafetyAlertDTO, error)
+}
+
+type SafetyAlertUsecaseImpl struct {
+	Repo repository.SafetyAlertRepository
+}
+
+func NewSafetyAlertUsecase(repo repository.SafetyAlertRepository) SafetyAlertUsecase {
+	return &SafetyAlertUsecaseImpl{Repo: repo}
+}
+
+func (u *SafetyAlertUsecaseImpl) GetAlerts(opts model.QueryOptions) ([]SafetyAlertDTO, model.PageInfo, error) {
+	alerts, page, err := u.Repo.GetAlerts(opts)
+	if err != nil {
+		return nil, model.PageInfo{}, fmt.Errorf("failed to fetch safety alerts: %w", err)
+	}
+
+	result := make([]SafetyAlertDTO, 0, len(alerts))
+	for _, alert := range alerts {
+		result = append(result, toSafetyAlertDTO(alert))
+	}
+	return result, page, nil
+}
+
+// Detail peringatan beserta potongan percakapan sebelumnya
+func (u *SafetyAlertUsecaseImpl) GetAlert(id int) (*SafetyAlertDTO, error) {
+	alert, err := u.Repo.GetAlertByID(id)
+	if err != nil {
+		return nil, ErrAlertNotFound
+	}
+
+	dto := toSafetyAlertDTO(*alert)
+	if alert.SessionID > 0 && alert.ChatLogID > 0 {
+		conversation, err := u.Repo.GetConversation(alert.SessionID, alert.ChatLogID, conversationContextSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch conversation: %v", err)
+		}
+		dto.Conversation = conversation
+	}
+	return &dto, nil
+}
+
+func (u *SafetyAlertUsecaseImpl) UpdateStatus(adminID, id int, status, note string) (*SafetyAlertDTO, error) {
+	if status != model.SafetyAlertStatusAcknowledged && status != model.SafetyAlertStatusResolved {
+		return nil, ErrInvalidAlertStatus
+	}
+
+	alert, err := u.Repo.GetAlertByID(id)
+	if err != nil {
+		return nil, ErrAlertNotFound
+	}
+	if alert.Status == model.SafetyAlertStatusResolved {
+		return nil, ErrAlertResolved
+	}
+
+	alert.Status = status
+	alert.HandledBy = &adminID
+	if note != "" {
+		alert.Note = note
+	}
+	if status == model.SafetyAlertStatusResolved {
+		now := time.Now()
+		alert.ResolvedAt = &now
+	}
+	if err := u.Repo.UpdateAlert(alert); err != nil {
+		return nil, fmt.Errorf("failed to update safety alert: %v", err)
+	}
+
+	dto := toSafetyAlertDTO(*alert)
+	return &dto, nil
+}
+
+func toSafetyAlertDTO(alert model.SafetyAlert) SafetyAlertDTO {
+	return SafetyAlertDTO{
+		SafetyAlert: alert,
+		User: model.UserDTO{
+			Avatar:       alert.User.Avatar,
+			Username:     alert.User.Username,
+			Email:        alert.User.Email,
+			JenisKelamin: alert.User.JenisKelamin,
+			NoHp:         alert.User.NoHp,
+			TglLahir:     alert.User.TglLahir,
+			Pekerjaan:    alert.User.Pekerjaan,
+		},
+	}
+}
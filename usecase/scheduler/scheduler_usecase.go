@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"calmind/model"
+	repository "calmind/repository/scheduler"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Jenis job terjadwal
+const (
+	JobConsultationExpire         = "consultation_expire"
+	JobConsultationReminder       = "consultation_reminder" // Pengingat untuk user
+	JobConsultationDoctorReminder = "consultation_doctor_reminder"
+	JobPaymentTimeout             = "payment_timeout"
+	JobAuthTokenCleanup           = "auth_token_cleanup"
+	JobTicketAnswerEmail          = "ticket_answer_email"
+)
+
+const (
+	defaultPollInterval = 15 * time.Second
+	defaultLockTimeout  = 5 * time.Minute
+	defaultBatchSize    = 20
+	baseRetryDelay      = 30 * time.Second
+	maxRetryDelay       = 30 * time.Minute
+)
+
+// Handler untuk satu jenis job. Error yang dikembalikan membuat job dicoba ulang.
+type JobHandler func(job *model.ScheduledJob) error
+
+type SchedulerUsecase interface {
+	Schedule(jobType, uniqueKey string, runAt time.Time, payload interface{}) error
+	Cancel(uniqueKey string) error
+	RegisterHandler(jobType string, handler JobHandler)
+	RunDueJobs() (int, error)
+	Start()
+}
+
+type SchedulerUsecaseImpl struct {
+	Repo         repository.SchedulerRepository
+	WorkerID     string
+	PollInterval time.Duration
+	LockTimeout  time.Duration
+	BatchSize    int
+	Now          func() time.Time
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+}
+
+func NewSchedulerUsecase(repo repository.SchedulerRepository) SchedulerUsecase {
+	hostname, _ := os.Hostname()
+	return &SchedulerUsecaseImpl{
+		Repo:         repo,
+		WorkerID:     fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		PollInterval: defaultPollInterval,
+		LockTimeout:  defaultLockTimeout,
+		BatchSize:    defaultBatchSize,
+		Now:          time.Now,
+		handlers:     map[string]JobHandler{},
+	}
+}
+
+// Menjadwalkan job. Job dengan uniqueKey yang sama akan dijadwalkan ulang, bukan diduplikasi.
+func (u *SchedulerUsecaseImpl) Schedule(jobType, uniqueKey string, runAt time.Time, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode job payload: %v", err)
+	}
+
+	job := &model.ScheduledJob{
+		Type:        jobType,
+		Payload:     string(data),
+		Status:      model.JobStatusPending,
+		RunAt:       runAt,
+		MaxAttempts: 5,
+	}
+	if uniqueKey != "" {
+		job.UniqueKey = &uniqueKey
+	}
+
+	if err := u.Repo.UpsertJob(job); err != nil {
+		return fmt.Errorf("failed to schedule job %s: %v", jobType, err)
+	}
+	return nil
+}
+
+func (u *SchedulerUsecaseImpl) Cancel(uniqueKey string) error {
+	return u.Repo.CancelJob(uniqueKey)
+}
+
+func (u *SchedulerUsecaseImpl) RegisterHandler(jobType string, handler JobHandler) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.handlers[jobType] = handler
+}
+
+// Menjalankan semua job yang sudah jatuh tempo, mengembalikan jumlah job yang diproses
+func (u *SchedulerUsecaseImpl) RunDueJobs() (int, error) {
+	now := u.Now()
+	jobs, err := u.Repo.ClaimDueJobs(u.WorkerID, now, now.Add(-u.LockTimeout), u.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim jobs: %v", err)
+	}
+
+	for i := range jobs {
+		u.runJob(&jobs[i])
+	}
+	return len(jobs), nil
+}
+
+// Menjalankan worker di background
+func (u *SchedulerUsecaseImpl) Start() {
+	go func() {
+		ticker := time.NewTicker(u.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			// Terus ambil batch berikutnya selama masih ada job yang jatuh tempo
+			for {
+				processed, err := u.RunDueJobs()
+				if err != nil {
+					log.Printf("Scheduler error: %v", err)
+					break
+				}
+				if processed < u.BatchSize {
+					break
+				}
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+func (u *SchedulerUsecaseImpl) runJob(job *model.ScheduledJob) {
+	u.mu.RLock()
+	handler, ok := u.handlers[job.Type]
+	u.mu.RUnlock()
+
+	var err error
+	if !ok {
+		err = fmt.Errorf("no handler registered for job type %s", job.Type)
+	} else {
+		err = safeRun(handler, job)
+	}
+
+	if err == nil {
+		if markErr := u.Repo.MarkDone(job.ID, u.WorkerID); markErr != nil {
+			log.Printf("Failed to mark job %d as done: %v", job.ID, markErr)
+		}
+		return
+	}
+
+	log.Printf("Job %d (%s) attempt %d failed: %v", job.ID, job.Type, job.Attempts, err)
+	if job.Attempts >= job.MaxAttempts {
+		if markErr := u.Repo.MarkFailed(job.ID, u.WorkerID, err.Error()); markErr != nil {
+			log.Printf("Failed to mark job %d as failed: %v", job.ID, markErr)
+		}
+		return
+	}
+
+	if markErr := u.Repo.MarkRetry(job.ID, u.WorkerID, u.Now().Add(RetryDelay(job.Attempts)), err.Error()); markErr != nil {
+		log.Printf("Failed to reschedule job %d: %v", job.ID, markErr)
+	}
+}
+
+// Handler yang panic diperlakukan sebagai kegagalan agar worker tetap berjalan
+func safeRun(handler JobHandler, job *model.ScheduledJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(job)
+}
+
+// Exponential backoff: 30 detik, 1 menit, 2 menit, ... maksimal 30 menit
+func RetryDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := baseRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+// Membuat unique_key standar untuk job milik satu entitas, contoh: consultation_expire:12
+func JobKey(jobType string, id int) string {
+	return fmt.Sprintf("%s:%d", jobType, id)
+}
+
+// Membuat unique_key untuk satu putaran job berulang, contoh: auth_token_cleanup:2024-12-01
+func JobDateKey(jobType string, day time.Time) string {
+	return fmt.Sprintf("%s:%s", jobType, day.Format("2006-01-02"))
+}
+
+// Membaca payload JSON job ke dalam target
+func DecodePayload(job *model.ScheduledJob, target interface{}) error {
+	if err := json.Unmarshal([]byte(job.Payload), target); err != nil {
+		return fmt.Errorf("invalid payload for job %d: %v", job.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"calmind/model"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// In-memory implementation of the scheduler repository for testing.
+type InMemorySchedulerRepo struct {
+	Jobs map[int]*model.ScheduledJob
+}
+
+func (m *InMemorySchedulerRepo) UpsertJob(job *model.ScheduledJob) error {
+	job.ID = len(m.Jobs) + 1
+	m.Jobs[job.ID] = job
+	return nil
+}
+
+func (m *InMemorySchedulerRepo) CancelJob(uniqueKey string) error {
+	return nil
+}
+
+func (m *InMemorySchedulerRepo) ClaimDueJobs(workerID string, now, staleBefore time.Time, limit int) ([]model.ScheduledJob, error) {
+	var jobs []model.ScheduledJob
+	for _, job := range m.Jobs {
+		if job.Status == model.JobStatusPending && !job.RunAt.After(now) {
+			job.Status = model.JobStatusRunning
+			job.Attempts++
+			job.LockedBy = workerID
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *InMemorySchedulerRepo) MarkDone(id int, workerID string) error {
+	m.Jobs[id].Status = model.JobStatusDone
+	return nil
+}
+
+func (m *InMemorySchedulerRepo) MarkRetry(id int, workerID string, runAt time.Time, lastError string) error {
+	m.Jobs[id].Status = model.JobStatusPending
+	m.Jobs[id].RunAt = runAt
+	m.Jobs[id].LastError = lastError
+	return nil
+}
+
+func (m *InMemorySchedulerRepo) MarkFailed(id int, workerID string, lastError string) error {
+	m.Jobs[id].Status = model.JobStatusFailed
+	m.Jobs[id].LastError = lastError
+	return nil
+}
+
+func newTestScheduler(now time.Time) (*SchedulerUsecaseImpl, *InMemorySchedulerRepo) {
+	repo := &InMemorySchedulerRepo{Jobs: map[int]*model.ScheduledJob{}}
+	scheduler := NewSchedulerUsecase(repo).(*SchedulerUsecaseImpl)
+	scheduler.Now = func() time.Time { return now }
+	return scheduler, repo
+}
+
+func TestRunDueJobs_Success(t *testing.T) {
+	now := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	scheduler, repo := newTestScheduler(now)
+
+	var received struct {
+		ConsultationID int `json:"consultation_id"`
+	}
+	scheduler.RegisterHandler(JobConsultationExpire, func(job *model.ScheduledJob) error {
+		return DecodePayload(job, &received)
+	})
+
+	assert.NoError(t, scheduler.Schedule(JobConsultationExpire, "consultation_expire:7", now.Add(-time.Minute), map[string]int{"consultation_id": 7}))
+	assert.NoError(t, scheduler.Schedule(JobConsultationExpire, "consultation_expire:8", now.Add(time.Hour), map[string]int{"consultation_id": 8}))
+
+	processed, err := scheduler.RunDueJobs()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed, "Hanya job yang jatuh tempo yang dijalankan")
+	assert.Equal(t, 7, received.ConsultationID)
+	assert.Equal(t, model.JobStatusDone, repo.Jobs[1].Status)
+	assert.Equal(t, model.JobStatusPending, repo.Jobs[2].Status)
+}
+
+func TestRunDueJobs_RetryThenFail(t *testing.T) {
+	now := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	scheduler, repo := newTestScheduler(now)
+	scheduler.RegisterHandler(JobPaymentTimeout, func(job *model.ScheduledJob) error {
+		return errors.New("database unavailable")
+	})
+
+	assert.NoError(t, scheduler.Schedule(JobPaymentTimeout, "", now, nil))
+
+	_, err := scheduler.RunDueJobs()
+	assert.NoError(t, err)
+	assert.Equal(t, model.JobStatusPending, repo.Jobs[1].Status)
+	assert.Equal(t, now.Add(30*time.Second), repo.Jobs[1].RunAt, "Percobaan pertama diulang setelah 30 detik")
+
+	// Habiskan sisa percobaan
+	for i := 0; i < 4; i++ {
+		repo.Jobs[1].RunAt = now
+		_, err = scheduler.RunDueJobs()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, model.JobStatusFailed, repo.Jobs[1].Status)
+	assert.Equal(t, "database unavailable", repo.Jobs[1].LastError)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, RetryDelay(1))
+	assert.Equal(t, 2*time.Minute, RetryDelay(3))
+	assert.Equal(t, 30*time.Minute, RetryDelay(20))
+}
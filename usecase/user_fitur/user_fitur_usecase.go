@@ -3,54 +3,85 @@ package usecase
 import (
 	"calmind/model"
 	repository "calmind/repository/user_fitur"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
+var ErrInvalidDoctorSearch = errors.New("kriteria pencarian dokter tidak valid")
+
 type UserFiturUsecase interface {
-	GetAllDoctors() ([]model.Doctor, error)
-	GetDoctorsByTag(tag string) ([]model.Doctor, error)
-	GetDoctorsByStatus(isActive bool) ([]model.Doctor, error)
-	SearchDoctors(query string) ([]model.Doctor, error)
+	GetAllDoctors(opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error)
+	GetDoctorsByTag(tag string, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error)
+	GetDoctorsByStatus(isActive bool, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error)
+	SearchDoctors(filter repository.DoctorSearchFilter, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error)
 	GetDoctorByID(id int) (*model.Doctor, error)
 	GetAllTags() ([]model.Tags, error)
 	GetAllTitles() ([]model.Title, error)
-	GetDoctorsByTitle(title string) ([]model.Doctor, error)
+	GetDoctorsByTitle(title string, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error)
 }
 
 type UserFiturUsecaseImpl struct {
 	DoctorRepo repository.UserFiturRepository
+	Now        func() time.Time
 }
 
 func NewUserFiturUsecase(repo repository.UserFiturRepository) UserFiturUsecase {
-	return &UserFiturUsecaseImpl{DoctorRepo: repo}
+	return &UserFiturUsecaseImpl{DoctorRepo: repo, Now: time.Now}
 }
 
 // Mendapatkan semua dokter
-func (u *UserFiturUsecaseImpl) GetAllDoctors() ([]model.Doctor, error) {
-	doctors, err := u.DoctorRepo.GetAllDoctors()
-	if err != nil {
-		return nil, err
-	}
-	return doctors, nil
+func (u *UserFiturUsecaseImpl) GetAllDoctors(opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
+	return u.DoctorRepo.GetAllDoctors(opts)
 }
 
 // Mendapatkan dokter berdasarkan tag
-func (u *UserFiturUsecaseImpl) GetDoctorsByTag(tag string) ([]model.Doctor, error) {
-	doctors, err := u.DoctorRepo.GetDoctorsByTag(tag)
+func (u *UserFiturUsecaseImpl) GetDoctorsByTag(tag string, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
+	doctors, page, err := u.DoctorRepo.GetDoctorsByTag(tag, opts)
 	if err != nil {
-		return nil, fmt.Errorf("no doctors found for tag '%s': %v", tag, err)
+		return nil, model.PageInfo{}, fmt.Errorf("no doctors found for tag '%s': %w", tag, err)
 	}
-	return doctors, nil
+	return doctors, page, nil
 }
 
 // Mendapatkan dokter berdasarkan status (aktif/tidak aktif)
-func (u *UserFiturUsecaseImpl) GetDoctorsByStatus(isActive bool) ([]model.Doctor, error) {
-	return u.DoctorRepo.GetDoctorsByStatus(isActive)
+func (u *UserFiturUsecaseImpl) GetDoctorsByStatus(isActive bool, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
+	return u.DoctorRepo.GetDoctorsByStatus(isActive, opts)
 }
 
-// Mencari dokter berdasarkan query
-func (u *UserFiturUsecaseImpl) SearchDoctors(query string) ([]model.Doctor, error) {
-	return u.DoctorRepo.SearchDoctors(query)
+// Pencarian dokter gabungan. Default urutan relevansi jika ada teks pencarian, selain itu rating tertinggi.
+func (u *UserFiturUsecaseImpl) SearchDoctors(filter repository.DoctorSearchFilter, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	filter.Title = strings.TrimSpace(filter.Title)
+	filter.Tags = normalizeTags(filter.Tags)
+
+	if filter.MinPrice < 0 || filter.MaxPrice < 0 || filter.MinExperience < 0 || filter.MaxExperience < 0 {
+		return nil, model.PageInfo{}, fmt.Errorf("%w: harga dan pengalaman tidak boleh negatif", ErrInvalidDoctorSearch)
+	}
+	if filter.MaxPrice > 0 && filter.MinPrice > filter.MaxPrice {
+		return nil, model.PageInfo{}, fmt.Errorf("%w: min_price lebih besar dari max_price", ErrInvalidDoctorSearch)
+	}
+	if filter.MaxExperience > 0 && filter.MinExperience > filter.MaxExperience {
+		return nil, model.PageInfo{}, fmt.Errorf("%w: min_experience lebih besar dari max_experience", ErrInvalidDoctorSearch)
+	}
+
+	if filter.AvailableNow {
+		filter.Now = u.Now()
+	}
+	if opts.Sort == "" {
+		if filter.Query != "" {
+			opts.Sort = "-relevance"
+		} else {
+			opts.Sort = "-rating"
+		}
+	}
+
+	doctors, page, err := u.DoctorRepo.SearchDoctors(filter, opts)
+	if err != nil {
+		return nil, model.PageInfo{}, fmt.Errorf("failed to search doctors: %w", err)
+	}
+	return doctors, page, nil
 }
 
 // Mendapatkan dokter berdasarkan ID
@@ -69,10 +100,25 @@ func (u *UserFiturUsecaseImpl) GetAllTitles() ([]model.Title, error) {
 }
 
 // Mendapatkan dokter berdasarkan title
-func (u *UserFiturUsecaseImpl) GetDoctorsByTitle(title string) ([]model.Doctor, error) {
-	doctors, err := u.DoctorRepo.GetDoctorsByTitle(title)
+func (u *UserFiturUsecaseImpl) GetDoctorsByTitle(title string, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
+	doctors, page, err := u.DoctorRepo.GetDoctorsByTitle(title, opts)
 	if err != nil {
-		return nil, fmt.Errorf("no doctors found for title '%s': %v", title, err)
+		return nil, model.PageInfo{}, fmt.Errorf("no doctors found for title '%s': %w", title, err)
+	}
+	return doctors, page, nil
+}
+
+// Menghapus tag kosong dan duplikat
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		result = append(result, tag)
 	}
-	return doctors, nil
+	return result
 }
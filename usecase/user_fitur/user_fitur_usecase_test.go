@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"calmind/model"
+	repository "calmind/repository/user_fitur"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// In-memory implementation of the doctor repository that records the last search.
+type InMemoryUserFiturRepo struct {
+	Doctors    []model.Doctor
+	LastFilter repository.DoctorSearchFilter
+	LastOpts   model.QueryOptions
+}
+
+func (repo *InMemoryUserFiturRepo) GetAllDoctors(opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
+	return repo.Doctors, model.NewPageInfo(opts, int64(len(repo.Doctors))), nil
+}
+
+func (repo *InMemoryUserFiturRepo) GetDoctorsByTag(tag string, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
+	return repo.Doctors, model.NewPageInfo(opts, int64(len(repo.Doctors))), nil
+}
+
+func (repo *InMemoryUserFiturRepo) GetDoctorsByStatus(isActive bool, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
+	return repo.Doctors, model.NewPageInfo(opts, int64(len(repo.Doctors))), nil
+}
+
+func (repo *InMemoryUserFiturRepo) SearchDoctors(filter repository.DoctorSearchFilter, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
+	repo.LastFilter = filter
+	repo.LastOpts = opts
+	return repo.Doctors, model.NewPageInfo(opts, int64(len(repo.Doctors))), nil
+}
+
+func (repo *InMemoryUserFiturRepo) GetDoctorByID(id int) (*model.Doctor, error) {
+	return nil, nil
+}
+
+func (repo *InMemoryUserFiturRepo) GetTags() ([]model.Tags, error) {
+	return nil, nil
+}
+
+func (repo *InMemoryUserFiturRepo) GetTitles() ([]model.Title, error) {
+	return nil, nil
+}
+
+func (repo *InMemoryUserFiturRepo) GetDoctorsByTitle(title string, opts model.QueryOptions) ([]model.Doctor, model.PageInfo, error) {
+	return repo.Doctors, model.NewPageInfo(opts, int64(len(repo.Doctors))), nil
+}
+
+func TestSearchDoctors_Normalizes(t *testing.T) {
+	repo := &InMemoryUserFiturRepo{Doctors: []model.Doctor{{ID: 1}}}
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	usecase := &UserFiturUsecaseImpl{DoctorRepo: repo, Now: func() time.Time { return now }}
+
+	doctors, page, err := usecase.SearchDoctors(repository.DoctorSearchFilter{
+		Query:        "  cemas ",
+		Tags:         []string{"Stres", " stres", "", "Depresi"},
+		AvailableNow: true,
+	}, model.QueryOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, doctors, 1)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, "cemas", repo.LastFilter.Query)
+	assert.Equal(t, []string{"Stres", "Depresi"}, repo.LastFilter.Tags)
+	assert.Equal(t, now, repo.LastFilter.Now)
+	assert.Equal(t, "-relevance", repo.LastOpts.Sort)
+
+	// Tanpa teks pencarian, default urutan rating tertinggi
+	_, _, err = usecase.SearchDoctors(repository.DoctorSearchFilter{}, model.QueryOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "-rating", repo.LastOpts.Sort)
+
+	_, _, err = usecase.SearchDoctors(repository.DoctorSearchFilter{Query: "cemas"}, model.QueryOptions{Sort: "price"})
+	assert.NoError(t, err)
+	assert.Equal(t, "price", repo.LastOpts.Sort)
+}
+
+func TestSearchDoctors_InvalidRange(t *testing.T) {
+	usecase := NewUserFiturUsecase(&InMemoryUserFiturRepo{})
+
+	_, _, err := usecase.SearchDoctors(repository.DoctorSearchFilter{MinPrice: 200000, MaxPrice: 100000}, model.QueryOptions{})
+	assert.ErrorIs(t, err, ErrInvalidDoctorSearch)
+
+	_, _, err = usecase.SearchDoctors(repository.DoctorSearchFilter{MinExperience: 10, MaxExperience: 5}, model.QueryOptions{})
+	assert.ErrorIs(t, err, ErrInvalidDoctorSearch)
+
+	_, _, err = usecase.SearchDoctors(repository.DoctorSearchFilter{MinPrice: -1}, model.QueryOptions{})
+	assert.ErrorIs(t, err, ErrInvalidDoctorSearch)
+}